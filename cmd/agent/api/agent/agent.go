@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"io/ioutil"
 	"net/http"
 	"sort"
 
@@ -41,22 +42,28 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/version", common.GetVersion).Methods("GET")
 	r.HandleFunc("/hostname", getHostname).Methods("GET")
 	r.HandleFunc("/flare", makeFlare).Methods("POST")
+	r.HandleFunc("/flare/providers", getFlareProviders).Methods("GET")
 	r.HandleFunc("/stop", stopAgent).Methods("POST")
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/dogstatsd-stats", getDogstatsdStats).Methods("GET")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
+	r.HandleFunc("/status/document", getStatusDocument).Methods("GET")
+	r.HandleFunc("/status/schema", getStatusSchema).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusHandler).Methods("POST")
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
+	r.HandleFunc("/autodiscovery/test", testTemplates).Methods("POST")
 	r.HandleFunc("/config", getFullRuntimeConfig).Methods("GET")
 	r.HandleFunc("/config/list-runtime", getRuntimeConfigurableSettings).Methods("GET")
+	r.HandleFunc("/config/history", getRuntimeSettingsHistory).Methods("GET")
 	r.HandleFunc("/config/{setting}", getRuntimeConfig).Methods("GET")
 	r.HandleFunc("/config/{setting}", setRuntimeConfig).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
+	r.HandleFunc("/secrets/refresh", secretRefresh).Methods("POST")
 
 	return r
 }
@@ -85,8 +92,25 @@ func makeFlare(w http.ResponseWriter, r *http.Request) {
 		logFile = common.DefaultLogFile
 	}
 
+	var opts flare.ArchiveOptions
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err == nil && len(body) > 0 {
+			if err := json.Unmarshal(body, &opts); err != nil {
+				log.Errorf("Invalid flare options: %s", err)
+				http.Error(w, fmt.Sprintf("invalid flare options: %s", err), 400)
+				return
+			}
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		log.Errorf("Invalid flare options: %s", err)
+		http.Error(w, fmt.Sprintf("invalid flare options: %s", err), 400)
+		return
+	}
+
 	log.Infof("Making a flare")
-	filePath, err := flare.CreateArchive(false, common.GetDistPath(), common.PyChecksPath, logFile)
+	filePath, err := flare.CreateArchiveWithOptions(false, common.GetDistPath(), common.PyChecksPath, logFile, opts)
 	if err != nil || filePath == "" {
 		if err != nil {
 			log.Errorf("The flare failed to be created: %s", err)
@@ -94,10 +118,17 @@ func makeFlare(w http.ResponseWriter, r *http.Request) {
 			log.Warnf("The flare failed to be created")
 		}
 		http.Error(w, err.Error(), 500)
+		return
 	}
 	w.Write([]byte(filePath))
 }
 
+func getFlareProviders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	j, _ := json.Marshal(flare.ProviderNames())
+	w.Write(j)
+}
+
 func componentConfigHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	component := vars["component"]
@@ -159,6 +190,35 @@ func getStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonStats)
 }
 
+func getStatusDocument(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request for the status document.")
+	doc := status.GetDocument()
+	w.Header().Set("Content-Type", "application/json")
+
+	jsonDoc, err := json.Marshal(doc)
+	if err != nil {
+		log.Errorf("Error marshalling status document. Error: %v", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonDoc)
+}
+
+func getStatusSchema(w http.ResponseWriter, r *http.Request) {
+	schema, err := status.DocumentSchema()
+	w.Header().Set("Content-Type", "application/schema+json")
+	if err != nil {
+		log.Errorf("Error generating the status schema. Error: %v", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(schema)
+}
+
 func getDogstatsdStats(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for the Dogstatsd stats.")
 
@@ -274,6 +334,43 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonConfig)
 }
 
+func testTemplates(w http.ResponseWriter, r *http.Request) {
+	if common.AC == nil {
+		log.Errorf("Trying to use /autodiscovery/test before the agent has been initialized.")
+		body, _ := json.Marshal(map[string]string{"error": "agent not initialized"})
+		http.Error(w, string(body), 503)
+		return
+	}
+
+	var req autodiscovery.TemplateTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid request: %s", err)})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	results := make([]autodiscovery.TemplateTestResult, 0, len(req.Templates))
+	for _, tpl := range req.Templates {
+		result, err := common.AC.TestTemplateForEntity(tpl, req.Entity)
+		if err != nil {
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), 404)
+			return
+		}
+		results = append(results, result)
+	}
+
+	jsonResults, err := json.Marshal(results)
+	if err != nil {
+		log.Errorf("Unable to marshal autodiscovery test response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonResults)
+}
+
 func getFullRuntimeConfig(w http.ResponseWriter, r *http.Request) {
 	runtimeConfig, err := yaml.Marshal(config.Datadog.AllSettings())
 	if err != nil {
@@ -339,6 +436,17 @@ func setRuntimeConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getRuntimeSettingsHistory(w http.ResponseWriter, r *http.Request) {
+	body, err := json.Marshal(settings.RuntimeSettingsHistory())
+	if err != nil {
+		log.Errorf("Unable to marshal runtime settings history response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(body)
+}
+
 func getRuntimeConfigurableSettings(w http.ResponseWriter, r *http.Request) {
 
 	configurableSettings := make(map[string]settings.RuntimeSettingResponse)
@@ -360,7 +468,7 @@ func getRuntimeConfigurableSettings(w http.ResponseWriter, r *http.Request) {
 
 func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	// query at the highest cardinality between checks and dogstatsd cardinalities
-	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), int(tagger.DogstatsdCardinality)))
+	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), int(tagger.DogstatsdCardinality())))
 	response := tagger.List(cardinality)
 
 	jsonTags, err := json.Marshal(response)
@@ -391,6 +499,24 @@ func secretInfo(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonInfo)
 }
 
+func secretRefresh(w http.ResponseWriter, r *http.Request) {
+	changed, err := secrets.Refresh()
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	jsonChanged, err := json.Marshal(changed)
+	if err != nil {
+		log.Errorf("Unable to marshal secrets refresh response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(jsonChanged)
+}
+
 // max returns the maximum value between a and b.
 func max(a, b int) int {
 	if a > b {
@@ -25,6 +25,62 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type TagCardinality int32
+
+const (
+	TagCardinality_LOW          TagCardinality = 0
+	TagCardinality_ORCHESTRATOR TagCardinality = 1
+	TagCardinality_HIGH         TagCardinality = 2
+)
+
+var TagCardinality_name = map[int32]string{
+	0: "LOW",
+	1: "ORCHESTRATOR",
+	2: "HIGH",
+}
+
+var TagCardinality_value = map[string]int32{
+	"LOW":          0,
+	"ORCHESTRATOR": 1,
+	"HIGH":         2,
+}
+
+func (x TagCardinality) String() string {
+	return proto.EnumName(TagCardinality_name, int32(x))
+}
+
+func (TagCardinality) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{0}
+}
+
+type EventType int32
+
+const (
+	EventType_ADDED    EventType = 0
+	EventType_MODIFIED EventType = 1
+	EventType_DELETED  EventType = 2
+)
+
+var EventType_name = map[int32]string{
+	0: "ADDED",
+	1: "MODIFIED",
+	2: "DELETED",
+}
+
+var EventType_value = map[string]int32{
+	"ADDED":    0,
+	"MODIFIED": 1,
+	"DELETED":  2,
+}
+
+func (x EventType) String() string {
+	return proto.EnumName(EventType_name, int32(x))
+}
+
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{1}
+}
+
 type HostnameRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -56,7 +112,7 @@ func (m *HostnameRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_HostnameRequest proto.InternalMessageInfo
 
-// The response message containing the greetings
+// The response message containing the requested hostname
 type HostnameReply struct {
 	Hostname             string   `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -96,26 +152,258 @@ func (m *HostnameReply) GetHostname() string {
 	return ""
 }
 
+// The request of the tagger stream, tags above the cardinality aren't sent
+type StreamTagsRequest struct {
+	Cardinality          TagCardinality `protobuf:"varint,1,opt,name=cardinality,proto3,enum=pb.TagCardinality" json:"cardinality,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *StreamTagsRequest) Reset()         { *m = StreamTagsRequest{} }
+func (m *StreamTagsRequest) String() string { return proto.CompactTextString(m) }
+func (*StreamTagsRequest) ProtoMessage()    {}
+func (*StreamTagsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{2}
+}
+
+func (m *StreamTagsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamTagsRequest.Unmarshal(m, b)
+}
+func (m *StreamTagsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamTagsRequest.Marshal(b, m, deterministic)
+}
+func (m *StreamTagsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamTagsRequest.Merge(m, src)
+}
+func (m *StreamTagsRequest) XXX_Size() int {
+	return xxx_messageInfo_StreamTagsRequest.Size(m)
+}
+func (m *StreamTagsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamTagsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamTagsRequest proto.InternalMessageInfo
+
+func (m *StreamTagsRequest) GetCardinality() TagCardinality {
+	if m != nil {
+		return m.Cardinality
+	}
+	return TagCardinality_LOW
+}
+
+type StreamTagsResponse struct {
+	Events               []*StreamTagsEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *StreamTagsResponse) Reset()         { *m = StreamTagsResponse{} }
+func (m *StreamTagsResponse) String() string { return proto.CompactTextString(m) }
+func (*StreamTagsResponse) ProtoMessage()    {}
+func (*StreamTagsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
+}
+
+func (m *StreamTagsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamTagsResponse.Unmarshal(m, b)
+}
+func (m *StreamTagsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamTagsResponse.Marshal(b, m, deterministic)
+}
+func (m *StreamTagsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamTagsResponse.Merge(m, src)
+}
+func (m *StreamTagsResponse) XXX_Size() int {
+	return xxx_messageInfo_StreamTagsResponse.Size(m)
+}
+func (m *StreamTagsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamTagsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamTagsResponse proto.InternalMessageInfo
+
+func (m *StreamTagsResponse) GetEvents() []*StreamTagsEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type StreamTagsEvent struct {
+	Type                 EventType `protobuf:"varint,1,opt,name=type,proto3,enum=pb.EventType" json:"type,omitempty"`
+	Entity               *Entity   `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *StreamTagsEvent) Reset()         { *m = StreamTagsEvent{} }
+func (m *StreamTagsEvent) String() string { return proto.CompactTextString(m) }
+func (*StreamTagsEvent) ProtoMessage()    {}
+func (*StreamTagsEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
+}
+
+func (m *StreamTagsEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamTagsEvent.Unmarshal(m, b)
+}
+func (m *StreamTagsEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamTagsEvent.Marshal(b, m, deterministic)
+}
+func (m *StreamTagsEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamTagsEvent.Merge(m, src)
+}
+func (m *StreamTagsEvent) XXX_Size() int {
+	return xxx_messageInfo_StreamTagsEvent.Size(m)
+}
+func (m *StreamTagsEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamTagsEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamTagsEvent proto.InternalMessageInfo
+
+func (m *StreamTagsEvent) GetType() EventType {
+	if m != nil {
+		return m.Type
+	}
+	return EventType_ADDED
+}
+
+func (m *StreamTagsEvent) GetEntity() *Entity {
+	if m != nil {
+		return m.Entity
+	}
+	return nil
+}
+
+// An entity and its tags, only the id is set for deleted entities
+type Entity struct {
+	Id                          string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Hash                        string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	HighCardinalityTags         []string `protobuf:"bytes,3,rep,name=high_cardinality_tags,json=highCardinalityTags,proto3" json:"high_cardinality_tags,omitempty"`
+	OrchestratorCardinalityTags []string `protobuf:"bytes,4,rep,name=orchestrator_cardinality_tags,json=orchestratorCardinalityTags,proto3" json:"orchestrator_cardinality_tags,omitempty"`
+	LowCardinalityTags          []string `protobuf:"bytes,5,rep,name=low_cardinality_tags,json=lowCardinalityTags,proto3" json:"low_cardinality_tags,omitempty"`
+	StandardTags                []string `protobuf:"bytes,6,rep,name=standard_tags,json=standardTags,proto3" json:"standard_tags,omitempty"`
+	XXX_NoUnkeyedLiteral        struct{} `json:"-"`
+	XXX_unrecognized            []byte   `json:"-"`
+	XXX_sizecache               int32    `json:"-"`
+}
+
+func (m *Entity) Reset()         { *m = Entity{} }
+func (m *Entity) String() string { return proto.CompactTextString(m) }
+func (*Entity) ProtoMessage()    {}
+func (*Entity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
+}
+
+func (m *Entity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Entity.Unmarshal(m, b)
+}
+func (m *Entity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Entity.Marshal(b, m, deterministic)
+}
+func (m *Entity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Entity.Merge(m, src)
+}
+func (m *Entity) XXX_Size() int {
+	return xxx_messageInfo_Entity.Size(m)
+}
+func (m *Entity) XXX_DiscardUnknown() {
+	xxx_messageInfo_Entity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Entity proto.InternalMessageInfo
+
+func (m *Entity) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Entity) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Entity) GetHighCardinalityTags() []string {
+	if m != nil {
+		return m.HighCardinalityTags
+	}
+	return nil
+}
+
+func (m *Entity) GetOrchestratorCardinalityTags() []string {
+	if m != nil {
+		return m.OrchestratorCardinalityTags
+	}
+	return nil
+}
+
+func (m *Entity) GetLowCardinalityTags() []string {
+	if m != nil {
+		return m.LowCardinalityTags
+	}
+	return nil
+}
+
+func (m *Entity) GetStandardTags() []string {
+	if m != nil {
+		return m.StandardTags
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterEnum("pb.TagCardinality", TagCardinality_name, TagCardinality_value)
+	proto.RegisterEnum("pb.EventType", EventType_name, EventType_value)
 	proto.RegisterType((*HostnameRequest)(nil), "pb.HostnameRequest")
 	proto.RegisterType((*HostnameReply)(nil), "pb.HostnameReply")
+	proto.RegisterType((*StreamTagsRequest)(nil), "pb.StreamTagsRequest")
+	proto.RegisterType((*StreamTagsResponse)(nil), "pb.StreamTagsResponse")
+	proto.RegisterType((*StreamTagsEvent)(nil), "pb.StreamTagsEvent")
+	proto.RegisterType((*Entity)(nil), "pb.Entity")
 }
 
 func init() { proto.RegisterFile("api.proto", fileDescriptor_00212fb1f9d3bf1c) }
 
 var fileDescriptor_00212fb1f9d3bf1c = []byte{
-	// 169 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4c, 0x2c, 0xc8, 0xd4,
-	0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2a, 0x48, 0x92, 0x92, 0x49, 0xcf, 0xcf, 0x4f, 0xcf,
-	0x49, 0xd5, 0x4f, 0x2c, 0xc8, 0xd4, 0x4f, 0xcc, 0xcb, 0xcb, 0x2f, 0x49, 0x2c, 0xc9, 0xcc, 0xcf,
-	0x2b, 0x86, 0xa8, 0x50, 0x12, 0xe4, 0xe2, 0xf7, 0xc8, 0x2f, 0x2e, 0xc9, 0x4b, 0xcc, 0x4d, 0x0d,
-	0x4a, 0x2d, 0x2c, 0x4d, 0x2d, 0x2e, 0x51, 0xd2, 0xe6, 0xe2, 0x45, 0x08, 0x15, 0xe4, 0x54, 0x0a,
-	0x49, 0x71, 0x71, 0x64, 0x40, 0x05, 0x24, 0x18, 0x15, 0x18, 0x35, 0x38, 0x83, 0xe0, 0x7c, 0xa3,
-	0x50, 0x2e, 0x56, 0xc7, 0xf4, 0xd4, 0xbc, 0x12, 0x21, 0x1f, 0x2e, 0x6e, 0xf7, 0xd4, 0x12, 0x98,
-	0x46, 0x21, 0x61, 0xbd, 0x82, 0x24, 0x3d, 0x34, 0x93, 0xa5, 0x04, 0x51, 0x05, 0x0b, 0x72, 0x2a,
-	0x95, 0x44, 0x9b, 0x2e, 0x3f, 0x99, 0xcc, 0xc4, 0x2f, 0xc4, 0xab, 0x5f, 0x66, 0xa8, 0x9f, 0x5e,
-	0x54, 0x90, 0xac, 0x0f, 0x32, 0x3a, 0x89, 0x0d, 0xec, 0x3a, 0x63, 0x40, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0xbd, 0x9d, 0x72, 0x5f, 0xcc, 0x00, 0x00, 0x00,
+	// 495 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x65, 0x53, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0x4e, 0x9a, 0xc6, 0xe3, 0xfc, 0x4e, 0x1b, 0x54, 0x05, 0x90, 0xc0, 0x5c, 0xaa, 0x56,
+	0x8a, 0x5b, 0x03, 0x0f, 0x10, 0x6a, 0x93, 0x44, 0x0a, 0x8a, 0xb4, 0xb5, 0x04, 0xb7, 0x6a, 0x93,
+	0xac, 0x6c, 0x4b, 0xa9, 0x6d, 0xec, 0xa5, 0x28, 0x57, 0x5e, 0x81, 0x3b, 0x2f, 0xc5, 0x2b, 0xf0,
+	0x16, 0x5c, 0x58, 0xaf, 0x9d, 0xc6, 0x71, 0x6f, 0xbb, 0xdf, 0xcf, 0x78, 0x76, 0xe6, 0x33, 0x68,
+	0x34, 0x0e, 0x46, 0x71, 0x12, 0xf1, 0x08, 0xd5, 0x78, 0x39, 0x7c, 0xe9, 0x45, 0x91, 0xb7, 0x61,
+	0xa6, 0x40, 0x4d, 0x1a, 0x86, 0x11, 0xa7, 0x3c, 0x88, 0xc2, 0x34, 0x57, 0x18, 0x7d, 0xe8, 0x4e,
+	0xa3, 0x94, 0x87, 0xf4, 0x9e, 0x11, 0xf6, 0xed, 0x3b, 0x4b, 0xb9, 0x71, 0x09, 0xed, 0x3d, 0x14,
+	0x6f, 0xb6, 0x38, 0x84, 0xa6, 0x5f, 0x00, 0x67, 0xca, 0x6b, 0xe5, 0x5c, 0x23, 0x8f, 0x77, 0x63,
+	0x06, 0xfd, 0x5b, 0x9e, 0x30, 0x7a, 0xef, 0x52, 0x2f, 0x2d, 0x2a, 0xe0, 0x7b, 0xd0, 0x57, 0x34,
+	0x59, 0x07, 0x21, 0xdd, 0x04, 0x7c, 0x2b, 0x3d, 0x1d, 0x0b, 0x47, 0xf1, 0x72, 0x24, 0x54, 0x37,
+	0x7b, 0x86, 0x94, 0x65, 0xc6, 0x18, 0xb0, 0x5c, 0x2a, 0x8d, 0x45, 0x97, 0x0c, 0x2f, 0xa1, 0xc1,
+	0x1e, 0x58, 0xc8, 0x53, 0x51, 0xa6, 0x76, 0xae, 0x5b, 0x27, 0x59, 0x99, 0xbd, 0xce, 0xc9, 0x38,
+	0x52, 0x48, 0x8c, 0xaf, 0xd0, 0xad, 0x50, 0xf8, 0x06, 0xea, 0x7c, 0x1b, 0xb3, 0xa2, 0x89, 0x76,
+	0xe6, 0x96, 0x84, 0x2b, 0x40, 0x22, 0x29, 0x34, 0xc4, 0x27, 0x42, 0x9e, 0x75, 0xaa, 0x0a, 0x91,
+	0x6e, 0x81, 0x14, 0x49, 0x84, 0x14, 0x8c, 0xf1, 0x4f, 0x81, 0x46, 0x0e, 0x61, 0x07, 0xd4, 0x60,
+	0x5d, 0x0c, 0x42, 0x9c, 0x10, 0xa1, 0xee, 0xd3, 0xd4, 0x97, 0x66, 0x8d, 0xc8, 0x33, 0x5a, 0x30,
+	0xf0, 0x03, 0xcf, 0xbf, 0x2b, 0xbd, 0xef, 0x8e, 0x8b, 0x9e, 0xce, 0x6a, 0xe2, 0x11, 0x1a, 0x39,
+	0xc9, 0xc8, 0xd2, 0x20, 0xb2, 0x76, 0xf1, 0x23, 0xbc, 0x8a, 0x92, 0x95, 0x2f, 0x06, 0x98, 0x50,
+	0x1e, 0x25, 0x4f, 0xbd, 0x75, 0xe9, 0x7d, 0x51, 0x16, 0x55, 0x6b, 0x5c, 0xc1, 0xe9, 0x26, 0xfa,
+	0xf1, 0xd4, 0x7a, 0x24, 0xad, 0x28, 0xb8, 0xaa, 0xe3, 0x2d, 0xb4, 0x53, 0x4e, 0xc3, 0xb5, 0x80,
+	0x73, 0x69, 0x43, 0x4a, 0x5b, 0x3b, 0x30, 0x13, 0x5d, 0x7c, 0x80, 0xce, 0xe1, 0xe6, 0xf0, 0x18,
+	0x6a, 0xf3, 0xc5, 0x97, 0xde, 0x33, 0xec, 0x41, 0x6b, 0x41, 0x6e, 0xa6, 0xce, 0xad, 0x4b, 0xc6,
+	0xee, 0x82, 0xf4, 0x14, 0x6c, 0x42, 0x7d, 0x3a, 0x9b, 0x4c, 0x7b, 0xea, 0xc5, 0x35, 0x68, 0x8f,
+	0xb3, 0x46, 0x0d, 0x8e, 0xc6, 0xb6, 0xed, 0xd8, 0xc2, 0xd3, 0x82, 0xe6, 0xe7, 0x85, 0x3d, 0xfb,
+	0x34, 0x13, 0x37, 0x05, 0x75, 0x38, 0xb6, 0x9d, 0xb9, 0xe3, 0x8a, 0x8b, 0x6a, 0xfd, 0x56, 0x84,
+	0xcc, 0xcb, 0x16, 0x37, 0x07, 0x7d, 0xc2, 0xf8, 0x2e, 0x89, 0x28, 0xf7, 0x5e, 0x89, 0xea, 0xb0,
+	0x7f, 0x08, 0x8a, 0xb0, 0x1a, 0x83, 0x9f, 0x7f, 0xfe, 0xfe, 0x52, 0xbb, 0xd8, 0x36, 0x1f, 0xae,
+	0x4d, 0x2f, 0x89, 0x57, 0x66, 0x96, 0x55, 0x9c, 0xc0, 0xa9, 0x78, 0x81, 0xc7, 0x92, 0x3c, 0x1f,
+	0x72, 0x95, 0x01, 0x4b, 0x71, 0x70, 0x18, 0xa7, 0x5d, 0xe1, 0xe7, 0x55, 0x38, 0x4f, 0xe3, 0x95,
+	0xb2, 0x6c, 0xc8, 0xff, 0xe6, 0xdd, 0x7f, 0x3b, 0x94, 0x17, 0xfa, 0x66, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -132,6 +420,9 @@ const _ = grpc.SupportPackageIsVersion4
 type AgentClient interface {
 	// get the hostname
 	GetHostname(ctx context.Context, in *HostnameRequest, opts ...grpc.CallOption) (*HostnameReply, error)
+	// stream the entities of the tagger: the first response holds all the
+	// known entities, the next ones the added, modified and deleted entities
+	TaggerStreamEntities(ctx context.Context, in *StreamTagsRequest, opts ...grpc.CallOption) (Agent_TaggerStreamEntitiesClient, error)
 }
 
 type agentClient struct {
@@ -151,10 +442,45 @@ func (c *agentClient) GetHostname(ctx context.Context, in *HostnameRequest, opts
 	return out, nil
 }
 
+func (c *agentClient) TaggerStreamEntities(ctx context.Context, in *StreamTagsRequest, opts ...grpc.CallOption) (Agent_TaggerStreamEntitiesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Agent_serviceDesc.Streams[0], "/pb.Agent/TaggerStreamEntities", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &agentTaggerStreamEntitiesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Agent_TaggerStreamEntitiesClient interface {
+	Recv() (*StreamTagsResponse, error)
+	grpc.ClientStream
+}
+
+type agentTaggerStreamEntitiesClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentTaggerStreamEntitiesClient) Recv() (*StreamTagsResponse, error) {
+	m := new(StreamTagsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // AgentServer is the server API for Agent service.
 type AgentServer interface {
 	// get the hostname
 	GetHostname(context.Context, *HostnameRequest) (*HostnameReply, error)
+	// stream the entities of the tagger: the first response holds all the
+	// known entities, the next ones the added, modified and deleted entities
+	TaggerStreamEntities(*StreamTagsRequest, Agent_TaggerStreamEntitiesServer) error
 }
 
 // UnimplementedAgentServer can be embedded to have forward compatible implementations.
@@ -164,6 +490,9 @@ type UnimplementedAgentServer struct {
 func (*UnimplementedAgentServer) GetHostname(ctx context.Context, req *HostnameRequest) (*HostnameReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHostname not implemented")
 }
+func (*UnimplementedAgentServer) TaggerStreamEntities(req *StreamTagsRequest, srv Agent_TaggerStreamEntitiesServer) error {
+	return status.Errorf(codes.Unimplemented, "method TaggerStreamEntities not implemented")
+}
 
 func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
 	s.RegisterService(&_Agent_serviceDesc, srv)
@@ -187,6 +516,27 @@ func _Agent_GetHostname_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Agent_TaggerStreamEntities_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTagsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).TaggerStreamEntities(m, &agentTaggerStreamEntitiesServer{stream})
+}
+
+type Agent_TaggerStreamEntitiesServer interface {
+	Send(*StreamTagsResponse) error
+	grpc.ServerStream
+}
+
+type agentTaggerStreamEntitiesServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentTaggerStreamEntitiesServer) Send(m *StreamTagsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _Agent_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Agent",
 	HandlerType: (*AgentServer)(nil),
@@ -196,6 +546,12 @@ var _Agent_serviceDesc = grpc.ServiceDesc{
 			Handler:    _Agent_GetHostname_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TaggerStreamEntities",
+			Handler:       _Agent_TaggerStreamEntities_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "api.proto",
 }
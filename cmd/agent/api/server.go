@@ -29,6 +29,7 @@ import (
 	pb "github.com/DataDog/datadog-agent/cmd/agent/api/pb"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	taggerserver "github.com/DataDog/datadog-agent/pkg/tagger/server"
 	hostutil "github.com/DataDog/datadog-agent/pkg/util"
 	gorilla "github.com/gorilla/mux"
 )
@@ -39,6 +40,7 @@ var (
 
 type server struct {
 	pb.UnimplementedAgentServer
+	taggerServer *taggerserver.Server
 }
 
 func (s *server) GetHostname(ctx context.Context, in *pb.HostnameRequest) (*pb.HostnameReply, error) {
@@ -49,6 +51,19 @@ func (s *server) GetHostname(ctx context.Context, in *pb.HostnameRequest) (*pb.H
 	return &pb.HostnameReply{Hostname: h}, nil
 }
 
+func (s *server) TaggerStreamEntities(in *pb.StreamTagsRequest, out pb.Agent_TaggerStreamEntitiesServer) error {
+	return s.taggerServer.TaggerStreamEntities(in, out)
+}
+
+// authStreamInterceptor validates the session token of the streaming calls,
+// which are only used by the other agents
+func authStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := util.ValidateGRPCRequest(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
 // grpcHandlerFunc returns an http.Handler that delegates to grpcServer on incoming gRPC
 // connections or otherHandler otherwise. Copied from cockroachdb.
 func grpcHandlerFunc(grpcServer *grpc.Server, otherHandler http.Handler) http.Handler {
@@ -85,10 +100,12 @@ func StartServer() error {
 	// gRPC server
 	mux := http.NewServeMux()
 	opts := []grpc.ServerOption{
-		grpc.Creds(credentials.NewClientTLSFromCert(tlsCertPool, tlsAddr))}
+		grpc.Creds(credentials.NewClientTLSFromCert(tlsCertPool, tlsAddr)),
+		grpc.StreamInterceptor(authStreamInterceptor),
+	}
 
 	s := grpc.NewServer(opts...)
-	pb.RegisterAgentServer(s, &server{})
+	pb.RegisterAgentServer(s, &server{taggerServer: taggerserver.NewServer()})
 
 	dcreds := credentials.NewTLS(&tls.Config{
 		ServerName: tlsAddr,
@@ -117,9 +134,14 @@ func StartServer() error {
 	mux.Handle("/check/", http.StripPrefix("/check", check.SetupHandlers(checkMux)))
 	mux.Handle("/", gwmux)
 
+	// The timeout only applies to the HTTP handlers, as a server WriteTimeout
+	// would also reset the gRPC streams of the remote taggers
+	timeout := config.Datadog.GetDuration("server_timeout") * time.Second
+	httpHandler := http.TimeoutHandler(mux, timeout, "Timeout while handling the request")
+
 	srv := &http.Server{
 		Addr:    tlsAddr,
-		Handler: grpcHandlerFunc(s, mux),
+		Handler: grpcHandlerFunc(s, httpHandler),
 		// Handler: grpcHandlerFunc(s, r),
 		TLSConfig: &tls.Config{
 			Certificates: []tls.Certificate{*tlsKeyPair},
@@ -128,7 +150,6 @@ func StartServer() error {
 		ErrorLog: stdLog.New(&config.ErrorLogWriter{
 			AdditionalDepth: 4, // Use a stack depth of 4 on top of the default one to get a relevant filename in the stdlib
 		}, "Error from the agent http API server: ", 0), // log errors to seelog,
-		WriteTimeout: config.Datadog.GetDuration("server_timeout") * time.Second,
 	}
 
 	tlsListener := tls.NewListener(listener, srv.TLSConfig)
@@ -0,0 +1,250 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	adTestEntity      string
+	adTestServiceFile string
+	adTestCheckName   string
+	adTestAnnotations bool
+)
+
+func init() {
+	AgentCmd.AddCommand(autodiscoveryCommand)
+	autodiscoveryCommand.AddCommand(autodiscoveryTestCommand)
+
+	autodiscoveryTestCommand.Flags().StringVarP(&adTestEntity, "entity", "e", "", "entity of a service discovered by the running agent, e.g. docker://<container id>")
+	autodiscoveryTestCommand.Flags().StringVarP(&adTestServiceFile, "service", "s", "", "YAML file describing a mocked service")
+	autodiscoveryTestCommand.Flags().StringVarP(&adTestCheckName, "check", "c", "", "name of the check of the template file, defaults to the name of its conf.d folder")
+	autodiscoveryTestCommand.Flags().BoolVarP(&adTestAnnotations, "annotations", "a", false, "the template file is a JSON object of pod annotations or docker labels")
+}
+
+var autodiscoveryCommand = &cobra.Command{
+	Use:   "autodiscovery",
+	Short: "Autodiscovery related commands",
+	Long:  ``,
+}
+
+var autodiscoveryTestCommand = &cobra.Command{
+	Use:   "test <template file>",
+	Short: "Resolve templates against a service without scheduling them",
+	Long: `Resolve the templates of a file against either a service discovered by the
+running agent (--entity) or a mocked service (--service), and print the resolved
+configs or every template variable and instance which isn't valid.
+
+A mocked service is described in YAML:
+
+  entity: docker://abcdef
+  ad_identifiers: [redis]
+  hosts: {bridge: 172.17.0.2}
+  ports: [{port: 6379, name: redis}]
+  pid: 42
+  hostname: redis-0
+  extra: {user: admin}
+  kube: {namespace: prod, pod_name: redis-0, labels: {app: cache}}`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		if (adTestEntity == "") == (adTestServiceFile == "") {
+			return fmt.Errorf("either --entity or --service is required")
+		}
+
+		// Secrets are not decrypted when testing templates
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		templates, err := readTemplates(args[0])
+		if err != nil {
+			return err
+		}
+
+		var results []autodiscovery.TemplateTestResult
+		if adTestEntity != "" {
+			results, err = testTemplatesOnAgent(templates, adTestEntity)
+			if err != nil {
+				return err
+			}
+		} else {
+			svc, err := readMockedService(adTestServiceFile)
+			if err != nil {
+				return err
+			}
+			for _, tpl := range templates {
+				results = append(results, autodiscovery.TestTemplate(tpl, svc))
+			}
+		}
+
+		var b bytes.Buffer
+		failed := printTemplateTestResults(&b, results)
+
+		// templates may hold credentials in plain text
+		scrubbed, err := log.CredentialsCleanerBytes(b.Bytes())
+		if err != nil {
+			return fmt.Errorf("unable to scrub sensitive data from the output: %v", err)
+		}
+		fmt.Fprint(color.Output, string(scrubbed))
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d templates can't be resolved", failed, len(results))
+		}
+		return nil
+	},
+}
+
+func readTemplates(path string) ([]integration.Config, error) {
+	if !adTestAnnotations {
+		name := adTestCheckName
+		if name == "" {
+			name = checkNameFromPath(path)
+		}
+		tpl, err := providers.GetIntegrationConfigFromFile(name, path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template file %s: %v", path, err)
+		}
+		return []integration.Config{tpl}, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	annotations := make(map[string]string)
+	if err := json.Unmarshal(content, &annotations); err != nil {
+		return nil, fmt.Errorf("invalid annotations file %s: %v", path, err)
+	}
+	templates, errs := providers.ExtractTemplatesFromAnnotations(annotations)
+	for _, err := range errs {
+		fmt.Fprintln(color.Output, fmt.Sprintf("%s: %s", color.RedString("Invalid annotations"), err))
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no valid template found in %s", path)
+	}
+	return templates, nil
+}
+
+// checkNameFromPath returns the check name of a conf.d/<check>.d/<file>.yaml file,
+// or the name of the file itself
+func checkNameFromPath(path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	if strings.HasSuffix(dir, ".d") {
+		return strings.TrimSuffix(dir, ".d")
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func readMockedService(path string) (*autodiscovery.MockedService, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	svc := &autodiscovery.MockedService{}
+	if err := yaml.UnmarshalStrict(content, svc); err != nil {
+		return nil, fmt.Errorf("invalid service file %s: %v", path, err)
+	}
+	if svc.Entity == "" {
+		svc.Entity = "mocked://" + filepath.Base(path)
+	}
+	return svc, nil
+}
+
+func testTemplatesOnAgent(templates []integration.Config, entity string) ([]autodiscovery.TemplateTestResult, error) {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return nil, err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(autodiscovery.TemplateTestRequest{Entity: entity, Templates: templates})
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("https://%v:%v/agent/autodiscovery/test", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	r, err := util.DoPost(c, url, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		if e, found := errMap["error"]; found {
+			return nil, fmt.Errorf("%s", e)
+		}
+		return nil, fmt.Errorf("failed to query the agent (running?): %s", err)
+	}
+
+	var results []autodiscovery.TemplateTestResult
+	if err := json.Unmarshal(r, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// printTemplateTestResults prints the results and returns the number of templates which failed
+func printTemplateTestResults(w *bytes.Buffer, results []autodiscovery.TemplateTestResult) int {
+	failed := 0
+	for _, result := range results {
+		name := result.Template.Name
+		if name == "" {
+			name = "logs"
+		}
+		fmt.Fprintln(w, fmt.Sprintf("=== %s for %s ===", color.BlueString(name), color.GreenString(result.Entity)))
+		if result.Template.Source != "" {
+			fmt.Fprintln(w, fmt.Sprintf("Source: %s", result.Template.Source))
+		}
+
+		for _, warning := range result.Warnings {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.YellowString("Warning"), warning))
+		}
+		for _, err := range result.Errors {
+			fmt.Fprintln(w, fmt.Sprintf("%s: %s", color.RedString("Error"), err))
+		}
+		if len(result.Errors) > 0 {
+			failed++
+		}
+
+		if result.Resolved != nil {
+			fmt.Fprintln(w, "Resolved config:")
+			fmt.Fprintln(w, result.Resolved.String())
+		}
+		fmt.Fprintln(w, "===")
+		fmt.Fprintln(w, "")
+	}
+	return failed
+}
@@ -11,6 +11,9 @@ import (
 	"fmt"
 	"html"
 	"net/http"
+	"net/url"
+	"os"
+	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/app/settings"
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
@@ -25,6 +28,10 @@ func init() {
 	configCommand.AddCommand(listRuntimeCommand)
 	configCommand.AddCommand(setCommand)
 	configCommand.AddCommand(getCommand)
+	configCommand.AddCommand(historyCommand)
+	configCommand.AddCommand(lintCommand)
+
+	lintCommand.Flags().BoolVarP(&lintJSON, "json", "j", false, "print out the issues as json")
 }
 
 var (
@@ -52,8 +59,27 @@ var (
 		Long:  ``,
 		RunE:  getConfigValue,
 	}
+	historyCommand = &cobra.Command{
+		Use:   "history",
+		Short: "Show the changes made at runtime to the configuration settings",
+		Long:  ``,
+		RunE:  showRuntimeSettingsHistory,
+	}
+	lintCommand = &cobra.Command{
+		Use:   "lint",
+		Short: "Check the configuration file and the environment variables for unknown keys and invalid values",
+		Long: `Validate the configuration file and the DD_* environment variables against the
+settings known by the agent. Unknown keys, with the key that was probably meant,
+and deprecated keys are reported as warnings. Values of the wrong type, or not
+in the valid values of a setting, are reported as errors and make the command
+fail.`,
+		RunE: lintConfiguration,
+	}
+	lintJSON bool
+
 	agentConfigURLPath = "/agent/config"
 	listRuntimeURLPath = agentConfigURLPath + "/list-runtime"
+	historyURLPath     = agentConfigURLPath + "/history"
 )
 
 func setupConfig() error {
@@ -153,9 +179,9 @@ func setConfigValue(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf("https://%v:%v"+agentConfigURLPath+"/%v", ipcAddress, config.Datadog.GetInt("cmd_port"), args[0])
-	body := fmt.Sprintf("value=%s", html.EscapeString(args[1]))
-	r, err := util.DoPost(c, url, "application/x-www-form-urlencoded", bytes.NewBuffer([]byte(body)))
+	settingURL := fmt.Sprintf("https://%v:%v"+agentConfigURLPath+"/%v", ipcAddress, config.Datadog.GetInt("cmd_port"), args[0])
+	body := url.Values{"value": {html.EscapeString(args[1])}}.Encode()
+	r, err := util.DoPost(c, settingURL, "application/x-www-form-urlencoded", bytes.NewBuffer([]byte(body)))
 	if err != nil {
 		var errMap = make(map[string]string)
 		json.Unmarshal(r, &errMap) //nolint:errcheck
@@ -210,6 +236,94 @@ func getConfigValue(cmd *cobra.Command, args []string) error {
 	return fmt.Errorf("unable to get value for this setting: %v", args[0])
 }
 
+func showRuntimeSettingsHistory(cmd *cobra.Command, args []string) error {
+	err := setupConfig()
+	if err != nil {
+		return err
+	}
+	c := util.GetClient(false)
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	historyURL := fmt.Sprintf("https://%v:%v"+historyURLPath, ipcAddress, config.Datadog.GetInt("cmd_port"))
+	r, err := util.DoGet(c, historyURL)
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return fmt.Errorf(e)
+		}
+		return err
+	}
+
+	var history []settings.RuntimeSettingChange
+	if err = json.Unmarshal(r, &history); err != nil {
+		return err
+	}
+
+	fmt.Println("=== Settings changed at runtime ===")
+	if len(history) == 0 {
+		fmt.Println("No setting was changed since the agent started")
+	}
+	for _, change := range history {
+		fmt.Printf("%s  %-30s %v -> %v", change.Time.Format(time.RFC3339), change.Setting, change.OldValue, change.NewValue)
+		if change.Error != "" {
+			status := "kept the old value"
+			if !change.RolledBack {
+				status = "couldn't restore the old value"
+			}
+			fmt.Printf("  %s (failed: %s, %s)", color.RedString("ERROR"), change.Error, status)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func lintConfiguration(cmd *cobra.Command, args []string) error {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	err := common.SetupConfigWithoutSecrets(confFilePath, "")
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+	file := config.Datadog.ConfigFileUsed()
+	if file == "" {
+		return fmt.Errorf("no configuration file found")
+	}
+
+	result, err := config.Lint(file, os.Environ())
+	if err != nil {
+		return err
+	}
+
+	if lintJSON {
+		r, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(r))
+	} else {
+		fmt.Printf("=== Linting %s ===\n", result.File)
+		for _, issue := range result.Issues {
+			severity := color.YellowString("WARNING")
+			if issue.Severity == config.LintError {
+				severity = color.RedString("ERROR")
+			}
+			fmt.Printf("%-7s %-40s %-12s %s\n", severity, issue.Key, issue.Source, issue.Message)
+		}
+		fmt.Printf("%d error(s), %d warning(s)\n", result.Errors, result.Warnings)
+	}
+
+	if result.Errors > 0 {
+		return fmt.Errorf("the configuration has %d error(s)", result.Errors)
+	}
+	return nil
+}
+
 func getRuntimeSettingsList(c *http.Client) (map[string]settings.RuntimeSettingResponse, error) {
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
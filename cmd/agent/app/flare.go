@@ -7,8 +7,10 @@ package app
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/api/util"
@@ -24,6 +26,12 @@ var (
 	customerEmail string
 	autoconfirm   bool
 	forceLocal    bool
+	noSend        bool
+	dryRun        bool
+	listProviders bool
+	includeFlare  []string
+	excludeFlare  []string
+	maxSizeMB     int64
 )
 
 func init() {
@@ -32,6 +40,12 @@ func init() {
 	flareCmd.Flags().StringVarP(&customerEmail, "email", "e", "", "Your email")
 	flareCmd.Flags().BoolVarP(&autoconfirm, "send", "s", false, "Automatically send flare (don't prompt for confirmation)")
 	flareCmd.Flags().BoolVarP(&forceLocal, "local", "l", false, "Force the creation of the flare by the command line instead of the agent process (useful when running in a containerized env)")
+	flareCmd.Flags().BoolVarP(&noSend, "no-send", "", false, "Only create the flare archive, without sending it to Datadog")
+	flareCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "List the files that would be sent and the redactions made in them, without sending or keeping the flare")
+	flareCmd.Flags().BoolVarP(&listProviders, "list-providers", "", false, "List the providers of the flare content that can be included or excluded")
+	flareCmd.Flags().StringSliceVarP(&includeFlare, "include", "", nil, "Only collect the content of these providers (comma-separated)")
+	flareCmd.Flags().StringSliceVarP(&excludeFlare, "exclude", "", nil, "Don't collect the content of these providers (comma-separated)")
+	flareCmd.Flags().Int64VarP(&maxSizeMB, "max-size", "", 0, "Maximum size in MB of the collected files, the content of the providers going over it is dropped (0 for no limit)")
 	flareCmd.SetArgs([]string{"caseID"})
 }
 
@@ -57,12 +71,23 @@ var flareCmd = &cobra.Command{
 			return err
 		}
 
+		if listProviders || len(includeFlare) > 0 || len(excludeFlare) > 0 {
+			names := providerNames()
+			if listProviders {
+				fmt.Println(strings.Join(names, "\n"))
+				return nil
+			}
+			if err := checkProviderNames(names, append(includeFlare, excludeFlare...)); err != nil {
+				return err
+			}
+		}
+
 		caseID := ""
 		if len(args) > 0 {
 			caseID = args[0]
 		}
 
-		if customerEmail == "" {
+		if customerEmail == "" && !noSend && !dryRun {
 			var err error
 			customerEmail, err = input.AskForEmail()
 			if err != nil {
@@ -99,6 +124,18 @@ func makeFlare(caseID string) error {
 		return err
 	}
 
+	if dryRun {
+		fmt.Fprintln(color.Output, color.BlueString("Dry run, the flare would contain:"))
+		err := flare.DescribeArchive(filePath, color.Output)
+		os.Remove(filePath) //nolint:errcheck
+		return err
+	}
+
+	if noSend {
+		fmt.Fprintln(color.Output, fmt.Sprintf("The flare was created at %s, and wasn't sent to Datadog", color.YellowString(filePath)))
+		return nil
+	}
+
 	fmt.Fprintln(color.Output, fmt.Sprintf("%s is going to be uploaded to Datadog", color.YellowString(filePath)))
 	if !autoconfirm {
 		confirmation := input.AskForConfirmation("Are you sure you want to upload a flare? [y/N]")
@@ -134,7 +171,12 @@ func requestArchive(logFile string) (string, error) {
 		return createArchive(logFile)
 	}
 
-	r, e := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer([]byte{}))
+	body, e := json.Marshal(archiveOptions())
+	if e != nil {
+		return "", e
+	}
+
+	r, e := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer(body))
 	if e != nil {
 		if r != nil && string(r) != "" {
 			fmt.Fprintln(color.Output, fmt.Sprintf("The agent ran into an error while making the flare: %s", color.RedString(string(r))))
@@ -148,10 +190,66 @@ func requestArchive(logFile string) (string, error) {
 
 func createArchive(logFile string) (string, error) {
 	fmt.Fprintln(color.Output, color.YellowString("Initiating flare locally."))
-	filePath, e := flare.CreateArchive(true, common.GetDistPath(), common.PyChecksPath, logFile)
+	filePath, e := flare.CreateArchiveWithOptions(true, common.GetDistPath(), common.PyChecksPath, logFile, archiveOptions())
 	if e != nil {
 		fmt.Printf("The flare zipfile failed to be created: %s\n", e)
 		return "", e
 	}
 	return filePath, nil
 }
+
+func archiveOptions() flare.ArchiveOptions {
+	return flare.ArchiveOptions{
+		Include: includeFlare,
+		Exclude: excludeFlare,
+		MaxSize: maxSizeMB * 1024 * 1024,
+	}
+}
+
+// providerNames returns the names of the flare providers of the agent, which
+// registers providers this process doesn't, or the ones of this process if
+// the flare is created locally or the agent can't be reached
+func providerNames() []string {
+	if !forceLocal {
+		if names, err := requestProviderNames(); err == nil {
+			return names
+		}
+	}
+	return flare.ProviderNames()
+}
+
+func requestProviderNames() ([]string, error) {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return nil, err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/flare/providers", ipcAddress, config.Datadog.GetInt("cmd_port"))
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return nil, err
+	}
+
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = json.Unmarshal(r, &names)
+	return names, err
+}
+
+// checkProviderNames returns an error if one of the names isn't the one of a flare provider
+func checkProviderNames(known []string, names []string) error {
+	providers := make(map[string]bool)
+	for _, name := range known {
+		providers[name] = true
+	}
+	for _, name := range names {
+		if !providers[name] {
+			return fmt.Errorf("unknown flare provider %q, run with --list-providers to list them", name)
+		}
+	}
+	return nil
+}
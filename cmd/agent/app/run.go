@@ -9,7 +9,9 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"syscall"
+	"time"
 
 	_ "expvar" // Blank import used because this isn't directly used in this file
 	"net/http"
@@ -34,7 +36,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
@@ -198,6 +202,16 @@ func StartAgent() error {
 	if config.Datadog.GetBool("telemetry.enabled") {
 		http.Handle("/telemetry", telemetry.Handler())
 	}
+	if config.Datadog.GetBool("status_prometheus_export") {
+		if addr := config.Datadog.GetString("status_prometheus_export_address"); addr != "" {
+			if err := status.ServePrometheus(common.MainCtx, addr); err != nil {
+				return log.Errorf("Error starting the prometheus export of the status, exiting: %v", err)
+			}
+			log.Debugf("Prometheus export of the status listening on %s", addr)
+		} else {
+			http.Handle("/status/metrics", status.PrometheusHandler())
+		}
+	}
 	go http.ListenAndServe("127.0.0.1:"+port, http.DefaultServeMux) //nolint:errcheck
 
 	// Setup healthcheck port
@@ -259,7 +273,8 @@ func StartAgent() error {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	common.Forwarder = forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	f := forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	common.Forwarder = f
 	log.Debugf("Starting forwarder")
 	common.Forwarder.Start() //nolint:errcheck
 	log.Debugf("Forwarder started")
@@ -319,11 +334,106 @@ func StartAgent() error {
 		}
 	}
 
+	// update the components using secrets when their value changes
+	secrets.RegisterRefreshCallback(func(changes []secrets.SecretChange) {
+		onSecretsChange(f, changes)
+	})
+	secrets.StartRefreshRoutine(config.Datadog.GetDuration("secret_refresh_interval") * time.Second)
+
 	// start dependent services
 	startDependentServices()
 	return nil
 }
 
+// onSecretsChange updates the API keys and the checks using the secrets that
+// changed, without restarting the agent
+func onSecretsChange(f *forwarder.DefaultForwarder, changes []secrets.SecretChange) {
+	var checkNames []string
+	for _, change := range changes {
+		for _, origin := range change.Origins {
+			if origin != "datadog.yaml" {
+				checkNames = append(checkNames, origin)
+				continue
+			}
+
+			// the secret is used in the main configuration: update the settings
+			// it was decrypted into, API keys included
+			apiKeyChanged := false
+			for _, key := range change.Keys[origin] {
+				value, ok := replaceSecretValue(config.Datadog.Get(key), change.OldValue, change.NewValue)
+				if !ok {
+					continue
+				}
+				log.Infof("Updating setting '%s' after a secret change", key)
+				config.Datadog.Set(key, value)
+				if key == "api_key" || strings.HasPrefix(key, "additional_endpoints") {
+					apiKeyChanged = true
+				}
+			}
+			if apiKeyChanged {
+				f.UpdateAPIKey(change.OldValue, change.NewValue)
+			}
+		}
+	}
+
+	if common.AC != nil {
+		common.AC.RescheduleConfigs(checkNames)
+	}
+}
+
+// replaceSecretValue returns the value of a setting with the previous value of
+// a secret replaced by the new one, and whether it was found in the setting
+func replaceSecretValue(value interface{}, oldValue, newValue string) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if v == oldValue {
+			return newValue, true
+		}
+	case []string:
+		updated, replaced := make([]string, len(v)), false
+		for i, item := range v {
+			updated[i] = item
+			if item == oldValue {
+				updated[i], replaced = newValue, true
+			}
+		}
+		return updated, replaced
+	case []interface{}:
+		updated, replaced := make([]interface{}, len(v)), false
+		for i, item := range v {
+			var ok bool
+			updated[i], ok = replaceSecretValue(item, oldValue, newValue)
+			replaced = replaced || ok
+		}
+		return updated, replaced
+	case map[string][]string:
+		updated, replaced := make(map[string][]string, len(v)), false
+		for k, items := range v {
+			item, ok := replaceSecretValue(items, oldValue, newValue)
+			updated[k] = item.([]string)
+			replaced = replaced || ok
+		}
+		return updated, replaced
+	case map[string]interface{}:
+		updated, replaced := make(map[string]interface{}, len(v)), false
+		for k, item := range v {
+			var ok bool
+			updated[k], ok = replaceSecretValue(item, oldValue, newValue)
+			replaced = replaced || ok
+		}
+		return updated, replaced
+	case map[interface{}]interface{}:
+		updated, replaced := make(map[interface{}]interface{}, len(v)), false
+		for k, item := range v {
+			var ok bool
+			updated[k], ok = replaceSecretValue(item, oldValue, newValue)
+			replaced = replaced || ok
+		}
+		return updated, replaced
+	}
+	return value, false
+}
+
 // StopAgent Tears down the agent process
 func StopAgent() {
 	// retrieve the agent health before stopping the components
@@ -338,6 +448,8 @@ func StopAgent() {
 	// gracefully shut down any component
 	common.MainCtxCancel()
 
+	secrets.StopRefreshRoutine()
+
 	if common.DSD != nil {
 		common.DSD.Stop()
 	}
@@ -6,6 +6,7 @@
 package app
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -21,6 +22,7 @@ import (
 
 func init() {
 	AgentCmd.AddCommand(secretInfoCommand)
+	secretInfoCommand.AddCommand(secretRefreshCommand)
 }
 
 var secretInfoCommand = &cobra.Command{
@@ -28,29 +30,28 @@ var secretInfoCommand = &cobra.Command{
 	Short: "Print information about decrypted secrets in configuration.",
 	Long:  ``,
 	RunE: func(cmd *cobra.Command, args []string) error {
-
-		if flagNoColor {
-			color.NoColor = true
+		if err := setupSecretCommand(); err != nil {
+			return err
 		}
 
-		err := common.SetupConfigWithoutSecrets(confFilePath, "")
-		if err != nil {
-			fmt.Printf("unable to set up global agent configuration: %v\n", err)
+		if err := showSecretInfo(); err != nil {
+			fmt.Println(err)
 			return nil
 		}
+		return nil
+	},
+}
 
-		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
-		if err != nil {
-			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+var secretRefreshCommand = &cobra.Command{
+	Use:   "refresh",
+	Short: "Fetch the secrets again and update the components using the ones that changed.",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupSecretCommand(); err != nil {
 			return err
 		}
 
-		if err := util.SetAuthToken(); err != nil {
-			fmt.Println(err)
-			return nil
-		}
-
-		if err := showSecretInfo(); err != nil {
+		if err := refreshSecrets(); err != nil {
 			fmt.Println(err)
 			return nil
 		}
@@ -58,6 +59,27 @@ var secretInfoCommand = &cobra.Command{
 	},
 }
 
+// setupSecretCommand loads the configuration, the logger and the auth token
+// needed to query the running agent
+func setupSecretCommand() error {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	err := common.SetupConfigWithoutSecrets(confFilePath, "")
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return err
+	}
+
+	return util.SetAuthToken()
+}
+
 func showSecretInfo() error {
 	c := util.GetClient(false)
 	ipcAddress, err := config.GetIPCAddress()
@@ -86,3 +108,39 @@ func showSecretInfo() error {
 	info.Print(os.Stdout)
 	return nil
 }
+
+func refreshSecrets() error {
+	c := util.GetClient(false)
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	apiConfigURL := fmt.Sprintf("https://%v:%v/agent/secrets/refresh", ipcAddress, config.Datadog.GetInt("cmd_port"))
+
+	r, err := util.DoPost(c, apiConfigURL, "application/json", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return fmt.Errorf("%s", e)
+		}
+
+		return fmt.Errorf("Could not reach agent: %v\nMake sure the agent is running before refreshing the secrets and contact support if you continue having issues", err)
+	}
+
+	changed := []string{}
+	if err = json.Unmarshal(r, &changed); err != nil {
+		return fmt.Errorf("Could not Unmarshal agent answer: %s", r)
+	}
+
+	if len(changed) == 0 {
+		fmt.Println("Secrets refreshed: no secret changed")
+		return nil
+	}
+	fmt.Printf("Secrets refreshed, %d secret(s) changed:\n", len(changed))
+	for _, handle := range changed {
+		fmt.Printf("- %s\n", handle)
+	}
+	return nil
+}
@@ -8,9 +8,26 @@ package settings
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/flare/provider"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-var runtimeSettings = make(map[string]RuntimeSetting)
+// maxHistorySize is the number of changes of the runtime settings kept in the history
+const maxHistorySize = 100
+
+var (
+	runtimeSettings = make(map[string]RuntimeSetting)
+	// settingsLock serializes the changes of the runtime settings, so that a
+	// rollback doesn't restore a value overwritten by a concurrent change
+	settingsLock sync.Mutex
+
+	history     []RuntimeSettingChange
+	historyLock sync.Mutex
+)
 
 // SettingNotFoundError is used to warn about non existing/not registered runtime setting
 type SettingNotFoundError struct {
@@ -23,6 +40,16 @@ type RuntimeSettingResponse struct {
 	Hidden      bool
 }
 
+// RuntimeSettingChange records a change of a runtime setting
+type RuntimeSettingChange struct {
+	Setting    string      `json:"setting"`
+	OldValue   interface{} `json:"old_value"`
+	NewValue   interface{} `json:"new_value"`
+	Time       time.Time   `json:"time"`
+	Error      string      `json:"error,omitempty"`       // set if the change failed
+	RolledBack bool        `json:"rolled_back,omitempty"` // whether the old value was restored after a failed change
+}
+
 func (e *SettingNotFoundError) Error() string {
 	return fmt.Sprintf("setting %s not found", e.name)
 }
@@ -48,7 +75,20 @@ func InitRuntimeSettings() error {
 	if err := registerRuntimeSetting(profilingRuntimeSetting("profiling")); err != nil {
 		return err
 	}
+	if err := registerRuntimeSetting(dsdTagCardinalityRuntimeSetting("dogstatsd_tag_cardinality")); err != nil {
+		return err
+	}
+	if err := registerRuntimeSetting(logsProcessingRulesRuntimeSetting("logs_processing_rules")); err != nil {
+		return err
+	}
+	if err := registerRuntimeSetting(checkIntervalsRuntimeSetting("min_collection_interval")); err != nil {
+		return err
+	}
+	if err := registerRuntimeSetting(forwarderWorkersRuntimeSetting("forwarder_num_workers")); err != nil {
+		return err
+	}
 
+	provider.Register(flareProviderName, flareProvider)
 	return nil
 }
 
@@ -66,15 +106,55 @@ func RuntimeSettings() map[string]RuntimeSetting {
 	return runtimeSettings
 }
 
-// SetRuntimeSetting changes the value of a runtime configurable setting
+// SetRuntimeSetting changes the value of a runtime configurable setting.
+// If the change fails, the previous value is restored. Every change is
+// recorded in the history.
 func SetRuntimeSetting(setting string, value interface{}) error {
 	if _, ok := runtimeSettings[setting]; !ok {
 		return &SettingNotFoundError{name: setting}
 	}
-	if err := runtimeSettings[setting].Set(value); err != nil {
-		return err
+
+	settingsLock.Lock()
+	defer settingsLock.Unlock()
+
+	change := RuntimeSettingChange{Setting: setting, NewValue: value, Time: time.Now()}
+	oldValue, getErr := runtimeSettings[setting].Get()
+	if getErr == nil {
+		change.OldValue = oldValue
+	}
+
+	err := runtimeSettings[setting].Set(value)
+	if err != nil {
+		change.Error = err.Error()
+		if getErr == nil {
+			if rollbackErr := runtimeSettings[setting].Set(oldValue); rollbackErr != nil {
+				log.Errorf("Unable to restore the value of setting %s after a failed change: %v", setting, rollbackErr)
+			} else {
+				change.RolledBack = true
+			}
+		}
+	}
+	recordChange(change)
+	return err
+}
+
+func recordChange(change RuntimeSettingChange) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	history = append(history, change)
+	if len(history) > maxHistorySize {
+		history = history[len(history)-maxHistorySize:]
 	}
-	return nil
+}
+
+// RuntimeSettingsHistory returns the changes of the runtime settings, the
+// oldest first
+func RuntimeSettingsHistory() []RuntimeSettingChange {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	return append([]RuntimeSettingChange{}, history...)
 }
 
 // GetRuntimeSetting returns the value of a runtime configurable setting
@@ -89,6 +169,25 @@ func GetRuntimeSetting(setting string) (interface{}, error) {
 	return value, nil
 }
 
+// getInt returns the int value contained in value.
+// If value is an int, returns its value
+// If value is a string, it's parsed as a base 10 integer.
+// Else, returns an error.
+func getInt(v interface{}) (int, error) {
+	switch value := v.(type) {
+	case int:
+		return value, nil
+	case string:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("getInt: bad parameter value provided: %v", value)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("getInt: bad parameter value provided")
+	}
+}
+
 // getBool returns the bool value contained in value.
 // If value is a bool, returns its value
 // If value is a string, it converts "true" to true and "false" to false.
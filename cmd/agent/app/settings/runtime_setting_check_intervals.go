@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+)
+
+// checkIntervalsRuntimeSetting wraps operations to change the collection interval of checks at runtime.
+type checkIntervalsRuntimeSetting string
+
+func (s checkIntervalsRuntimeSetting) Description() string {
+	return "Set/get the collection intervals overriding the min_collection_interval of checks, as a comma-separated list of <check>=<seconds>, an empty value removes the overrides"
+}
+
+func (s checkIntervalsRuntimeSetting) Hidden() bool {
+	return false
+}
+
+func (s checkIntervalsRuntimeSetting) Name() string {
+	return string(s)
+}
+
+func (s checkIntervalsRuntimeSetting) Get() (interface{}, error) {
+	if common.Coll == nil {
+		return nil, fmt.Errorf("the collector is not running")
+	}
+
+	intervals := common.Coll.CheckIntervals()
+	overrides := make([]string, 0, len(intervals))
+	for name, interval := range intervals {
+		overrides = append(overrides, fmt.Sprintf("%s=%d", name, interval/time.Second))
+	}
+	sort.Strings(overrides)
+	return strings.Join(overrides, ","), nil
+}
+
+func (s checkIntervalsRuntimeSetting) Set(v interface{}) error {
+	value, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("checkIntervalsRuntimeSetting: bad parameter value provided: %v", v)
+	}
+	intervals, err := parseCheckIntervals(value)
+	if err != nil {
+		return err
+	}
+
+	if common.Coll == nil {
+		return fmt.Errorf("the collector is not running")
+	}
+	return common.Coll.SetCheckIntervals(intervals)
+}
+
+// parseCheckIntervals parses a comma-separated list of <check>=<seconds>
+func parseCheckIntervals(value string) (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration)
+	for _, override := range strings.Split(value, ",") {
+		override = strings.TrimSpace(override)
+		if override == "" {
+			continue
+		}
+
+		parts := strings.SplitN(override, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid interval %q, the expected format is <check>=<seconds>", override)
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid interval %q, the number of seconds must be a positive integer", override)
+		}
+		intervals[strings.TrimSpace(parts[0])] = time.Duration(seconds) * time.Second
+	}
+	return intervals, nil
+}
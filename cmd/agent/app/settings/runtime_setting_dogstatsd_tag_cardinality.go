@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+// dsdTagCardinalityRuntimeSetting wraps operations to change the cardinality of the dogstatsd tags at runtime.
+type dsdTagCardinalityRuntimeSetting string
+
+func (s dsdTagCardinalityRuntimeSetting) Description() string {
+	return "Set/get the cardinality of the tags added to the dogstatsd metrics, valid values are: low, orchestrator, high"
+}
+
+func (s dsdTagCardinalityRuntimeSetting) Hidden() bool {
+	return false
+}
+
+func (s dsdTagCardinalityRuntimeSetting) Name() string {
+	return string(s)
+}
+
+func (s dsdTagCardinalityRuntimeSetting) Get() (interface{}, error) {
+	switch tagger.DogstatsdCardinality() {
+	case collectors.HighCardinality:
+		return "high", nil
+	case collectors.OrchestratorCardinality:
+		return "orchestrator", nil
+	default:
+		return "low", nil
+	}
+}
+
+func (s dsdTagCardinalityRuntimeSetting) Set(v interface{}) error {
+	cardinality, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("dsdTagCardinalityRuntimeSetting: bad parameter value provided: %v", v)
+	}
+	if err := tagger.SetDogstatsdCardinality(cardinality); err != nil {
+		return err
+	}
+	config.Datadog.Set("dogstatsd_tag_cardinality", cardinality)
+	return nil
+}
@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// flareProviderName is the name of the flare provider of the runtime settings
+const flareProviderName = "runtime-settings"
+
+// flareProvider adds the current values of the runtime settings and the
+// history of their changes to the flare
+func flareProvider() (map[string][]byte, error) {
+	values := make(map[string]interface{}, len(runtimeSettings))
+	for name, setting := range runtimeSettings {
+		value, err := setting.Get()
+		if err != nil {
+			value = fmt.Sprintf("unable to get the value: %s", err)
+		}
+		values[name] = value
+	}
+
+	current, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	history, err := json.MarshalIndent(RuntimeSettingsHistory(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"runtime_settings.json": current,
+		"history.json":          history,
+	}, nil
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package settings
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// workersSetter is implemented by the forwarders whose number of workers
+// can be changed at runtime
+type workersSetter interface {
+	SetNumberOfWorkers(numberOfWorkers int) error
+}
+
+// forwarderWorkersRuntimeSetting wraps operations to change the number of workers of the forwarder at runtime.
+type forwarderWorkersRuntimeSetting string
+
+func (s forwarderWorkersRuntimeSetting) Description() string {
+	return "Set/get the number of concurrent requests the forwarder sends to each domain, it must be at least 1"
+}
+
+func (s forwarderWorkersRuntimeSetting) Hidden() bool {
+	return false
+}
+
+func (s forwarderWorkersRuntimeSetting) Name() string {
+	return string(s)
+}
+
+func (s forwarderWorkersRuntimeSetting) Get() (interface{}, error) {
+	return config.Datadog.GetInt("forwarder_num_workers"), nil
+}
+
+func (s forwarderWorkersRuntimeSetting) Set(v interface{}) error {
+	workers, err := getInt(v)
+	if err != nil {
+		return fmt.Errorf("forwarderWorkersRuntimeSetting: %v", err)
+	}
+
+	f, ok := common.Forwarder.(workersSetter)
+	if !ok {
+		return fmt.Errorf("the number of workers of the forwarder can't be changed at runtime")
+	}
+	if err := f.SetNumberOfWorkers(workers); err != nil {
+		return err
+	}
+	config.Datadog.Set("forwarder_num_workers", workers)
+	return nil
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs"
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+)
+
+// logsProcessingRulesRuntimeSetting wraps operations to change the global logs processing rules at runtime.
+type logsProcessingRulesRuntimeSetting string
+
+// processingRule is the JSON representation of a processing rule
+type processingRule struct {
+	Type               string `json:"type"`
+	Name               string `json:"name"`
+	Pattern            string `json:"pattern"`
+	ReplacePlaceholder string `json:"replace_placeholder,omitempty"`
+}
+
+func (s logsProcessingRulesRuntimeSetting) Description() string {
+	return "Set/get the global processing rules applied to all logs, as a JSON list of rules with a type, a name and a pattern"
+}
+
+func (s logsProcessingRulesRuntimeSetting) Hidden() bool {
+	return false
+}
+
+func (s logsProcessingRulesRuntimeSetting) Name() string {
+	return string(s)
+}
+
+func (s logsProcessingRulesRuntimeSetting) Get() (interface{}, error) {
+	rules, err := logsconfig.GlobalProcessingRules()
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := make([]processingRule, 0, len(rules))
+	for _, rule := range rules {
+		encoded = append(encoded, processingRule{
+			Type:               rule.Type,
+			Name:               rule.Name,
+			Pattern:            rule.Pattern,
+			ReplacePlaceholder: rule.ReplacePlaceholder,
+		})
+	}
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (s logsProcessingRulesRuntimeSetting) Set(v interface{}) error {
+	value, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("logsProcessingRulesRuntimeSetting: bad parameter value provided: %v", v)
+	}
+
+	var rules []*logsconfig.ProcessingRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return fmt.Errorf("invalid processing rules: %v", err)
+	}
+	if err := logs.SetGlobalProcessingRules(rules); err != nil {
+		return fmt.Errorf("invalid processing rules: %v", err)
+	}
+	config.Datadog.Set("logs_config.processing_rules", value)
+	return nil
+}
@@ -6,15 +6,21 @@
 package settings
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -184,3 +190,163 @@ func TestProfiling(t *testing.T) {
 	err = ll.Set("on")
 	assert.NotNil(t, err)
 }
+
+type failingTestSetting struct {
+	runtimeTestSetting
+	sets []interface{}
+}
+
+func (t *failingTestSetting) Set(v interface{}) error {
+	t.sets = append(t.sets, v)
+	if v.(int) < 0 {
+		return errors.New("negative value")
+	}
+	t.value = v.(int)
+	return nil
+}
+
+func TestSetRuntimeSettingHistory(t *testing.T) {
+	cleanRuntimeSetting()
+	history = nil
+	s := &failingTestSetting{runtimeTestSetting: runtimeTestSetting{1}}
+	require.Nil(t, registerRuntimeSetting(s))
+
+	require.Nil(t, SetRuntimeSetting("name", 2))
+
+	// a failed change restores the old value
+	err := SetRuntimeSetting("name", -1)
+	assert.NotNil(t, err)
+	assert.Equal(t, []interface{}{2, -1, 2}, s.sets)
+	v, _ := GetRuntimeSetting("name")
+	assert.Equal(t, 2, v)
+
+	h := RuntimeSettingsHistory()
+	require.Len(t, h, 2)
+	assert.Equal(t, "name", h[0].Setting)
+	assert.Equal(t, 1, h[0].OldValue)
+	assert.Equal(t, 2, h[0].NewValue)
+	assert.Empty(t, h[0].Error)
+	assert.Equal(t, 2, h[1].OldValue)
+	assert.Equal(t, -1, h[1].NewValue)
+	assert.Equal(t, "negative value", h[1].Error)
+	assert.True(t, h[1].RolledBack)
+
+	// the history is capped
+	for i := 0; i < maxHistorySize; i++ {
+		SetRuntimeSetting("name", i)
+	}
+	h = RuntimeSettingsHistory()
+	assert.Len(t, h, maxHistorySize)
+	assert.Equal(t, maxHistorySize-1, h[maxHistorySize-1].NewValue)
+}
+
+// slowTestSetting fails to set negative values, after giving concurrent
+// changes the time to run
+type slowTestSetting struct {
+	runtimeTestSetting
+	m sync.Mutex
+}
+
+func (t *slowTestSetting) Get() (interface{}, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.value, nil
+}
+
+func (t *slowTestSetting) Set(v interface{}) error {
+	t.m.Lock()
+	t.value = v.(int)
+	t.m.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	if v.(int) < 0 {
+		return errors.New("negative value")
+	}
+	return nil
+}
+
+func TestSetRuntimeSettingConcurrentRollback(t *testing.T) {
+	cleanRuntimeSetting()
+	history = nil
+	s := &slowTestSetting{runtimeTestSetting: runtimeTestSetting{1}}
+	require.Nil(t, registerRuntimeSetting(s))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		SetRuntimeSetting("name", -1)
+	}()
+	go func() {
+		defer wg.Done()
+		time.Sleep(2 * time.Millisecond)
+		SetRuntimeSetting("name", 2)
+	}()
+	wg.Wait()
+
+	// the rollback of the failed change never overwrites the successful one
+	assert.Len(t, RuntimeSettingsHistory(), 2)
+	v, _ := GetRuntimeSetting("name")
+	assert.Equal(t, 2, v)
+}
+
+func TestFlareProvider(t *testing.T) {
+	cleanRuntimeSetting()
+	history = nil
+	require.Nil(t, registerRuntimeSetting(&runtimeTestSetting{1}))
+	require.Nil(t, SetRuntimeSetting("name", 2))
+
+	files, err := flareProvider()
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"name": 2}`, string(files["runtime_settings.json"]))
+
+	var h []RuntimeSettingChange
+	require.Nil(t, json.Unmarshal(files["history.json"], &h))
+	require.Len(t, h, 1)
+	assert.Equal(t, "name", h[0].Setting)
+}
+
+func TestDogstatsdTagCardinality(t *testing.T) {
+	cleanRuntimeSetting()
+	setupConf()
+
+	s := dsdTagCardinalityRuntimeSetting("dogstatsd_tag_cardinality")
+
+	require.Nil(t, s.Set("orchestrator"))
+	v, err := s.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, "orchestrator", v)
+	assert.Equal(t, collectors.OrchestratorCardinality, tagger.DogstatsdCardinality())
+
+	assert.NotNil(t, s.Set("invalid"))
+	v, _ = s.Get()
+	assert.Equal(t, "orchestrator", v)
+
+	require.Nil(t, s.Set("low"))
+	assert.Equal(t, collectors.LowCardinality, tagger.DogstatsdCardinality())
+}
+
+func TestParseCheckIntervals(t *testing.T) {
+	intervals, err := parseCheckIntervals("cpu=30, disk = 60,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]time.Duration{"cpu": 30 * time.Second, "disk": time.Minute}, intervals)
+
+	intervals, err = parseCheckIntervals("")
+	assert.Nil(t, err)
+	assert.Empty(t, intervals)
+
+	for _, invalid := range []string{"cpu", "cpu=", "=30", "cpu=0", "cpu=-5", "cpu=1s"} {
+		_, err = parseCheckIntervals(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestForwarderWorkers(t *testing.T) {
+	cleanRuntimeSetting()
+	setupConf()
+
+	s := forwarderWorkersRuntimeSetting("forwarder_num_workers")
+	common.Forwarder = nil
+	assert.NotNil(t, s.Set("2"))
+	assert.NotNil(t, s.Set("two"))
+}
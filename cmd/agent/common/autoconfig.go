@@ -7,6 +7,7 @@ package common
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
@@ -18,6 +19,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+const (
+	fileConfigDebounce     = 2 * time.Second
+	fileConfigPollInterval = 1 * time.Second
+)
+
 // SetupAutoConfig configures the global AutoConfig:
 //   1. add the configuration providers
 //   2. add the check loaders
@@ -50,7 +56,18 @@ func SetupAutoConfig(confdPath string) {
 		filepath.Join(GetDistPath(), "conf.d"),
 		"",
 	}
-	AC.AddConfigProvider(providers.NewFileConfigProvider(confSearchPaths), false, 0)
+	fileConfigProvider := providers.NewFileConfigProvider(confSearchPaths)
+	if config.Datadog.GetBool("autoconf_config_files_watch") {
+		// Changes are polled once debounced by the watcher
+		if err := fileConfigProvider.Watch(fileConfigDebounce); err == nil {
+			AC.AddConfigProvider(fileConfigProvider, true, fileConfigPollInterval)
+		} else {
+			log.Errorf("Unable to watch configuration files, they'll only be loaded at startup: %v", err)
+			AC.AddConfigProvider(fileConfigProvider, false, 0)
+		}
+	} else {
+		AC.AddConfigProvider(fileConfigProvider, false, 0)
+	}
 
 	// Register additional configuration providers
 	var CP []config.ConfigurationProviders
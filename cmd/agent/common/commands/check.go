@@ -57,6 +57,10 @@ var (
 	profileMemoryFilters string
 	profileMemoryUnit    string
 	profileMemoryVerbose string
+	snapshotFile         string
+	compareSnapshotFile  string
+	snapshotTolerance    float64
+	snapshotIgnoredTags  []string
 )
 
 func setupCmd(cmd *cobra.Command) {
@@ -70,6 +74,10 @@ func setupCmd(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&profileMemory, "profile-memory", "m", false, "run the memory profiler (Python checks only)")
 	cmd.Flags().BoolVar(&fullSketches, "full-sketches", false, "output sketches with bins information")
 	config.Datadog.BindPFlag("cmd.check.fullsketches", cmd.Flags().Lookup("full-sketches")) //nolint:errcheck
+	cmd.Flags().StringVar(&snapshotFile, "snapshot", "", "write the collected series, sketches, service checks and events to a normalized snapshot file")
+	cmd.Flags().StringVar(&compareSnapshotFile, "compare-snapshot", "", "compare the collected series, sketches, service checks and events to a snapshot file, and fail on mismatch")
+	cmd.Flags().Float64Var(&snapshotTolerance, "snapshot-tolerance", 0, "relative difference allowed between the values of the snapshots, e.g. 0.1 for 10%")
+	cmd.Flags().StringSliceVar(&snapshotIgnoredTags, "snapshot-ignore-tags", nil, "comma-separated list of tag names, or full tags, ignored in the snapshots")
 
 	// Power user flags - mark as hidden
 	createHiddenStringFlag(cmd, &profileMemoryDir, "m-dir", "", "an existing directory in which to store memory profiling data, ignoring clean-up")
@@ -282,6 +290,9 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				fmt.Println("Multiple check instances found, running each of them")
 			}
 
+			snapshotOpts := snapshotOptions{ignoredTags: snapshotIgnoredTags, tolerance: snapshotTolerance}
+			snapshot := &checkSnapshot{}
+
 			var instancesData []interface{}
 			for _, c := range cs {
 				s := runCheck(c, agg)
@@ -289,7 +300,11 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				// Sleep for a while to allow the aggregator to finish ingesting all the metrics/events/sc
 				time.Sleep(time.Duration(checkDelay) * time.Millisecond)
 
-				if formatJSON {
+				if snapshotMode() {
+					snapshot.addAggregatorData(agg, snapshotOpts)
+					checkStatus, _ := status.GetCheckStatus(c, s)
+					fmt.Println(string(checkStatus))
+				} else if formatJSON {
 					aggregatorData := getMetricsData(agg)
 					var collectorData map[string]interface{}
 
@@ -378,7 +393,28 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				standalone.PrintWindowsUserWarning("check")
 			}
 
-			if formatJSON {
+			if snapshotFile != "" {
+				if err := writeSnapshot(snapshotFile, snapshot); err != nil {
+					return err
+				}
+				fmt.Fprintln(color.Output, fmt.Sprintf("Snapshot written to %s", color.BlueString(snapshotFile)))
+			}
+			if compareSnapshotFile != "" {
+				expected, err := readSnapshot(compareSnapshotFile)
+				if err != nil {
+					return err
+				}
+				if diffs := compareSnapshots(expected, snapshot, snapshotOpts); len(diffs) != 0 {
+					fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.RedString("Snapshot mismatch")))
+					for _, diff := range diffs {
+						fmt.Println(diff)
+					}
+					return fmt.Errorf("the check output doesn't match the snapshot %s: %d difference(s)", compareSnapshotFile, len(diffs))
+				}
+				fmt.Fprintln(color.Output, fmt.Sprintf("The check output matches the snapshot %s", color.GreenString(compareSnapshotFile)))
+			}
+
+			if formatJSON && !snapshotMode() {
 				fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("JSON")))
 				instancesJSON, _ := json.MarshalIndent(instancesData, "", "  ")
 				fmt.Println(string(instancesJSON))
@@ -486,6 +522,11 @@ func getMetricsData(agg *aggregator.BufferedAggregator) map[string]interface{} {
 	return aggData
 }
 
+// snapshotMode returns whether the check output is written to or compared with a snapshot
+func snapshotMode() bool {
+	return snapshotFile != "" || compareSnapshotFile != ""
+}
+
 func singleCheckRun() bool {
 	return checkRate == false && checkTimes < 2
 }
@@ -0,0 +1,382 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// checkSnapshot is the normalized output of a check run: it doesn't contain
+// the timestamps nor the hostname, and every list is sorted, so that two runs
+// of the same check on different hosts produce the same snapshot
+type checkSnapshot struct {
+	Series        []snapshotSerie        `json:"series"`
+	Sketches      []snapshotSketch       `json:"sketches"`
+	ServiceChecks []snapshotServiceCheck `json:"service_checks"`
+	Events        []snapshotEvent        `json:"events"`
+}
+
+type snapshotSerie struct {
+	Metric string    `json:"metric"`
+	Type   string    `json:"type"`
+	Tags   []string  `json:"tags"`
+	Values []float64 `json:"values"`
+}
+
+type snapshotSketch struct {
+	Metric string   `json:"metric"`
+	Tags   []string `json:"tags"`
+	Count  int64    `json:"count"`
+	Min    float64  `json:"min"`
+	Max    float64  `json:"max"`
+	Sum    float64  `json:"sum"`
+	Avg    float64  `json:"avg"`
+}
+
+type snapshotServiceCheck struct {
+	Check   string   `json:"check"`
+	Tags    []string `json:"tags"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+}
+
+type snapshotEvent struct {
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	Tags           []string `json:"tags"`
+	Priority       string   `json:"priority"`
+	AlertType      string   `json:"alert_type"`
+	AggregationKey string   `json:"aggregation_key"`
+	SourceTypeName string   `json:"source_type_name"`
+}
+
+// snapshotOptions configures how the snapshots are built and compared
+type snapshotOptions struct {
+	// ignoredTags are the tag names, or full tags, removed from the snapshots
+	ignoredTags []string
+	// tolerance is the relative difference allowed between two values
+	tolerance float64
+}
+
+func (o snapshotOptions) normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if o.isIgnoredTag(tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func (o snapshotOptions) isIgnoredTag(tag string) bool {
+	name := strings.SplitN(tag, ":", 2)[0]
+	for _, ignored := range o.ignoredTags {
+		if ignored == tag || ignored == name {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares two values with the relative tolerance of the options
+func (o snapshotOptions) valuesEqual(expected, actual float64) bool {
+	if expected == actual {
+		return true
+	}
+	return math.Abs(expected-actual) <= o.tolerance*math.Abs(expected)
+}
+
+// addAggregatorData adds the data collected by the aggregator since the last call to the snapshot
+func (s *checkSnapshot) addAggregatorData(agg *aggregator.BufferedAggregator, opts snapshotOptions) {
+	series, sketches := agg.GetSeriesAndSketches()
+	s.addSeries(series, opts)
+	s.addSketches(sketches, opts)
+	s.addServiceChecks(agg.GetServiceChecks(), opts)
+	s.addEvents(agg.GetEvents(), opts)
+	s.sort()
+}
+
+func (s *checkSnapshot) addSeries(series metrics.Series, opts snapshotOptions) {
+	for _, serie := range series {
+		tags := serie.Tags
+		if serie.Device != "" {
+			tags = append(append([]string{}, tags...), "device:"+serie.Device)
+		}
+		values := make([]float64, 0, len(serie.Points))
+		for _, point := range serie.Points {
+			values = append(values, point.Value)
+		}
+		s.mergeSerie(snapshotSerie{
+			Metric: serie.Name,
+			Type:   serie.MType.String(),
+			Tags:   opts.normalizeTags(tags),
+			Values: values,
+		}, opts)
+	}
+}
+
+// mergeSerie adds a serie to the snapshot. Ignoring tags may merge several
+// series, their values are sorted to keep the snapshot stable.
+func (s *checkSnapshot) mergeSerie(serie snapshotSerie, opts snapshotOptions) {
+	for i := range s.Series {
+		if s.Series[i].id(opts) == serie.id(opts) {
+			s.Series[i].Values = append(append([]float64{}, s.Series[i].Values...), serie.Values...)
+			sort.Float64s(s.Series[i].Values)
+			return
+		}
+	}
+	s.Series = append(s.Series, serie)
+}
+
+func (s *checkSnapshot) addSketches(sketches metrics.SketchSeriesList, opts snapshotOptions) {
+	for _, sketch := range sketches {
+		for _, point := range sketch.Points {
+			if point.Sketch == nil {
+				continue
+			}
+			s.mergeSketch(snapshotSketch{
+				Metric: sketch.Name,
+				Tags:   opts.normalizeTags(sketch.Tags),
+				Count:  point.Sketch.Basic.Cnt,
+				Min:    point.Sketch.Basic.Min,
+				Max:    point.Sketch.Basic.Max,
+				Sum:    point.Sketch.Basic.Sum,
+				Avg:    point.Sketch.Basic.Avg,
+			}, opts)
+		}
+	}
+}
+
+// mergeSketch adds a sketch to the snapshot. Ignoring tags may merge several
+// sketches, their summaries are combined.
+func (s *checkSnapshot) mergeSketch(sketch snapshotSketch, opts snapshotOptions) {
+	for i := range s.Sketches {
+		merged := &s.Sketches[i]
+		if merged.id(opts) != sketch.id(opts) {
+			continue
+		}
+		merged.Min = math.Min(merged.Min, sketch.Min)
+		merged.Max = math.Max(merged.Max, sketch.Max)
+		merged.Count += sketch.Count
+		merged.Sum += sketch.Sum
+		if merged.Count > 0 {
+			merged.Avg = merged.Sum / float64(merged.Count)
+		}
+		return
+	}
+	s.Sketches = append(s.Sketches, sketch)
+}
+
+func (s *checkSnapshot) addServiceChecks(serviceChecks metrics.ServiceChecks, opts snapshotOptions) {
+	for _, sc := range serviceChecks {
+		s.ServiceChecks = append(s.ServiceChecks, snapshotServiceCheck{
+			Check:   sc.CheckName,
+			Tags:    opts.normalizeTags(sc.Tags),
+			Status:  sc.Status.String(),
+			Message: sc.Message,
+		})
+	}
+}
+
+func (s *checkSnapshot) addEvents(events metrics.Events, opts snapshotOptions) {
+	for _, e := range events {
+		s.Events = append(s.Events, snapshotEvent{
+			Title:          e.Title,
+			Text:           e.Text,
+			Tags:           opts.normalizeTags(e.Tags),
+			Priority:       string(e.Priority),
+			AlertType:      string(e.AlertType),
+			AggregationKey: e.AggregationKey,
+			SourceTypeName: e.SourceTypeName,
+		})
+	}
+}
+
+func (s *checkSnapshot) sort() {
+	var opts snapshotOptions
+	sort.SliceStable(s.Series, func(i, j int) bool { return s.Series[i].id(opts) < s.Series[j].id(opts) })
+	sort.SliceStable(s.Sketches, func(i, j int) bool { return s.Sketches[i].id(opts) < s.Sketches[j].id(opts) })
+	sort.SliceStable(s.ServiceChecks, func(i, j int) bool { return s.ServiceChecks[i].id(opts) < s.ServiceChecks[j].id(opts) })
+	sort.SliceStable(s.Events, func(i, j int) bool { return s.Events[i].id(opts) < s.Events[j].id(opts) })
+}
+
+// writeSnapshot writes a snapshot to a file
+func writeSnapshot(path string, s *checkSnapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal the snapshot: %v", err)
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("unable to write the snapshot to %s: %v", path, err)
+	}
+	return nil
+}
+
+// readSnapshot reads a snapshot written by writeSnapshot
+func readSnapshot(path string) (*checkSnapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the snapshot %s: %v", path, err)
+	}
+	s := &checkSnapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("unable to parse the snapshot %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// snapshotEntry is a series, sketch, service check or event of a snapshot
+type snapshotEntry interface {
+	// id identifies the entry in a snapshot, its tags are normalized again as
+	// the tags ignored now may not have been ignored when the snapshot was written
+	id(opts snapshotOptions) string
+	// diff describes the difference with the entry of the same id in another snapshot,
+	// it returns an empty string if they match
+	diff(other snapshotEntry, opts snapshotOptions) string
+}
+
+func (s snapshotSerie) id(opts snapshotOptions) string {
+	return fmt.Sprintf("series %s (%s) [%s]", s.Metric, s.Type, strings.Join(opts.normalizeTags(s.Tags), ","))
+}
+
+func (s snapshotSerie) diff(other snapshotEntry, opts snapshotOptions) string {
+	got := other.(snapshotSerie)
+	if !opts.allValuesEqual(s.Values, got.Values) {
+		return fmt.Sprintf("expected values %v, got %v", s.Values, got.Values)
+	}
+	return ""
+}
+
+func (s snapshotSketch) id(opts snapshotOptions) string {
+	return fmt.Sprintf("sketch %s [%s]", s.Metric, strings.Join(opts.normalizeTags(s.Tags), ","))
+}
+
+func (s snapshotSketch) diff(other snapshotEntry, opts snapshotOptions) string {
+	got := other.(snapshotSketch)
+	if s.Count != got.Count ||
+		!opts.allValuesEqual([]float64{s.Min, s.Max, s.Sum, s.Avg}, []float64{got.Min, got.Max, got.Sum, got.Avg}) {
+		return fmt.Sprintf("expected count=%d min=%v max=%v sum=%v avg=%v, got count=%d min=%v max=%v sum=%v avg=%v",
+			s.Count, s.Min, s.Max, s.Sum, s.Avg, got.Count, got.Min, got.Max, got.Sum, got.Avg)
+	}
+	return ""
+}
+
+func (s snapshotServiceCheck) id(opts snapshotOptions) string {
+	return fmt.Sprintf("service check %s [%s]", s.Check, strings.Join(opts.normalizeTags(s.Tags), ","))
+}
+
+func (s snapshotServiceCheck) diff(other snapshotEntry, opts snapshotOptions) string {
+	got := other.(snapshotServiceCheck)
+	if s.Status != got.Status || s.Message != got.Message {
+		return fmt.Sprintf("expected %s %q, got %s %q", s.Status, s.Message, got.Status, got.Message)
+	}
+	return ""
+}
+
+func (e snapshotEvent) id(opts snapshotOptions) string {
+	return fmt.Sprintf("event %s (%s) [%s]", e.Title, e.SourceTypeName, strings.Join(opts.normalizeTags(e.Tags), ","))
+}
+
+func (e snapshotEvent) diff(other snapshotEntry, opts snapshotOptions) string {
+	got := other.(snapshotEvent)
+	var diffs []string
+	for _, field := range []struct{ name, expected, actual string }{
+		{"text", e.Text, got.Text},
+		{"priority", e.Priority, got.Priority},
+		{"alert_type", e.AlertType, got.AlertType},
+		{"aggregation_key", e.AggregationKey, got.AggregationKey},
+	} {
+		if field.expected != field.actual {
+			diffs = append(diffs, fmt.Sprintf("expected %s %q, got %q", field.name, field.expected, field.actual))
+		}
+	}
+	return strings.Join(diffs, ", ")
+}
+
+// entries returns the entries of the snapshot to compare, the series and
+// sketches whose tags are now ignored are merged again
+func (s *checkSnapshot) entries(opts snapshotOptions) []snapshotEntry {
+	merged := &checkSnapshot{}
+	for _, serie := range s.Series {
+		merged.mergeSerie(serie, opts)
+	}
+	for _, sketch := range s.Sketches {
+		merged.mergeSketch(sketch, opts)
+	}
+
+	var entries []snapshotEntry
+	for _, serie := range merged.Series {
+		entries = append(entries, serie)
+	}
+	for _, sketch := range merged.Sketches {
+		entries = append(entries, sketch)
+	}
+	for _, sc := range s.ServiceChecks {
+		entries = append(entries, sc)
+	}
+	for _, e := range s.Events {
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// compareSnapshots returns the differences between an expected snapshot and a
+// fresh one, an empty list means they match. Several entries may have the same
+// id, e.g. service checks sent at each run, they must be found as many times.
+func compareSnapshots(expected, actual *checkSnapshot, opts snapshotOptions) []string {
+	var diffs []string
+
+	actualEntries := map[string][]snapshotEntry{}
+	for _, entry := range actual.entries(opts) {
+		id := entry.id(opts)
+		actualEntries[id] = append(actualEntries[id], entry)
+	}
+
+	for _, entry := range expected.entries(opts) {
+		id := entry.id(opts)
+		got := actualEntries[id]
+		if len(got) == 0 {
+			diffs = append(diffs, fmt.Sprintf("missing %s", id))
+			continue
+		}
+		actualEntries[id] = got[1:]
+		if diff := entry.diff(got[0], opts); diff != "" {
+			diffs = append(diffs, fmt.Sprintf("%s: %s", id, diff))
+		}
+	}
+
+	var unexpected []string
+	for id, entries := range actualEntries {
+		for range entries {
+			unexpected = append(unexpected, fmt.Sprintf("unexpected %s", id))
+		}
+	}
+	sort.Strings(unexpected)
+
+	return append(diffs, unexpected...)
+}
+
+func (o snapshotOptions) allValuesEqual(expected, actual []float64) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+	for i := range expected {
+		if !o.valuesEqual(expected[i], actual[i]) {
+			return false
+		}
+	}
+	return true
+}
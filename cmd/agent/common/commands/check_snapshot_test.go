@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func testSnapshot(opts snapshotOptions, value float64, host string) *checkSnapshot {
+	s := &checkSnapshot{}
+	s.addSeries(metrics.Series{
+		{
+			Name:   "my.metric",
+			Points: []metrics.Point{{Ts: 1000, Value: value}},
+			Tags:   []string{"env:prod", "host:" + host, "app:foo"},
+			Host:   host,
+			MType:  metrics.APIGaugeType,
+		},
+		{
+			Name:   "my.count",
+			Points: []metrics.Point{{Ts: 1000, Value: 5}},
+			Host:   host,
+			MType:  metrics.APICountType,
+		},
+	}, opts)
+	s.addServiceChecks(metrics.ServiceChecks{
+		{CheckName: "my.can_connect", Host: host, Ts: 1000, Status: metrics.ServiceCheckOK, Tags: []string{"app:foo"}},
+	}, opts)
+	s.addEvents(metrics.Events{
+		{Title: "Restart", Text: "foo restarted", Ts: 1000, Host: host, Tags: []string{"app:foo"}, AlertType: metrics.EventAlertTypeInfo},
+	}, opts)
+	s.sort()
+	return s
+}
+
+func TestCheckSnapshotNormalization(t *testing.T) {
+	s := testSnapshot(snapshotOptions{ignoredTags: []string{"host"}}, 10, "host1")
+
+	require.Len(t, s.Series, 2)
+	assert.Equal(t, snapshotSerie{Metric: "my.count", Type: "count", Tags: []string{}, Values: []float64{5}}, s.Series[0])
+	assert.Equal(t, snapshotSerie{Metric: "my.metric", Type: "gauge", Tags: []string{"app:foo", "env:prod"}, Values: []float64{10}}, s.Series[1])
+	require.Len(t, s.ServiceChecks, 1)
+	assert.Equal(t, "OK", s.ServiceChecks[0].Status)
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, "info", s.Events[0].AlertType)
+}
+
+func TestCheckSnapshotMergedSeries(t *testing.T) {
+	s := &checkSnapshot{}
+	s.addSeries(metrics.Series{
+		{Name: "my.metric", Points: []metrics.Point{{Value: 3}}, Tags: []string{"pid:1"}, MType: metrics.APIGaugeType},
+		{Name: "my.metric", Points: []metrics.Point{{Value: 1}}, Tags: []string{"pid:2"}, MType: metrics.APIGaugeType},
+	}, snapshotOptions{ignoredTags: []string{"pid"}})
+
+	require.Len(t, s.Series, 1)
+	assert.Equal(t, []float64{1, 3}, s.Series[0].Values)
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	opts := snapshotOptions{ignoredTags: []string{"host"}}
+	expected := testSnapshot(opts, 10, "host1")
+
+	// same output on another host
+	assert.Empty(t, compareSnapshots(expected, testSnapshot(opts, 10, "host2"), opts))
+
+	// different value
+	diffs := compareSnapshots(expected, testSnapshot(opts, 10.5, "host2"), opts)
+	assert.Equal(t, []string{"series my.metric (gauge) [app:foo,env:prod]: expected values [10], got [10.5]"}, diffs)
+
+	// the value is in the tolerance
+	opts.tolerance = 0.1
+	assert.Empty(t, compareSnapshots(expected, testSnapshot(opts, 10.5, "host2"), opts))
+
+	// missing and unexpected entries
+	actual := testSnapshot(opts, 10, "host2")
+	actual.ServiceChecks[0].Status = "CRITICAL"
+	actual.Events = nil
+	actual.Series = append(actual.Series, snapshotSerie{Metric: "my.new_metric", Type: "gauge", Values: []float64{1}})
+	diffs = compareSnapshots(expected, actual, opts)
+	assert.Equal(t, []string{
+		`service check my.can_connect [app:foo]: expected OK "", got CRITICAL ""`,
+		"missing event Restart () [app:foo]",
+		"unexpected series my.new_metric (gauge) []",
+	}, diffs)
+}
+
+func TestCompareSnapshotsDuplicates(t *testing.T) {
+	opts := snapshotOptions{ignoredTags: []string{"host"}}
+	expected := testSnapshot(opts, 10, "host1")
+	expected.ServiceChecks = append(expected.ServiceChecks, expected.ServiceChecks[0])
+
+	// the service check was only sent once
+	diffs := compareSnapshots(expected, testSnapshot(opts, 10, "host2"), opts)
+	assert.Equal(t, []string{"missing service check my.can_connect [app:foo]"}, diffs)
+
+	// the service check was sent three times
+	actual := testSnapshot(opts, 10, "host2")
+	actual.ServiceChecks = append(actual.ServiceChecks, actual.ServiceChecks[0], actual.ServiceChecks[0])
+	diffs = compareSnapshots(expected, actual, opts)
+	assert.Equal(t, []string{"unexpected service check my.can_connect [app:foo]"}, diffs)
+
+	actual.ServiceChecks = actual.ServiceChecks[:2]
+	assert.Empty(t, compareSnapshots(expected, actual, opts))
+}
+
+func TestCompareSnapshotsMergedSketches(t *testing.T) {
+	// the snapshot was written without ignoring the pid tag
+	expected := &checkSnapshot{Sketches: []snapshotSketch{
+		{Metric: "my.dist", Tags: []string{"pid:1"}, Count: 2, Min: 1, Max: 3, Sum: 4, Avg: 2},
+		{Metric: "my.dist", Tags: []string{"pid:2"}, Count: 2, Min: 5, Max: 7, Sum: 12, Avg: 6},
+	}}
+	opts := snapshotOptions{ignoredTags: []string{"pid"}}
+	actual := &checkSnapshot{}
+	actual.mergeSketch(snapshotSketch{Metric: "my.dist", Tags: opts.normalizeTags([]string{"pid:3"}), Count: 1, Min: 1, Max: 1, Sum: 1, Avg: 1}, opts)
+	actual.mergeSketch(snapshotSketch{Metric: "my.dist", Tags: opts.normalizeTags([]string{"pid:4"}), Count: 3, Min: 3, Max: 7, Sum: 15, Avg: 5}, opts)
+
+	require.Len(t, actual.Sketches, 1)
+	assert.Equal(t, snapshotSketch{Metric: "my.dist", Tags: []string{}, Count: 4, Min: 1, Max: 7, Sum: 16, Avg: 4}, actual.Sketches[0])
+	assert.Empty(t, compareSnapshots(expected, actual, opts))
+}
+
+func TestCompareSnapshotsIgnoredTagsAfterWrite(t *testing.T) {
+	// the snapshot was written without ignoring the host tag
+	expected := testSnapshot(snapshotOptions{}, 10, "host1")
+	opts := snapshotOptions{ignoredTags: []string{"host:host2", "host:host1"}}
+	assert.Empty(t, compareSnapshots(expected, testSnapshot(opts, 10, "host2"), opts))
+}
+
+func TestSnapshotReadWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check-snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.json")
+	s := testSnapshot(snapshotOptions{}, 10, "host1")
+	require.NoError(t, writeSnapshot(path, s))
+
+	read, err := readSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, s, read)
+	assert.Empty(t, compareSnapshots(s, read, snapshotOptions{}))
+}
@@ -28,6 +28,7 @@ func installClusterCheckEndpoints(r *mux.Router, sc clusteragent.ServerContext)
 	r.HandleFunc("/clusterchecks/status/{nodeName}", postCheckStatus(sc)).Methods("POST")
 	r.HandleFunc("/clusterchecks/configs/{nodeName}", getCheckConfigs(sc)).Methods("GET")
 	r.HandleFunc("/clusterchecks", getState(sc)).Methods("GET")
+	r.HandleFunc("/clusterchecks/rebalance", getRebalancePlan(sc)).Methods("GET")
 }
 
 // postCheckStatus is used by the node-agent's config provider
@@ -114,6 +115,25 @@ func getState(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http
 	}
 }
 
+// getRebalancePlan is used by the clusterchecks rebalance command
+func getRebalancePlan(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http.Request) {
+	if sc.ClusterCheckHandler == nil {
+		return clusterChecksDisabledHandler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// No redirection for this one, internal endpoint
+		response, err := sc.ClusterCheckHandler.GetRebalancePlan()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			incrementRequestMetric("getRebalancePlan", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONResponse(w, response, "getRebalancePlan")
+	}
+}
+
 // writeJSONResponse serialises and writes data to the response
 func writeJSONResponse(w http.ResponseWriter, data interface{}, handler string) {
 	slcB, err := json.Marshal(data)
@@ -19,35 +19,54 @@ import (
 )
 
 func GetClusterChecksCobraCmd(flagNoColor *bool, confPath *string, loggerName config.LoggerName) *cobra.Command {
+	setup := func() error {
+		if *flagNoColor {
+			color.NoColor = true
+		}
+
+		// we'll search for a config file named `datadog-cluster.yaml`
+		config.Datadog.SetConfigName("datadog-cluster")
+		err := common.SetupConfig(*confPath)
+		if err != nil {
+			return fmt.Errorf("unable to set up global cluster agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+		return nil
+	}
+
 	clusterChecksCmd := &cobra.Command{
 		Use:   "clusterchecks",
 		Short: "Prints the active cluster check configurations",
 		RunE: func(cmd *cobra.Command, args []string) error {
-
-			if *flagNoColor {
-				color.NoColor = true
-			}
-
-			// we'll search for a config file named `datadog-cluster.yaml`
-			config.Datadog.SetConfigName("datadog-cluster")
-			err := common.SetupConfig(*confPath)
-			if err != nil {
-				return fmt.Errorf("unable to set up global cluster agent configuration: %v", err)
+			if err := setup(); err != nil {
+				return err
 			}
 
-			err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
-			if err != nil {
-				fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			if err := flare.GetClusterChecks(color.Output); err != nil {
 				return err
 			}
 
-			if err = flare.GetClusterChecks(color.Output); err != nil {
+			return flare.GetEndpointsChecks(color.Output)
+		},
+	}
+
+	rebalanceCmd := &cobra.Command{
+		Use:   "rebalance",
+		Short: "Prints the checks the cost rebalance strategy would move, without moving them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setup(); err != nil {
 				return err
 			}
 
-			return flare.GetEndpointsChecks(color.Output)
+			return flare.GetClusterChecksRebalancePlan(color.Output)
 		},
 	}
+	clusterChecksCmd.AddCommand(rebalanceCmd)
 
 	return clusterChecksCmd
 }
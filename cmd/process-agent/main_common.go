@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/process/statsd"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/remote"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -109,6 +110,14 @@ func runAgent(exit chan struct{}) {
 	log.Infof("running version: %s", versionString(", "))
 
 	// Tagger must be initialized after agent config has been setup
+	if ddconfig.Datadog.GetBool("process_config.remote_tagger") {
+		t := remote.NewTagger()
+		if err := t.Start(); err != nil {
+			log.Errorf("Unable to start the remote tagger, collecting tags locally: %s", err)
+		} else {
+			tagger.SetDefaultTagger(t)
+		}
+	}
 	tagger.Init()
 	defer tagger.Stop() //nolint:errcheck
 
@@ -100,6 +100,17 @@ func (nt *networkTracer) Register(httpMux *http.ServeMux) error {
 		utils.WriteAsJSON(w, stats)
 	})
 
+	httpMux.HandleFunc("/debug/dns_stats", func(w http.ResponseWriter, req *http.Request) {
+		stats, err := nt.tracer.DebugDNSStats()
+		if err != nil {
+			log.Errorf("unable to retrieve DNS stats: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, stats)
+	})
+
 	// Convenience logging if nothing has made any requests to the system-probe in some time, let's log something.
 	// This should be helpful for customers + support to debug the underlying issue.
 	time.AfterFunc(inactivityLogDuration, func() {
@@ -6,10 +6,15 @@
 package util
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 )
 
@@ -97,6 +102,24 @@ func Validate(w http.ResponseWriter, r *http.Request) error {
 	return err
 }
 
+// ValidateGRPCRequest validates the session token sent in the metadata of a gRPC call
+func ValidateGRPCRequest(ctx context.Context) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md.Get("authorization")) == 0 {
+		return status.Error(codes.Unauthenticated, "no session token provided")
+	}
+
+	tok := strings.Split(md.Get("authorization")[0], " ")
+	if tok[0] != "Bearer" {
+		return status.Errorf(codes.Unauthenticated, "unsupported authorization scheme: %s", tok[0])
+	}
+
+	if len(tok) < 2 || tok[1] != GetAuthToken() {
+		return status.Error(codes.PermissionDenied, "invalid session token")
+	}
+	return nil
+}
+
 // ValidateDCARequest is used for the exposed endpoints of the DCA.
 // It is different from Validate as we want to have different validations.
 func ValidateDCARequest(w http.ResponseWriter, r *http.Request) error {
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -204,29 +205,7 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 		}
 
 		if fileConfPd, ok := pd.provider.(*providers.FileConfigProvider); ok {
-			var goodConfs []integration.Config
-			for _, cfg := range cfgs {
-				// JMX checks can have 2 YAML files: one containing the metrics to collect, one containing the
-				// instance configuration
-				// If the file provider finds any of these metric YAMLs, we store them in a map for future access
-				if cfg.MetricConfig != nil {
-					// We don't want to save metric files, it's enough to store them in the map
-					ac.store.setJMXMetricsForConfigName(cfg.Name, cfg.MetricConfig)
-					continue
-				}
-
-				goodConfs = append(goodConfs, cfg)
-
-				// Clear any old errors if a valid config file is found
-				errorStats.removeConfigError(cfg.Name)
-			}
-
-			// Grab any errors that occurred when reading the YAML file
-			for name, e := range fileConfPd.Errors {
-				errorStats.setConfigError(name, e)
-			}
-
-			cfgs = goodConfs
+			cfgs = ac.processFileConfigs(fileConfPd, cfgs)
 		}
 		// Store all raw configs in the provider
 		pd.configs = cfgs
@@ -242,6 +221,30 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 	return resolvedConfigs
 }
 
+// processFileConfigs stores the JMX metrics files collected by the file provider
+// and its errors, and returns the other configs
+func (ac *AutoConfig) processFileConfigs(fileConfPd *providers.FileConfigProvider, cfgs []integration.Config) []integration.Config {
+	var goodConfs []integration.Config
+	for _, cfg := range cfgs {
+		// JMX checks can have 2 YAML files: one containing the metrics to collect, one containing the
+		// instance configuration
+		// If the file provider finds any of these metric YAMLs, we store them in a map for future access
+		if cfg.MetricConfig != nil {
+			// We don't want to save metric files, it's enough to store them in the map
+			ac.store.setJMXMetricsForConfigName(cfg.Name, cfg.MetricConfig)
+			continue
+		}
+
+		goodConfs = append(goodConfs, cfg)
+	}
+
+	// Grab the errors that occurred when reading the YAML files, clearing the
+	// ones of the files which were fixed or removed
+	errorStats.setConfigErrors(fileConfPd.Errors)
+
+	return goodConfs
+}
+
 // schedule takes a slice of configs and schedule them
 func (ac *AutoConfig) schedule(configs []integration.Config) {
 	ac.scheduler.Schedule(configs)
@@ -409,28 +412,35 @@ func (ac *AutoConfig) RemoveScheduler(name string) {
 func decryptConfig(conf integration.Config) (integration.Config, error) {
 	var err error
 
+	// Only the configuration files can use the built-in secret backends, the
+	// other providers and the templates get values from containers or services
+	decrypt := secrets.DecryptWithoutBuiltinBackends
+	if conf.Provider == names.File && !conf.IsTemplate() {
+		decrypt = secrets.Decrypt
+	}
+
 	// init_config
-	conf.InitConfig, err = secrets.Decrypt(conf.InitConfig, conf.Name)
+	conf.InitConfig, err = decrypt(conf.InitConfig, conf.Name)
 	if err != nil {
 		return conf, fmt.Errorf("error while decrypting secrets in 'init_config': %s", err)
 	}
 
 	// instances
 	for idx := range conf.Instances {
-		conf.Instances[idx], err = secrets.Decrypt(conf.Instances[idx], conf.Name)
+		conf.Instances[idx], err = decrypt(conf.Instances[idx], conf.Name)
 		if err != nil {
 			return conf, fmt.Errorf("error while decrypting secrets in an instance: %s", err)
 		}
 	}
 
 	// metrics
-	conf.MetricConfig, err = secrets.Decrypt(conf.MetricConfig, conf.Name)
+	conf.MetricConfig, err = decrypt(conf.MetricConfig, conf.Name)
 	if err != nil {
 		return conf, fmt.Errorf("error while decrypting secrets in 'metrics': %s", err)
 	}
 
 	// logs
-	conf.LogsConfig, err = secrets.Decrypt(conf.LogsConfig, conf.Name)
+	conf.LogsConfig, err = decrypt(conf.LogsConfig, conf.Name)
 	if err != nil {
 		return conf, fmt.Errorf("error while decrypting secrets 'logs': %s", err)
 	}
@@ -463,6 +473,53 @@ func (ac *AutoConfig) removeConfigTemplates(configs []integration.Config) {
 	}
 }
 
+// RescheduleConfigs unschedules the configurations of the given checks and
+// schedules them again, decrypting their secrets again. It's used when the
+// value of a secret used by these checks changed.
+func (ac *AutoConfig) RescheduleConfigs(names []string) {
+	if len(names) == 0 {
+		return
+	}
+	nameSet := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		nameSet[name] = struct{}{}
+	}
+
+	// collect the raw configurations from the providers, before their secrets were decrypted
+	var rawConfigs []integration.Config
+	ac.m.RLock()
+	for _, pd := range ac.providers {
+		for _, config := range pd.configs {
+			if _, found := nameSet[config.Name]; found {
+				config.Provider = pd.provider.String()
+				rawConfigs = append(rawConfigs, config)
+			}
+		}
+	}
+	ac.m.RUnlock()
+
+	if len(rawConfigs) == 0 {
+		return
+	}
+
+	// remove the loaded configurations: the templates remove their resolved
+	// configurations, the others are found by name
+	var removed []integration.Config
+	for _, config := range ac.store.getLoadedConfigs() {
+		if _, found := nameSet[config.Name]; found && config.Entity == "" {
+			removed = append(removed, config)
+		}
+	}
+	ac.processRemovedConfigs(removed)
+	ac.removeConfigTemplates(rawConfigs)
+
+	for _, config := range rawConfigs {
+		log.Infof("Rescheduling the configurations of %s after a secret change", config.Name)
+		resolvedConfigs := ac.processNewConfig(config)
+		ac.schedule(resolvedConfigs)
+	}
+}
+
 // resolveTemplate attempts to resolve a configuration template using the AD
 // identifiers in the `integration.Config` struct to match a Service.
 //
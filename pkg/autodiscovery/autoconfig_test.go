@@ -346,6 +346,59 @@ func TestRemoveTemplate(t *testing.T) {
 	assert.Len(t, ac.GetLoadedConfigs(), 1)
 }
 
+type recordingScheduler struct {
+	scheduled   []string
+	unscheduled []string
+}
+
+func (s *recordingScheduler) Schedule(configs []integration.Config) {
+	for _, c := range configs {
+		s.scheduled = append(s.scheduled, c.Name)
+	}
+}
+
+func (s *recordingScheduler) Unschedule(configs []integration.Config) {
+	for _, c := range configs {
+		s.unscheduled = append(s.unscheduled, c.Name)
+	}
+}
+
+func (s *recordingScheduler) Stop() {}
+
+func TestRescheduleConfigs(t *testing.T) {
+	ms := scheduler.NewMetaScheduler()
+	ac := NewAutoConfig(ms)
+
+	static := integration.Config{Name: "memory"}
+	other := integration.Config{Name: "disk"}
+	tpl := integration.Config{Name: "cpu", ADIdentifiers: []string{"redis"}}
+	ac.providers = append(ac.providers, newConfigPoller(&MockProvider{}, false, 0))
+	ac.providers[0].configs = []integration.Config{static, other, tpl}
+
+	service := dummyService{
+		ID:            "a5901276aed16ae9ea11660a41fecd674da47e8f5d8d5bce0080a611feed2be9",
+		ADIdentifiers: []string{"redis"},
+	}
+	ac.processNewService(&service)
+	for _, c := range ac.providers[0].configs {
+		ac.processNewConfig(c)
+	}
+	assert.Len(t, ac.GetLoadedConfigs(), 3)
+
+	sch := &recordingScheduler{}
+	ms.Register("recording", sch)
+
+	// unknown checks are ignored
+	ac.RescheduleConfigs([]string{"unknown"})
+	assert.Empty(t, sch.unscheduled)
+	assert.Empty(t, sch.scheduled)
+
+	ac.RescheduleConfigs([]string{"memory", "cpu"})
+	assert.ElementsMatch(t, []string{"memory", "cpu"}, sch.unscheduled)
+	assert.ElementsMatch(t, []string{"memory", "cpu"}, sch.scheduled)
+	assert.Len(t, ac.GetLoadedConfigs(), 3)
+}
+
 func TestGetLoadedConfigNotInitialized(t *testing.T) {
 	ac := AutoConfig{}
 	cfgs := ac.GetLoadedConfigs()
@@ -90,7 +90,7 @@ func (pd *configPoller) poll(ac *AutoConfig) {
 
 			// retrieve the list of newly added configurations as well
 			// as removed configurations
-			newConfigs, removedConfigs := pd.collect()
+			newConfigs, removedConfigs := pd.collect(ac)
 			if len(newConfigs) > 0 || len(removedConfigs) > 0 {
 				log.Infof("%v provider: collected %d new configurations, removed %d", pd.provider, len(newConfigs), len(removedConfigs))
 			} else {
@@ -113,7 +113,7 @@ func (pd *configPoller) poll(ac *AutoConfig) {
 
 // collect is just a convenient wrapper to fetch configurations from a provider and
 // see what changed from the last time we called Collect().
-func (pd *configPoller) collect() ([]integration.Config, []integration.Config) {
+func (pd *configPoller) collect(ac *AutoConfig) ([]integration.Config, []integration.Config) {
 	var newConf []integration.Config
 	var removedConf []integration.Config
 	old := pd.configs
@@ -124,6 +124,10 @@ func (pd *configPoller) collect() ([]integration.Config, []integration.Config) {
 		return nil, nil
 	}
 
+	if fileConfPd, ok := pd.provider.(*providers.FileConfigProvider); ok {
+		fetched = ac.processFileConfigs(fileConfPd, fetched)
+	}
+
 	for _, c := range fetched {
 		if !pd.contains(&c) {
 			newConf = append(newConf, c)
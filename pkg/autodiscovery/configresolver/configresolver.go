@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -28,6 +29,7 @@ var templateVariables = map[string]variableGetter{
 	"port":     getPort,
 	"hostname": getHostname,
 	"extra":    getExtra,
+	"kube":     getKube,
 }
 
 // SubstituteTemplateVariables replaces %%VARIABLES%% using the variableGetters passed in
@@ -73,6 +75,29 @@ func SubstituteTemplateEnvVars(config *integration.Config) error {
 	return retErr
 }
 
+// ValidateTemplateVariables resolves every template variable of the template
+// instances against the service and returns an error for each one which can't
+// be resolved, unlike Resolve which stops at the first error.
+func ValidateTemplateVariables(tpl integration.Config, svc listeners.Service) []error {
+	var errs []error
+	for i := 0; i < len(tpl.Instances); i++ {
+		for _, v := range tpl.GetTemplateVariablesForInstance(i) {
+			var err error
+			if f, found := templateVariables[string(v.Name)]; found {
+				_, err = f(v.Key, svc)
+			} else if string(v.Name) == "env" {
+				_, err = getEnvvar(v.Key)
+			} else {
+				err = fmt.Errorf("unknown template variable")
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("instance %d: %s: %s", i, v.Raw, err))
+			}
+		}
+	}
+	return errs
+}
+
 // Resolve takes a template and a service and generates a config with
 // valid connection info and relevant tags.
 func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
@@ -253,6 +278,49 @@ func getExtra(tplVar []byte, svc listeners.Service) ([]byte, error) {
 	return value, nil
 }
 
+// getKube returns the Kubernetes metadata of the service: kube_namespace,
+// kube_pod_name, kube_container_name, kube_image_tag, kube_label_<name> or
+// kube_annotation_<name>. Absent metadata resolves to an empty string, or
+// to the default value given after a pipe, e.g. %%kube_label_app|web%%.
+func getKube(tplVar []byte, svc listeners.Service) ([]byte, error) {
+	key, defaultValue := string(tplVar), ""
+	if idx := strings.IndexByte(key, '|'); idx >= 0 {
+		key, defaultValue = key[:idx], key[idx+1:]
+	}
+
+	var meta *listeners.KubeMetadata
+	if kubeSvc, ok := svc.(listeners.KubeService); ok {
+		meta = kubeSvc.GetKubeMetadata()
+	}
+	if meta == nil {
+		meta = &listeners.KubeMetadata{}
+	}
+
+	var value string
+	switch {
+	case key == "namespace":
+		value = meta.Namespace
+	case key == "pod_name":
+		value = meta.PodName
+	case key == "container_name":
+		value = meta.ContainerName
+	case key == "image_tag":
+		value = meta.ImageTag
+	case strings.HasPrefix(key, "label_"):
+		value = meta.Labels[strings.TrimPrefix(key, "label_")]
+	case strings.HasPrefix(key, "annotation_"):
+		value = meta.Annotations[strings.TrimPrefix(key, "annotation_")]
+	default:
+		return nil, fmt.Errorf("unknown kube template variable %q for service %s, skipping config", key, svc.GetEntity())
+	}
+
+	if value == "" {
+		log.Debugf("No value for kube template variable %q of service %s, using %q", key, svc.GetEntity(), defaultValue)
+		value = defaultValue
+	}
+	return []byte(value), nil
+}
+
 // getEnvvar returns a system environment variable if found
 func getEnvvar(envVar []byte) ([]byte, error) {
 	if len(envVar) == 0 {
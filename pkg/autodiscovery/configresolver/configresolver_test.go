@@ -98,6 +98,16 @@ func (s *dummyService) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte(s.ExtraConfig[string(key)]), nil
 }
 
+type dummyKubeService struct {
+	dummyService
+	KubeMetadata *listeners.KubeMetadata
+}
+
+// GetKubeMetadata returns dummy Kubernetes metadata
+func (s *dummyKubeService) GetKubeMetadata() *listeners.KubeMetadata {
+	return s.KubeMetadata
+}
+
 func TestGetFallbackHost(t *testing.T) {
 	ip, err := getFallbackHost(map[string]string{"bridge": "172.17.0.1"})
 	assert.Equal(t, "172.17.0.1", ip)
@@ -558,6 +568,93 @@ func TestResolve(t *testing.T) {
 				Entity:        "a5901276aed1",
 			},
 		},
+		//// kube template vars
+		{
+			testName: "kube metadata",
+			svc: &dummyKubeService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+				KubeMetadata: &listeners.KubeMetadata{
+					Namespace:     "prod",
+					PodName:       "redis-0",
+					ContainerName: "redis",
+					ImageTag:      "6.0",
+					Labels:        map[string]string{"app.kubernetes.io/name": "cache"},
+					Annotations:   map[string]string{"team": "storage"},
+				},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances: []integration.Data{integration.Data(
+					"service: %%kube_label_app.kubernetes.io/name%%\n" +
+						"team: %%kube_annotation_team%%\nversion: '%%kube_image_tag%%'\n" +
+						"name: %%kube_namespace%%/%%kube_pod_name%%/%%kube_container_name%%")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances: []integration.Data{integration.Data(
+					"service: cache\n" +
+						"team: storage\nversion: '6.0'\n" +
+						"name: prod/redis-0/redis")},
+				Entity: "a5901276aed1",
+			},
+		},
+		{
+			testName: "absent kube metadata",
+			svc: &dummyKubeService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+				KubeMetadata: &listeners.KubeMetadata{Namespace: "prod"},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: %%kube_label_app|redis%%\nteam: '%%kube_annotation_team%%'")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: redis\nteam: ''")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "kube template vars on non-kube service",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: %%kube_label_app|redis%%")},
+			},
+			out: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: redis")},
+				Entity:        "a5901276aed1",
+			},
+		},
+		{
+			testName: "unknown kube template var",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "cpu",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("service: %%kube_foo%%")},
+			},
+			errorString: "unknown kube template variable \"foo\" for service a5901276aed1, skipping config",
+		},
 	}
 	validTemplates := 0
 
@@ -579,6 +676,32 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+func TestValidateTemplateVariables(t *testing.T) {
+	os.Setenv("test_envvar_key", "test_value")
+	os.Unsetenv("test_envvar_not_set")
+	defer os.Unsetenv("test_envvar_key")
+
+	svc := &dummyService{
+		ID:            "a5901276aed1",
+		ADIdentifiers: []string{"redis"},
+		Hosts:         map[string]string{"bridge": "127.0.0.1"},
+	}
+	tpl := integration.Config{
+		Name:          "cpu",
+		ADIdentifiers: []string{"redis"},
+		Instances: []integration.Data{
+			integration.Data("host: %%host%%\nport: %%port%%\nkey: %%env_test_envvar_key%%"),
+			integration.Data("host: %%host_custom%%\npassword: %%env_test_envvar_not_set%%\nfoo: %%foo%%"),
+		},
+	}
+
+	errs := ValidateTemplateVariables(tpl, svc)
+	require.Len(t, errs, 3)
+	assert.EqualError(t, errs[0], "instance 0: %%port%%: no port found for container a5901276aed1 - ignoring it")
+	assert.EqualError(t, errs[1], "instance 1: %%env_test_envvar_not_set%%: failed to retrieve envvar test_envvar_not_set")
+	assert.EqualError(t, errs[2], "instance 1: %%foo%%: unknown template variable")
+}
+
 func newFakeContainerPorts() []listeners.ContainerPort {
 	return []listeners.ContainerPort{
 		{Port: 1, Name: "foo"},
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/configresolver"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+)
+
+// TemplateTestResult is the outcome of the resolution of a template against a service
+type TemplateTestResult struct {
+	Template integration.Config  `json:"template"`
+	Entity   string              `json:"entity"`
+	Resolved *integration.Config `json:"resolved,omitempty"`
+	Warnings []string            `json:"warnings,omitempty"`
+	Errors   []string            `json:"errors,omitempty"`
+}
+
+// TemplateTestRequest asks a running agent to test templates against one of its services
+type TemplateTestRequest struct {
+	Entity    string               `json:"entity"`
+	Templates []integration.Config `json:"templates"`
+}
+
+// TestTemplate resolves the template against the service like before scheduling
+// a config, without scheduling it. Every template variable which can't be resolved
+// and every invalid instance is reported. Secrets are not decrypted: the resolved
+// config is sent back to the caller, so its ENC[] handles are left as they are.
+func TestTemplate(tpl integration.Config, svc listeners.Service) TemplateTestResult {
+	result := TemplateTestResult{
+		Template: tpl,
+		Entity:   svc.GetEntity(),
+	}
+
+	if len(tpl.ADIdentifiers) > 0 && !matchesADIdentifiers(tpl, svc) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("the service doesn't have any of the AD identifiers of the template %v, it wouldn't be resolved against it", tpl.ADIdentifiers))
+	}
+	if !svc.IsReady() {
+		result.Warnings = append(result.Warnings, "the service isn't ready, check configs are only resolved against ready services")
+	}
+
+	for _, err := range configresolver.ValidateTemplateVariables(tpl, svc) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	if len(result.Errors) > 0 {
+		return result
+	}
+
+	config, err := configresolver.Resolve(tpl, svc)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	result.Resolved = &config
+
+	for _, err := range validateConfig(config) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	return result
+}
+
+// TestTemplateForEntity runs TestTemplate against the service of the entity, which
+// is either the entity or the tagger entity of a service known by AutoConfig
+func (ac *AutoConfig) TestTemplateForEntity(tpl integration.Config, entity string) (TemplateTestResult, error) {
+	svc := ac.store.getServiceForEntity(entity)
+	if svc == nil {
+		for _, s := range ac.store.getServices() {
+			if s.GetTaggerEntity() == entity {
+				svc = s
+				break
+			}
+		}
+	}
+	if svc == nil {
+		return TemplateTestResult{}, fmt.Errorf("no service found for entity %s", entity)
+	}
+	return TestTemplate(tpl, svc), nil
+}
+
+func matchesADIdentifiers(tpl integration.Config, svc listeners.Service) bool {
+	svcIDs, err := svc.GetADIdentifiers()
+	if err != nil {
+		return false
+	}
+	for _, id := range tpl.ADIdentifiers {
+		for _, svcID := range svcIDs {
+			if id == svcID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateConfig checks the sections of a resolved config are YAML mappings, and
+// that the fields of the instances reserved to the agent have the right type
+func validateConfig(config integration.Config) []error {
+	var errs []error
+
+	if len(config.InitConfig) > 0 {
+		var initConfig interface{}
+		if err := yaml.Unmarshal(config.InitConfig, &initConfig); err != nil {
+			errs = append(errs, fmt.Errorf("init_config is not valid YAML: %s", err))
+		} else if _, ok := initConfig.(integration.RawMap); !ok && initConfig != nil {
+			errs = append(errs, fmt.Errorf("init_config is not a mapping"))
+		}
+	}
+
+	for i, instance := range config.Instances {
+		var rawInstance interface{}
+		if err := yaml.Unmarshal(instance, &rawInstance); err != nil {
+			errs = append(errs, fmt.Errorf("instance %d is not valid YAML: %s", i, err))
+			continue
+		}
+		if _, ok := rawInstance.(integration.RawMap); !ok {
+			errs = append(errs, fmt.Errorf("instance %d is not a mapping", i))
+			continue
+		}
+		var common integration.CommonInstanceConfig
+		if err := yaml.Unmarshal(instance, &common); err != nil {
+			errs = append(errs, fmt.Errorf("instance %d: %s", i, err))
+		}
+	}
+	return errs
+}
+
+// MockedService is a service described by the user, to test templates against it
+type MockedService struct {
+	Entity        string                    `yaml:"entity"`
+	ADIdentifiers []string                  `yaml:"ad_identifiers"`
+	Hosts         map[string]string         `yaml:"hosts"`
+	Ports         []listeners.ContainerPort `yaml:"ports"`
+	Pid           int                       `yaml:"pid"`
+	Hostname      string                    `yaml:"hostname"`
+	Tags          []string                  `yaml:"tags"`
+	Extra         map[string]string         `yaml:"extra"`
+	Kube          *listeners.KubeMetadata   `yaml:"kube"`
+}
+
+// Make sure MockedService implements the Service and KubeService interfaces
+var _ listeners.Service = &MockedService{}
+var _ listeners.KubeService = &MockedService{}
+
+// GetEntity returns the entity of the service
+func (s *MockedService) GetEntity() string {
+	return s.Entity
+}
+
+// GetTaggerEntity returns the entity of the service
+func (s *MockedService) GetTaggerEntity() string {
+	return s.Entity
+}
+
+// GetADIdentifiers returns the AD identifiers of the service
+func (s *MockedService) GetADIdentifiers() ([]string, error) {
+	return s.ADIdentifiers, nil
+}
+
+// GetHosts returns the hosts of the service
+func (s *MockedService) GetHosts() (map[string]string, error) {
+	return s.Hosts, nil
+}
+
+// GetPorts returns the ports of the service
+func (s *MockedService) GetPorts() ([]listeners.ContainerPort, error) {
+	return s.Ports, nil
+}
+
+// GetTags returns the tags of the service
+func (s *MockedService) GetTags() ([]string, error) {
+	return s.Tags, nil
+}
+
+// GetPid returns the pid of the service, if described
+func (s *MockedService) GetPid() (int, error) {
+	if s.Pid == 0 {
+		return -1, listeners.ErrNotSupported
+	}
+	return s.Pid, nil
+}
+
+// GetHostname returns the hostname of the service, if described
+func (s *MockedService) GetHostname() (string, error) {
+	if s.Hostname == "" {
+		return "", listeners.ErrNotSupported
+	}
+	return s.Hostname, nil
+}
+
+// GetCreationTime returns integration.After
+func (s *MockedService) GetCreationTime() integration.CreationTime {
+	return integration.After
+}
+
+// IsReady returns true
+func (s *MockedService) IsReady() bool {
+	return true
+}
+
+// GetCheckNames returns nil
+func (s *MockedService) GetCheckNames() []string {
+	return nil
+}
+
+// HasFilter returns false
+func (s *MockedService) HasFilter(filter containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig returns the extra value of the service for the key
+func (s *MockedService) GetExtraConfig(key []byte) ([]byte, error) {
+	value, found := s.Extra[string(key)]
+	if !found {
+		return []byte{}, listeners.ErrNotSupported
+	}
+	return []byte(value), nil
+}
+
+// GetKubeMetadata returns the Kubernetes metadata of the service
+func (s *MockedService) GetKubeMetadata() *listeners.KubeMetadata {
+	return s.Kube
+}
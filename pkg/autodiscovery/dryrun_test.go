@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
+)
+
+func TestMockedServiceFromYAML(t *testing.T) {
+	var svc MockedService
+	err := yaml.Unmarshal([]byte(`
+entity: docker://abcdef
+ad_identifiers: [redis]
+hosts:
+  bridge: 172.17.0.2
+ports:
+  - port: 6379
+    name: redis
+extra:
+  user: admin
+kube:
+  namespace: prod
+  pod_name: redis-0
+  labels:
+    app: cache
+`), &svc)
+	require.NoError(t, err)
+
+	assert.Equal(t, []listeners.ContainerPort{{Port: 6379, Name: "redis"}}, svc.Ports)
+	assert.Equal(t, "redis-0", svc.GetKubeMetadata().PodName)
+	assert.Equal(t, "cache", svc.GetKubeMetadata().Labels["app"])
+	_, err = svc.GetPid()
+	assert.Equal(t, listeners.ErrNotSupported, err)
+}
+
+func TestTestTemplate(t *testing.T) {
+	svc := &MockedService{
+		Entity:        "docker://abcdef",
+		ADIdentifiers: []string{"redis"},
+		Hosts:         map[string]string{"bridge": "172.17.0.2"},
+		Ports:         []listeners.ContainerPort{{Port: 6379, Name: "redis"}},
+		Kube:          &listeners.KubeMetadata{Labels: map[string]string{"app": "cache"}},
+	}
+
+	// valid template
+	result := TestTemplate(integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%\nport: %%port_redis%%\nservice: %%kube_label_app%%")},
+	}, svc)
+	assert.Equal(t, "docker://abcdef", result.Entity)
+	assert.Empty(t, result.Warnings)
+	assert.Empty(t, result.Errors)
+	require.NotNil(t, result.Resolved)
+	assert.Equal(t, []integration.Data{integration.Data("host: 172.17.0.2\nport: 6379\nservice: cache")}, result.Resolved.Instances)
+
+	// secrets are not decrypted
+	result = TestTemplate(integration.Config{
+		Name:      "redisdb",
+		Instances: []integration.Data{integration.Data("host: %%host%%\npassword: ENC[redis_password]")},
+	}, svc)
+	assert.Empty(t, result.Errors)
+	require.NotNil(t, result.Resolved)
+	assert.Equal(t, []integration.Data{integration.Data("host: 172.17.0.2\npassword: ENC[redis_password]")}, result.Resolved.Instances)
+
+	// every unresolvable variable is reported
+	result = TestTemplate(integration.Config{
+		Name:          "redisdb",
+		ADIdentifiers: []string{"memcached"},
+		Instances:     []integration.Data{integration.Data("host: %%host%%\nport: %%port_foo%%\npid: %%pid%%")},
+	}, svc)
+	assert.Len(t, result.Warnings, 1)
+	assert.Nil(t, result.Resolved)
+	assert.Equal(t, []string{
+		"instance 0: %%port_foo%%: port foo not found, skipping container docker://abcdef",
+		"instance 0: %%pid%%: failed to get pid for service docker://abcdef, skipping config - AD: variable not supported by listener",
+	}, result.Errors)
+
+	// schema issues of the resolved config are reported
+	result = TestTemplate(integration.Config{
+		Name:       "redisdb",
+		InitConfig: integration.Data("- foo"),
+		Instances:  []integration.Data{integration.Data("host: %%host%%\nmin_collection_interval: often")},
+	}, svc)
+	assert.Empty(t, result.Warnings)
+	require.NotNil(t, result.Resolved)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, "init_config is not a mapping", result.Errors[0])
+	assert.Contains(t, result.Errors[1], "instance 0: yaml: unmarshal errors")
+
+	// resolution errors are reported
+	result = TestTemplate(integration.Config{
+		Name:      "redisdb",
+		Instances: []integration.Data{integration.Data("- %%host%%")},
+	}, svc)
+	assert.Nil(t, result.Resolved)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "unable to add tags for service 'docker://abcdef'")
+}
+
+func TestTestTemplateForEntity(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	svc := &MockedService{Entity: "docker://abcdef", Hosts: map[string]string{"bridge": "172.17.0.2"}}
+	ac.store.setServiceForEntity(svc, svc.GetEntity())
+
+	tpl := integration.Config{Name: "redisdb", Instances: []integration.Data{integration.Data("host: %%host%%")}}
+	result, err := ac.TestTemplateForEntity(tpl, "docker://abcdef")
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+
+	_, err = ac.TestTemplateForEntity(tpl, "docker://123456")
+	assert.EqualError(t, err, "no service found for entity docker://123456")
+}
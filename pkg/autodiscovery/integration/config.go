@@ -60,6 +60,7 @@ type Config struct {
 // CommonInstanceConfig holds the reserved fields for the yaml instance data
 type CommonInstanceConfig struct {
 	MinCollectionInterval int      `yaml:"min_collection_interval"`
+	CheckTimeout          int      `yaml:"check_timeout"`
 	EmptyDefaultHostname  bool     `yaml:"empty_default_hostname"`
 	Tags                  []string `yaml:"tags"`
 	Service               string   `yaml:"service"`
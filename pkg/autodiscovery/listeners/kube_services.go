@@ -45,10 +45,12 @@ type KubeServiceService struct {
 	hosts        map[string]string
 	ports        []ContainerPort
 	creationTime integration.CreationTime
+	kubeMetadata *KubeMetadata
 }
 
-// Make sure KubeServiceService implements the Service interface
+// Make sure KubeServiceService implements the Service and KubeService interfaces
 var _ Service = &KubeServiceService{}
+var _ KubeService = &KubeServiceService{}
 
 func init() {
 	Register("kube_services", NewKubeServiceListener)
@@ -193,6 +195,11 @@ func processService(ksvc *v1.Service, firstRun bool) *KubeServiceService {
 	svc := &KubeServiceService{
 		entity:       apiserver.EntityForService(ksvc),
 		creationTime: integration.After,
+		kubeMetadata: &KubeMetadata{
+			Namespace:   ksvc.Namespace,
+			Labels:      ksvc.GetLabels(),
+			Annotations: ksvc.GetAnnotations(),
+		},
 	}
 	if firstRun {
 		svc.creationTime = integration.Before
@@ -313,3 +320,8 @@ func (s *KubeServiceService) HasFilter(filter containers.FilterType) bool {
 func (s *KubeServiceService) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte{}, ErrNotSupported
 }
+
+// GetKubeMetadata returns the namespace, labels and annotations of the service
+func (s *KubeServiceService) GetKubeMetadata() *KubeMetadata {
+	return s.kubeMetadata
+}
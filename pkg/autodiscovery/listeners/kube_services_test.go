@@ -76,6 +76,11 @@ func TestProcessService(t *testing.T) {
 	sort.Strings(tags)
 	assert.Equal(t, expectedTags, tags)
 
+	meta := svc.GetKubeMetadata()
+	assert.Equal(t, "default", meta.Namespace)
+	assert.Equal(t, "dev", meta.Labels["tags.datadoghq.com/env"])
+	assert.Equal(t, "[{}]", meta.Annotations["ad.datadoghq.com/service.init_configs"])
+
 	svc = processService(ksvc, false)
 	assert.Equal(t, integration.After, svc.GetCreationTime())
 }
@@ -54,10 +54,12 @@ type KubeContainerService struct {
 	checkNames      []string
 	metricsExcluded bool
 	logsExcluded    bool
+	kubeMetadata    *KubeMetadata
 }
 
-// Make sure KubeContainerService implements the Service interface
+// Make sure KubeContainerService implements the Service and KubeService interfaces
 var _ Service = &KubeContainerService{}
+var _ KubeService = &KubeContainerService{}
 
 // KubePodService registers pod as a Service, implements and store results from the Service interface for the Kubelet listener
 // needed to run checks on pod's endpoints
@@ -67,10 +69,12 @@ type KubePodService struct {
 	hosts         map[string]string
 	ports         []ContainerPort
 	creationTime  integration.CreationTime
+	kubeMetadata  *KubeMetadata
 }
 
-// Make sure KubePodService implements the Service interface
+// Make sure KubePodService implements the Service and KubeService interfaces
 var _ Service = &KubePodService{}
+var _ KubeService = &KubePodService{}
 
 func init() {
 	Register("kubelet", NewKubeletListener)
@@ -192,6 +196,7 @@ func (l *KubeletListener) createPodService(pod *kubelet.Pod, firstRun bool) {
 		hosts:         map[string]string{"pod": podIP},
 		ports:         ports,
 		creationTime:  crTime,
+		kubeMetadata:  podKubeMetadata(pod),
 	}
 
 	l.m.Lock()
@@ -212,6 +217,7 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 		entity:       entity,
 		creationTime: crTime,
 		ready:        kubelet.IsPodReady(pod),
+		kubeMetadata: podKubeMetadata(pod),
 	}
 	podName := pod.Metadata.Name
 
@@ -230,6 +236,7 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 			svc.logsExcluded = l.filters.IsExcluded(containers.LogsFilter, container.Name, container.Image, pod.Metadata.Namespace)
 
 			containerName = container.Name
+			svc.kubeMetadata.ContainerName = container.Name
 
 			// Add container uid as ID
 			svc.adIdentifiers = append(svc.adIdentifiers, entity)
@@ -245,10 +252,11 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 
 			// Add other identifiers if no template found
 			svc.adIdentifiers = append(svc.adIdentifiers, container.Image)
-			_, short, _, err := containers.SplitImageName(container.Image)
+			_, short, tag, err := containers.SplitImageName(container.Image)
 			if err != nil {
 				log.Warnf("Error while spliting image name: %s", err)
 			}
+			svc.kubeMetadata.ImageTag = tag
 			if len(short) > 0 && short != container.Image {
 				svc.adIdentifiers = append(svc.adIdentifiers, short)
 			}
@@ -289,6 +297,16 @@ func (l *KubeletListener) createService(entity string, pod *kubelet.Pod, firstRu
 	l.newService <- &svc
 }
 
+// podKubeMetadata returns the metadata shared by the services of a pod
+func podKubeMetadata(pod *kubelet.Pod) *KubeMetadata {
+	return &KubeMetadata{
+		Namespace:   pod.Metadata.Namespace,
+		PodName:     pod.Metadata.Name,
+		Labels:      pod.Metadata.Labels,
+		Annotations: pod.Metadata.Annotations,
+	}
+}
+
 // podHasADTemplate looks in pod annotations and looks for annotations containing an
 // AD template. It does not try to validate it, just having the `instance` fields is
 // OK to return true.
@@ -399,6 +417,11 @@ func (s *KubeContainerService) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte{}, ErrNotSupported
 }
 
+// GetKubeMetadata returns the metadata of the container and its pod
+func (s *KubeContainerService) GetKubeMetadata() *KubeMetadata {
+	return s.kubeMetadata
+}
+
 // GetCheckNames returns names of checks defined in pod annotations
 func (s *KubeContainerService) GetCheckNames() []string {
 	return s.checkNames
@@ -486,3 +509,8 @@ func (s *KubePodService) HasFilter(filter containers.FilterType) bool {
 func (s *KubePodService) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte{}, ErrNotSupported
 }
+
+// GetKubeMetadata returns the metadata of the pod
+func (s *KubePodService) GetKubeMetadata() *KubeMetadata {
+	return s.kubeMetadata
+}
@@ -164,8 +164,10 @@ func getMockedPods() []*kubelet.Pod {
 			Spec:   kubeletSpec,
 			Status: kubeletStatus,
 			Metadata: kubelet.PodMetadata{
-				UID:  "mock-pod-uid",
-				Name: "mock-pod",
+				UID:       "mock-pod-uid",
+				Name:      "mock-pod",
+				Namespace: "mock-namespace",
+				Labels:    map[string]string{"app": "mock-app"},
 				Annotations: map[string]string{
 					"ad.datadoghq.com/baz.check_names": "[\"baz_check\"]",
 					"ad.datadoghq.com/baz.instances":   "[]",
@@ -215,6 +217,14 @@ func TestProcessNewPod(t *testing.T) {
 		_, err = service.GetPid()
 		assert.Equal(t, ErrNotSupported, err)
 		assert.Len(t, service.GetCheckNames(), 0)
+		assert.Equal(t, &KubeMetadata{
+			Namespace:     "mock-namespace",
+			PodName:       "mock-pod",
+			ContainerName: "foo",
+			ImageTag:      "latest",
+			Labels:        map[string]string{"app": "mock-app"},
+			Annotations:   getMockedPods()[0].Metadata.Annotations,
+		}, service.(KubeService).GetKubeMetadata())
 	default:
 		assert.FailNow(t, "first service not in channel")
 	}
@@ -351,6 +361,12 @@ func TestProcessNewPod(t *testing.T) {
 		assert.Len(t, service.GetCheckNames(), 0)
 		assert.False(t, service.HasFilter(containers.MetricsFilter))
 		assert.False(t, service.HasFilter(containers.LogsFilter))
+		assert.Equal(t, &KubeMetadata{
+			Namespace:   "mock-namespace",
+			PodName:     "mock-pod",
+			Labels:      map[string]string{"app": "mock-app"},
+			Annotations: getMockedPods()[0].Metadata.Annotations,
+		}, service.(KubeService).GetKubeMetadata())
 	default:
 		assert.FailNow(t, "pod service not in channel")
 	}
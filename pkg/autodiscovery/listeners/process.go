@@ -0,0 +1,489 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+// +build linux
+
+package listeners
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	defaultProcessDiscoveryInterval = 30
+	processEntityPrefix             = "process://"
+	// tcpListenState is the state of listening sockets in /proc/net/tcp
+	tcpListenState = "0A"
+)
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// ProcessListenerConfig holds the configuration of the process listener
+type ProcessListenerConfig struct {
+	DiscoveryInterval int             `mapstructure:"discovery_interval"`
+	Processes         []ProcessConfig `mapstructure:"processes"`
+}
+
+// ProcessConfig describes processes to discover. Name and Cmdline are regular
+// expressions matched against the process name and its full command line, a
+// process has to match all of the ones which are set.
+type ProcessConfig struct {
+	ADIdentifier string `mapstructure:"ad_identifier"`
+	Name         string `mapstructure:"name"`
+	Cmdline      string `mapstructure:"cmdline"`
+}
+
+type processMatcher struct {
+	adIdentifier string
+	name         *regexp.Regexp
+	cmdline      *regexp.Regexp
+}
+
+// ProcessListener discovers the processes of the host matching the configured
+// patterns, so that check templates can be applied to services which don't run
+// in containers
+type ProcessListener struct {
+	procRoot   string
+	interval   time.Duration
+	matchers   []processMatcher
+	services   map[int]*ProcessService // keyed by pid
+	newService chan<- Service
+	delService chan<- Service
+	stop       chan bool
+}
+
+// ProcessService implements the Service interface for processes of the host
+type ProcessService struct {
+	entity       string
+	adIdentifier string
+	pid          int
+	startTime    string // tells apart the processes which got the same pid
+	hosts        map[string]string
+	ports        []ContainerPort
+	creationTime integration.CreationTime
+}
+
+// Make sure ProcessService implements the Service interface
+var _ Service = &ProcessService{}
+
+// NewProcessListener creates a ProcessListener
+func NewProcessListener() (ServiceListener, error) {
+	var conf ProcessListenerConfig
+	if err := config.Datadog.UnmarshalKey("process_listener", &conf); err != nil {
+		return nil, err
+	}
+	return newProcessListener(config.Datadog.GetString("procfs_path"), conf)
+}
+
+func newProcessListener(procRoot string, conf ProcessListenerConfig) (*ProcessListener, error) {
+	matchers, err := buildProcessMatchers(conf.Processes)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchers) == 0 {
+		return nil, fmt.Errorf("no processes configured in process_listener.processes")
+	}
+
+	interval := conf.DiscoveryInterval
+	if interval <= 0 {
+		interval = defaultProcessDiscoveryInterval
+	}
+
+	return &ProcessListener{
+		procRoot: procRoot,
+		interval: time.Duration(interval) * time.Second,
+		matchers: matchers,
+		services: make(map[int]*ProcessService),
+		stop:     make(chan bool),
+	}, nil
+}
+
+func buildProcessMatchers(processes []ProcessConfig) ([]processMatcher, error) {
+	matchers := make([]processMatcher, 0, len(processes))
+	for _, p := range processes {
+		if p.Name == "" && p.Cmdline == "" {
+			return nil, fmt.Errorf("process_listener: a name or a cmdline pattern is required for each process")
+		}
+
+		m := processMatcher{adIdentifier: p.ADIdentifier}
+		var err error
+		if p.Name != "" {
+			if m.name, err = regexp.Compile(p.Name); err != nil {
+				return nil, fmt.Errorf("process_listener: invalid name pattern %q: %s", p.Name, err)
+			}
+		}
+		if p.Cmdline != "" {
+			if m.cmdline, err = regexp.Compile(p.Cmdline); err != nil {
+				return nil, fmt.Errorf("process_listener: invalid cmdline pattern %q: %s", p.Cmdline, err)
+			}
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Listen periodically lists the processes of the host
+func (l *ProcessListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {
+	// setup the I/O channels
+	l.newService = newSvc
+	l.delService = delSvc
+
+	go func() {
+		l.refresh(true)
+
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				l.refresh(false)
+			}
+		}
+	}()
+}
+
+// Stop queues a shutdown of ProcessListener
+func (l *ProcessListener) Stop() {
+	l.stop <- true
+}
+
+// refresh creates services for new matching processes, and removes the ones of
+// processes which exited. Processes are identified by their pid and start time,
+// the service of a process whose pid was reused by a new process is removed before
+// the service of the new process is created. The services of processes whose
+// listening ports changed are re-created since the ports are resolved into the
+// check configs.
+func (l *ProcessListener) refresh(firstRun bool) {
+	entries, err := ioutil.ReadDir(l.procRoot)
+	if err != nil {
+		log.Errorf("Couldn't list processes in %s: %s", l.procRoot, err)
+		return
+	}
+
+	crTime := integration.After
+	if firstRun {
+		crTime = integration.Before
+	}
+
+	seen := make(map[int]struct{}, len(l.services))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		svc := l.inspectProcess(pid, crTime)
+		if svc == nil {
+			continue
+		}
+		seen[pid] = struct{}{}
+
+		if prev, found := l.services[pid]; found {
+			if prev.startTime != svc.startTime {
+				log.Debugf("Process %d was replaced by a new process, re-creating its service", pid)
+			} else if !portsEqual(prev.ports, svc.ports) {
+				log.Debugf("Listening ports of process %d changed, re-creating its service", pid)
+			} else {
+				continue
+			}
+			l.delService <- prev
+		}
+		l.services[pid] = svc
+		l.newService <- svc
+	}
+
+	for pid, svc := range l.services {
+		if _, found := seen[pid]; !found {
+			l.delService <- svc
+			delete(l.services, pid)
+		}
+	}
+}
+
+// inspectProcess returns a service for pid if it matches one of the configured patterns
+func (l *ProcessListener) inspectProcess(pid int, crTime integration.CreationTime) *ProcessService {
+	procDir := filepath.Join(l.procRoot, strconv.Itoa(pid))
+
+	rawCmdline, err := ioutil.ReadFile(filepath.Join(procDir, "cmdline"))
+	if err != nil || len(rawCmdline) == 0 {
+		// the process exited or is a kernel thread
+		return nil
+	}
+	cmdline := strings.TrimSpace(strings.Replace(string(rawCmdline), "\x00", " ", -1))
+
+	rawName, err := ioutil.ReadFile(filepath.Join(procDir, "comm"))
+	if err != nil {
+		return nil
+	}
+	name := strings.TrimSpace(string(rawName))
+
+	adIdentifier, matched := l.match(name, cmdline)
+	if !matched {
+		return nil
+	}
+
+	startTime, err := readStartTime(procDir)
+	if err != nil {
+		log.Debugf("Couldn't read the start time of process %d: %s", pid, err)
+		return nil
+	}
+
+	hosts, ports := listeningAddresses(procDir)
+	svc := &ProcessService{
+		entity:       fmt.Sprintf("%s%d", processEntityPrefix, pid),
+		adIdentifier: adIdentifier,
+		pid:          pid,
+		startTime:    startTime,
+		hosts:        hosts,
+		ports:        ports,
+		creationTime: crTime,
+	}
+	return svc
+}
+
+// match returns the AD identifier of the first pattern matching the process,
+// which defaults to the process name
+func (l *ProcessListener) match(name, cmdline string) (string, bool) {
+	for _, m := range l.matchers {
+		if m.name != nil && !m.name.MatchString(name) {
+			continue
+		}
+		if m.cmdline != nil && !m.cmdline.MatchString(cmdline) {
+			continue
+		}
+		if m.adIdentifier != "" {
+			return m.adIdentifier, true
+		}
+		return name, true
+	}
+	return "", false
+}
+
+// readStartTime returns the start time of the process, in clock ticks since boot
+func readStartTime(procDir string) (string, error) {
+	stat, err := ioutil.ReadFile(filepath.Join(procDir, "stat"))
+	if err != nil {
+		return "", err
+	}
+	// the process name, 2nd field, is between parentheses and may contain spaces
+	end := strings.LastIndexByte(string(stat), ')')
+	if end < 0 {
+		return "", fmt.Errorf("malformed stat file")
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	// starttime is the 22nd field, fields now starts at the 3rd one
+	if len(fields) < 20 {
+		return "", fmt.Errorf("malformed stat file")
+	}
+	return fields[19], nil
+}
+
+// listeningAddresses returns the host and the TCP ports the process listens on,
+// resolved from the sockets it holds and the TCP tables of its network namespace
+func listeningAddresses(procDir string) (map[string]string, []ContainerPort) {
+	inodes := socketInodes(procDir)
+	if len(inodes) == 0 {
+		return map[string]string{}, []ContainerPort{}
+	}
+
+	var addrs []net.IP
+	portSet := make(map[int]struct{})
+	for _, table := range []string{"tcp", "tcp6"} {
+		content, err := ioutil.ReadFile(filepath.Join(procDir, "net", table))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n")[1:] {
+			fields := strings.Fields(line)
+			if len(fields) < 10 || fields[3] != tcpListenState {
+				continue
+			}
+			if _, ok := inodes[fields[9]]; !ok {
+				continue
+			}
+			ip, port, err := parseProcNetAddr(fields[1])
+			if err != nil {
+				log.Debugf("Couldn't parse socket address %q: %s", fields[1], err)
+				continue
+			}
+			addrs = append(addrs, ip)
+			portSet[port] = struct{}{}
+		}
+	}
+
+	ports := make([]ContainerPort, 0, len(portSet))
+	for port := range portSet {
+		ports = append(ports, ContainerPort{Port: port, Name: fmt.Sprintf("p%d", port)})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+
+	hosts := map[string]string{}
+	if host := pickHost(addrs); host != "" {
+		hosts[""] = host
+	}
+	return hosts, ports
+}
+
+// socketInodes returns the inodes of the sockets held by the process
+func socketInodes(procDir string) map[string]struct{} {
+	fdDir := filepath.Join(procDir, "fd")
+	fds, err := ioutil.ReadDir(fdDir)
+	if err != nil {
+		// reading the fds of processes owned by other users requires privileges
+		log.Debugf("Couldn't list the file descriptors in %s: %s", fdDir, err)
+		return nil
+	}
+
+	inodes := make(map[string]struct{})
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+		inodes[strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")] = struct{}{}
+	}
+	return inodes
+}
+
+// parseProcNetAddr parses an address of /proc/net/tcp{,6}, e.g. 0100007F:1F90.
+// IPs are written as 32 bits words in the host byte order, i.e. little-endian.
+func parseProcNetAddr(addr string) (net.IP, int, error) {
+	parts := strings.Split(addr, ":")
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("invalid address")
+	}
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid IP")
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port")
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return ip, int(port), nil
+}
+
+// pickHost returns the address to reach the process on, IPv4 being preferred.
+// Sockets bound to all interfaces are reached on the loopback.
+func pickHost(addrs []net.IP) string {
+	var v6 string
+	for _, ip := range addrs {
+		if ip4 := ip.To4(); ip4 != nil {
+			if ip4.IsUnspecified() {
+				return "127.0.0.1"
+			}
+			return ip4.String()
+		}
+		if v6 == "" {
+			if ip.IsUnspecified() {
+				v6 = net.IPv6loopback.String()
+			} else {
+				v6 = ip.String()
+			}
+		}
+	}
+	return v6
+}
+
+func portsEqual(a, b []ContainerPort) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetEntity returns the unique entity ID linked to that service
+func (s *ProcessService) GetEntity() string {
+	return s.entity
+}
+
+// GetTaggerEntity returns the unique entity ID linked to that service
+func (s *ProcessService) GetTaggerEntity() string {
+	return s.entity
+}
+
+// GetADIdentifiers returns the configured AD identifier, or the process name
+func (s *ProcessService) GetADIdentifiers() ([]string, error) {
+	return []string{s.adIdentifier}, nil
+}
+
+// GetHosts returns the address the process listens on
+func (s *ProcessService) GetHosts() (map[string]string, error) {
+	return s.hosts, nil
+}
+
+// GetPorts returns the TCP ports the process listens on
+func (s *ProcessService) GetPorts() ([]ContainerPort, error) {
+	return s.ports, nil
+}
+
+// GetTags returns the list of tags - currently always empty
+func (s *ProcessService) GetTags() ([]string, error) {
+	return []string{}, nil
+}
+
+// GetPid returns the process identifier
+func (s *ProcessService) GetPid() (int, error) {
+	return s.pid, nil
+}
+
+// GetHostname returns nothing - not supported
+func (s *ProcessService) GetHostname() (string, error) {
+	return "", ErrNotSupported
+}
+
+// GetCreationTime returns the creation time of the Service
+func (s *ProcessService) GetCreationTime() integration.CreationTime {
+	return s.creationTime
+}
+
+// IsReady returns true
+func (s *ProcessService) IsReady() bool {
+	return true
+}
+
+// GetCheckNames returns nil
+func (s *ProcessService) GetCheckNames() []string {
+	return nil
+}
+
+// HasFilter returns false on processes
+func (s *ProcessService) HasFilter(filter containers.FilterType) bool {
+	return false
+}
+
+// GetExtraConfig isn't supported
+func (s *ProcessService) GetExtraConfig(key []byte) ([]byte, error) {
+	return []byte{}, ErrNotSupported
+}
@@ -0,0 +1,242 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+// +build linux
+
+package listeners
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+const procNetTCPHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+type fakeProcess struct {
+	pid       int
+	comm      string
+	cmdline   []string
+	startTime int
+	inodes    []int
+	tcp       string
+	tcp6      string
+}
+
+func writeFakeProcess(t *testing.T, root string, p fakeProcess) {
+	dir := filepath.Join(root, strconv.Itoa(p.pid))
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "fd")))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "net"), 0755))
+
+	cmdline := ""
+	for _, arg := range p.cmdline {
+		cmdline += arg + "\x00"
+	}
+	stat := fmt.Sprintf("%d (%s) S 1 1 1 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 %d 0 0", p.pid, p.comm, p.startTime)
+	files := map[string]string{
+		"comm":     p.comm + "\n",
+		"cmdline":  cmdline,
+		"stat":     stat,
+		"net/tcp":  procNetTCPHeader + p.tcp,
+		"net/tcp6": procNetTCPHeader + p.tcp6,
+	}
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	for i, inode := range p.inodes {
+		require.NoError(t, os.Symlink(fmt.Sprintf("socket:[%d]", inode), filepath.Join(dir, "fd", strconv.Itoa(i+3))))
+	}
+	require.NoError(t, os.Symlink("/dev/null", filepath.Join(dir, "fd", "0")))
+}
+
+func TestParseProcNetAddr(t *testing.T) {
+	ip, port, err := parseProcNetAddr("0100007F:1F90")
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", ip.String())
+	assert.Equal(t, 8080, port)
+
+	ip, port, err = parseProcNetAddr("00000000000000000000000001000000:0050")
+	require.NoError(t, err)
+	assert.Equal(t, "::1", ip.String())
+	assert.Equal(t, 80, port)
+
+	_, _, err = parseProcNetAddr("0100007F")
+	assert.Error(t, err)
+	_, _, err = parseProcNetAddr("01007F:0050")
+	assert.Error(t, err)
+}
+
+func TestPickHost(t *testing.T) {
+	assert.Equal(t, "", pickHost(nil))
+	assert.Equal(t, "127.0.0.1", pickHost([]net.IP{net.ParseIP("::"), net.ParseIP("0.0.0.0")}))
+	assert.Equal(t, "10.0.0.2", pickHost([]net.IP{net.ParseIP("10.0.0.2")}))
+	assert.Equal(t, "::1", pickHost([]net.IP{net.ParseIP("::")}))
+	assert.Equal(t, "fd00::2", pickHost([]net.IP{net.ParseIP("fd00::2")}))
+}
+
+func TestProcessListenerConfig(t *testing.T) {
+	_, err := newProcessListener("/proc", ProcessListenerConfig{})
+	assert.Error(t, err)
+	_, err = newProcessListener("/proc", ProcessListenerConfig{Processes: []ProcessConfig{{ADIdentifier: "foo"}}})
+	assert.Error(t, err)
+	_, err = newProcessListener("/proc", ProcessListenerConfig{Processes: []ProcessConfig{{Name: "("}}})
+	assert.Error(t, err)
+
+	l, err := newProcessListener("/proc", ProcessListenerConfig{Processes: []ProcessConfig{{Name: "^redis"}}})
+	require.NoError(t, err)
+	assert.Equal(t, defaultProcessDiscoveryInterval, int(l.interval.Seconds()))
+}
+
+func TestProcessListener(t *testing.T) {
+	root, err := ioutil.TempDir("", "procfs")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	redis := fakeProcess{
+		pid:       42,
+		comm:      "redis-server",
+		cmdline:   []string{"/usr/bin/redis-server", "*:6379"},
+		startTime: 1000,
+		inodes:    []int{1234, 5678},
+		// 0.0.0.0:6379 listening, 127.0.0.1:6379 -> 127.0.0.1:50000 established
+		tcp: "   0: 00000000:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 1234 1 0000000000000000 100 0 0 10 0\n" +
+			"   1: 0100007F:18EB 0100007F:C350 01 00000000:00000000 00:00000000 00000000   999        0 5678 1 0000000000000000 100 0 0 10 0\n" +
+			// another process listening
+			"   2: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 9999 1 0000000000000000 100 0 0 10 0\n",
+	}
+	tomcat := fakeProcess{
+		pid:       43,
+		comm:      "java",
+		cmdline:   []string{"java", "org.apache.catalina.startup.Bootstrap", "start"},
+		startTime: 2000,
+		inodes:    []int{4321},
+		// [fd00::2]:8080 listening
+		tcp6: "   0: 000000FD000000000000000002000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 4321 1 0000000000000000 100 0 0 10 0\n",
+	}
+	other := fakeProcess{pid: 44, comm: "bash", cmdline: []string{"bash"}, startTime: 3000}
+	kthread := fakeProcess{pid: 2, comm: "kthreadd", startTime: 1}
+	for _, p := range []fakeProcess{redis, tomcat, other, kthread} {
+		writeFakeProcess(t, root, p)
+	}
+
+	l, err := newProcessListener(root, ProcessListenerConfig{
+		Processes: []ProcessConfig{
+			{Name: "^redis-server$"},
+			{ADIdentifier: "tomcat", Cmdline: `org\.apache\.catalina\.startup\.Bootstrap`},
+			{ADIdentifier: "kernel", Name: "kthreadd"},
+		},
+	})
+	require.NoError(t, err)
+
+	newSvc := make(chan Service, 10)
+	delSvc := make(chan Service, 10)
+	l.newService = newSvc
+	l.delService = delSvc
+
+	l.refresh(true)
+	require.Len(t, newSvc, 2)
+	require.Len(t, delSvc, 0)
+
+	services := map[int]*ProcessService{}
+	for i := 0; i < 2; i++ {
+		svc := (<-newSvc).(*ProcessService)
+		services[svc.pid] = svc
+	}
+
+	svc := services[42]
+	require.NotNil(t, svc)
+	assert.Equal(t, "process://42", svc.GetEntity())
+	assert.Equal(t, "process://42", svc.GetTaggerEntity())
+	adIDs, _ := svc.GetADIdentifiers()
+	assert.Equal(t, []string{"redis-server"}, adIDs)
+	hosts, _ := svc.GetHosts()
+	assert.Equal(t, map[string]string{"": "127.0.0.1"}, hosts)
+	ports, _ := svc.GetPorts()
+	assert.Equal(t, []ContainerPort{{Port: 6379, Name: "p6379"}}, ports)
+	pid, _ := svc.GetPid()
+	assert.Equal(t, 42, pid)
+	assert.Equal(t, integration.Before, svc.GetCreationTime())
+
+	svc = services[43]
+	require.NotNil(t, svc)
+	adIDs, _ = svc.GetADIdentifiers()
+	assert.Equal(t, []string{"tomcat"}, adIDs)
+	hosts, _ = svc.GetHosts()
+	assert.Equal(t, map[string]string{"": "fd00::2"}, hosts)
+	ports, _ = svc.GetPorts()
+	assert.Equal(t, []ContainerPort{{Port: 8080, Name: "p8080"}}, ports)
+
+	// nothing changed
+	l.refresh(false)
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 0)
+
+	// tomcat exited and its pid was reused by a new instance
+	tomcat.startTime = 2500
+	writeFakeProcess(t, root, tomcat)
+	// redis doesn't listen anymore
+	redis.inodes = nil
+	writeFakeProcess(t, root, redis)
+
+	// record the order of the events, the previous service of a process must
+	// be removed before the new one is created since they share the same entity
+	type event struct {
+		deleted bool
+		svc     *ProcessService
+	}
+	var events []event
+	eventsDone := make(chan struct{})
+	newSvc2, delSvc2 := make(chan Service), make(chan Service)
+	l.newService, l.delService = newSvc2, delSvc2
+	go func() {
+		defer close(eventsDone)
+		for len(events) < 4 {
+			select {
+			case svc := <-newSvc2:
+				events = append(events, event{svc: svc.(*ProcessService)})
+			case svc := <-delSvc2:
+				events = append(events, event{deleted: true, svc: svc.(*ProcessService)})
+			}
+		}
+	}()
+	l.refresh(false)
+	<-eventsDone
+
+	for i := 0; i < 4; i += 2 {
+		del, add := events[i], events[i+1]
+		require.True(t, del.deleted)
+		require.False(t, add.deleted)
+		require.Equal(t, del.svc.pid, add.svc.pid)
+		assert.Equal(t, integration.After, add.svc.GetCreationTime())
+		switch add.svc.pid {
+		case 42:
+			ports, _ := add.svc.GetPorts()
+			assert.Empty(t, ports)
+		case 43:
+			assert.Equal(t, "2000", del.svc.startTime)
+			assert.Equal(t, "2500", add.svc.startTime)
+		}
+	}
+	l.newService, l.delService = newSvc, delSvc
+
+	// all processes exited
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "42")))
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "43")))
+	l.refresh(false)
+	assert.Len(t, newSvc, 0)
+	assert.Len(t, delSvc, 2)
+	assert.Empty(t, l.services)
+}
@@ -41,6 +41,22 @@ type Service interface {
 	GetExtraConfig([]byte) ([]byte, error)     // Extra configuration values
 }
 
+// KubeMetadata holds the Kubernetes metadata of a service, which can be used
+// in check templates through the %%kube_*%% template variables
+type KubeMetadata struct {
+	Namespace     string            `yaml:"namespace"`
+	PodName       string            `yaml:"pod_name"`
+	ContainerName string            `yaml:"container_name"`
+	ImageTag      string            `yaml:"image_tag"`
+	Labels        map[string]string `yaml:"labels"`
+	Annotations   map[string]string `yaml:"annotations"`
+}
+
+// KubeService is implemented by the services which have Kubernetes metadata
+type KubeService interface {
+	GetKubeMetadata() *KubeMetadata
+}
+
 // ServiceListener monitors running services and triggers check (un)scheduling
 //
 // It holds a cache of running services, listens to new/killed services and
@@ -86,7 +86,8 @@ func (c *ClusterChecksConfigProvider) IsUpToDate() (bool, error) {
 	}
 
 	status := types.NodeStatus{
-		LastChange: c.lastChange,
+		LastChange:   c.lastChange,
+		CheckRunners: config.Datadog.GetInt("check_runners"),
 	}
 
 	reply, err := c.dcaClient.PostClusterCheckStatus(c.nodeName, status)
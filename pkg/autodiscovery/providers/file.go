@@ -7,10 +7,12 @@ package providers
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/configresolver"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
@@ -50,7 +52,12 @@ type configEntry struct {
 // FileConfigProvider collect configuration files from disk
 type FileConfigProvider struct {
 	paths  []string
-	Errors map[string]string
+	Errors map[string]string // check name -> errors of its invalid files
+
+	m            sync.RWMutex
+	watching     bool
+	upToDate     bool
+	stopWatching chan struct{}
 }
 
 // NewFileConfigProvider creates a new FileConfigProvider searching for
@@ -70,6 +77,14 @@ func (c *FileConfigProvider) Collect() ([]integration.Config, error) {
 	configNames := make(map[string]struct{}) // use this map as a python set
 	defaultConfigs := []integration.Config{}
 
+	// Files changed from now on are collected at the next call
+	c.m.Lock()
+	c.upToDate = true
+	c.m.Unlock()
+
+	// Errors of the files which were fixed or removed are cleared
+	c.Errors = make(map[string]string)
+
 	for _, path := range c.paths {
 		log.Infof("%v: searching for configuration files at: %s", c, path)
 
@@ -131,9 +146,13 @@ func (c *FileConfigProvider) Collect() ([]integration.Config, error) {
 	return configs, nil
 }
 
-// IsUpToDate is not implemented for the file Providers as the files are not meant to change very often.
+// IsUpToDate returns false if the files changed since the last Collect, or
+// if they aren't watched
 func (c *FileConfigProvider) IsUpToDate() (bool, error) {
-	return false, nil
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	return c.watching && c.upToDate, nil
 }
 
 // String returns a string representation of the FileConfigProvider
@@ -182,7 +201,12 @@ func (c *FileConfigProvider) collectEntry(file os.FileInfo, path string, integra
 	entry.conf, err = GetIntegrationConfigFromFile(integrationName, absPath)
 	if err != nil {
 		log.Warnf("%s is not a valid config file: %s", absPath, err)
-		c.Errors[integrationName] = err.Error()
+		fileErr := fmt.Sprintf("%s: %s", absPath, err)
+		if previous, found := c.Errors[integrationName]; found {
+			// Several files of the check are invalid
+			fileErr = previous + "\n" + fileErr
+		}
+		c.Errors[integrationName] = fileErr
 		entry.err = errors.New("Invalid config file format")
 		return entry
 	}
@@ -192,7 +216,6 @@ func (c *FileConfigProvider) collectEntry(file os.FileInfo, path string, integra
 		entry.isLogsOnly = true
 	}
 
-	delete(c.Errors, integrationName) // noop if entry is nonexistant
 	log.Debug("Found valid configuration in file:", absPath)
 	return entry
 }
@@ -247,23 +270,31 @@ func (c *FileConfigProvider) collectDir(parentPath string, folder os.FileInfo) c
 
 // GetIntegrationConfigFromFile returns an instance of integration.Config if `fpath` points to a valid config file
 func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error) {
-	cf := configFormat{}
-	config := integration.Config{Name: name}
-
 	// Read file contents
 	// FIXME: ReadFile reads the entire file, possible security implications
 	yamlFile, err := readFilePtr(fpath)
 	if err != nil {
-		return config, err
+		return integration.Config{Name: name}, err
 	}
 
+	config, err := parseIntegrationConfig(name, yamlFile, "config file "+fpath)
+	config.Source = "file:" + fpath
+	return config, err
+}
+
+// parseIntegrationConfig parses the content of a configuration file, origin
+// describes where it comes from in logs
+func parseIntegrationConfig(name string, yamlFile []byte, origin string) (integration.Config, error) {
+	cf := configFormat{}
+	config := integration.Config{Name: name}
+
 	// Parse configuration
 	// Try UnmarshalStrict first, so we can warn about duplicated keys
 	if strictErr := yaml.UnmarshalStrict(yamlFile, &cf); strictErr != nil {
 		if err := yaml.Unmarshal(yamlFile, &cf); err != nil {
 			return config, err
 		}
-		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
+		log.Warnf("reading %v: %v\n", origin, strictErr)
 	}
 
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
@@ -315,7 +346,5 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Interpolate env vars. Returns an error a variable wasn't subsituted, ignore it.
 	_ = configresolver.SubstituteTemplateEnvVars(&config)
 
-	config.Source = "file:" + fpath
-
-	return config, err
+	return config, nil
 }
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// kubeDataSymlink is swapped by the kubelet to update the files of the ConfigMap and
+// Secret volumes, which are symlinks to it: their own names don't get any event
+const kubeDataSymlink = "..data"
+
+// Watch starts watching the configuration paths and their `<check>.d` folders.
+// Once no file changed for the debounce duration, IsUpToDate returns false
+// for AutoConfig to collect the configs again and only reschedule the ones
+// that changed.
+func (c *FileConfigProvider) Watch(debounce time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range c.paths {
+		if path == "" {
+			continue
+		}
+		if err := watcher.Add(path); err != nil {
+			log.Debugf("%v: not watching %s: %s", c, path, err)
+			continue
+		}
+		entries, err := readDirPtr(path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && isConfigDir(entry.Name()) {
+				c.watchDir(watcher, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	c.m.Lock()
+	c.watching = true
+	c.upToDate = true
+	c.stopWatching = make(chan struct{})
+	c.m.Unlock()
+
+	go c.watch(watcher, debounce)
+	return nil
+}
+
+// StopWatching stops watching the configuration paths
+func (c *FileConfigProvider) StopWatching() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if !c.watching {
+		return
+	}
+	close(c.stopWatching)
+	c.watching = false
+}
+
+func (c *FileConfigProvider) watch(watcher *fsnotify.Watcher, debounce time.Duration) {
+	defer watcher.Close()
+
+	// Stopped until the first event
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-c.stopWatching:
+			timer.Stop()
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !c.isRelevantEvent(watcher, event) {
+				continue
+			}
+			log.Tracef("%v: %s", c, event)
+			timer.Reset(debounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			// Events may have been dropped, collect again to be safe
+			log.Warnf("%v: error watching configuration files: %s", c, err)
+			timer.Reset(debounce)
+		case <-timer.C:
+			log.Debugf("%v: configuration files changed", c)
+			c.m.Lock()
+			c.upToDate = false
+			c.m.Unlock()
+		}
+	}
+}
+
+// isRelevantEvent returns whether the event may change the collected configs,
+// and watches the `<check>.d` folders created in a configuration path
+func (c *FileConfigProvider) isRelevantEvent(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	name := filepath.Base(event.Name)
+	if name == kubeDataSymlink {
+		return true
+	}
+	if isConfigDir(name) {
+		if event.Op&fsnotify.Create == fsnotify.Create {
+			c.watchDir(watcher, event.Name)
+		}
+		// Removed folders are unwatched by fsnotify
+		return true
+	}
+
+	ext := filepath.Ext(strings.TrimSuffix(name, ".default"))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func (c *FileConfigProvider) watchDir(watcher *fsnotify.Watcher, path string) {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return
+	}
+	if err := watcher.Add(path); err != nil {
+		log.Warnf("%v: not watching %s: %s", c, path, err)
+	}
+}
+
+func isConfigDir(name string) bool {
+	return filepath.Ext(name) == ".d"
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package providers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWatchConfig = "init_config:\ninstances:\n  - host: localhost\n"
+
+func isUpToDate(t *testing.T, provider *FileConfigProvider) func() bool {
+	return func() bool {
+		upToDate, err := provider.IsUpToDate()
+		require.NoError(t, err)
+		return upToDate
+	}
+}
+
+func TestFileConfigProviderWatch(t *testing.T) {
+	confd, err := ioutil.TempDir("", "conf.d")
+	require.NoError(t, err)
+	defer os.RemoveAll(confd)
+
+	require.NoError(t, os.Mkdir(filepath.Join(confd, "redisdb.d"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confd, "redisdb.d", "conf.yaml"), []byte(testWatchConfig), 0644))
+
+	provider := NewFileConfigProvider([]string{confd, "", filepath.Join(confd, "missing")})
+
+	// not watched
+	upToDate, err := provider.IsUpToDate()
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	require.NoError(t, provider.Watch(10*time.Millisecond))
+	defer provider.StopWatching()
+
+	configs, err := provider.Collect()
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.True(t, isUpToDate(t, provider)())
+
+	// files which aren't configs are ignored
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confd, "redisdb.d", "README.md"), []byte("readme"), 0644))
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, isUpToDate(t, provider)())
+
+	// modified file in a check folder
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confd, "redisdb.d", "conf.yaml"), []byte(testWatchConfig+"  - host: remote\n"), 0644))
+	assert.Eventually(t, func() bool { return !isUpToDate(t, provider)() }, time.Second, 10*time.Millisecond)
+	configs, err = provider.Collect()
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Len(t, configs[0].Instances, 2)
+
+	// new check folder, then new file in it
+	require.NoError(t, os.Mkdir(filepath.Join(confd, "nginx.d"), 0755))
+	assert.Eventually(t, func() bool { return !isUpToDate(t, provider)() }, time.Second, 10*time.Millisecond)
+	_, err = provider.Collect()
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confd, "nginx.d", "conf.yaml.default"), []byte(testWatchConfig), 0644))
+	assert.Eventually(t, func() bool { return !isUpToDate(t, provider)() }, time.Second, 10*time.Millisecond)
+	configs, err = provider.Collect()
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+
+	// removed file at the root
+	require.NoError(t, os.RemoveAll(filepath.Join(confd, "redisdb.d")))
+	assert.Eventually(t, func() bool { return !isUpToDate(t, provider)() }, time.Second, 10*time.Millisecond)
+	configs, err = provider.Collect()
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "nginx", configs[0].Name)
+
+	// updated ConfigMap volume: the files are symlinks to the `..data` symlink, which gets swapped
+	nginx := filepath.Join(confd, "nginx.d")
+	require.NoError(t, os.Mkdir(filepath.Join(nginx, "..v1"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(nginx, "..v1", "conf.yaml"), []byte(testWatchConfig), 0644))
+	require.NoError(t, os.Symlink("..v1", filepath.Join(nginx, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "conf.yaml"), filepath.Join(nginx, "conf.yaml")))
+	assert.Eventually(t, func() bool { return !isUpToDate(t, provider)() }, time.Second, 10*time.Millisecond)
+	configs, err = provider.Collect()
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Len(t, configs[0].Instances, 1)
+	require.NoError(t, os.Mkdir(filepath.Join(nginx, "..v2"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(nginx, "..v2", "conf.yaml"), []byte(testWatchConfig+"  - host: remote\n"), 0644))
+	require.NoError(t, os.Symlink("..v2", filepath.Join(nginx, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(nginx, "..data_tmp"), filepath.Join(nginx, "..data")))
+	assert.Eventually(t, func() bool { return !isUpToDate(t, provider)() }, time.Second, 10*time.Millisecond)
+	configs, err = provider.Collect()
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Len(t, configs[0].Instances, 2)
+
+	// not watched anymore
+	provider.StopWatching()
+	assert.False(t, isUpToDate(t, provider)())
+}
+
+func TestFileConfigProviderErrors(t *testing.T) {
+	confd, err := ioutil.TempDir("", "conf.d")
+	require.NoError(t, err)
+	defer os.RemoveAll(confd)
+
+	dir := filepath.Join(confd, "redisdb.d")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.yaml"), []byte("init_config:\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.yaml"), []byte(testWatchConfig), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "c.yaml"), []byte("instances: ["), 0644))
+
+	provider := NewFileConfigProvider([]string{confd})
+	configs, err := provider.Collect()
+	require.NoError(t, err)
+	assert.Len(t, configs, 1)
+
+	// the valid file doesn't clear the errors of the other files of the check
+	require.Len(t, provider.Errors, 1)
+	errs := strings.Split(provider.Errors["redisdb"], "\n")
+	require.Len(t, errs, 2)
+	assert.True(t, strings.HasPrefix(errs[0], filepath.Join(dir, "a.yaml")+": "))
+	assert.True(t, strings.HasPrefix(errs[1], filepath.Join(dir, "c.yaml")+": "))
+
+	// fixed files are cleared
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.yaml")))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "c.yaml"), []byte(testWatchConfig), 0644))
+	configs, err = provider.Collect()
+	require.NoError(t, err)
+	assert.Len(t, configs, 2)
+	assert.Empty(t, provider.Errors)
+}
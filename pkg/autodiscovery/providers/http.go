@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package providers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const httpProviderTimeout = 10 * time.Second
+
+// httpConfigPayload is the payload served by the HTTP endpoint, a list of configs
+// like {"check_name": "redisdb", "ad_identifiers": ["redis"], "init_config": {}, "instances": [{"host": "%%host%%"}]}
+type httpConfigPayload struct {
+	Configs []httpConfig `json:"configs"`
+}
+
+type httpConfig struct {
+	CheckName               string            `json:"check_name"`
+	ADIdentifiers           []string          `json:"ad_identifiers"`
+	InitConfig              json.RawMessage   `json:"init_config"`
+	Instances               []json.RawMessage `json:"instances"`
+	Logs                    json.RawMessage   `json:"logs"`
+	ClusterCheck            bool              `json:"cluster_check"`
+	IgnoreAutodiscoveryTags bool              `json:"ignore_autodiscovery_tags"`
+}
+
+// HTTPConfigProvider implements the ConfigProvider interface for configs
+// served as JSON by an HTTP endpoint. The payload is only downloaded again
+// when its ETag changes.
+type HTTPConfigProvider struct {
+	client  *http.Client
+	url     string
+	headers http.Header
+	etag    string
+	payload []byte
+	fresh   bool // payload was downloaded by IsUpToDate and not collected yet
+}
+
+// NewHTTPConfigProvider returns a new HTTPConfigProvider polling cfg.TemplateURL
+func NewHTTPConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	if cfg.TemplateURL == "" {
+		return nil, fmt.Errorf("template_url is required")
+	}
+
+	tlsConfig, err := buildHTTPTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(http.Header)
+	for name, value := range cfg.Headers {
+		headers.Set(name, value)
+	}
+	if cfg.Token != "" {
+		headers.Set("Authorization", "Bearer "+cfg.Token)
+	} else if cfg.Username != "" {
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+		headers.Set("Authorization", req.Header.Get("Authorization"))
+	}
+
+	return &HTTPConfigProvider{
+		client: &http.Client{
+			Timeout:   httpProviderTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+		},
+		url:     cfg.TemplateURL,
+		headers: headers,
+	}, nil
+}
+
+func buildHTTPTLSConfig(cfg config.ConfigurationProviders) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if cfg.CAFile != "" {
+		caCert, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_file: %s", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificate found in ca_file %s", cfg.CAFile)
+		}
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load cert_file and key_file: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// Collect builds the configs of the last payload, downloading it if IsUpToDate didn't
+func (p *HTTPConfigProvider) Collect() ([]integration.Config, error) {
+	if !p.fresh {
+		if _, err := p.download(); err != nil {
+			return nil, err
+		}
+	}
+	p.fresh = false
+
+	return parseHTTPConfigPayload(p.payload, p.url)
+}
+
+// IsUpToDate downloads the payload if its ETag changed, or if the endpoint doesn't
+// support ETags, and compares it to the last one
+func (p *HTTPConfigProvider) IsUpToDate() (bool, error) {
+	changed, err := p.download()
+	if err != nil {
+		return false, err
+	}
+	p.fresh = changed
+	return !changed, nil
+}
+
+// download gets the payload, returns whether it changed since the last download
+func (p *HTTPConfigProvider) download() (bool, error) {
+	req, err := http.NewRequest("GET", p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	for name, values := range p.headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.etag != "" && p.payload != nil {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("unable to get configs from %s: %s", p.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code %d getting configs from %s", resp.StatusCode, p.url)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("unable to read configs from %s: %s", p.url, err)
+	}
+
+	changed := p.payload == nil || !bytes.Equal(body, p.payload)
+	p.payload = body
+	p.etag = resp.Header.Get("ETag")
+	return changed, nil
+}
+
+func parseHTTPConfigPayload(payload []byte, url string) ([]integration.Config, error) {
+	var p httpConfigPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid configs payload from %s: %s", url, err)
+	}
+
+	configs := make([]integration.Config, 0, len(p.Configs))
+	for idx, c := range p.Configs {
+		if c.CheckName == "" && len(c.Logs) == 0 {
+			log.Errorf("Ignoring config %d from %s: check_name is required", idx, url)
+			continue
+		}
+		if c.CheckName != "" && len(c.Instances) == 0 {
+			log.Errorf("Ignoring config %d from %s: no instances", idx, url)
+			continue
+		}
+
+		conf := integration.Config{
+			Name:                    c.CheckName,
+			ADIdentifiers:           c.ADIdentifiers,
+			ClusterCheck:            c.ClusterCheck,
+			IgnoreAutodiscoveryTags: c.IgnoreAutodiscoveryTags,
+			Source:                  fmt.Sprintf("http:%s#%d", url, idx),
+		}
+		// JSON being valid YAML, the sections are used as is
+		if len(c.InitConfig) > 0 {
+			conf.InitConfig = integration.Data(c.InitConfig)
+		} else {
+			conf.InitConfig = integration.Data("{}")
+		}
+		for _, instance := range c.Instances {
+			conf.Instances = append(conf.Instances, integration.Data(instance))
+		}
+		if len(c.Logs) > 0 {
+			conf.LogsConfig = integration.Data(c.Logs)
+		}
+		configs = append(configs, conf)
+	}
+	return configs, nil
+}
+
+func init() {
+	RegisterProvider("http", NewHTTPConfigProvider)
+}
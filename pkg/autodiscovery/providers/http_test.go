@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const testHTTPPayload = `{"configs": [
+  {"check_name": "redisdb", "ad_identifiers": ["redis"], "init_config": {}, "instances": [{"host": "%%host%%"}]},
+  {"check_name": "http_check", "cluster_check": true, "instances": [{"url": "http://example.com"}]},
+  {"check_name": "no_instances"},
+  {"ad_identifiers": ["nginx"], "logs": [{"type": "file", "path": "/var/log/nginx.log"}]}
+]}`
+
+func TestParseHTTPConfigPayload(t *testing.T) {
+	configs, err := parseHTTPConfigPayload([]byte(testHTTPPayload), "http://test")
+	require.NoError(t, err)
+	require.Len(t, configs, 3)
+
+	assert.Equal(t, "redisdb", configs[0].Name)
+	assert.Equal(t, []string{"redis"}, configs[0].ADIdentifiers)
+	assert.Equal(t, integration.Data("{}"), configs[0].InitConfig)
+	assert.Equal(t, []integration.Data{integration.Data(`{"host": "%%host%%"}`)}, configs[0].Instances)
+	assert.Equal(t, "http:http://test#0", configs[0].Source)
+
+	assert.Equal(t, "http_check", configs[1].Name)
+	assert.True(t, configs[1].ClusterCheck)
+	assert.Equal(t, integration.Data("{}"), configs[1].InitConfig)
+
+	assert.Equal(t, "", configs[2].Name)
+	assert.Equal(t, integration.Data(`[{"type": "file", "path": "/var/log/nginx.log"}]`), configs[2].LogsConfig)
+	assert.Equal(t, "http:http://test#3", configs[2].Source)
+
+	_, err = parseHTTPConfigPayload([]byte("not json"), "http://test")
+	assert.Error(t, err)
+}
+
+func TestHTTPConfigProvider(t *testing.T) {
+	payload := testHTTPPayload
+	etag := `"v1"`
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "bar", r.Header.Get("X-Foo"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(payload))
+	}))
+	defer ts.Close()
+
+	_, err := NewHTTPConfigProvider(config.ConfigurationProviders{})
+	assert.Error(t, err)
+
+	p, err := NewHTTPConfigProvider(config.ConfigurationProviders{
+		TemplateURL: ts.URL,
+		Token:       "secret",
+		Headers:     map[string]string{"X-Foo": "bar"},
+	})
+	require.NoError(t, err)
+
+	// first collection without IsUpToDate
+	configs, err := p.Collect()
+	require.NoError(t, err)
+	assert.Len(t, configs, 3)
+	assert.Equal(t, 1, requests)
+
+	// same ETag
+	upToDate, err := p.IsUpToDate()
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+	assert.Equal(t, 2, requests)
+
+	// new ETag, the payload downloaded by IsUpToDate is collected
+	payload = `{"configs": [{"check_name": "redisdb", "instances": [{"host": "localhost"}]}]}`
+	etag = `"v2"`
+	upToDate, err = p.IsUpToDate()
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+	configs, err = p.Collect()
+	require.NoError(t, err)
+	assert.Len(t, configs, 1)
+	assert.Equal(t, 3, requests)
+
+	// no ETag, same payload
+	etag = ""
+	upToDate, err = p.IsUpToDate()
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+}
+
+func TestHTTPConfigProviderBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "admin" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"configs": []}`))
+	}))
+	defer ts.Close()
+
+	p, err := NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: ts.URL, Username: "admin", Password: "pass"})
+	require.NoError(t, err)
+	configs, err := p.Collect()
+	require.NoError(t, err)
+	assert.Empty(t, configs)
+
+	p, err = NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: ts.URL, Username: "admin", Password: "wrong"})
+	require.NoError(t, err)
+	_, err = p.Collect()
+	assert.Error(t, err)
+}
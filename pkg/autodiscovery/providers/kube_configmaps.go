@@ -0,0 +1,180 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// ConfigMaps holding integration configs are opted in with this label
+	defaultConfigMapLabelSelector = "ad.datadoghq.com/configs=true"
+)
+
+// KubeConfigMapConfigProvider implements the ConfigProvider interface for
+// integration configs stored in labelled ConfigMaps. Every key of the ConfigMaps
+// ending with .yaml or .yml is parsed like a file of the conf.d folder, the key
+// without extension being the check name.
+type KubeConfigMapConfigProvider struct {
+	lister    listersv1.ConfigMapLister
+	hasSynced cache.InformerSynced
+	namespace string
+	upToDate  bool
+}
+
+// NewKubeConfigMapConfigProvider returns a new ConfigProvider watching the ConfigMaps
+// matching the label selector in a single namespace, the agent's one by default.
+func NewKubeConfigMapConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = common.GetResourcesNamespace()
+	}
+	selector := cfg.LabelSelector
+	if selector == "" {
+		selector = defaultConfigMapLabelSelector
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("invalid label_selector %q: %s", selector, err)
+	}
+
+	informerFactory, err := apiserver.NewInformerFactoryWithOptions(
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	configMapsInformer := informerFactory.Core().V1().ConfigMaps()
+	p := &KubeConfigMapConfigProvider{
+		lister:    configMapsInformer.Lister(),
+		hasSynced: configMapsInformer.Informer().HasSynced,
+		namespace: namespace,
+	}
+
+	configMapsInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.invalidate,
+		UpdateFunc: p.invalidateIfChanged,
+		DeleteFunc: p.invalidate,
+	})
+
+	// The informer lives as long as the agent
+	informerFactory.Start(make(chan struct{}))
+
+	return p, nil
+}
+
+// String returns a string representation of the KubeConfigMapConfigProvider
+func (k *KubeConfigMapConfigProvider) String() string {
+	return names.KubeConfigMaps
+}
+
+// Collect retrieves the ConfigMaps from the informer cache, builds Config objects and returns them
+func (k *KubeConfigMapConfigProvider) Collect() ([]integration.Config, error) {
+	if !k.hasSynced() {
+		return nil, fmt.Errorf("configmaps of namespace %s not synced yet", k.namespace)
+	}
+
+	configMaps, err := k.lister.ConfigMaps(k.namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	k.upToDate = true
+
+	return parseConfigMaps(configMaps), nil
+}
+
+// IsUpToDate allows to cache configs as long as no changes are detected in the apiserver
+func (k *KubeConfigMapConfigProvider) IsUpToDate() (bool, error) {
+	return k.upToDate, nil
+}
+
+func (k *KubeConfigMapConfigProvider) invalidate(obj interface{}) {
+	if obj != nil {
+		log.Trace("Invalidating configs on new/deleted configmap")
+		k.upToDate = false
+	}
+}
+
+func (k *KubeConfigMapConfigProvider) invalidateIfChanged(old, obj interface{}) {
+	// Cast the updated object, don't invalidate on casting error.
+	// nil pointers are safely handled by the casting logic.
+	castedObj, ok := obj.(*v1.ConfigMap)
+	if !ok {
+		log.Errorf("Expected a ConfigMap type, got: %v", obj)
+		return
+	}
+	// Cast the old object, invalidate on casting error
+	castedOld, ok := old.(*v1.ConfigMap)
+	if !ok {
+		log.Errorf("Expected a ConfigMap type, got: %v", old)
+		k.upToDate = false
+		return
+	}
+	// Quick exit if resversion did not change
+	if castedObj.ResourceVersion == castedOld.ResourceVersion {
+		return
+	}
+	log.Trace("Invalidating configs on configmap change")
+	k.upToDate = false
+}
+
+func parseConfigMaps(configMaps []*v1.ConfigMap) []integration.Config {
+	var configs []integration.Config
+	for _, cm := range configMaps {
+		if cm == nil {
+			continue
+		}
+
+		// Sort the keys for the configs to be in a stable order
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			ext := filepath.Ext(key)
+			if ext != ".yaml" && ext != ".yml" {
+				continue
+			}
+			source := fmt.Sprintf("%s/%s/%s", cm.Namespace, cm.Name, key)
+			conf, err := parseIntegrationConfig(strings.TrimSuffix(key, ext), []byte(cm.Data[key]), "configmap "+source)
+			if err != nil {
+				log.Errorf("Cannot parse config %s of configmap %s/%s: %s", key, cm.Namespace, cm.Name, err)
+				continue
+			}
+			conf.Source = "kube_configmaps:" + source
+			configs = append(configs, conf)
+		}
+	}
+	return configs
+}
+
+func init() {
+	RegisterProvider("kube_configmaps", NewKubeConfigMapConfigProvider)
+}
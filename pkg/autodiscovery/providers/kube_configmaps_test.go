@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+func TestParseConfigMaps(t *testing.T) {
+	configMaps := []*v1.ConfigMap{
+		nil,
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "checks", Namespace: "datadog"},
+			Data: map[string]string{
+				"redisdb.yaml":   "ad_identifiers:\n  - redis\ninit_config:\ninstances:\n  - port: 6379\n    host: localhost\n",
+				"http_check.yml": "cluster_check: true\ninit_config:\ninstances:\n  - url: http://example.com\n",
+				"README.md":      "not a config",
+				"invalid.yaml":   "init_config:\n",
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "datadog"},
+			Data: map[string]string{
+				"nginx.yaml": "logs:\n  - type: file\n    path: /var/log/nginx.log\n    service: nginx\n    source: nginx\n",
+			},
+		},
+	}
+
+	configs := parseConfigMaps(configMaps)
+	require.Len(t, configs, 3)
+
+	assert.Equal(t, "http_check", configs[0].Name)
+	assert.True(t, configs[0].ClusterCheck)
+	assert.Equal(t, []integration.Data{integration.Data("url: http://example.com\n")}, configs[0].Instances)
+	assert.Equal(t, "kube_configmaps:datadog/checks/http_check.yml", configs[0].Source)
+
+	assert.Equal(t, "redisdb", configs[1].Name)
+	assert.Equal(t, []string{"redis"}, configs[1].ADIdentifiers)
+	assert.Equal(t, []integration.Data{integration.Data("host: localhost\nport: 6379\n")}, configs[1].Instances)
+	assert.Equal(t, "kube_configmaps:datadog/checks/redisdb.yaml", configs[1].Source)
+
+	assert.Equal(t, "nginx", configs[2].Name)
+	assert.Empty(t, configs[2].Instances)
+	assert.Contains(t, string(configs[2].LogsConfig), "/var/log/nginx.log")
+	assert.Equal(t, "kube_configmaps:datadog/logs/nginx.yaml", configs[2].Source)
+}
+
+func TestKubeConfigMapInvalidateIfChanged(t *testing.T) {
+	p := &KubeConfigMapConfigProvider{upToDate: true}
+
+	old := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"}}
+	p.invalidateIfChanged(old, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"}})
+	assert.True(t, p.upToDate)
+
+	p.invalidateIfChanged(old, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "2"}})
+	assert.False(t, p.upToDate)
+
+	p.upToDate = true
+	p.invalidate(old)
+	assert.False(t, p.upToDate)
+}
@@ -15,9 +15,11 @@ const (
 	EndpointsChecks = "endpoints-checks"
 	Etcd            = "etcd"
 	File            = "file"
+	HTTP            = "http"
 	Kubernetes      = "kubernetes"
 	KubeServices    = "kubernetes-services"
 	KubeEndpoints   = "kubernetes-endpoints"
+	KubeConfigMaps  = "kubernetes-configmaps"
 	SNMP            = "snmp"
 	Zookeeper       = "zookeeper"
 )
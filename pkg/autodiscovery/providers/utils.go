@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -150,6 +152,40 @@ func extractTemplatesFromMap(key string, input map[string]string, prefix string)
 	return configs, errors
 }
 
+// ExtractTemplatesFromAnnotations returns the templates defined in pod annotations
+// or docker labels, under any prefix, e.g. "ad.datadoghq.com/redis.". Since these
+// templates are bound to the entity holding the annotations, no AD identifier is set.
+func ExtractTemplatesFromAnnotations(annotations map[string]string) ([]integration.Config, []error) {
+	prefixSet := make(map[string]struct{})
+	for key := range annotations {
+		for _, suffix := range []string{checkNamePath, logsConfigPath} {
+			if key == suffix || strings.HasSuffix(key, "."+suffix) {
+				prefixSet[strings.TrimSuffix(key, suffix)] = struct{}{}
+			}
+		}
+	}
+	prefixes := make([]string, 0, len(prefixSet))
+	for prefix := range prefixSet {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var configs []integration.Config
+	var errors []error
+	for _, prefix := range prefixes {
+		c, errs := extractTemplatesFromMap("", annotations, prefix)
+		for _, err := range errs {
+			errors = append(errors, fmt.Errorf("%s: %s", prefix, err))
+		}
+		for idx := range c {
+			c[idx].ADIdentifiers = nil
+			c[idx].Source = "annotations:" + prefix
+		}
+		configs = append(configs, c...)
+	}
+	return configs, errors
+}
+
 // extractCheckTemplatesFromMap returns all the check configurations from a given map.
 func extractCheckTemplatesFromMap(key string, input map[string]string, prefix string) ([]integration.Config, error) {
 	value, found := input[prefix+checkNamePath]
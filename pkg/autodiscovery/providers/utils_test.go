@@ -425,6 +425,31 @@ func TestExtractTemplatesFromMap(t *testing.T) {
 	}
 }
 
+func TestExtractTemplatesFromAnnotations(t *testing.T) {
+	configs, errs := ExtractTemplatesFromAnnotations(map[string]string{
+		"ad.datadoghq.com/redis.check_names":  "[\"redisdb\"]",
+		"ad.datadoghq.com/redis.init_configs": "[{}]",
+		"ad.datadoghq.com/redis.instances":    "[{\"host\": \"%%host%%\"}]",
+		"ad.datadoghq.com/nginx.logs":         "[{\"service\": \"nginx\"}]",
+		"ad.datadoghq.com/broken.check_names": "[\"http_check\"]",
+		"foo":                                 "bar",
+	})
+	assert.Equal(t, []integration.Config{
+		{
+			LogsConfig: integration.Data("[{\"service\":\"nginx\"}]"),
+			Source:     "annotations:ad.datadoghq.com/nginx.",
+		},
+		{
+			Name:       "redisdb",
+			InitConfig: integration.Data("{}"),
+			Instances:  []integration.Data{integration.Data("{\"host\":\"%%host%%\"}")},
+			Source:     "annotations:ad.datadoghq.com/redis.",
+		},
+	}, configs)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "ad.datadoghq.com/broken.: could not extract checks config: missing init_configs key")
+}
+
 func TestGetPollInterval(t *testing.T) {
 	cp := config.ConfigurationProviders{}
 	assert.Equal(t, GetPollInterval(cp), 10*time.Second)
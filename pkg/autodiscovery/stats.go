@@ -24,20 +24,15 @@ func newAcErrorStats() *acErrorStats {
 	}
 }
 
-// setConfigError will safely set the error for a check configuration file
-func (es *acErrorStats) setConfigError(checkName string, err string) {
+// setConfigErrors will safely replace the errors of all the check configuration files
+func (es *acErrorStats) setConfigErrors(errors map[string]string) {
 	es.m.Lock()
 	defer es.m.Unlock()
 
-	es.config[checkName] = err
-}
-
-// removeConfigErrors removes the errors for a check config file
-func (es *acErrorStats) removeConfigError(checkName string) {
-	es.m.Lock()
-	defer es.m.Unlock()
-
-	delete(es.config, checkName)
+	es.config = make(map[string]string, len(errors))
+	for checkName, err := range errors {
+		es.config[checkName] = err
+	}
 }
 
 // getConfigErrors will safely get the errors a check config file
@@ -16,29 +16,19 @@ func TestNewAcErrorStats(t *testing.T) {
 	assert.Len(t, s.config, 0)
 }
 
-func TestSetConfigError(t *testing.T) {
+func TestGetConfigErrors(t *testing.T) {
 	s := newAcErrorStats()
 	name := "foo.yaml"
-	s.setConfigError(name, "anError")
-	s.setConfigError(name, "anotherError")
-
-	assert.Len(t, s.config, 1)
-	assert.Equal(t, s.config[name], "anotherError")
-}
+	s.setConfigErrors(map[string]string{name: "anError"})
+	err := s.getConfigErrors()
 
-func TestRemoveConfigError(t *testing.T) {
-	s := newAcErrorStats()
-	name := "foo.yaml"
-	s.setConfigError(name, "anError")
-	s.removeConfigError(name)
-	assert.Len(t, s.config, 0)
+	assert.Len(t, err, 1)
 }
 
-func TestGetConfigErrors(t *testing.T) {
+func TestSetConfigErrors(t *testing.T) {
 	s := newAcErrorStats()
-	name := "foo.yaml"
-	s.setConfigError(name, "anError")
-	err := s.getConfigErrors()
+	s.setConfigErrors(map[string]string{"foo": "anError"})
+	s.setConfigErrors(map[string]string{"bar": "anotherError"})
 
-	assert.Len(t, err, 1)
+	assert.Equal(t, map[string]string{"bar": "anotherError"}, s.getConfigErrors())
 }
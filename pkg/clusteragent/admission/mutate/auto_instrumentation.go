@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	admiv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// libVersionAnnotationKeyFormat is the annotation requesting the injection of the library of a language
+	libVersionAnnotationKeyFormat = "admission.datadoghq.com/%s-lib.version"
+	// customLibAnnotationKeyFormat is the annotation overriding the whole image of the library of a language
+	customLibAnnotationKeyFormat = "admission.datadoghq.com/%s-lib.custom-image"
+
+	defaultLibVersion = "latest"
+
+	volumeName = "datadog-auto-instrumentation"
+	mountPath  = "/datadog-lib"
+)
+
+type language string
+
+const (
+	java   language = "java"
+	js     language = "js"
+	python language = "python"
+)
+
+// supportedLanguages is ordered to inject the init containers deterministically
+var supportedLanguages = []language{java, js, python}
+
+// libEnv is an environment variable making the runtime of a language load the library
+type libEnv struct {
+	name  string
+	value string
+	// separator joins the value to the one already set by the user, if any
+	separator string
+	// prepend puts the value before the one set by the user instead of after
+	prepend bool
+}
+
+var languageEnvs = map[language]libEnv{
+	java: {
+		name:      "JAVA_TOOL_OPTIONS",
+		value:     "-javaagent:" + mountPath + "/dd-java-agent.jar",
+		separator: " ",
+	},
+	js: {
+		name:      "NODE_OPTIONS",
+		value:     "--require=" + mountPath + "/node_modules/dd-trace/init",
+		separator: " ",
+	},
+	python: {
+		name:      "PYTHONPATH",
+		value:     mountPath + "/",
+		separator: ":",
+		prepend:   true,
+	},
+}
+
+// libInfo describes a library to inject into a pod
+type libInfo struct {
+	lang  language
+	image string
+}
+
+// InjectAutoInstrumentation injects the tracing libraries requested by the
+// pod annotations through init containers, and configures the runtimes of the
+// application containers to load them
+func InjectAutoInstrumentation(req *admiv1beta1.AdmissionRequest, dc dynamic.Interface) (*admiv1beta1.AdmissionResponse, error) {
+	return mutate(req, injectAutoInstrumentation, dc)
+}
+
+// injectAutoInstrumentation injects the tracing libraries into a pod template if needed
+func injectAutoInstrumentation(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
+	var injected bool
+	defer func() {
+		metrics.MutationAttempts.Inc(metrics.LibInjectionMutationType, strconv.FormatBool(injected))
+	}()
+
+	if pod == nil {
+		metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "nil pod")
+		return errors.New("cannot inject lib into nil pod")
+	}
+
+	if !shouldInjectLibs(pod) {
+		return nil
+	}
+
+	libs := extractLibInfo(pod)
+	if len(libs) == 0 {
+		return nil
+	}
+
+	injected = injectLibs(pod, libs)
+	return nil
+}
+
+// shouldInjectLibs returns whether the libraries can be injected, the pods
+// explicitly filtered-out with the admission.datadoghq.com/enabled label are ignored
+func shouldInjectLibs(pod *corev1.Pod) bool {
+	if val := pod.GetLabels()[admission.EnabledLabelKey]; val == "false" {
+		return false
+	}
+	return true
+}
+
+// extractLibInfo returns the libraries requested by the annotations of the pod
+func extractLibInfo(pod *corev1.Pod) []libInfo {
+	annotations := pod.GetAnnotations()
+	libs := []libInfo{}
+	for _, lang := range supportedLanguages {
+		if image, found := annotations[fmt.Sprintf(customLibAnnotationKeyFormat, lang)]; found {
+			libs = append(libs, libInfo{lang: lang, image: image})
+			continue
+		}
+
+		if version, found := annotations[fmt.Sprintf(libVersionAnnotationKeyFormat, lang)]; found {
+			if version == "" {
+				version = defaultLibVersion
+			}
+			libs = append(libs, libInfo{lang: lang, image: libImage(lang, version)})
+		}
+	}
+	return libs
+}
+
+// libImage returns the image of the library of a language from the configuration
+func libImage(lang language, version string) string {
+	repository := config.Datadog.GetString(fmt.Sprintf("admission_controller.auto_instrumentation.images.%s", lang))
+	return fmt.Sprintf("%s:%s", repository, version)
+}
+
+// injectLibs adds the volume shared by the init containers copying the
+// libraries and the application containers, and configures the runtimes.
+// Every step is skipped if it was already applied, to keep the mutation idempotent.
+func injectLibs(pod *corev1.Pod, libs []libInfo) bool {
+	injected := false
+	podStr := podString(pod)
+
+	if injectLibVolume(pod) {
+		injected = true
+	}
+
+	for _, lib := range libs {
+		log.Debugf("Injecting the %s library into pod %s with image %s", lib.lang, podStr, lib.image)
+
+		if injectLibInitContainer(pod, lib) {
+			injected = true
+		}
+
+		for i := range pod.Spec.Containers {
+			ctr := &pod.Spec.Containers[i]
+			if injectLibVolumeMount(ctr) {
+				injected = true
+			}
+			if injectLibEnv(ctr, languageEnvs[lib.lang], podStr) {
+				injected = true
+			}
+		}
+	}
+
+	return injected
+}
+
+// injectLibVolume adds the emptyDir volume holding the libraries if it doesn't exist
+func injectLibVolume(pod *corev1.Pod) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Name == volumeName {
+			return false
+		}
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	return true
+}
+
+// injectLibVolumeMount mounts the volume holding the libraries into a container if it's not mounted
+func injectLibVolumeMount(ctr *corev1.Container) bool {
+	for _, mount := range ctr.VolumeMounts {
+		if mount.Name == volumeName {
+			return false
+		}
+	}
+	ctr.VolumeMounts = append(ctr.VolumeMounts, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+	})
+	return true
+}
+
+// injectLibInitContainer adds the init container copying the library of a language if it doesn't exist
+func injectLibInitContainer(pod *corev1.Pod, lib libInfo) bool {
+	name := initContainerName(lib.lang)
+	for _, ctr := range pod.Spec.InitContainers {
+		if ctr.Name == name {
+			log.Debugf("Ignoring init container '%s' in pod %s: it already exists", name, podString(pod))
+			return false
+		}
+	}
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:    name,
+		Image:   lib.image,
+		Command: []string{"sh", "copy-lib.sh", mountPath},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      volumeName,
+				MountPath: mountPath,
+			},
+		},
+	})
+	return true
+}
+
+// injectLibEnv sets the environment variable loading the library, merging it
+// with the value set by the user if any
+func injectLibEnv(ctr *corev1.Container, env libEnv, podStr string) bool {
+	for i, e := range ctr.Env {
+		if e.Name != env.name {
+			continue
+		}
+		if e.ValueFrom != nil {
+			log.Warnf("Ignoring container '%s' in pod %s: env var '%s' is set from a reference and cannot be merged", ctr.Name, podStr, env.name)
+			return false
+		}
+		if strings.Contains(e.Value, env.value) {
+			return false
+		}
+		if e.Value == "" {
+			ctr.Env[i].Value = env.value
+		} else if env.prepend {
+			ctr.Env[i].Value = env.value + env.separator + e.Value
+		} else {
+			ctr.Env[i].Value = e.Value + env.separator + env.value
+		}
+		return true
+	}
+	ctr.Env = append(ctr.Env, corev1.EnvVar{
+		Name:  env.name,
+		Value: env.value,
+	})
+	return true
+}
+
+func initContainerName(lang language) string {
+	return fmt.Sprintf("datadog-lib-%s-init", lang)
+}
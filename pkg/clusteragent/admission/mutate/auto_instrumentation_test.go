@@ -0,0 +1,207 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_extractLibInfo(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.images.java", "registry/dd-lib-java-init")
+	mockConfig.Set("admission_controller.auto_instrumentation.images.python", "registry/dd-lib-python-init")
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want []libInfo
+	}{
+		{
+			name: "no annotation",
+			pod:  fakePod("foo-pod"),
+			want: []libInfo{},
+		},
+		{
+			name: "java version",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1.2.3"),
+			want: []libInfo{{lang: java, image: "registry/dd-lib-java-init:v1.2.3"}},
+		},
+		{
+			name: "empty version",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/python-lib.version", ""),
+			want: []libInfo{{lang: python, image: "registry/dd-lib-python-init:latest"}},
+		},
+		{
+			name: "custom image",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/js-lib.custom-image", "foo/bar:baz"),
+			want: []libInfo{{lang: js, image: "foo/bar:baz"}},
+		},
+		{
+			name: "unsupported language",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/cobol-lib.version", "v1"),
+			want: []libInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractLibInfo(tt.pod))
+		})
+	}
+}
+
+func Test_injectAutoInstrumentation(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.images.java", "registry/dd-lib-java-init")
+	mockConfig.Set("admission_controller.auto_instrumentation.images.python", "registry/dd-lib-python-init")
+
+	libVolume := corev1.Volume{
+		Name: "datadog-auto-instrumentation",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+	libMount := corev1.VolumeMount{
+		Name:      "datadog-auto-instrumentation",
+		MountPath: "/datadog-lib",
+	}
+	initContainer := func(lang, image string) corev1.Container {
+		return corev1.Container{
+			Name:         "datadog-lib-" + lang + "-init",
+			Image:        image,
+			Command:      []string{"sh", "copy-lib.sh", "/datadog-lib"},
+			VolumeMounts: []corev1.VolumeMount{libMount},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		pod         func() *corev1.Pod
+		wantPodFunc func() *corev1.Pod
+	}{
+		{
+			name: "no annotation",
+			pod:  func() *corev1.Pod { return fakePod("foo-pod") },
+			wantPodFunc: func() *corev1.Pod {
+				return fakePod("foo-pod")
+			},
+		},
+		{
+			name: "disabled by label",
+			pod: func() *corev1.Pod {
+				pod := fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1")
+				pod.Labels = map[string]string{"admission.datadoghq.com/enabled": "false"}
+				return pod
+			},
+			wantPodFunc: func() *corev1.Pod {
+				pod := fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1")
+				pod.Labels = map[string]string{"admission.datadoghq.com/enabled": "false"}
+				return pod
+			},
+		},
+		{
+			name: "java",
+			pod: func() *corev1.Pod {
+				return fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1")
+			},
+			wantPodFunc: func() *corev1.Pod {
+				pod := fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1")
+				pod.Spec.Volumes = []corev1.Volume{libVolume}
+				pod.Spec.InitContainers = []corev1.Container{initContainer("java", "registry/dd-lib-java-init:v1")}
+				pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{libMount}
+				pod.Spec.Containers[0].Env = []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-javaagent:/datadog-lib/dd-java-agent.jar")}
+				return pod
+			},
+		},
+		{
+			name: "merge with the env vars of the user",
+			pod: func() *corev1.Pod {
+				pod := fakePodWithContainer("foo-pod", corev1.Container{
+					Name: "foo-container",
+					Env: []corev1.EnvVar{
+						fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g"),
+						fakeEnvWithValue("PYTHONPATH", "/app"),
+					},
+				})
+				pod.Annotations = map[string]string{
+					"admission.datadoghq.com/java-lib.version":   "v1",
+					"admission.datadoghq.com/python-lib.version": "v2",
+				}
+				return pod
+			},
+			wantPodFunc: func() *corev1.Pod {
+				pod := fakePodWithContainer("foo-pod", corev1.Container{
+					Name: "foo-container",
+					Env: []corev1.EnvVar{
+						fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g -javaagent:/datadog-lib/dd-java-agent.jar"),
+						fakeEnvWithValue("PYTHONPATH", "/datadog-lib/:/app"),
+					},
+					VolumeMounts: []corev1.VolumeMount{libMount},
+				})
+				pod.Annotations = map[string]string{
+					"admission.datadoghq.com/java-lib.version":   "v1",
+					"admission.datadoghq.com/python-lib.version": "v2",
+				}
+				pod.Spec.Volumes = []corev1.Volume{libVolume}
+				pod.Spec.InitContainers = []corev1.Container{
+					initContainer("java", "registry/dd-lib-java-init:v1"),
+					initContainer("python", "registry/dd-lib-python-init:v2"),
+				}
+				return pod
+			},
+		},
+		{
+			name: "env var set from a reference",
+			pod: func() *corev1.Pod {
+				pod := fakePodWithContainer("foo-pod", corev1.Container{
+					Name: "foo-container",
+					Env: []corev1.EnvVar{{
+						Name:      "NODE_OPTIONS",
+						ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+					}},
+				})
+				pod.Annotations = map[string]string{"admission.datadoghq.com/js-lib.custom-image": "foo/bar:baz"}
+				return pod
+			},
+			wantPodFunc: func() *corev1.Pod {
+				pod := fakePodWithContainer("foo-pod", corev1.Container{
+					Name: "foo-container",
+					Env: []corev1.EnvVar{{
+						Name:      "NODE_OPTIONS",
+						ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+					}},
+					VolumeMounts: []corev1.VolumeMount{libMount},
+				})
+				pod.Annotations = map[string]string{"admission.datadoghq.com/js-lib.custom-image": "foo/bar:baz"}
+				pod.Spec.Volumes = []corev1.Volume{libVolume}
+				pod.Spec.InitContainers = []corev1.Container{initContainer("js", "foo/bar:baz")}
+				return pod
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := tt.pod()
+			require.NoError(t, injectAutoInstrumentation(pod, "", nil))
+			assert.Equal(t, tt.wantPodFunc(), pod)
+
+			// The mutation is idempotent
+			require.NoError(t, injectAutoInstrumentation(pod, "", nil))
+			assert.Equal(t, tt.wantPodFunc(), pod)
+		})
+	}
+}
+
+func Test_injectAutoInstrumentationNilPod(t *testing.T) {
+	assert.Error(t, injectAutoInstrumentation(nil, "", nil))
+}
@@ -68,3 +68,9 @@ func fakePod(name string) *corev1.Pod {
 func boolPointer(b bool) *bool {
 	return &b
 }
+
+func fakePodWithAnnotation(k, v string) *corev1.Pod {
+	pod := fakePod("foo-pod")
+	pod.Annotations = map[string]string{k: v}
+	return pod
+}
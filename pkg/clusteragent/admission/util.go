@@ -63,6 +63,22 @@ func generateWebhooks() []admiv1beta1.MutatingWebhook {
 		webhooks = append(webhooks, webhook)
 	}
 
+	// Tracing libraries injection
+	if config.Datadog.GetBool("admission_controller.auto_instrumentation.enabled") {
+		webhook := getWebhookSkeleton("auto.instrumentation", config.Datadog.GetString("admission_controller.auto_instrumentation.endpoint"))
+		// Accept all, the libraries are only injected into the pods requesting them with annotations
+		webhook.ObjectSelector = &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      EnabledLabelKey,
+					Operator: metav1.LabelSelectorOpNotIn,
+					Values:   []string{"false"},
+				},
+			},
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
 	return webhooks
 }
 
@@ -50,6 +50,22 @@ func (h *Handler) GetState() (types.StateResponse, error) {
 	}
 }
 
+// GetRebalancePlan returns the moves the cost-aware rebalancing would do, without
+// applying them, for the clusterchecks cmd
+func (h *Handler) GetRebalancePlan() (types.RebalancePlanResponse, error) {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	switch h.state {
+	case leader:
+		return h.dispatcher.buildRebalancePlan(), nil
+	case follower:
+		return types.RebalancePlanResponse{NotRunning: "currently follower"}, nil
+	default:
+		return types.RebalancePlanResponse{NotRunning: notReadyReason}, nil
+	}
+}
+
 // GetConfigs returns configurations dispatched to a given node
 func (h *Handler) GetConfigs(nodeName string) (types.ConfigResponse, error) {
 	configs, lastChange, err := h.dispatcher.getNodeConfigs(nodeName)
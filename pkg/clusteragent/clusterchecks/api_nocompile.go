@@ -29,6 +29,11 @@ func (h *Handler) GetState() (types.StateResponse, error) {
 	return types.StateResponse{}, ErrNotCompiled
 }
 
+// GetRebalancePlan not implemented
+func (h *Handler) GetRebalancePlan() (types.RebalancePlanResponse, error) {
+	return types.RebalancePlanResponse{}, ErrNotCompiled
+}
+
 // NewHandler not implemented
 func NewHandler(_ *autodiscovery.AutoConfig) (*Handler, error) {
 	return nil, ErrNotCompiled
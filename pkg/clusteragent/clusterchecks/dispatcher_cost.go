@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	busynessRebalanceStrategy = "busyness"
+	costRebalanceStrategy     = "cost"
+
+	// defaultCheckRunners is the default check_runners value of the node-agents,
+	// used for the ones not reporting it
+	defaultCheckRunners = 4
+)
+
+// costNode is the state of a node while planning a rebalance
+type costNode struct {
+	name         string
+	checkRunners int
+	cost         int            // execution time of all the checks running on the node
+	plannedCost  int            // cost after the planned moves
+	movable      map[string]int // cost of the cluster checks which can be moved, by check ID
+}
+
+// load returns the execution time per check runner of the node after the planned moves
+func (n *costNode) load(cost int) float64 {
+	return float64(cost) / float64(n.checkRunners)
+}
+
+// rebalanceByCost moves the cluster checks of the rebalance plan
+func (d *dispatcher) rebalanceByCost() {
+	start := time.Now()
+	defer func() {
+		rebalancingDuration.Set(time.Since(start).Seconds(), le.JoinLeaderValue)
+	}()
+
+	plan := d.buildRebalancePlan()
+	for _, move := range plan.Moves {
+		rebalancingDecisions.Inc(le.JoinLeaderValue)
+		if err := d.moveCheck(move.Source, move.Destination, move.CheckID); err != nil {
+			log.Debugf("Cannot move check %s: %v", move.CheckID, err)
+			continue
+		}
+		successfulRebalancing.Inc(le.JoinLeaderValue)
+		log.Debugf("Check %s with cost %d moved from %s to %s", move.CheckID, move.Cost, move.Source, move.Destination)
+	}
+}
+
+// buildRebalancePlan plans the cluster check moves balancing the execution
+// time per check runner of the nodes, from the last collected runner stats.
+// It is used by rebalanceByCost, and by the clusterchecks cmd to simulate a
+// rebalance without applying it.
+func (d *dispatcher) buildRebalancePlan() types.RebalancePlanResponse {
+	checkNames := make(map[string]string)
+	var nodes []*costNode
+
+	d.store.RLock()
+	for name, node := range d.store.nodes {
+		if name == "" {
+			continue
+		}
+		node.RLock()
+		if node.busyness == defaultBusynessValue {
+			// runner stats not collected yet
+			node.RUnlock()
+			continue
+		}
+		n := &costNode{
+			name:         name,
+			checkRunners: node.checkRunners(),
+			movable:      make(map[string]int),
+		}
+		for id, stats := range node.clcRunnerStats {
+			cost := checkCost(stats)
+			n.cost += cost
+			if !stats.IsClusterCheck {
+				continue
+			}
+			checkName := d.store.digestToConfig[d.store.idToDigest[check.ID(id)]].Name
+			checkNames[id] = checkName
+			if d.pinnedChecks[checkName] {
+				continue
+			}
+			n.movable[id] = cost
+		}
+		n.plannedCost = n.cost
+		node.RUnlock()
+		nodes = append(nodes, n)
+	}
+	d.store.RUnlock()
+
+	plan := types.RebalancePlanResponse{
+		Moves: planRebalance(nodes, d.rebalanceHysteresis, d.rebalanceMaxMoves),
+	}
+	for i := range plan.Moves {
+		plan.Moves[i].CheckName = checkNames[plan.Moves[i].CheckID]
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].name < nodes[j].name })
+	for _, n := range nodes {
+		plan.Nodes = append(plan.Nodes, types.RebalanceNodeLoad{
+			Name:         n.name,
+			CheckRunners: n.checkRunners,
+			Cost:         n.cost,
+			PlannedCost:  n.plannedCost,
+		})
+	}
+	return plan
+}
+
+// planRebalance moves checks from the most loaded node to the least loaded one,
+// as long as a move lowers the highest load of the two nodes by more than the
+// hysteresis ratio of the load of the most loaded node, and up to maxMoves
+// moves. The hysteresis keeps the checks from moving back and forth between
+// nodes of similar loads. A check is moved once at most.
+func planRebalance(nodes []*costNode, hysteresis float64, maxMoves int) []types.RebalanceMove {
+	var moves []types.RebalanceMove
+	moved := make(map[string]bool)
+
+	for len(moves) < maxMoves && len(nodes) > 1 {
+		sort.Slice(nodes, func(i, j int) bool {
+			li, lj := nodes[i].load(nodes[i].plannedCost), nodes[j].load(nodes[j].plannedCost)
+			if li != lj {
+				return li > lj
+			}
+			return nodes[i].name < nodes[j].name
+		})
+		src, dest := nodes[0], nodes[len(nodes)-1]
+
+		// pick the check minimizing the highest load of the two nodes
+		checkID := ""
+		bestPeak := src.load(src.plannedCost) * (1 - hysteresis)
+		for _, id := range sortedCheckIDs(src.movable) {
+			cost := src.movable[id]
+			if moved[id] || cost <= 0 {
+				continue
+			}
+			peak := math.Max(src.load(src.plannedCost-cost), dest.load(dest.plannedCost+cost))
+			if peak < bestPeak {
+				bestPeak = peak
+				checkID = id
+			}
+		}
+		if checkID == "" {
+			break
+		}
+
+		cost := src.movable[checkID]
+		src.plannedCost -= cost
+		dest.plannedCost += cost
+		delete(src.movable, checkID)
+		moved[checkID] = true
+		moves = append(moves, types.RebalanceMove{
+			CheckID:     checkID,
+			Source:      src.name,
+			Destination: dest.name,
+			Cost:        cost,
+		})
+	}
+
+	return moves
+}
+
+func sortedCheckIDs(m map[string]int) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
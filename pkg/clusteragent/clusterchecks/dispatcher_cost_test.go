@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+func newCostNode(name string, checkRunners int, nodeChecksCost int, movable map[string]int) *costNode {
+	n := &costNode{
+		name:         name,
+		checkRunners: checkRunners,
+		cost:         nodeChecksCost,
+		movable:      movable,
+	}
+	for _, cost := range movable {
+		n.cost += cost
+	}
+	n.plannedCost = n.cost
+	return n
+}
+
+func TestPlanRebalance(t *testing.T) {
+	for i, tc := range []struct {
+		name     string
+		nodes    []*costNode
+		maxMoves int
+		moves    []types.RebalanceMove
+	}{
+		{
+			name: "heaviest check balancing the nodes",
+			nodes: []*costNode{
+				newCostNode("A", 4, 0, map[string]int{"a1": 300, "a2": 200, "a3": 100}),
+				newCostNode("B", 4, 0, map[string]int{}),
+			},
+			maxMoves: 10,
+			moves: []types.RebalanceMove{
+				{CheckID: "a1", Source: "A", Destination: "B", Cost: 300},
+			},
+		},
+		{
+			name: "nodes of different capacities",
+			nodes: []*costNode{
+				newCostNode("A", 2, 0, map[string]int{"a1": 200, "a2": 200}),
+				newCostNode("B", 8, 400, map[string]int{}),
+			},
+			maxMoves: 10,
+			moves: []types.RebalanceMove{
+				{CheckID: "a1", Source: "A", Destination: "B", Cost: 200},
+			},
+		},
+		{
+			name: "improvement below the hysteresis",
+			nodes: []*costNode{
+				newCostNode("A", 4, 0, map[string]int{"a1": 100, "a2": 100}),
+				newCostNode("B", 4, 150, map[string]int{}),
+			},
+			maxMoves: 10,
+		},
+		{
+			name: "node checks can't be moved",
+			nodes: []*costNode{
+				newCostNode("A", 4, 1000, map[string]int{}),
+				newCostNode("B", 4, 0, map[string]int{}),
+			},
+			maxMoves: 10,
+		},
+		{
+			name: "moves limited by maxMoves",
+			nodes: []*costNode{
+				newCostNode("A", 1, 0, map[string]int{"a1": 100, "a2": 100, "a3": 100, "a4": 100}),
+				newCostNode("B", 1, 0, map[string]int{}),
+				newCostNode("C", 1, 0, map[string]int{}),
+			},
+			maxMoves: 1,
+			moves: []types.RebalanceMove{
+				{CheckID: "a1", Source: "A", Destination: "C", Cost: 100},
+			},
+		},
+		{
+			name: "several destinations",
+			nodes: []*costNode{
+				newCostNode("A", 1, 0, map[string]int{"a1": 100, "a2": 100, "a3": 100, "a4": 100}),
+				newCostNode("B", 1, 0, map[string]int{}),
+				newCostNode("C", 1, 0, map[string]int{}),
+			},
+			maxMoves: 10,
+			moves: []types.RebalanceMove{
+				{CheckID: "a1", Source: "A", Destination: "C", Cost: 100},
+				{CheckID: "a2", Source: "A", Destination: "B", Cost: 100},
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.name), func(t *testing.T) {
+			moves := planRebalance(tc.nodes, 0.1, tc.maxMoves)
+			assert.Equal(t, tc.moves, moves)
+		})
+	}
+}
+
+func TestBuildRebalancePlan(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.rebalanceHysteresis = 0.1
+	dispatcher.rebalanceMaxMoves = 10
+	dispatcher.pinnedChecks = map[string]bool{"pinned": true}
+
+	configs := map[string]integration.Config{}
+	ids := map[string]string{}
+	for _, name := range []string{"pinned", "heavy", "light"} {
+		configs[name] = integration.Config{
+			Name:         name,
+			Instances:    []integration.Data{integration.Data("{}")},
+			InitConfig:   integration.Data("{}"),
+			ClusterCheck: true,
+		}
+		ids[name] = string(check.BuildID(name, configs[name].Instances[0], configs[name].InitConfig))
+	}
+
+	dispatcher.store.active = true
+	for _, node := range []string{"A", "B", "C"} {
+		dispatcher.store.nodes[node] = newNodeStore(node, "")
+	}
+	dispatcher.addConfig(configs["pinned"], "A")
+	dispatcher.addConfig(configs["heavy"], "A")
+	dispatcher.addConfig(configs["light"], "A")
+
+	dispatcher.store.nodes["A"].busyness = 0
+	dispatcher.store.nodes["A"].lastStatus.CheckRunners = 2
+	dispatcher.store.nodes["A"].clcRunnerStats = types.CLCRunnersStats{
+		ids["pinned"]: {AverageExecutionTime: 1000, IsClusterCheck: true},
+		ids["heavy"]:  {AverageExecutionTime: 500, IsClusterCheck: true},
+		ids["light"]:  {AverageExecutionTime: 100, IsClusterCheck: true},
+		"cpu":         {AverageExecutionTime: 50},
+	}
+	dispatcher.store.nodes["B"].busyness = 0
+	dispatcher.store.nodes["B"].clcRunnerStats = types.CLCRunnersStats{
+		"cpu": {AverageExecutionTime: 50},
+	}
+	// runner stats not collected yet
+	dispatcher.store.nodes["C"].busyness = defaultBusynessValue
+
+	plan := dispatcher.buildRebalancePlan()
+	assert.Equal(t, []types.RebalanceNodeLoad{
+		{Name: "A", CheckRunners: 2, Cost: 1650, PlannedCost: 1150},
+		{Name: "B", CheckRunners: defaultCheckRunners, Cost: 50, PlannedCost: 550},
+	}, plan.Nodes)
+	// moving the light check too would only lower the load of A from 575 to 525
+	// per check runner, less than the hysteresis
+	require.Len(t, plan.Moves, 1)
+	assert.Equal(t, types.RebalanceMove{CheckID: ids["heavy"], CheckName: "heavy", Source: "A", Destination: "B", Cost: 500}, plan.Moves[0])
+
+	// the plan isn't applied
+	assert.Len(t, dispatcher.store.nodes["A"].clcRunnerStats, 4)
+
+	requireNotLocked(t, dispatcher.store)
+}
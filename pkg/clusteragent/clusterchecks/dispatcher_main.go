@@ -31,6 +31,10 @@ type dispatcher struct {
 	extraTags             []string
 	clcRunnersClient      clusteragent.CLCRunnerClientInterface
 	advancedDispatching   bool
+	rebalanceStrategy     string
+	rebalanceHysteresis   float64
+	rebalanceMaxMoves     int
+	pinnedChecks          map[string]bool // names of the checks never moved by the cost rebalancing
 }
 
 func newDispatcher() *dispatcher {
@@ -50,6 +54,18 @@ func newDispatcher() *dispatcher {
 		d.extraTags = append(d.extraTags, fmt.Sprintf("kube_cluster_name:%s", clusterTagValue))
 	}
 
+	d.rebalanceStrategy = config.Datadog.GetString("cluster_checks.rebalance_strategy")
+	if d.rebalanceStrategy != busynessRebalanceStrategy && d.rebalanceStrategy != costRebalanceStrategy {
+		log.Warnf("Unknown cluster_checks.rebalance_strategy %q, using %q", d.rebalanceStrategy, busynessRebalanceStrategy)
+		d.rebalanceStrategy = busynessRebalanceStrategy
+	}
+	d.rebalanceHysteresis = config.Datadog.GetFloat64("cluster_checks.rebalance_hysteresis")
+	d.rebalanceMaxMoves = config.Datadog.GetInt("cluster_checks.rebalance_max_moves")
+	d.pinnedChecks = make(map[string]bool)
+	for _, name := range config.Datadog.GetStringSlice("cluster_checks.rebalance_pinned_checks") {
+		d.pinnedChecks[name] = true
+	}
+
 	d.advancedDispatching = config.Datadog.GetBool("cluster_checks.advanced_dispatching_enabled")
 	if !d.advancedDispatching {
 		return d
@@ -199,7 +215,11 @@ func (d *dispatcher) run(ctx context.Context) {
 				// Collect CLC runners stats and update cache
 				d.updateRunnersStats()
 				// Rebalance checks distribution
-				d.rebalance()
+				if d.rebalanceStrategy == costRebalanceStrategy {
+					d.rebalanceByCost()
+				} else {
+					d.rebalance()
+				}
 			}
 		}
 	}
@@ -74,7 +74,7 @@ func (d *dispatcher) processNodeStatus(nodeName, clientIP string, status types.N
 func (d *dispatcher) getLeastBusyNode() string {
 	var leastBusyNode string
 	minCheckCount := int(-1)
-	minBusyness := int(-1)
+	minBusyness := float64(-1)
 
 	d.store.RLock()
 	defer d.store.RUnlock()
@@ -87,9 +87,16 @@ func (d *dispatcher) getLeastBusyNode() string {
 			// dispatching based on clc runners stats
 			// only when advancedDispatching is true and
 			// started collecting busyness values
-			if minBusyness == -1 || store.busyness < minBusyness {
+			busyness := float64(store.busyness)
+			if d.rebalanceStrategy == costRebalanceStrategy {
+				// execution time per check runner
+				store.RLock()
+				busyness = float64(store.cost) / float64(store.checkRunners())
+				store.RUnlock()
+			}
+			if minBusyness == -1 || busyness < minBusyness {
 				leastBusyNode = name
-				minBusyness = store.busyness
+				minBusyness = busyness
 			}
 		} else {
 			// count-based round robin dispatching
@@ -183,6 +190,7 @@ func (d *dispatcher) updateRunnersStats() {
 		node.clcRunnerStats = stats
 		log.Tracef("Updated CLC Runner stats on node: %s, node IP: %s, stats: %v", name, node.clientIP, stats)
 		node.busyness = calculateBusyness(stats)
+		node.cost = calculateCost(stats)
 		log.Debugf("Updated busyness on node: %s, node IP: %s, busyness value: %d", name, node.clientIP, node.busyness)
 		busyness.Set(float64(node.busyness), node.name, le.JoinLeaderValue)
 		node.Unlock()
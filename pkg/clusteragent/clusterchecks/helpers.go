@@ -54,6 +54,21 @@ func busynessFunc(s types.CLCRunnerStats) int {
 	return int(checkExecutionTimeWeight*float64(s.AverageExecutionTime) + checkMetricSamplesWeight*float64(s.MetricSamples))
 }
 
+// calculateCost returns the execution time of the checks of a node
+func calculateCost(checkStats types.CLCRunnersStats) int {
+	cost := 0
+	for _, stats := range checkStats {
+		cost += checkCost(stats)
+	}
+	return cost
+}
+
+// checkCost returns the average execution time of a check in milliseconds.
+// Unlike its busyness, failing checks keep their cost as they may be timing out.
+func checkCost(s types.CLCRunnerStats) int {
+	return s.AverageExecutionTime
+}
+
 // orderedKeys sorts the keys of a map and return them in a slice
 func orderedKeys(m map[string]int) []string {
 	keys := []string{}
@@ -89,6 +89,7 @@ type nodeStore struct {
 	clientIP         string
 	clcRunnerStats   types.CLCRunnersStats
 	busyness         int
+	cost             int // execution time of the checks in ms, see checkCost
 }
 
 func newNodeStore(name, clientIP string) *nodeStore {
@@ -163,6 +164,15 @@ func (s *nodeStore) GetBusyness(busynessFunc func(stats types.CLCRunnerStats) in
 	return busyness
 }
 
+// checkRunners returns the number of check runners of the node, the default
+// value of the node-agents if it wasn't reported
+func (s *nodeStore) checkRunners() int {
+	if s.lastStatus.CheckRunners > 0 {
+		return s.lastStatus.CheckRunners
+	}
+	return defaultCheckRunners
+}
+
 // GetMostWeightedClusterCheck returns the Cluster Check with the most weight on the node
 // The nodeStore handles thread safety for this public method
 func (s *nodeStore) GetMostWeightedClusterCheck(busynessFunc func(stats types.CLCRunnerStats) int) (string, int, error) {
@@ -11,7 +11,8 @@ import (
 
 // NodeStatus holds the status report from the node-agent
 type NodeStatus struct {
-	LastChange int64 `json:"last_change"`
+	LastChange   int64 `json:"last_change"`
+	CheckRunners int   `json:"check_runners,omitempty"` // capacity of the node-agent, not sent by older agents
 }
 
 // StatusResponse holds the DCA response for a status report
@@ -39,6 +40,32 @@ type StateNodeResponse struct {
 	Configs []integration.Config `json:"configs"`
 }
 
+// RebalancePlanResponse holds the check moves proposed by the cost-aware
+// rebalancing, for the clusterchecks cmd
+type RebalancePlanResponse struct {
+	NotRunning string              `json:"not_running"` // Reason why not running, empty if leading
+	Nodes      []RebalanceNodeLoad `json:"nodes"`
+	Moves      []RebalanceMove     `json:"moves"`
+}
+
+// RebalanceNodeLoad is the load of a node before and after the moves of a
+// rebalance plan. Costs are in milliseconds of execution time per check run.
+type RebalanceNodeLoad struct {
+	Name         string `json:"name"`
+	CheckRunners int    `json:"check_runners"`
+	Cost         int    `json:"cost"`
+	PlannedCost  int    `json:"planned_cost"`
+}
+
+// RebalanceMove is a cluster check moved from a node to another by a rebalance plan
+type RebalanceMove struct {
+	CheckID     string `json:"check_id"`
+	CheckName   string `json:"check_name"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Cost        int    `json:"cost"`
+}
+
 // Stats holds statistics for the agent status command
 type Stats struct {
 	// Following
@@ -66,15 +66,29 @@ func (mr *MetricsRetriever) retrieveMetricsValues() {
 		return
 	}
 
-	queries := getUniqueQueries(datadogMetrics)
-	log.Debugf("Starting refreshing external metrics with: %d queries", len(queries))
-
-	results, err := mr.processor.QueryExternalMetric(queries)
-	globalError := false
-	// Check for global failure
-	if len(results) == 0 && err != nil {
-		globalError = true
-		log.Errorf("Unable to fetch external metrics: %v", err)
+	// Queries are grouped by time window, expressions are split into the queries they depend on
+	expressions := make(map[string]queryExpression, len(datadogMetrics))
+	for _, datadogMetric := range datadogMetrics {
+		if datadogMetric.QueryExpression {
+			expressions[datadogMetric.ID] = parseQueryExpression(datadogMetric.Query)
+		} else {
+			expressions[datadogMetric.ID] = &queryLeaf{query: datadogMetric.Query}
+		}
+	}
+	queriesByWindow := getUniqueQueriesByTimeWindow(datadogMetrics, expressions)
+
+	results := make(map[time.Duration]map[string]autoscalers.Point, len(queriesByWindow))
+	globalErrors := make(map[time.Duration]bool, len(queriesByWindow))
+	for timeWindow, queries := range queriesByWindow {
+		log.Debugf("Starting refreshing external metrics with: %d queries, time window: %v", len(queries), timeWindow)
+
+		windowResults, err := mr.processor.QueryExternalMetric(queries, timeWindow)
+		// Check for global failure
+		if len(windowResults) == 0 && err != nil {
+			globalErrors[timeWindow] = true
+			log.Errorf("Unable to fetch external metrics: %v", err)
+		}
+		results[timeWindow] = windowResults
 	}
 
 	// Update store with current results
@@ -87,34 +101,35 @@ func (mr *MetricsRetriever) retrieveMetricsValues() {
 			continue
 		}
 
-		if queryResult, found := results[datadogMetric.Query]; found {
-			log.Debug("QueryResult from DD: %v", queryResult)
-
-			if queryResult.Valid {
-				datadogMetricFromStore.Value = queryResult.Value
-
-				// If we get a valid but old metric, flag it as invalid
-				if currentTime.Unix()-queryResult.Timestamp <= mr.metricsMaxAge {
-					datadogMetricFromStore.Valid = true
-					datadogMetricFromStore.Error = nil
-					datadogMetricFromStore.UpdateTime = time.Unix(queryResult.Timestamp, 0).UTC()
-				} else {
-					datadogMetricFromStore.Valid = false
-					datadogMetricFromStore.Error = fmt.Errorf(invalidMetricOutdatedErrorMessage, datadogMetric.Query)
-					datadogMetricFromStore.UpdateTime = currentTime
-				}
-			} else {
-				datadogMetricFromStore.Valid = false
-				datadogMetricFromStore.Error = fmt.Errorf(invalidMetricBackendErrorMessage, datadogMetric.Query)
-				datadogMetricFromStore.UpdateTime = currentTime
-			}
-		} else {
+		maxAge := mr.metricsMaxAge
+		if datadogMetric.MaxAge > 0 {
+			maxAge = int64(datadogMetric.MaxAge.Seconds())
+		}
+
+		result, qErr := expressions[datadogMetric.ID].evaluate(results[datadogMetric.TimeWindow])
+		if qErr != nil && qErr.reason == model.DatadogMetricErrorReasonNoData && globalErrors[datadogMetric.TimeWindow] {
+			qErr = &queryError{reason: model.DatadogMetricErrorReasonGlobal, err: fmt.Errorf(invalidMetricGlobalErrorMessage)}
+		}
+
+		switch {
+		case qErr != nil:
 			datadogMetricFromStore.Valid = false
-			if globalError {
-				datadogMetricFromStore.Error = fmt.Errorf(invalidMetricGlobalErrorMessage)
-			} else {
-				datadogMetricFromStore.Error = fmt.Errorf(invalidMetricNoDataErrorMessage, datadogMetric.Query)
-			}
+			datadogMetricFromStore.Error = qErr.err
+			datadogMetricFromStore.ErrorReason = qErr.reason
+			datadogMetricFromStore.UpdateTime = currentTime
+		case currentTime.Unix()-result.timestamp <= maxAge:
+			log.Debugf("QueryResult from DD for %s: %v", datadogMetric.Query, result)
+			datadogMetricFromStore.Value = result.value
+			datadogMetricFromStore.Valid = true
+			datadogMetricFromStore.Error = nil
+			datadogMetricFromStore.ErrorReason = ""
+			datadogMetricFromStore.UpdateTime = time.Unix(result.timestamp, 0).UTC()
+		default:
+			// If we get a valid but old metric, flag it as invalid
+			datadogMetricFromStore.Value = result.value
+			datadogMetricFromStore.Valid = false
+			datadogMetricFromStore.Error = fmt.Errorf(invalidMetricOutdatedErrorMessage, datadogMetric.Query)
+			datadogMetricFromStore.ErrorReason = model.DatadogMetricErrorReasonOutdated
 			datadogMetricFromStore.UpdateTime = currentTime
 		}
 
@@ -122,15 +137,22 @@ func (mr *MetricsRetriever) retrieveMetricsValues() {
 	}
 }
 
-func getUniqueQueries(datadogMetrics []model.DatadogMetricInternal) []string {
-	queries := make([]string, 0, len(datadogMetrics))
-	unique := make(map[string]struct{}, len(queries))
+func getUniqueQueriesByTimeWindow(datadogMetrics []model.DatadogMetricInternal, expressions map[string]queryExpression) map[time.Duration][]string {
+	queriesByWindow := make(map[time.Duration][]string)
+	unique := make(map[time.Duration]map[string]struct{})
 	for _, datadogMetric := range datadogMetrics {
-		if _, found := unique[datadogMetric.Query]; !found {
-			unique[datadogMetric.Query] = struct{}{}
-			queries = append(queries, datadogMetric.Query)
+		timeWindow := datadogMetric.TimeWindow
+		if _, found := unique[timeWindow]; !found {
+			unique[timeWindow] = make(map[string]struct{})
+		}
+
+		for _, query := range expressions[datadogMetric.ID].queries() {
+			if _, found := unique[timeWindow][query]; !found {
+				unique[timeWindow][query] = struct{}{}
+				queriesByWindow[timeWindow] = append(queriesByWindow[timeWindow], query)
+			}
 		}
 	}
 
-	return queries
+	return queriesByWindow
 }
@@ -19,17 +19,31 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// mockedProcessor is a fake query backend, returning the points of the queries it knows
+// and recording the queries it received for each time window
 type mockedProcessor struct {
-	points map[string]autoscalers.Point
-	err    error
+	points  map[string]autoscalers.Point
+	err     error
+	queries map[time.Duration][]string
 }
 
 func (p *mockedProcessor) UpdateExternalMetrics(emList map[string]custommetrics.ExternalMetricValue) map[string]custommetrics.ExternalMetricValue {
 	return nil
 }
 
-func (p *mockedProcessor) QueryExternalMetric(queries []string) (map[string]autoscalers.Point, error) {
-	return p.points, p.err
+func (p *mockedProcessor) QueryExternalMetric(queries []string, timeWindow time.Duration) (map[string]autoscalers.Point, error) {
+	if p.queries == nil {
+		p.queries = make(map[time.Duration][]string)
+	}
+	p.queries[timeWindow] = append(p.queries[timeWindow], queries...)
+
+	points := make(map[string]autoscalers.Point, len(queries))
+	for _, query := range queries {
+		if point, found := p.points[query]; found {
+			points[query] = point
+		}
+	}
+	return points, p.err
 }
 
 func (p *mockedProcessor) ProcessEMList(emList []custommetrics.ExternalMetricValue) map[string]custommetrics.ExternalMetricValue {
@@ -43,6 +57,8 @@ type metricsFixture struct {
 	queryResults map[string]autoscalers.Point
 	queryError   error
 	expected     []model.DatadogMetricInternal
+	// expectedQueries are the queries sent to the backend for each time window, not checked if nil
+	expectedQueries map[time.Duration][]string
 }
 
 func (f *metricsFixture) run(t *testing.T, testTime time.Time) {
@@ -63,6 +79,13 @@ func (f *metricsFixture) run(t *testing.T, testTime time.Time) {
 	assert.Nil(t, err)
 	metricsRetriever.retrieveMetricsValues()
 
+	if f.expectedQueries != nil {
+		assert.Len(t, mockedProcessor.queries, len(f.expectedQueries))
+		for timeWindow, queries := range f.expectedQueries {
+			assert.ElementsMatch(t, queries, mockedProcessor.queries[timeWindow])
+		}
+	}
+
 	for _, expectedDatadogMetric := range f.expected {
 		datadogMetric := store.Get(expectedDatadogMetric.ID)
 
@@ -203,12 +226,13 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 					Error:      nil,
 				},
 				{
-					ID:     "metric1",
-					Active: true,
-					Query:  "query-metric1",
-					Value:  11.0,
-					Valid:  false,
-					Error:  fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric1"),
+					ID:          "metric1",
+					Active:      true,
+					Query:       "query-metric1",
+					Value:       11.0,
+					Valid:       false,
+					Error:       fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric1"),
+					ErrorReason: model.DatadogMetricErrorReasonOutdated,
 					// UpdateTime not set as it will not be compared directly
 				},
 			},
@@ -260,12 +284,13 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 					Error:      nil,
 				},
 				{
-					ID:     "metric1",
-					Active: true,
-					Query:  "query-metric1",
-					Value:  11.0,
-					Valid:  false,
-					Error:  fmt.Errorf(invalidMetricBackendErrorMessage, "query-metric1"),
+					ID:          "metric1",
+					Active:      true,
+					Query:       "query-metric1",
+					Value:       11.0,
+					Valid:       false,
+					Error:       fmt.Errorf(invalidMetricBackendErrorMessage, "query-metric1"),
+					ErrorReason: model.DatadogMetricErrorReasonBackend,
 					// UpdateTime not set as it will not be compared directly
 				},
 			},
@@ -297,21 +322,23 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 			queryError:   fmt.Errorf("Backend error 500"),
 			expected: []model.DatadogMetricInternal{
 				{
-					ID:     "metric0",
-					Active: true,
-					Query:  "query-metric0",
-					Value:  1.0,
-					Valid:  false,
-					Error:  fmt.Errorf(invalidMetricGlobalErrorMessage),
+					ID:          "metric0",
+					Active:      true,
+					Query:       "query-metric0",
+					Value:       1.0,
+					Valid:       false,
+					Error:       fmt.Errorf(invalidMetricGlobalErrorMessage),
+					ErrorReason: model.DatadogMetricErrorReasonGlobal,
 					// UpdateTime not set as it will not be compared directly
 				},
 				{
-					ID:     "metric1",
-					Active: true,
-					Query:  "query-metric1",
-					Value:  2.0,
-					Valid:  false,
-					Error:  fmt.Errorf(invalidMetricGlobalErrorMessage),
+					ID:          "metric1",
+					Active:      true,
+					Query:       "query-metric1",
+					Value:       2.0,
+					Valid:       false,
+					Error:       fmt.Errorf(invalidMetricGlobalErrorMessage),
+					ErrorReason: model.DatadogMetricErrorReasonGlobal,
 					// UpdateTime not set as it will not be compared directly
 				},
 			},
@@ -358,12 +385,13 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 					Error:      nil,
 				},
 				{
-					ID:     "metric1",
-					Active: true,
-					Query:  "query-metric1",
-					Value:  2.0,
-					Valid:  false,
-					Error:  fmt.Errorf(invalidMetricNoDataErrorMessage, "query-metric1"),
+					ID:          "metric1",
+					Active:      true,
+					Query:       "query-metric1",
+					Value:       2.0,
+					Valid:       false,
+					Error:       fmt.Errorf(invalidMetricNoDataErrorMessage, "query-metric1"),
+					ErrorReason: model.DatadogMetricErrorReasonNoData,
 					// UpdateTime not set as it will not be compared directly
 				},
 			},
@@ -499,3 +527,323 @@ func TestRetrieveMetricsNotActive(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieveMetricsTimeWindowAndMaxAge(t *testing.T) {
+	defaultTestTime := time.Now().Add(time.Duration(-1) * time.Second).UTC().Truncate(time.Second)
+	defaultPreviousUpdateTime := time.Now().Add(time.Duration(-11) * time.Second).UTC().Truncate(time.Second)
+	oldTestTime := time.Now().Add(time.Duration(-60) * time.Second).UTC().Truncate(time.Second)
+
+	fixtures := []metricsFixture{
+		{
+			maxAge: 30,
+			desc:   "Test queries are grouped by time window",
+			storeContent: []model.DatadogMetricInternal{
+				{
+					ID:         "metric0",
+					Active:     true,
+					Query:      "query-metric0",
+					UpdateTime: defaultPreviousUpdateTime,
+				},
+				{
+					ID:         "metric1",
+					Active:     true,
+					Query:      "query-metric1",
+					UpdateTime: defaultPreviousUpdateTime,
+					TimeWindow: 15 * time.Minute,
+				},
+				{
+					ID:         "metric2",
+					Active:     true,
+					Query:      "query-metric0",
+					UpdateTime: defaultPreviousUpdateTime,
+					TimeWindow: 15 * time.Minute,
+				},
+			},
+			queryResults: map[string]autoscalers.Point{
+				"query-metric0": {
+					Value:     10.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+				"query-metric1": {
+					Value:     11.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+			},
+			expectedQueries: map[time.Duration][]string{
+				0:                {"query-metric0"},
+				15 * time.Minute: {"query-metric1", "query-metric0"},
+			},
+			expected: []model.DatadogMetricInternal{
+				{
+					ID:         "metric0",
+					Active:     true,
+					Query:      "query-metric0",
+					Value:      10.0,
+					UpdateTime: defaultTestTime,
+					Valid:      true,
+				},
+				{
+					ID:         "metric1",
+					Active:     true,
+					Query:      "query-metric1",
+					Value:      11.0,
+					UpdateTime: defaultTestTime,
+					Valid:      true,
+					TimeWindow: 15 * time.Minute,
+				},
+				{
+					ID:         "metric2",
+					Active:     true,
+					Query:      "query-metric0",
+					Value:      10.0,
+					UpdateTime: defaultTestTime,
+					Valid:      true,
+					TimeWindow: 15 * time.Minute,
+				},
+			},
+		},
+		{
+			maxAge: 30,
+			desc:   "Test max age overridden by DatadogMetric",
+			storeContent: []model.DatadogMetricInternal{
+				{
+					ID:         "metric0",
+					Active:     true,
+					Query:      "query-metric0",
+					UpdateTime: defaultPreviousUpdateTime,
+				},
+				{
+					ID:         "metric1",
+					Active:     true,
+					Query:      "query-metric0",
+					UpdateTime: defaultPreviousUpdateTime,
+					MaxAge:     2 * time.Minute,
+				},
+			},
+			queryResults: map[string]autoscalers.Point{
+				"query-metric0": {
+					Value:     10.0,
+					Timestamp: oldTestTime.Unix(),
+					Valid:     true,
+				},
+			},
+			expected: []model.DatadogMetricInternal{
+				{
+					ID:          "metric0",
+					Active:      true,
+					Query:       "query-metric0",
+					Value:       10.0,
+					Valid:       false,
+					Error:       fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric0"),
+					ErrorReason: model.DatadogMetricErrorReasonOutdated,
+				},
+				{
+					ID:         "metric1",
+					Active:     true,
+					Query:      "query-metric0",
+					Value:      10.0,
+					UpdateTime: oldTestTime,
+					Valid:      true,
+					MaxAge:     2 * time.Minute,
+				},
+			},
+		},
+	}
+
+	for i, fixture := range fixtures {
+		t.Run(fmt.Sprintf("#%d %s", i, fixture.desc), func(t *testing.T) {
+			fixture.run(t, defaultTestTime)
+		})
+	}
+}
+
+func TestRetrieveMetricsExpressions(t *testing.T) {
+	defaultTestTime := time.Now().Add(time.Duration(-1) * time.Second).UTC().Truncate(time.Second)
+	defaultPreviousUpdateTime := time.Now().Add(time.Duration(-11) * time.Second).UTC().Truncate(time.Second)
+	olderTestTime := time.Now().Add(time.Duration(-5) * time.Second).UTC().Truncate(time.Second)
+
+	fixtures := []metricsFixture{
+		{
+			maxAge: 30,
+			desc:   "Test expressions are sent as is without the annotation",
+			storeContent: []model.DatadogMetricInternal{
+				{
+					ID:         "metric0",
+					Active:     true,
+					Query:      "avg:requests{app:foo} / avg:pods{app:foo}",
+					UpdateTime: defaultPreviousUpdateTime,
+				},
+			},
+			queryResults: map[string]autoscalers.Point{
+				"avg:requests{app:foo} / avg:pods{app:foo}": {
+					Value:     25.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+			},
+			expectedQueries: map[time.Duration][]string{
+				0: {"avg:requests{app:foo} / avg:pods{app:foo}"},
+			},
+			expected: []model.DatadogMetricInternal{
+				{
+					ID:         "metric0",
+					Active:     true,
+					Query:      "avg:requests{app:foo} / avg:pods{app:foo}",
+					Value:      25.0,
+					UpdateTime: defaultTestTime,
+					Valid:      true,
+				},
+			},
+		},
+		{
+			maxAge: 30,
+			desc:   "Test expressions combining several queries",
+			storeContent: []model.DatadogMetricInternal{
+				{
+					ID:              "metric0",
+					Active:          true,
+					Query:           "avg:requests{app:foo} / avg:pods{app:foo}",
+					QueryExpression: true,
+					UpdateTime:      defaultPreviousUpdateTime,
+				},
+				{
+					ID:              "metric1",
+					Active:          true,
+					Query:           "(avg:requests{app:foo} + avg:errors{app:foo}) * 2",
+					QueryExpression: true,
+					UpdateTime:      defaultPreviousUpdateTime,
+				},
+			},
+			queryResults: map[string]autoscalers.Point{
+				"avg:requests{app:foo}": {
+					Value:     100.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+				"avg:pods{app:foo}": {
+					Value:     4.0,
+					Timestamp: olderTestTime.Unix(),
+					Valid:     true,
+				},
+				"avg:errors{app:foo}": {
+					Value:     5.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+			},
+			expectedQueries: map[time.Duration][]string{
+				0: {"avg:requests{app:foo}", "avg:pods{app:foo}", "avg:errors{app:foo}"},
+			},
+			expected: []model.DatadogMetricInternal{
+				{
+					ID:              "metric0",
+					Active:          true,
+					Query:           "avg:requests{app:foo} / avg:pods{app:foo}",
+					QueryExpression: true,
+					Value:           25.0,
+					UpdateTime:      olderTestTime,
+					Valid:           true,
+				},
+				{
+					ID:              "metric1",
+					Active:          true,
+					Query:           "(avg:requests{app:foo} + avg:errors{app:foo}) * 2",
+					QueryExpression: true,
+					Value:           210.0,
+					UpdateTime:      defaultTestTime,
+					Valid:           true,
+				},
+			},
+		},
+		{
+			maxAge: 30,
+			desc:   "Test expressions with errors",
+			storeContent: []model.DatadogMetricInternal{
+				{
+					ID:              "metric0",
+					Active:          true,
+					Query:           "avg:requests{app:foo} / avg:pods{app:foo}",
+					QueryExpression: true,
+					Value:           3.0,
+					UpdateTime:      defaultPreviousUpdateTime,
+					Valid:           true,
+				},
+				{
+					ID:              "metric1",
+					Active:          true,
+					Query:           "avg:requests{app:foo} - avg:missing{app:foo}",
+					QueryExpression: true,
+					Value:           4.0,
+					UpdateTime:      defaultPreviousUpdateTime,
+					Valid:           true,
+				},
+				{
+					ID:              "metric2",
+					Active:          true,
+					Query:           "avg:requests{app:foo} + avg:invalid{app:foo}",
+					QueryExpression: true,
+					Value:           5.0,
+					UpdateTime:      defaultPreviousUpdateTime,
+					Valid:           true,
+				},
+			},
+			queryResults: map[string]autoscalers.Point{
+				"avg:requests{app:foo}": {
+					Value:     100.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+				"avg:pods{app:foo}": {
+					Value:     0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+				"avg:invalid{app:foo}": {
+					Value:     0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     false,
+				},
+			},
+			expected: []model.DatadogMetricInternal{
+				{
+					ID:              "metric0",
+					Active:          true,
+					Query:           "avg:requests{app:foo} / avg:pods{app:foo}",
+					QueryExpression: true,
+					Value:           3.0,
+					Valid:           false,
+					Error:           fmt.Errorf(divisionByZeroErrorMessage, "avg:requests{app:foo} / avg:pods{app:foo}"),
+					ErrorReason:     model.DatadogMetricErrorReasonEvaluation,
+				},
+				{
+					ID:              "metric1",
+					Active:          true,
+					Query:           "avg:requests{app:foo} - avg:missing{app:foo}",
+					QueryExpression: true,
+					Value:           4.0,
+					Valid:           false,
+					Error:           fmt.Errorf(invalidMetricNoDataErrorMessage, "avg:missing{app:foo}"),
+					ErrorReason:     model.DatadogMetricErrorReasonNoData,
+				},
+				{
+					ID:              "metric2",
+					Active:          true,
+					Query:           "avg:requests{app:foo} + avg:invalid{app:foo}",
+					QueryExpression: true,
+					Value:           5.0,
+					Valid:           false,
+					Error:           fmt.Errorf(invalidMetricBackendErrorMessage, "avg:invalid{app:foo}"),
+					ErrorReason:     model.DatadogMetricErrorReasonBackend,
+				},
+			},
+		},
+	}
+
+	for i, fixture := range fixtures {
+		t.Run(fmt.Sprintf("#%d %s", i, fixture.desc), func(t *testing.T) {
+			fixture.run(t, defaultTestTime)
+		})
+	}
+}
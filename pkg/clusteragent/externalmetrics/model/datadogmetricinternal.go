@@ -23,6 +23,19 @@ import (
 
 const (
 	DatadogMetricErrorConditionReason string = "Unable to fetch data from Datadog"
+
+	// Reasons of the Error condition, set when the cause of the error is known
+	DatadogMetricErrorReasonGlobal     string = "GlobalBackendError"
+	DatadogMetricErrorReasonBackend    string = "InvalidMetric"
+	DatadogMetricErrorReasonNoData     string = "NoData"
+	DatadogMetricErrorReasonOutdated   string = "OutdatedData"
+	DatadogMetricErrorReasonEvaluation string = "EvaluationError"
+
+	// Annotations of `DatadogMetric` overriding the time window of the query and the max age of its value
+	DatadogMetricTimeWindowAnnotationKey string = "external-metrics.datadoghq.com/time-window"
+	DatadogMetricMaxAgeAnnotationKey     string = "external-metrics.datadoghq.com/max-age"
+	// Annotation of `DatadogMetric` set to "true" for its query to be evaluated as an expression by the Cluster Agent
+	DatadogMetricQueryExpressionAnnotationKey string = "external-metrics.datadoghq.com/query-expression"
 )
 
 // DatadogMetricInternal is a flatten, easier to use, representation of `DatadogMetric` CRD
@@ -37,6 +50,14 @@ type DatadogMetricInternal struct {
 	Value              float64
 	UpdateTime         time.Time
 	Error              error
+	// ErrorReason is the reason of the Error condition, DatadogMetricErrorConditionReason if empty
+	ErrorReason string
+	// TimeWindow is the time window of the query, the default one if zero
+	TimeWindow time.Duration
+	// MaxAge is the max age of the value before it's considered outdated, the default one if zero
+	MaxAge time.Duration
+	// QueryExpression is true when the query combines several queries, evaluated by the Cluster Agent
+	QueryExpression bool
 }
 
 // NewDatadogMetricInternal returns a `DatadogMetricInternal` object from a `DatadogMetric` CRD Object
@@ -56,6 +77,9 @@ func NewDatadogMetricInternal(id string, datadogMetric datadoghq.DatadogMetric)
 		internal.ExternalMetricName = datadogMetric.Spec.ExternalMetricName
 	}
 
+	internal.TimeWindow, internal.MaxAge = parseDatadogMetricDurations(id, datadogMetric.GetAnnotations())
+	internal.QueryExpression = parseQueryExpressionAnnotation(id, datadogMetric.GetAnnotations())
+
 	for _, condition := range datadogMetric.Status.Conditions {
 		switch {
 		case condition.Type == datadoghq.DatadogMetricConditionTypeValid && condition.Status == corev1.ConditionTrue:
@@ -66,6 +90,9 @@ func NewDatadogMetricInternal(id string, datadogMetric datadoghq.DatadogMetric)
 			internal.UpdateTime = condition.LastUpdateTime.UTC()
 		case condition.Type == datadoghq.DatadogMetricConditionTypeError && condition.Status == corev1.ConditionTrue:
 			internal.Error = errors.New(condition.Message)
+			if condition.Reason != DatadogMetricErrorConditionReason {
+				internal.ErrorReason = condition.Reason
+			}
 		}
 	}
 
@@ -103,9 +130,11 @@ func NewDatadogMetricInternalFromExternalMetric(id, query, metricName string) Da
 	}
 }
 
-// UpdateFrom updates the `DatadogMetricInternal` from `DatadogMetric` Spec, returns modified instance
-func (d *DatadogMetricInternal) UpdateFrom(currentSpec datadoghq.DatadogMetricSpec) {
-	d.Query = currentSpec.Query
+// UpdateFrom updates the `DatadogMetricInternal` from `DatadogMetric` Spec and annotations
+func (d *DatadogMetricInternal) UpdateFrom(current datadoghq.DatadogMetric) {
+	d.Query = current.Spec.Query
+	d.TimeWindow, d.MaxAge = parseDatadogMetricDurations(d.ID, current.GetAnnotations())
+	d.QueryExpression = parseQueryExpressionAnnotation(d.ID, current.GetAnnotations())
 }
 
 // IsNewerThan returns true if the current `DatadogMetricInternal` has been updated more recently than `DatadogMetric` Status
@@ -152,6 +181,9 @@ func (d *DatadogMetricInternal) BuildStatus(currentStatus *datadoghq.DatadogMetr
 	errorCondition := d.newCondition(d.Error != nil, updateTime, datadoghq.DatadogMetricConditionTypeError, existingConditions[datadoghq.DatadogMetricConditionTypeError])
 	if d.Error != nil {
 		errorCondition.Reason = DatadogMetricErrorConditionReason
+		if d.ErrorReason != "" {
+			errorCondition.Reason = d.ErrorReason
+		}
 		errorCondition.Message = d.Error.Error()
 	}
 
@@ -9,6 +9,9 @@ package model
 
 import (
 	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 func parseDatadogMetricValue(s string) (float64, error) {
@@ -22,3 +25,40 @@ func parseDatadogMetricValue(s string) (float64, error) {
 func formatDatadogMetricValue(v float64) string {
 	return strconv.FormatFloat(v, 'f', -1, 64)
 }
+
+// parseDatadogMetricDurations returns the time window and max age set by the annotations of a `DatadogMetric`,
+// invalid durations and durations under a second are ignored to fall back to the default ones
+func parseDatadogMetricDurations(id string, annotations map[string]string) (timeWindow, maxAge time.Duration) {
+	return parseDuration(id, annotations, DatadogMetricTimeWindowAnnotationKey), parseDuration(id, annotations, DatadogMetricMaxAgeAnnotationKey)
+}
+
+func parseDuration(id string, annotations map[string]string, key string) time.Duration {
+	value, found := annotations[key]
+	if !found {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < time.Second {
+		log.Warnf("Ignoring invalid annotation %s: '%s' on DatadogMetric: %s, it must be a duration of at least 1s", key, value, id)
+		return 0
+	}
+
+	return duration
+}
+
+// parseQueryExpressionAnnotation returns whether the query of a `DatadogMetric` is opted-in to be evaluated as an expression
+func parseQueryExpressionAnnotation(id string, annotations map[string]string) bool {
+	value, found := annotations[DatadogMetricQueryExpressionAnnotationKey]
+	if !found {
+		return false
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warnf("Ignoring invalid annotation %s: '%s' on DatadogMetric: %s, it must be a boolean", DatadogMetricQueryExpressionAnnotationKey, value, id)
+		return false
+	}
+
+	return enabled
+}
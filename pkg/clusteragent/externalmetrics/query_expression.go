@@ -0,0 +1,265 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics/model"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+)
+
+const (
+	divisionByZeroErrorMessage string = "Division by zero while evaluating query: %s"
+)
+
+// queryExpression is a query combining the results of several Datadog queries
+// with arithmetic operators, evaluated by the Cluster Agent
+type queryExpression interface {
+	// queries returns the Datadog queries the expression depends on
+	queries() []string
+	// evaluate computes the value of the expression from the results of the queries
+	evaluate(results map[string]autoscalers.Point) (expressionResult, *queryError)
+}
+
+// expressionResult is the value of an expression, its timestamp is the one of the oldest query
+type expressionResult struct {
+	value     float64
+	timestamp int64
+}
+
+// queryError is an error evaluating an expression, with the reason reported in the Error condition
+type queryError struct {
+	reason string
+	err    error
+}
+
+type queryLeaf struct {
+	query string
+}
+
+func (l *queryLeaf) queries() []string {
+	return []string{l.query}
+}
+
+func (l *queryLeaf) evaluate(results map[string]autoscalers.Point) (expressionResult, *queryError) {
+	point, found := results[l.query]
+	if !found {
+		return expressionResult{}, &queryError{reason: model.DatadogMetricErrorReasonNoData, err: fmt.Errorf(invalidMetricNoDataErrorMessage, l.query)}
+	}
+	if !point.Valid {
+		return expressionResult{}, &queryError{reason: model.DatadogMetricErrorReasonBackend, err: fmt.Errorf(invalidMetricBackendErrorMessage, l.query)}
+	}
+	return expressionResult{value: point.Value, timestamp: point.Timestamp}, nil
+}
+
+type constantLeaf struct {
+	value float64
+}
+
+func (c *constantLeaf) queries() []string {
+	return nil
+}
+
+func (c *constantLeaf) evaluate(map[string]autoscalers.Point) (expressionResult, *queryError) {
+	// Constants don't get outdated, the timestamp is set by the queries of the expression
+	return expressionResult{value: c.value, timestamp: math.MaxInt64}, nil
+}
+
+type binaryOperation struct {
+	operator    byte
+	left, right queryExpression
+	// expression is the text of the operation, for error messages
+	expression string
+}
+
+func (o *binaryOperation) queries() []string {
+	return append(o.left.queries(), o.right.queries()...)
+}
+
+func (o *binaryOperation) evaluate(results map[string]autoscalers.Point) (expressionResult, *queryError) {
+	left, qErr := o.left.evaluate(results)
+	if qErr != nil {
+		return expressionResult{}, qErr
+	}
+	right, qErr := o.right.evaluate(results)
+	if qErr != nil {
+		return expressionResult{}, qErr
+	}
+
+	result := expressionResult{timestamp: left.timestamp}
+	if right.timestamp < result.timestamp {
+		result.timestamp = right.timestamp
+	}
+
+	switch o.operator {
+	case '+':
+		result.value = left.value + right.value
+	case '-':
+		result.value = left.value - right.value
+	case '*':
+		result.value = left.value * right.value
+	case '/':
+		if right.value == 0 {
+			return expressionResult{}, &queryError{reason: model.DatadogMetricErrorReasonEvaluation, err: fmt.Errorf(divisionByZeroErrorMessage, o.expression)}
+		}
+		result.value = left.value / right.value
+	}
+
+	return result, nil
+}
+
+// parseQueryExpression splits a query on its top-level arithmetic operators, it's
+// only used for the `DatadogMetric` opted-in with the query-expression annotation.
+// The query is kept as a single Datadog query, evaluated by the backend, unless
+// each operand is either a number or a scoped metric query (`avg:metric{scope}`),
+// so that queries that can't be split reliably keep working as before.
+func parseQueryExpression(query string) queryExpression {
+	p := expressionParser{input: query}
+	expr, err := p.parseSum()
+	if err != nil || p.pos != len(p.input) || !p.splittable || len(expr.queries()) == 0 {
+		return &queryLeaf{query: query}
+	}
+	return expr
+}
+
+type expressionParser struct {
+	input string
+	pos   int
+	// splittable is true when at least one operator was found and all the operands are valid
+	splittable bool
+}
+
+func (p *expressionParser) parseSum() (queryExpression, error) {
+	start := p.pos
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return left, nil
+		}
+		operator := p.input[p.pos]
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryOperation{operator: operator, left: left, right: right, expression: strings.TrimSpace(p.input[start:p.pos])}
+		p.splittable = true
+	}
+}
+
+func (p *expressionParser) parseProduct() (queryExpression, error) {
+	start := p.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return left, nil
+		}
+		operator := p.input[p.pos]
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryOperation{operator: operator, left: left, right: right, expression: strings.TrimSpace(p.input[start:p.pos])}
+		p.splittable = true
+	}
+}
+
+func (p *expressionParser) parseOperand() (queryExpression, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis at position %d", p.pos)
+		}
+		p.pos++
+		return expr, nil
+	case c == '-':
+		p.pos++
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryOperation{operator: '*', left: &constantLeaf{value: -1}, right: operand, expression: "-"}, nil
+	case (c >= '0' && c <= '9') || c == '.':
+		return p.parseNumber()
+	default:
+		return p.parseQuery()
+	}
+}
+
+func (p *expressionParser) parseNumber() (queryExpression, error) {
+	start := p.pos
+	for p.pos < len(p.input) && ((p.input[p.pos] >= '0' && p.input[p.pos] <= '9') || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return nil, err
+	}
+	return &constantLeaf{value: value}, nil
+}
+
+// parseQuery reads a Datadog query up to the next top-level operator,
+// operators nested in braces or parentheses (scopes, functions) are part of the query
+func (p *expressionParser) parseQuery() (queryExpression, error) {
+	start := p.pos
+	depth := 0
+loop:
+	for ; p.pos < len(p.input); p.pos++ {
+		switch p.input[p.pos] {
+		case '{', '(':
+			depth++
+		case '}':
+			depth--
+		case ')':
+			if depth == 0 {
+				break loop
+			}
+			depth--
+		case '+', '-', '*', '/':
+			if depth == 0 {
+				break loop
+			}
+		}
+	}
+
+	query := strings.TrimSpace(p.input[start:p.pos])
+	if depth != 0 || !strings.Contains(query, "{") {
+		return nil, fmt.Errorf("invalid query: %s", query)
+	}
+	return &queryLeaf{query: query}, nil
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQueryExpression(t *testing.T) {
+	tests := []struct {
+		query   string
+		queries []string
+		split   bool
+	}{
+		{
+			query:   "avg:nginx.net.request_per_s{kube_container_name:nginx}.rollup(60)",
+			queries: []string{"avg:nginx.net.request_per_s{kube_container_name:nginx}.rollup(60)"},
+			split:   false,
+		},
+		{
+			query:   "avg:foo{*} by {kube-deployment}",
+			queries: []string{"avg:foo{*} by {kube-deployment}"},
+			split:   false,
+		},
+		{
+			query:   "query-metric0",
+			queries: []string{"query-metric0"},
+			split:   false,
+		},
+		{
+			query:   "avg:foo{app:a-b} / avg:bar{app:c/d}.rollup(avg, 60)",
+			queries: []string{"avg:foo{app:a-b}", "avg:bar{app:c/d}.rollup(avg, 60)"},
+			split:   true,
+		},
+		{
+			query:   "(avg:foo{*} + abs(avg:bar{*})) * 2",
+			queries: []string{"avg:foo{*}", "abs(avg:bar{*})"},
+			split:   true,
+		},
+		{
+			query:   "1 + 2",
+			queries: []string{"1 + 2"},
+			split:   false,
+		},
+		{
+			query:   "avg:foo{*} / (avg:bar{*}",
+			queries: []string{"avg:foo{*} / (avg:bar{*}"},
+			split:   false,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("#%d %s", i, tt.query), func(t *testing.T) {
+			expr := parseQueryExpression(tt.query)
+			assert.Equal(t, tt.queries, expr.queries())
+			_, isLeaf := expr.(*queryLeaf)
+			assert.Equal(t, tt.split, !isLeaf)
+		})
+	}
+}
+
+func TestEvaluateQueryExpression(t *testing.T) {
+	results := map[string]autoscalers.Point{
+		"avg:a{*}": {Value: 10, Timestamp: 100, Valid: true},
+		"avg:b{*}": {Value: 4, Timestamp: 90, Valid: true},
+		"avg:c{*}": {Value: 0, Timestamp: 100, Valid: true},
+	}
+	tests := []struct {
+		query     string
+		value     float64
+		timestamp int64
+	}{
+		{query: "avg:a{*} + avg:b{*} * 2", value: 18, timestamp: 90},
+		{query: "(avg:a{*} + avg:b{*}) * 2", value: 28, timestamp: 90},
+		{query: "avg:a{*} - avg:b{*} - 1", value: 5, timestamp: 90},
+		{query: "-avg:a{*} / 4 + avg:c{*}", value: -2.5, timestamp: 100},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("#%d %s", i, tt.query), func(t *testing.T) {
+			result, qErr := parseQueryExpression(tt.query).evaluate(results)
+			require.Nil(t, qErr)
+			assert.Equal(t, tt.value, result.value)
+			assert.Equal(t, tt.timestamp, result.timestamp)
+		})
+	}
+}
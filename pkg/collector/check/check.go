@@ -6,6 +6,7 @@
 package check
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -24,4 +25,14 @@ type Check interface {
 	Version() string                                                    // return the version of the check if available
 	ConfigSource() string                                               // return the configuration source of the check
 	IsTelemetryEnabled() bool                                           // return if telemetry is enabled for this check
+	Timeout() time.Duration                                             // return the maximum duration of a run, 0 if it's not limited
+}
+
+// TimeoutError is the error of a check run that didn't complete within the timeout of the check
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("check run timed out after %s", e.Timeout)
 }
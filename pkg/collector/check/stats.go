@@ -23,6 +23,8 @@ var (
 		[]string{"check_name"}, "Events count")
 	tlmServices = telemetry.NewCounter("checks", "services_checks",
 		[]string{"check_name"}, "Service checks count")
+	tlmTimeouts = telemetry.NewCounter("checks", "timeouts",
+		[]string{"check_name"}, "Check runs that timed out")
 	tlmExecutionTime = telemetry.NewGauge("checks", "execution_time",
 		[]string{"check_name"}, "Check execution time")
 )
@@ -35,6 +37,7 @@ type Stats struct {
 	CheckID              ID
 	TotalRuns            uint64
 	TotalErrors          uint64
+	TotalTimeouts        uint64
 	TotalWarnings        uint64
 	MetricSamples        int64
 	Events               int64
@@ -92,6 +95,12 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats m
 			tlmRuns.Inc(cs.CheckName, "fail")
 		}
 		cs.LastError = err.Error()
+		if _, ok := err.(TimeoutError); ok {
+			cs.TotalTimeouts++
+			if cs.telemetry {
+				tlmTimeouts.Inc(cs.CheckName)
+			}
+		}
 	} else {
 		if cs.telemetry {
 			tlmRuns.Inc(cs.CheckName, "ok")
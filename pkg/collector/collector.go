@@ -9,11 +9,13 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -38,6 +40,9 @@ type Collector struct {
 func NewCollector(paths ...string) *Collector {
 	run := runner.NewRunner()
 	sched := scheduler.NewScheduler(run.GetChan())
+	if config.Datadog.GetInt("check_runners_high_priority") > 0 {
+		sched.SetHighPriorityPipe(run.GetHighPriorityChan())
+	}
 
 	// let the runner some visibility into the scheduler
 	run.SetScheduler(sched)
@@ -230,6 +235,30 @@ func (c *Collector) GetAllInstanceIDs(checkName string) []check.ID {
 	return instances
 }
 
+// CheckIntervals returns the collection intervals that override the ones of
+// the checks, by check name
+func (c *Collector) CheckIntervals() map[string]time.Duration {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	if c.state != started {
+		return map[string]time.Duration{}
+	}
+	return c.scheduler.Intervals()
+}
+
+// SetCheckIntervals replaces the collection intervals that override the ones
+// of the checks, by check name, and reschedules the running checks
+func (c *Collector) SetCheckIntervals(intervals map[string]time.Duration) error {
+	c.m.RLock()
+	defer c.m.RUnlock()
+
+	if c.state != started {
+		return fmt.Errorf("the collector is not running")
+	}
+	return c.scheduler.SetIntervals(intervals)
+}
+
 // ReloadAllCheckInstances completely restarts a check with a new configuration
 func (c *Collector) ReloadAllCheckInstances(name string, newInstances []check.Check) ([]check.ID, error) {
 	if !c.started() {
@@ -30,6 +30,7 @@ func (c *TestCheck) Run() error                                           { <-c.
 func (c *TestCheck) GetWarnings() []error                                 { return []error{} }
 func (c *TestCheck) GetMetricStats() (map[string]int64, error)            { return make(map[string]int64), nil }
 func (c *TestCheck) IsTelemetryEnabled() bool                             { return false }
+func (c *TestCheck) Timeout() time.Duration                               { return 0 }
 func (c *TestCheck) ID() check.ID {
 	if c.uniqueID != "" {
 		return c.uniqueID
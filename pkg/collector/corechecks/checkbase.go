@@ -42,6 +42,7 @@ type CheckBase struct {
 	checkID        check.ID
 	latestWarnings []error
 	checkInterval  time.Duration
+	checkTimeout   time.Duration
 	source         string
 	telemetry      bool
 }
@@ -118,6 +119,11 @@ func (c *CheckBase) CommonConfigure(instance integration.Data, source string) er
 		c.checkInterval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a run timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.checkTimeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.checkID)
@@ -209,6 +215,11 @@ func (c *CheckBase) IsTelemetryEnabled() bool {
 	return c.telemetry
 }
 
+// Timeout returns the maximum duration of a run of the check, 0 if it's not limited
+func (c *CheckBase) Timeout() time.Duration {
+	return c.checkTimeout
+}
+
 // GetWarnings grabs the latest integration warnings for the check.
 func (c *CheckBase) GetWarnings() []error {
 	if len(c.latestWarnings) == 0 {
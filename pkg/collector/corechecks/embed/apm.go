@@ -191,6 +191,11 @@ func (c *APMCheck) IsTelemetryEnabled() bool {
 	return c.telemetry
 }
 
+// Timeout returns 0, the long-running checks are not limited
+func (c *APMCheck) Timeout() time.Duration {
+	return 0
+}
+
 // Stop sends a termination signal to the APM process
 func (c *APMCheck) Stop() {
 	if atomic.LoadUint32(&c.running) == 0 {
@@ -89,6 +89,11 @@ func (c *JMXCheck) IsTelemetryEnabled() bool {
 	return c.telemetry
 }
 
+// Timeout returns 0, the long-running checks are not limited
+func (c *JMXCheck) Timeout() time.Duration {
+	return 0
+}
+
 func (c *JMXCheck) GetWarnings() []error {
 	return []error{}
 }
@@ -188,6 +188,11 @@ func (c *ProcessAgentCheck) IsTelemetryEnabled() bool {
 	return c.telemetry
 }
 
+// Timeout returns 0, the long-running checks are not limited
+func (c *ProcessAgentCheck) Timeout() time.Duration {
+	return 0
+}
+
 // Stop sends a termination signal to the process-agent process
 func (c *ProcessAgentCheck) Stop() {
 	if atomic.LoadUint32(&c.running) == 0 {
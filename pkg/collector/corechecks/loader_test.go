@@ -27,6 +27,7 @@ func (c *TestCheck) ID() check.ID                              { return check.ID
 func (c *TestCheck) GetWarnings() []error                      { return []error{} }
 func (c *TestCheck) GetMetricStats() (map[string]int64, error) { return make(map[string]int64), nil }
 func (c *TestCheck) IsTelemetryEnabled() bool                  { return false }
+func (c *TestCheck) Timeout() time.Duration                    { return 0 }
 func (c *TestCheck) Configure(data integration.Data, initData integration.Data, source string) error {
 	if string(data) == "err" {
 		return fmt.Errorf("testError")
@@ -43,6 +43,7 @@ type PythonCheck struct {
 	class        *C.rtloader_pyobject_t
 	ModuleName   string
 	interval     time.Duration
+	timeout      time.Duration
 	lastWarnings []error
 	source       string
 	telemetry    bool // whether or not the telemetry is enabled for this check
@@ -126,6 +127,11 @@ func (c *PythonCheck) IsTelemetryEnabled() bool {
 	return c.telemetry
 }
 
+// Timeout returns the maximum duration of a run of the check, 0 if it's not limited
+func (c *PythonCheck) Timeout() time.Duration {
+	return c.timeout
+}
+
 // ConfigSource returns the source of the configuration for this check
 func (c *PythonCheck) ConfigSource() string {
 	return c.source
@@ -200,6 +206,11 @@ func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Da
 		c.interval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// See if a run timeout was specified
+	if commonOptions.CheckTimeout > 0 {
+		c.timeout = time.Duration(commonOptions.CheckTimeout) * time.Second
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.id)
@@ -62,9 +62,11 @@ type Runner struct {
 	running          uint32                   // Flag to see if the Runner is, well, running
 	staticNumWorkers bool                     // Flag indicating if numWorkers is dynamically updated
 	pending          chan check.Check         // The channel where checks come from
+	highPriority     chan check.Check         // The channel where high priority checks come from
 	runningChecks    map[check.ID]check.Check // The list of checks running
+	stuckChecks      map[check.ID]check.Check // The list of running checks that timed out
 	scheduler        *scheduler.Scheduler     // Scheduler runner operates on
-	m                sync.Mutex               // To control races on runningChecks
+	m                sync.Mutex               // To control races on runningChecks and stuckChecks
 
 }
 
@@ -75,7 +77,9 @@ func NewRunner() *Runner {
 	r := &Runner{
 		// initialize the channel
 		pending:          make(chan check.Check),
+		highPriority:     make(chan check.Check),
 		runningChecks:    make(map[check.ID]check.Check),
+		stuckChecks:      make(map[check.ID]check.Check),
 		running:          1,
 		staticNumWorkers: numWorkers != 0,
 	}
@@ -89,7 +93,15 @@ func NewRunner() *Runner {
 		r.AddWorker()
 	}
 
-	log.Infof("Runner started with %d workers.", numWorkers)
+	// start the workers dedicated to high priority checks
+	numHighPriorityWorkers := config.Datadog.GetInt("check_runners_high_priority")
+	for i := 0; i < numHighPriorityWorkers; i++ {
+		runnerStats.Add("HighPriorityWorkers", 1)
+		TestWg.Add(1)
+		go r.work(r.highPriority, "HighPriorityWorkers")
+	}
+
+	log.Infof("Runner started with %d workers and %d high priority workers.", numWorkers, numHighPriorityWorkers)
 	return r
 }
 
@@ -97,7 +109,7 @@ func NewRunner() *Runner {
 func (r *Runner) AddWorker() {
 	runnerStats.Add("Workers", 1)
 	TestWg.Add(1)
-	go r.work()
+	go r.work(r.pending, "Workers")
 }
 
 // UpdateNumWorkers checks if the current number of workers is reasonable, and adds more if needed
@@ -149,6 +161,7 @@ func (r *Runner) Stop() {
 	log.Info("Runner is shutting down...")
 
 	close(r.pending)
+	close(r.highPriority)
 	atomic.StoreUint32(&r.running, 0)
 
 	// stop checks that are still running
@@ -200,6 +213,12 @@ func (r *Runner) GetChan() chan<- check.Check {
 	return r.pending
 }
 
+// GetHighPriorityChan returns a write-only version of the channel of the
+// workers dedicated to high priority checks
+func (r *Runner) GetHighPriorityChan() chan<- check.Check {
+	return r.highPriority
+}
+
 // SetScheduler sets the scheduler for the runner
 func (r *Runner) SetScheduler(s *scheduler.Scheduler) {
 	r.m.Lock()
@@ -236,17 +255,23 @@ func (r *Runner) StopCheck(id check.ID) error {
 	}
 }
 
-// work waits for checks and run them as long as they arrive on the channel
-func (r *Runner) work() {
+// work waits for checks and run them as long as they arrive on the channel,
+// workersStat is the runner stat counting the workers of this channel
+func (r *Runner) work(pending <-chan check.Check, workersStat string) {
 	log.Debug("Ready to process checks...")
 	defer TestWg.Done()
-	defer runnerStats.Add("Workers", -1)
+	defer runnerStats.Add(workersStat, -1)
 
-	for check := range r.pending {
+	for check := range pending {
 		// see if the check is already running
 		r.m.Lock()
 		if _, isRunning := r.runningChecks[check.ID()]; isRunning {
-			log.Debugf("Check %s is already running, skip execution...", check)
+			if _, isStuck := r.stuckChecks[check.ID()]; isStuck {
+				log.Warnf("Check %s is stuck in a run that timed out, skip execution...", check)
+				runnerStats.Add("SkippedStuckRuns", 1)
+			} else {
+				log.Debugf("Check %s is already running, skip execution...", check)
+			}
 			r.m.Unlock()
 			continue
 		} else {
@@ -264,13 +289,17 @@ func (r *Runner) work() {
 		}
 
 		// run the check
-		var err error
 		t0 := time.Now()
 
-		err = check.Run()
+		stuck, err := r.runCheck(check)
 		longRunning := check.Interval() == 0
 
-		warnings := check.GetWarnings()
+		// a stuck check is still running, don't wait on it to collect its
+		// warnings and metric stats
+		var warnings []error
+		if !stuck {
+			warnings = check.GetWarnings()
+		}
 
 		// use the default sender for the service checks
 		sender, e := aggregator.GetDefaultSender()
@@ -288,24 +317,31 @@ func (r *Runner) work() {
 			serviceCheckStatus = metrics.ServiceCheckWarning
 		}
 
+		serviceCheckMessage := ""
 		if err != nil {
 			log.Errorf("Error running check %s: %s", check, err)
 			runnerStats.Add("Errors", 1)
 			serviceCheckStatus = metrics.ServiceCheckCritical
 		}
+		if stuck {
+			serviceCheckMessage = err.Error()
+		}
 
 		if sender != nil && !longRunning {
-			sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, "")
+			sender.ServiceCheck("datadog.agent.check_status", serviceCheckStatus, hostname, serviceCheckTags, serviceCheckMessage)
 			sender.Commit()
 		}
 
-		// remove the check from the running list
-		r.m.Lock()
-		delete(r.runningChecks, check.ID())
-		r.m.Unlock()
+		// remove the check from the running list, unless it's stuck: it's
+		// removed once its run completes, see runCheck
+		if !stuck {
+			r.m.Lock()
+			delete(r.runningChecks, check.ID())
+			r.m.Unlock()
+			runnerStats.Add("RunningChecks", -1)
+		}
 
 		// publish statistics about this run
-		runnerStats.Add("RunningChecks", -1)
 		runnerStats.Add("Runs", 1)
 
 		r.m.Lock()
@@ -313,7 +349,10 @@ func (r *Runner) work() {
 			// If the scheduler isn't assigned (it should), just add stats
 			// otherwise only do so if the check is in the scheduler
 			if r.scheduler == nil || r.scheduler.IsCheckScheduled(check.ID()) {
-				mStats, _ := check.GetMetricStats()
+				var mStats map[string]int64
+				if !stuck {
+					mStats, _ = check.GetMetricStats()
+				}
 				addWorkStats(check, time.Since(t0), err, warnings, mStats)
 			}
 		}
@@ -338,6 +377,53 @@ func (r *Runner) work() {
 	log.Debug("Finished processing checks.")
 }
 
+// runCheck runs a check and returns the error of the run. If the run doesn't
+// complete within the timeout of the check, a check.TimeoutError is returned
+// and the check is reported as stuck: the worker moves on to the next checks,
+// as if it was replaced, while the run completes in the background. The check
+// stays in the list of running checks until then, so that it's not run again.
+func (r *Runner) runCheck(c check.Check) (bool, error) {
+	timeout := c.Timeout()
+	if timeout <= 0 || c.Interval() == 0 {
+		return false, c.Run()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Run()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+	}
+
+	log.Warnf("Check %s didn't complete within %s, running the next checks without waiting for it", c, timeout)
+	r.m.Lock()
+	r.stuckChecks[c.ID()] = c
+	r.m.Unlock()
+	runnerStats.Add("Timeouts", 1)
+	runnerStats.Add("StuckChecks", 1)
+
+	go func(t0 time.Time) {
+		err := <-done
+		log.Infof("Stuck check %s completed after %s: %v", c, time.Since(t0)+timeout, err)
+
+		r.m.Lock()
+		delete(r.stuckChecks, c.ID())
+		delete(r.runningChecks, c.ID())
+		r.m.Unlock()
+		runnerStats.Add("StuckChecks", -1)
+		runnerStats.Add("RunningChecks", -1)
+	}(time.Now())
+
+	return true, check.TimeoutError{Timeout: timeout}
+}
+
 func shouldLog(id check.ID) (doLog bool, lastLog bool) {
 	checkStats.M.RLock()
 	defer checkStats.M.RUnlock()
@@ -45,6 +45,7 @@ func (c *TestCheck) Stop()
 func (c *TestCheck) Configure(integration.Data, integration.Data, string) error { return nil }
 func (c *TestCheck) Interval() time.Duration                                    { return 1 }
 func (c *TestCheck) IsTelemetryEnabled() bool                                   { return false }
+func (c *TestCheck) Timeout() time.Duration                                     { return 0 }
 func (c *TestCheck) Run() error {
 	c.Lock()
 	defer c.Unlock()
@@ -180,3 +181,76 @@ func TestStopCheck(t *testing.T) {
 	err = r.StopCheck(c2.ID())
 	assert.Equal(t, "timeout during stop operation on check id TestCheck:2", err.Error())
 }
+
+type HangingCheck struct {
+	TestCheck
+	release chan struct{}
+	runs    chan struct{}
+}
+
+func newHangingCheck(id string) *HangingCheck {
+	return &HangingCheck{
+		TestCheck: *newTestCheck(false, id),
+		release:   make(chan struct{}),
+		runs:      make(chan struct{}, 10),
+	}
+}
+
+func (c *HangingCheck) Run() error {
+	c.runs <- struct{}{}
+	<-c.release
+	return nil
+}
+func (c *HangingCheck) String() string         { return "HangingCheck" }
+func (c *HangingCheck) Timeout() time.Duration { return 50 * time.Millisecond }
+
+func TestWorkTimeout(t *testing.T) {
+	r := NewRunner()
+	defer r.Stop()
+
+	c1 := newHangingCheck("1")
+	r.pending <- c1
+	<-c1.runs
+
+	// the worker gives up on the stuck check and keeps running other checks
+	c2 := newTestCheck(false, "2")
+	r.pending <- c2
+	select {
+	case <-c2.done:
+	case <-time.After(1 * time.Second):
+		require.Fail(t, "Check hasn't run 1 second after being scheduled")
+	}
+	assert.True(t, c2.HasRun())
+
+	require.Eventually(t, func() bool {
+		r.m.Lock()
+		defer r.m.Unlock()
+		_, stuck := r.stuckChecks[c1.ID()]
+		return stuck
+	}, time.Second, 10*time.Millisecond)
+
+	var stats *check.Stats
+	require.Eventually(t, func() bool {
+		checkStats.M.RLock()
+		defer checkStats.M.RUnlock()
+		stats = checkStats.Stats[c1.String()][c1.ID()]
+		return stats != nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+	assert.Equal(t, "check run timed out after 50ms", stats.LastError)
+
+	// runs are skipped while the check is stuck
+	r.pending <- c1
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, c1.runs, 0)
+
+	// the check is forgotten once its run completes
+	close(c1.release)
+	require.Eventually(t, func() bool {
+		r.m.Lock()
+		defer r.m.Unlock()
+		_, running := r.runningChecks[c1.ID()]
+		_, stuck := r.stuckChecks[c1.ID()]
+		return !running && !stuck
+	}, time.Second, 10*time.Millisecond)
+}
@@ -66,6 +66,8 @@ type jobQueue struct {
 	sparseStep          uint
 	currentBucketIdx    uint
 	schedulingBucketIdx uint
+	spread              bool // schedule checks in the least loaded bucket
+	highPriority        bool // checks are sent to the high priority pipe of the scheduler
 	running             bool
 	health              *health.Handle
 	mu                  sync.RWMutex // to protect critical sections in struct's fields
@@ -119,10 +121,31 @@ func (jq *jobQueue) addJob(c check.Check) {
 	defer jq.mu.Unlock()
 
 	// Checks scheduled to buckets scheduled with sparse round-robin
-	jq.buckets[jq.schedulingBucketIdx].addJob(c)
+	idx := jq.schedulingBucketIdx
+	if jq.spread {
+		idx = jq.leastLoadedBucket(idx)
+	}
+	jq.buckets[idx].addJob(c)
 	jq.schedulingBucketIdx = (jq.schedulingBucketIdx + jq.sparseStep) % uint(len(jq.buckets))
 }
 
+// leastLoadedBucket returns the index of the bucket with the fewest jobs,
+// looking from the `from` index onwards so that ties are broken the same
+// way as the round-robin
+func (jq *jobQueue) leastLoadedBucket(from uint) uint {
+	nb := uint(len(jq.buckets))
+	best := from
+	bestSize := jq.buckets[from].size()
+	for i := uint(1); i < nb && bestSize > 0; i++ {
+		idx := (from + i) % nb
+		if size := jq.buckets[idx].size(); size < bestSize {
+			best = idx
+			bestSize = size
+		}
+	}
+	return best
+}
+
 func (jq *jobQueue) removeJob(id check.ID) error {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
@@ -148,9 +171,10 @@ func (jq *jobQueue) stats() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"Interval": jq.interval / time.Second,
-		"Buckets":  nBuckets,
-		"Size":     nJobs,
+		"Interval":     jq.interval / time.Second,
+		"Buckets":      nBuckets,
+		"Size":         nJobs,
+		"HighPriority": jq.highPriority,
 	}
 }
 
@@ -195,6 +219,11 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 		log.Tracef("Jobs in bucket: %v", jobs)
 
+		pipe := s.checksPipe
+		if jq.highPriority && s.highPriorityPipe != nil {
+			pipe = s.highPriorityPipe
+		}
+
 		for _, check := range jobs {
 			if !s.IsCheckScheduled(check.ID()) {
 				continue
@@ -202,7 +231,8 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 
 			select {
 			// blocking, we'll be here as long as it takes
-			case s.checksPipe <- check:
+			case pipe <- check:
+				s.recordQueueLatency(check, time.Since(t))
 			case <-jq.stop:
 				jq.health.Deregister() //nolint:errcheck
 				return false
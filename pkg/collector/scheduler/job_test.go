@@ -6,6 +6,7 @@
 package scheduler
 
 import (
+	"fmt"
 	"runtime"
 	"testing"
 	"time"
@@ -65,3 +66,30 @@ func TestBucket_RemoveJob(t *testing.T) {
 	// use the bucket, just to keep it alive during the earlier GC run
 	bucket.addJob(&TestJobCheck{id: "here so the GC doesn't GC the entire bucket"})
 }
+
+func TestJobQueue_Spread(t *testing.T) {
+	for _, spread := range []bool{false, true} {
+		jq := newJobQueue(5 * time.Second)
+		jq.spread = spread
+		for i := 0; i < 5; i++ {
+			jq.addJob(&TestJobCheck{id: fmt.Sprintf("%d", i)})
+		}
+		for _, bucket := range jq.buckets {
+			require.Equal(t, 1, bucket.size())
+		}
+
+		// free a bucket that's not next in the round-robin
+		require.Nil(t, jq.removeJob("1"))
+		jq.addJob(&TestJobCheck{id: "5"})
+
+		if spread {
+			// the check fills the empty bucket
+			for _, bucket := range jq.buckets {
+				require.Equal(t, 1, bucket.size())
+			}
+		} else {
+			require.Equal(t, 2, jq.buckets[0].size())
+		}
+		jq.health.Deregister() //nolint:errcheck
+	}
+}
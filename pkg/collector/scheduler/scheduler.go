@@ -8,10 +8,12 @@ package scheduler
 import (
 	"expvar"
 	"fmt"
+	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -28,6 +30,8 @@ var (
 		[]string{"check_name"}, "How many checks are currently tracked by the scheduler")
 	tlmQueuesCount = telemetry.NewCounter("scheduler", "queues_count",
 		[]string{"check_name"}, "How many queues were opened")
+	tlmQueueLatency = telemetry.NewGauge("scheduler", "queue_latency",
+		[]string{"check_name"}, "Time between the scheduled time of a check run and its handover to the runner, in milliseconds")
 )
 
 func init() {
@@ -36,6 +40,14 @@ func init() {
 	schedulerExpvars.Set("ChecksEntered", &schedulerChecksEntered)
 }
 
+// QueueLatency holds the time a check waited between its scheduled time
+// and its handover to the runner, in milliseconds
+type QueueLatency struct {
+	Last    int64
+	Max     int64
+	Average int64 // exponential moving average
+}
+
 // Scheduler keeps things rolling.
 // More docs to come...
 type Scheduler struct {
@@ -46,15 +58,38 @@ type Scheduler struct {
 	started          chan bool                   // Used to internally communicate the queues are up
 	jobQueues        map[time.Duration]*jobQueue // We have one scheduling queue for every interval
 	checkToQueue     map[check.ID]*jobQueue      // Keep track of what is the queue for any Check
+	checks           map[check.ID]check.Check    // The scheduled checks, to move them when their interval changes
+	intervals        map[string]time.Duration    // Collection intervals overriding the ones of the checks, by check name
 	tlmTrackedChecks map[check.ID]string         // Keep track of the checks that are tracked with telemetry
 	mu               sync.Mutex                  // To protect critical sections in struct's fields
 
+	highPriorityPipe   chan<- check.Check          // The pipe the Runner pops high priority checks from, nil if there's none
+	highPriorityChecks map[string]struct{}         // Names of the checks with a high priority
+	highPriorityQueues map[time.Duration]*jobQueue // Scheduling queues of the high priority checks
+	delayedChecks      map[check.ID]*delayedJob    // Checks waiting for their start-time jitter before joining their queue
+	jitter             time.Duration               // Maximum start-time jitter
+	spread             bool                        // Schedule checks in the least loaded bucket of their queue
+
+	latencies   map[check.ID]*QueueLatency // Queue latency of the checks
+	latenciesMu sync.RWMutex               // To protect latencies, updated by the queues
+
 	cancelOneTime chan bool      // Used to internally communicate a cancel signal to one-time schedule goroutines
 	wgOneTime     sync.WaitGroup // WaitGroup to track the exit of one-time schedule goroutines
 }
 
+// delayedJob is a check waiting for its start-time jitter, its timer is only
+// accessed with the scheduler lock held
+type delayedJob struct {
+	timer *time.Timer
+}
+
 // NewScheduler create a Scheduler and returns a pointer to it.
 func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
+	highPriorityChecks := make(map[string]struct{})
+	for _, name := range config.Datadog.GetStringSlice("check_high_priority_checks") {
+		highPriorityChecks[name] = struct{}{}
+	}
+
 	return &Scheduler{
 		checksPipe:       checksPipe,
 		done:             make(chan bool),
@@ -62,14 +97,34 @@ func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
 		started:          make(chan bool),
 		jobQueues:        make(map[time.Duration]*jobQueue),
 		checkToQueue:     make(map[check.ID]*jobQueue),
+		checks:           make(map[check.ID]check.Check),
+		intervals:        make(map[string]time.Duration),
 		tlmTrackedChecks: make(map[check.ID]string),
 		running:          0,
 		cancelOneTime:    make(chan bool),
 		wgOneTime:        sync.WaitGroup{},
+
+		highPriorityChecks: highPriorityChecks,
+		highPriorityQueues: make(map[time.Duration]*jobQueue),
+		delayedChecks:      make(map[check.ID]*delayedJob),
+		jitter:             time.Duration(config.Datadog.GetInt("check_scheduling_jitter")) * time.Second,
+		spread:             config.Datadog.GetBool("check_scheduling_spread"),
+		latencies:          make(map[check.ID]*QueueLatency),
 	}
 }
 
-// Enter schedules a `Check`s for execution accordingly to the `Check.Interval()` value.
+// SetHighPriorityPipe sets the pipe the checks listed in `check_high_priority_checks`
+// are sent to, so that they're run apart from the other checks.
+// Must be called before any check enters the scheduler.
+func (s *Scheduler) SetHighPriorityPipe(pipe chan<- check.Check) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.highPriorityPipe = pipe
+}
+
+// Enter schedules a `Check`s for execution accordingly to the `Check.Interval()` value,
+// or to the interval set for its name by `SetIntervals`.
 // If the interval is 0, the check is supposed to run only once.
 func (s *Scheduler) Enter(check check.Check) error {
 	// enqueue immediately if this is a one-time schedule
@@ -78,27 +133,61 @@ func (s *Scheduler) Enter(check check.Check) error {
 		return nil
 	}
 
-	if check.Interval() < minAllowedInterval {
+	// sync when accessing `jobQueues` and `check2queue`
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enter(check)
+}
+
+// enter schedules a check, s.mu must be held
+func (s *Scheduler) enter(check check.Check) error {
+	interval := check.Interval()
+	if override, found := s.intervals[check.String()]; found {
+		interval = override
+	}
+
+	if interval < minAllowedInterval {
 		return fmt.Errorf("Schedule interval must be greater than %v or 0", minAllowedInterval)
 	}
 
-	log.Infof("Scheduling check %v with an interval of %v", check, check.Interval())
+	log.Infof("Scheduling check %v with an interval of %v", check, interval)
 
-	// sync when accessing `jobQueues` and `check2queue`
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	// high priority checks get their own queues, so that they're never
+	// waiting for the other checks of a bucket to be picked up by the runner
+	_, highPriority := s.highPriorityChecks[check.String()]
+	queues := s.jobQueues
+	if highPriority {
+		queues = s.highPriorityQueues
+	}
 
-	if _, ok := s.jobQueues[check.Interval()]; !ok {
-		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
-		s.startQueue(s.jobQueues[check.Interval()])
+	if _, ok := queues[interval]; !ok {
+		q := newJobQueue(interval)
+		q.highPriority = highPriority
+		q.spread = s.spread
+		queues[interval] = q
+		s.startQueue(q)
 		if check.IsTelemetryEnabled() {
 			tlmQueuesCount.Inc(check.String())
 		}
 		schedulerQueuesCount.Add(1)
 	}
-	s.jobQueues[check.Interval()].addJob(check)
+	q := queues[interval]
+	if delay := s.startDelay(interval); delay > 0 {
+		log.Debugf("Delaying the first run of check %v by %v", check, delay)
+		// the job is allocated before the timer for its callback to identify it
+		// without reading the timer, which is set while s.mu is held
+		job := &delayedJob{}
+		job.timer = time.AfterFunc(delay, func() {
+			s.addDelayedJob(q, check, job)
+		})
+		s.delayedChecks[check.ID()] = job
+	} else {
+		q.addJob(check)
+	}
 	// map each check to the Job Queue it was assigned to
-	s.checkToQueue[check.ID()] = s.jobQueues[check.Interval()]
+	s.checkToQueue[check.ID()] = q
+	s.checks[check.ID()] = check
 
 	schedulerChecksEntered.Add(1)
 	if check.IsTelemetryEnabled() {
@@ -107,6 +196,7 @@ func (s *Scheduler) Enter(check check.Check) error {
 		tlmChecksEntered.Inc(checkName)
 	}
 	schedulerExpvars.Set("Queues", expvar.Func(expQueues(s)))
+	schedulerExpvars.Set("QueueLatencies", expvar.Func(expQueueLatencies(s)))
 	return nil
 }
 
@@ -118,16 +208,28 @@ func (s *Scheduler) Cancel(id check.ID) error {
 
 	log.Infof("Unscheduling check %s", string(id))
 
+	return s.cancel(id)
+}
+
+// cancel removes a check from the schedule, s.mu must be held
+func (s *Scheduler) cancel(id check.ID) error {
 	if _, ok := s.checkToQueue[id]; !ok {
 		return nil
 	}
 
-	// remove it from the queue
-	err := s.checkToQueue[id].removeJob(id)
-	if err != nil {
+	// remove it from the queue, or cancel its delayed entry in the queue
+	if job, delayed := s.delayedChecks[id]; delayed {
+		job.timer.Stop()
+		delete(s.delayedChecks, id)
+	} else if err := s.checkToQueue[id].removeJob(id); err != nil {
 		return fmt.Errorf("unable to remove the Job from the queue: %s", err)
 	}
 	delete(s.checkToQueue, id)
+	delete(s.checks, id)
+
+	s.latenciesMu.Lock()
+	delete(s.latencies, id)
+	s.latenciesMu.Unlock()
 
 	schedulerChecksEntered.Add(-1)
 	if checkName, ok := s.tlmTrackedChecks[id]; ok {
@@ -138,6 +240,76 @@ func (s *Scheduler) Cancel(id check.ID) error {
 	return nil
 }
 
+// Intervals returns the collection intervals that override the ones of the
+// checks, by check name
+func (s *Scheduler) Intervals() map[string]time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intervals := make(map[string]time.Duration, len(s.intervals))
+	for name, interval := range s.intervals {
+		intervals[name] = interval
+	}
+	return intervals
+}
+
+// SetIntervals replaces the collection intervals that override the ones of
+// the checks, by check name. The scheduled checks whose interval changes are
+// moved to the queue of their new interval. One-time checks are never
+// affected.
+func (s *Scheduler) SetIntervals(intervals map[string]time.Duration) error {
+	for name, interval := range intervals {
+		if interval < minAllowedInterval {
+			return fmt.Errorf("the interval of check %s must be greater than %v", name, minAllowedInterval)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.intervals
+	s.intervals = make(map[string]time.Duration, len(intervals))
+	for name, interval := range intervals {
+		s.intervals[name] = interval
+	}
+
+	var moved []check.Check
+	for id, c := range s.checks {
+		interval := c.Interval()
+		if override, found := s.intervals[c.String()]; found {
+			interval = override
+		}
+		if s.checkToQueue[id].interval == interval {
+			continue
+		}
+		moved = append(moved, c)
+		if err := s.cancel(id); err != nil {
+			s.restoreIntervals(previous, moved)
+			return err
+		}
+		if err := s.enter(c); err != nil {
+			s.restoreIntervals(previous, moved)
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreIntervals restores the previous interval overrides after SetIntervals
+// failed, and moves the checks it moved back to their queue, s.mu must be held
+func (s *Scheduler) restoreIntervals(previous map[string]time.Duration, moved []check.Check) {
+	s.intervals = previous
+	for _, c := range moved {
+		if err := s.cancel(c.ID()); err != nil {
+			log.Errorf("Unable to unschedule check %v while restoring the collection intervals: %s", c, err)
+			continue
+		}
+		if err := s.enter(c); err != nil {
+			log.Errorf("Unable to schedule check %v again while restoring the collection intervals: %s", c, err)
+		}
+	}
+}
+
 // Run is the Scheduler main loop.
 // This doesn't block but waits for the queues to be ready before returning.
 func (s *Scheduler) Run() {
@@ -213,15 +385,17 @@ func (s *Scheduler) stopQueues() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	log.Debugf("Stopping %v queue(s)", len(s.jobQueues))
-	for _, q := range s.jobQueues {
-		// check that the queue is actually running or this blocks
-		// while posting to the channel
-		if q.running {
-			q.stop <- true
-			<-q.stopped
-			log.Debugf("Stopped queue %v", q.interval)
-			q.running = false
+	log.Debugf("Stopping %v queue(s)", len(s.jobQueues)+len(s.highPriorityQueues))
+	for _, queues := range []map[time.Duration]*jobQueue{s.jobQueues, s.highPriorityQueues} {
+		for _, q := range queues {
+			// check that the queue is actually running or this blocks
+			// while posting to the channel
+			if q.running {
+				q.stop <- true
+				<-q.stopped
+				log.Debugf("Stopped queue %v", q.interval)
+				q.running = false
+			}
 		}
 	}
 }
@@ -232,8 +406,10 @@ func (s *Scheduler) startQueues() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, q := range s.jobQueues {
-		s.startQueue(q)
+	for _, queues := range []map[time.Duration]*jobQueue{s.jobQueues, s.highPriorityQueues} {
+		for _, q := range queues {
+			s.startQueue(q)
+		}
 	}
 }
 
@@ -245,6 +421,67 @@ func (s *Scheduler) startQueue(q *jobQueue) {
 	}
 }
 
+// startDelay returns a random start-time jitter for a check scheduled with
+// the given interval, capped at the interval
+func (s *Scheduler) startDelay(interval time.Duration) time.Duration {
+	maxDelay := s.jitter
+	if maxDelay > interval {
+		maxDelay = interval
+	}
+	if maxDelay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(maxDelay)))
+}
+
+// addDelayedJob adds a check to its queue once its start-time jitter has
+// elapsed, unless it was cancelled in the meantime
+func (s *Scheduler) addDelayedJob(q *jobQueue, c check.Check, job *delayedJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j, found := s.delayedChecks[c.ID()]; !found || j != job {
+		return
+	}
+	delete(s.delayedChecks, c.ID())
+	q.addJob(c)
+}
+
+// recordQueueLatency records the time a check waited between its scheduled
+// time and its handover to the runner
+func (s *Scheduler) recordQueueLatency(c check.Check, latency time.Duration) {
+	ms := latency.Nanoseconds() / 1e6
+
+	s.latenciesMu.Lock()
+	l, found := s.latencies[c.ID()]
+	if !found {
+		l = &QueueLatency{Average: ms}
+		s.latencies[c.ID()] = l
+	}
+	l.Last = ms
+	if ms > l.Max {
+		l.Max = ms
+	}
+	l.Average = (l.Average*7 + ms) / 8
+	s.latenciesMu.Unlock()
+
+	if c.IsTelemetryEnabled() {
+		tlmQueueLatency.Set(float64(ms), c.String())
+	}
+}
+
+// QueueLatencies returns the queue latency of the scheduled checks
+func (s *Scheduler) QueueLatencies() map[check.ID]QueueLatency {
+	s.latenciesMu.RLock()
+	defer s.latenciesMu.RUnlock()
+
+	latencies := make(map[check.ID]QueueLatency, len(s.latencies))
+	for id, l := range s.latencies {
+		latencies[id] = *l
+	}
+	return latencies
+}
+
 // enqueueOnce enqueues a check once to the checksPipe.
 // Do not block, in case the runner has not started yet.
 // The queuing can be cancelled by closing the `cancelOneTime` channel.
@@ -271,6 +508,16 @@ func expQueues(s *Scheduler) func() interface{} {
 		for _, queue := range s.jobQueues {
 			queues = append(queues, queue.stats())
 		}
+		for _, queue := range s.highPriorityQueues {
+			queues = append(queues, queue.stats())
+		}
 		return queues
 	}
 }
+
+// expQueueLatencies return a function to get the queue latency of the checks
+func expQueueLatencies(s *Scheduler) func() interface{} {
+	return func() interface{} {
+		return s.QueueLatencies()
+	}
+}
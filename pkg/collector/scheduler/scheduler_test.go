@@ -11,7 +11,9 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // FIXTURE
@@ -28,6 +30,7 @@ func (c *TestCheck) ID() check.ID
 func (c *TestCheck) GetWarnings() []error                                       { return []error{} }
 func (c *TestCheck) GetMetricStats() (map[string]int64, error)                  { return make(map[string]int64), nil }
 func (c *TestCheck) IsTelemetryEnabled() bool                                   { return false }
+func (c *TestCheck) Timeout() time.Duration                                     { return 0 }
 
 var initialMinAllowedInterval = minAllowedInterval
 
@@ -204,3 +207,135 @@ func TestStopOneTimeSchedule(t *testing.T) {
 	// sleep to make the runtime schedule the hanging goroutines, if there are any
 	time.Sleep(time.Millisecond)
 }
+
+type HighPriorityTestCheck struct{ TestCheck }
+
+func (c *HighPriorityTestCheck) String() string { return "cpu" }
+func (c *HighPriorityTestCheck) ID() check.ID   { return check.ID(c.String()) }
+
+func TestEnterHighPriority(t *testing.T) {
+	ch := make(chan check.Check)
+	hp := make(chan check.Check)
+	stop := make(chan bool)
+	mockConfig := config.Mock()
+	mockConfig.Set("check_high_priority_checks", []string{"cpu"})
+	defer mockConfig.Set("check_high_priority_checks", []string{})
+	s := NewScheduler(ch)
+	s.SetHighPriorityPipe(hp)
+
+	// consume the enqueued checks
+	go consume(ch, stop)
+	defer func() {
+		stop <- true
+	}()
+
+	c := &HighPriorityTestCheck{TestCheck{intl: 1 * time.Second}}
+	s.Enter(c)
+	s.Enter(&TestCheck{intl: 1 * time.Second})
+	assert.Len(t, s.jobQueues, 1)
+	assert.Len(t, s.highPriorityQueues, 1)
+	assert.True(t, s.highPriorityQueues[c.intl].highPriority)
+	assert.Len(t, s.highPriorityQueues[c.intl].buckets[0].jobs, 1)
+
+	s.Run()
+	defer s.Stop()
+
+	// the high priority check is sent to its own pipe
+	select {
+	case got := <-hp:
+		assert.Equal(t, c.ID(), got.ID())
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "High priority check wasn't enqueued")
+	}
+
+	// its queue latency is recorded once it's handed over
+	assert.Eventually(t, func() bool {
+		_, found := s.QueueLatencies()[c.ID()]
+		return found
+	}, time.Second, 10*time.Millisecond)
+
+	s.Cancel(c.ID())
+	assert.NotContains(t, s.QueueLatencies(), c.ID())
+}
+
+func TestEnterJitter(t *testing.T) {
+	s := getScheduler()
+	c := &TestCheck{intl: 10 * time.Second}
+
+	// the check joins its queue once its start-time jitter has elapsed
+	s.jitter = time.Hour
+	s.Enter(c)
+	assert.True(t, s.IsCheckScheduled(c.ID()))
+	assert.Contains(t, s.delayedChecks, c.ID())
+	assert.Equal(t, 0, s.jobQueues[c.intl].stats()["Size"])
+
+	// cancelling it before then is fine
+	assert.Nil(t, s.Cancel(c.ID()))
+	assert.False(t, s.IsCheckScheduled(c.ID()))
+	assert.NotContains(t, s.delayedChecks, c.ID())
+
+	s.jitter = 10 * time.Millisecond
+	s.Enter(c)
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.delayedChecks) == 0 && s.jobQueues[c.intl].stats()["Size"] == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSetIntervals(t *testing.T) {
+	s := getScheduler()
+	c := &TestCheck{intl: 10 * time.Second}
+	s.Enter(c)
+	require.Equal(t, 1, s.jobQueues[10*time.Second].stats()["Size"])
+
+	// intervals below the minimum are rejected
+	assert.NotNil(t, s.SetIntervals(map[string]time.Duration{"TestCheck": time.Millisecond}))
+	assert.Empty(t, s.Intervals())
+
+	// the scheduled check moves to the queue of its new interval
+	require.Nil(t, s.SetIntervals(map[string]time.Duration{"TestCheck": 30 * time.Second, "other": time.Minute}))
+	assert.Equal(t, 0, s.jobQueues[10*time.Second].stats()["Size"])
+	assert.Equal(t, 1, s.jobQueues[30*time.Second].stats()["Size"])
+	assert.True(t, s.IsCheckScheduled(c.ID()))
+	assert.Equal(t, map[string]time.Duration{"TestCheck": 30 * time.Second, "other": time.Minute}, s.Intervals())
+
+	// the override applies to the checks entering later
+	require.Nil(t, s.Cancel(c.ID()))
+	s.Enter(c)
+	assert.Equal(t, 0, s.jobQueues[10*time.Second].stats()["Size"])
+	assert.Equal(t, 1, s.jobQueues[30*time.Second].stats()["Size"])
+
+	// and goes away when it's removed
+	require.Nil(t, s.SetIntervals(nil))
+	assert.Equal(t, 1, s.jobQueues[10*time.Second].stats()["Size"])
+	assert.Equal(t, 0, s.jobQueues[30*time.Second].stats()["Size"])
+}
+
+type namedTestCheck struct {
+	TestCheck
+	name string
+}
+
+func (c *namedTestCheck) String() string { return c.name }
+func (c *namedTestCheck) ID() check.ID   { return check.ID(c.name) }
+
+func TestSetIntervalsRollback(t *testing.T) {
+	s := getScheduler()
+	a := &namedTestCheck{TestCheck: TestCheck{intl: 10 * time.Second}, name: "a"}
+	b := &namedTestCheck{TestCheck: TestCheck{intl: 10 * time.Second}, name: "b"}
+	require.Nil(t, s.Enter(a))
+	require.Nil(t, s.Enter(b))
+	require.Nil(t, s.SetIntervals(map[string]time.Duration{"other": time.Minute}))
+
+	// b can't be removed from its queue, whether a was moved before or not,
+	// the previous intervals and queues are restored
+	require.Nil(t, s.jobQueues[10*time.Second].removeJob(b.ID()))
+	assert.NotNil(t, s.SetIntervals(map[string]time.Duration{"a": 30 * time.Second, "b": 30 * time.Second}))
+	assert.Equal(t, map[string]time.Duration{"other": time.Minute}, s.Intervals())
+	assert.Equal(t, 10*time.Second, s.checkToQueue[a.ID()].interval)
+	assert.Equal(t, 1, s.jobQueues[10*time.Second].stats()["Size"])
+	if q, found := s.jobQueues[30*time.Second]; found {
+		assert.Equal(t, 0, q.stats()["Size"])
+	}
+}
@@ -75,6 +75,10 @@ func (c *baseCheck) IsTelemetryEnabled() bool {
 	return false
 }
 
+func (c *baseCheck) Timeout() time.Duration {
+	return 0
+}
+
 func (c *baseCheck) setStaticKV(field compliance.ReportedField, kv event.Data) bool {
 	key := field.As
 
@@ -76,19 +76,22 @@ type MetadataProviders struct {
 
 // ConfigurationProviders helps unmarshalling `config_providers` config param
 type ConfigurationProviders struct {
-	Name             string `mapstructure:"name"`
-	Polling          bool   `mapstructure:"polling"`
-	PollInterval     string `mapstructure:"poll_interval"`
-	TemplateURL      string `mapstructure:"template_url"`
-	TemplateDir      string `mapstructure:"template_dir"`
-	Username         string `mapstructure:"username"`
-	Password         string `mapstructure:"password"`
-	CAFile           string `mapstructure:"ca_file"`
-	CAPath           string `mapstructure:"ca_path"`
-	CertFile         string `mapstructure:"cert_file"`
-	KeyFile          string `mapstructure:"key_file"`
-	Token            string `mapstructure:"token"`
-	GraceTimeSeconds int    `mapstructure:"grace_time_seconds"`
+	Name             string            `mapstructure:"name"`
+	Polling          bool              `mapstructure:"polling"`
+	PollInterval     string            `mapstructure:"poll_interval"`
+	TemplateURL      string            `mapstructure:"template_url"`
+	TemplateDir      string            `mapstructure:"template_dir"`
+	Username         string            `mapstructure:"username"`
+	Password         string            `mapstructure:"password"`
+	CAFile           string            `mapstructure:"ca_file"`
+	CAPath           string            `mapstructure:"ca_path"`
+	CertFile         string            `mapstructure:"cert_file"`
+	KeyFile          string            `mapstructure:"key_file"`
+	Token            string            `mapstructure:"token"`
+	GraceTimeSeconds int               `mapstructure:"grace_time_seconds"`
+	Headers          map[string]string `mapstructure:"headers"`
+	Namespace        string            `mapstructure:"namespace"`
+	LabelSelector    string            `mapstructure:"label_selector"`
 }
 
 // Listeners helps unmarshalling `listeners` config param
@@ -169,6 +172,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_runners_high_priority", int64(0))
+	config.BindEnvAndSetDefault("check_high_priority_checks", []string{})
+	config.BindEnvAndSetDefault("check_scheduling_jitter", 0)
+	config.BindEnvAndSetDefault("check_scheduling_spread", false)
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnvAndSetDefault("bind_host", "localhost")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -213,6 +220,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("secret_backend_arguments", []string{})
 	config.BindEnvAndSetDefault("secret_backend_output_max_size", secrets.SecretBackendOutputMaxSize)
 	config.BindEnvAndSetDefault("secret_backend_timeout", 5)
+	config.BindEnvAndSetDefault("secret_backend_builtin_enabled", false)
+	config.BindEnvAndSetDefault("secret_backend_file_root", "")
+	config.BindEnvAndSetDefault("secret_backend_k8s_secrets_root", "/etc/secret-volumes")
+	config.BindEnvAndSetDefault("secret_refresh_interval", 0)
 
 	// Use to output logs in JSON format
 	config.BindEnvAndSetDefault("log_format_json", false)
@@ -228,6 +239,7 @@ func InitConfig(config Config) {
 
 	// Yaml keys which values are stripped from flare
 	config.BindEnvAndSetDefault("flare_stripped_keys", []string{})
+	config.SetKnown("flare_scrubbing_rules")
 
 	// Agent GUI access port
 	config.BindEnvAndSetDefault("GUI_port", defaultGuiPort)
@@ -345,6 +357,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("statsd_metric_namespace_blacklist", StandardStatsdPrefixes)
 	// Autoconfig
 	config.BindEnvAndSetDefault("autoconf_template_dir", "/datadog/check_configs")
+	config.BindEnvAndSetDefault("autoconf_config_files_watch", false)
 	config.BindEnvAndSetDefault("exclude_pause_container", true)
 	config.BindEnvAndSetDefault("ac_include", []string{})
 	config.BindEnvAndSetDefault("ac_exclude", []string{})
@@ -367,6 +380,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_node_labels_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("container_cgroup_prefix", "")
 
+	// Host processes and provisioned tags
+	config.BindEnvAndSetDefault("process_env_as_tags", map[string]string{})
+	config.BindEnvAndSetDefault("tag_files_dir", "")
+
 	// CRI
 	config.BindEnvAndSetDefault("cri_socket_path", "")              // empty is disabled
 	config.BindEnvAndSetDefault("cri_connection_timeout", int64(1)) // in seconds
@@ -407,6 +424,10 @@ func InitConfig(config Config) {
 	config.SetKnown("snmp_listener.workers")
 	config.SetKnown("snmp_listener.configs")
 
+	// Process listener
+	config.SetKnown("process_listener.discovery_interval")
+	config.SetKnown("process_listener.processes")
+
 	// Kube ApiServer
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
 	config.BindEnvAndSetDefault("leader_lease_duration", "60")
@@ -472,6 +493,10 @@ func InitConfig(config Config) {
 
 	// Go_expvar server port
 	config.BindEnvAndSetDefault("expvar_port", "5000")
+	// Export of the status in the prometheus format on the expvar server
+	config.BindEnvAndSetDefault("status_prometheus_export", false)
+	// Address of a dedicated server for the export, empty to use the expvar server
+	config.BindEnvAndSetDefault("status_prometheus_export_address", "")
 
 	// Profiling
 	config.BindEnvAndSetDefault("profiling.enabled", false)
@@ -488,6 +513,7 @@ func InitConfig(config Config) {
 	} else {
 		config.BindEnvAndSetDefault("apm_config.enabled", true)
 	}
+	config.BindEnvAndSetDefault("apm_config.remote_tagger", false)
 
 	// Process agent
 	config.SetDefault("process_config.enabled", "false")
@@ -500,6 +526,7 @@ func InitConfig(config Config) {
 
 	config.BindEnv("process_config.process_dd_url", "")      //nolint:errcheck
 	config.BindEnv("process_config.orchestrator_dd_url", "") //nolint:errcheck
+	config.BindEnvAndSetDefault("process_config.remote_tagger", false)
 
 	// Logs Agent
 
@@ -586,6 +613,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("cluster_checks.cluster_tag_name", "cluster_name")
 	config.BindEnvAndSetDefault("cluster_checks.extra_tags", []string{})
 	config.BindEnvAndSetDefault("cluster_checks.advanced_dispatching_enabled", false)
+	config.BindEnvAndSetDefault("cluster_checks.rebalance_strategy", "busyness")
+	config.BindEnvAndSetDefault("cluster_checks.rebalance_hysteresis", 0.1)
+	config.BindEnvAndSetDefault("cluster_checks.rebalance_max_moves", 10)
+	config.BindEnvAndSetDefault("cluster_checks.rebalance_pinned_checks", []string{})
 	config.BindEnvAndSetDefault("cluster_checks.clc_runners_port", 5005)
 	// Cluster check runner
 	config.BindEnvAndSetDefault("clc_runner_enabled", false)
@@ -607,6 +638,11 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.endpoint", "/injecttags")
 	config.BindEnvAndSetDefault("admission_controller.pod_owners_cache_validity", 10) // in minutes
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.endpoint", "/injectlib")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.images.java", "gcr.io/datadoghq/dd-lib-java-init")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.images.js", "gcr.io/datadoghq/dd-lib-js-init")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.images.python", "gcr.io/datadoghq/dd-lib-python-init")
 
 	// Telemetry
 	// Enable telemetry metrics on the internals of the Agent.
@@ -673,6 +709,10 @@ func InitConfig(config Config) {
 	config.SetKnown("system_probe_config.closed_channel_size")
 	config.SetKnown("system_probe_config.dns_timeout_in_s")
 	config.SetKnown("system_probe_config.collect_dns_stats")
+	config.SetKnown("system_probe_config.collect_dns_domains")
+	config.SetKnown("system_probe_config.max_dns_domains")
+	config.SetKnown("system_probe_config.enable_http_monitoring")
+	config.SetKnown("system_probe_config.http_timeout_in_s")
 	config.SetKnown("system_probe_config.offset_guess_threshold")
 	config.SetKnown("system_probe_config.enable_tcp_queue_length")
 	config.SetKnown("system_probe_config.enable_oom_kill")
@@ -693,6 +733,8 @@ func InitConfig(config Config) {
 	config.SetKnown("apm_config.receiver_port")
 	config.SetKnown("apm_config.receiver_socket")
 	config.SetKnown("apm_config.connection_limit")
+	config.SetKnown("apm_config.inspect_buffer_size")
+	config.SetKnown("apm_config.debug.port")
 	config.SetKnown("apm_config.ignore_resources")
 	config.SetKnown("apm_config.replace_tags")
 	config.SetKnown("apm_config.obfuscation.elasticsearch.enabled")
@@ -910,8 +952,13 @@ func ResolveSecrets(config Config, origin string) error {
 		config.GetInt("secret_backend_timeout"),
 		config.GetInt("secret_backend_output_max_size"),
 	)
+	secrets.InitBuiltinBackends(
+		config.GetBool("secret_backend_builtin_enabled"),
+		config.GetString("secret_backend_file_root"),
+		config.GetString("secret_backend_k8s_secrets_root"),
+	)
 
-	if config.GetString("secret_backend_command") != "" {
+	if config.GetString("secret_backend_command") != "" || config.GetBool("secret_backend_builtin_enabled") {
 		// Viper doesn't expose the final location of the file it
 		// loads. Since we are searching for 'datadog.yaml' in multiple
 		// locations we let viper determine the one to use before
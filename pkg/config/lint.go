@@ -0,0 +1,394 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Kinds of the issues found by Lint
+const (
+	LintUnknownKey    = "unknown_key"
+	LintUnknownEnvVar = "unknown_env_var"
+	LintDeprecatedKey = "deprecated_key"
+	LintInvalidType   = "invalid_type"
+	LintInvalidValue  = "invalid_value"
+)
+
+// envVarPrefix is the prefix of the env vars of the agent
+const envVarPrefix = "DD_"
+
+// externalEnvVars lists the env vars that aren't bound to a key but read
+// directly, mostly by the other agents, they're not reported as unknown. The
+// ones ending with a '_' are prefixes.
+var externalEnvVars = []string{
+	// proxy settings, read by loadProxyFromEnv
+	"DD_PROXY_HTTP", "DD_PROXY_HTTPS", "DD_PROXY_NO_PROXY",
+	// trace-agent
+	"DD_APM_", "DD_CONNECTION_LIMIT", "DD_RECEIVER_PORT", "DD_MAX_EPS", "DD_MAX_TPS", "DD_IGNORE_RESOURCE",
+	// process-agent
+	"DD_PROCESS_", "DD_ORCHESTRATOR_", "DD_SCRUB_ARGS", "DD_STRIP_PROCESS_ARGS", "DD_CUSTOM_SENSITIVE_WORDS",
+	"DD_LOGS_STDOUT", "DD_LOG_TO_CONSOLE",
+	// system-probe
+	"DD_SYSTEM_PROBE_", "DD_SYSPROBE_SOCKET", "DD_DISABLE_TCP_TRACING", "DD_DISABLE_UDP_TRACING",
+	"DD_DISABLE_IPV6_TRACING", "DD_DISABLE_DNS_INSPECTION", "DD_COLLECT_LOCAL_DNS",
+	// secrets of the env secret backend
+	"DD_SECRET_",
+}
+
+// Severities of the issues found by Lint
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintIssue is an issue found in the configuration
+type LintIssue struct {
+	Key        string `json:"key"`
+	Source     string `json:"source"` // "file", or "env:<name>" for an environment variable
+	Kind       string `json:"kind"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"` // key or env var that was probably meant for an unknown one
+}
+
+// LintResult lists the issues found in the configuration
+type LintResult struct {
+	File     string      `json:"file"`
+	Issues   []LintIssue `json:"issues"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+}
+
+// Lint validates the configuration file and the environment variables, given
+// as "key=value" strings, against the schema of the configuration. Unknown
+// keys, unknown DD_* env vars and deprecated keys are reported as warnings,
+// values of the wrong type or not in the valid ones of a key are reported as
+// errors.
+func Lint(file string, environ []string) (*LintResult, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := lint(content, environ, Schema())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", file, err)
+	}
+	result.File = file
+	return result, nil
+}
+
+func lint(content []byte, environ []string, schema []KeySchema) (*LintResult, error) {
+	var settings map[interface{}]interface{}
+	if err := yaml.Unmarshal(content, &settings); err != nil {
+		return nil, err
+	}
+
+	l := &linter{
+		schema: make(map[string]KeySchema, len(schema)),
+		result: &LintResult{Issues: []LintIssue{}},
+	}
+	for _, ks := range schema {
+		l.schema[ks.Key] = ks
+	}
+
+	l.lintFile("", settings)
+	l.lintEnv(environ)
+
+	sort.SliceStable(l.result.Issues, func(i, j int) bool {
+		a, b := l.result.Issues[i], l.result.Issues[j]
+		return a.Key < b.Key || (a.Key == b.Key && a.Source < b.Source)
+	})
+	for _, issue := range l.result.Issues {
+		if issue.Severity == LintError {
+			l.result.Errors++
+		} else {
+			l.result.Warnings++
+		}
+	}
+	return l.result, nil
+}
+
+type linter struct {
+	schema map[string]KeySchema
+	result *LintResult
+}
+
+// lintFile checks the settings of the configuration file under the given
+// prefix, the sections that aren't keys themselves are walked through
+func (l *linter) lintFile(prefix string, settings map[interface{}]interface{}) {
+	for k, value := range settings {
+		key := strings.ToLower(fmt.Sprint(k))
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if ks, found := l.schema[key]; found {
+			l.lintValue(ks, "file", value)
+			continue
+		}
+		if _, found := l.schema[key+".*"]; found {
+			continue
+		}
+		if section, ok := value.(map[interface{}]interface{}); ok && l.hasSection(key) {
+			l.lintFile(key, section)
+			continue
+		}
+
+		issue := LintIssue{
+			Key:      key,
+			Source:   "file",
+			Kind:     LintUnknownKey,
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("unknown key %s, it's ignored", key),
+		}
+		if suggestion := closest(key, l.keyCandidates()); suggestion != "" {
+			issue.Suggestion = suggestion
+			issue.Message += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		l.result.Issues = append(l.result.Issues, issue)
+	}
+}
+
+// lintEnv checks the environment variables of the known keys, and reports
+// the DD_* ones that no key is bound to
+func (l *linter) lintEnv(environ []string) {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if parts := strings.SplitN(kv, "=", 2); len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+
+	known := make(map[string]struct{})
+	for _, ks := range l.schema {
+		if ks.EnvVar == "" {
+			continue
+		}
+		known[ks.EnvVar] = struct{}{}
+		if value, found := env[ks.EnvVar]; found {
+			l.lintValue(ks, "env:"+ks.EnvVar, value)
+		}
+	}
+
+	for name := range env {
+		if !strings.HasPrefix(name, envVarPrefix) || isExternalEnvVar(name) {
+			continue
+		}
+		if _, found := known[name]; found {
+			continue
+		}
+
+		issue := LintIssue{
+			Key:      name,
+			Source:   "env:" + name,
+			Kind:     LintUnknownEnvVar,
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("unknown environment variable %s, it's ignored", name),
+		}
+		if suggestion := closest(name, known); suggestion != "" {
+			issue.Suggestion = suggestion
+			issue.Message += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		l.result.Issues = append(l.result.Issues, issue)
+	}
+}
+
+// isExternalEnvVar returns whether an env var is read by the other agents
+func isExternalEnvVar(name string) bool {
+	for _, external := range externalEnvVars {
+		if name == external || (strings.HasSuffix(external, "_") && strings.HasPrefix(name, external)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSection returns whether some keys are under the given section
+func (l *linter) hasSection(section string) bool {
+	for key := range l.schema {
+		if strings.HasPrefix(key, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *linter) lintValue(ks KeySchema, source string, value interface{}) {
+	if ks.Deprecated != "" {
+		l.result.Issues = append(l.result.Issues, LintIssue{
+			Key:      ks.Key,
+			Source:   source,
+			Kind:     LintDeprecatedKey,
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("%s is deprecated, %s", ks.Key, ks.Deprecated),
+		})
+	}
+
+	if value == nil {
+		return
+	}
+	if !isValidValue(ks.Type, value) {
+		l.result.Issues = append(l.result.Issues, LintIssue{
+			Key:      ks.Key,
+			Source:   source,
+			Kind:     LintInvalidType,
+			Severity: LintError,
+			Message:  fmt.Sprintf("%s must be a %s, got %v", ks.Key, ks.Type, value),
+		})
+		return
+	}
+	if len(ks.Enum) > 0 {
+		s := strings.ToLower(fmt.Sprint(value))
+		for _, valid := range ks.Enum {
+			if s == valid {
+				return
+			}
+		}
+		l.result.Issues = append(l.result.Issues, LintIssue{
+			Key:      ks.Key,
+			Source:   source,
+			Kind:     LintInvalidValue,
+			Severity: LintError,
+			Message:  fmt.Sprintf("invalid value %v for %s, valid values are: %s", value, ks.Key, strings.Join(ks.Enum, ", ")),
+		})
+	}
+}
+
+// isValidValue returns whether the value can be read as the given type, the
+// way the configuration casts it
+func isValidValue(keyType string, value interface{}) bool {
+	s, isString := value.(string)
+	if isString {
+		s = strings.TrimSpace(s)
+	}
+
+	switch keyType {
+	case KeyTypeBool:
+		switch value.(type) {
+		case bool, int:
+			return true
+		}
+		_, err := strconv.ParseBool(s)
+		return isString && err == nil
+	case KeyTypeInt:
+		switch v := value.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return v == float64(int64(v))
+		}
+		_, err := strconv.ParseInt(s, 10, 64)
+		return isString && err == nil
+	case KeyTypeFloat:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		_, err := strconv.ParseFloat(s, 64)
+		return isString && err == nil
+	case KeyTypeDuration:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); isString && err == nil {
+			return true
+		}
+		_, err := time.ParseDuration(s)
+		return isString && err == nil
+	case KeyTypeString:
+		switch value.(type) {
+		case []interface{}, map[interface{}]interface{}:
+			return false
+		}
+		return true
+	case KeyTypeList:
+		switch value.(type) {
+		case []interface{}, string:
+			return true
+		}
+		return false
+	case KeyTypeMap:
+		switch value.(type) {
+		case map[interface{}]interface{}, string:
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// keyCandidates returns the known keys and sections, to suggest one for an
+// unknown key
+func (l *linter) keyCandidates() map[string]struct{} {
+	candidates := make(map[string]struct{})
+	for known := range l.schema {
+		known = strings.TrimSuffix(known, ".*")
+		parts := strings.Split(known, ".")
+		for i := range parts {
+			candidates[strings.Join(parts[:i+1], ".")] = struct{}{}
+		}
+	}
+	return candidates
+}
+
+// closest returns the candidate that's the closest to an unknown name, if
+// it's close enough to be a typo
+func closest(name string, candidates map[string]struct{}) string {
+	suggestion := ""
+	best := -1
+	for known := range candidates {
+		d := levenshtein(name, known)
+		if best < 0 || d < best || (d == best && known < suggestion) {
+			suggestion, best = known, d
+		}
+	}
+	if best < 0 || (best > 2 && best*4 > len(name)) {
+		return ""
+	}
+	return suggestion
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = []KeySchema{
+	{Key: "api_key", Type: KeyTypeString, EnvVar: "DD_API_KEY"},
+	{Key: "log_level", Type: KeyTypeString, EnvVar: "DD_LOG_LEVEL", Enum: keyEnums["log_level"]},
+	{Key: "log_enabled", Type: KeyTypeBool, EnvVar: "DD_LOG_ENABLED", Deprecated: deprecatedKeys["log_enabled"]},
+	{Key: "logs_enabled", Type: KeyTypeBool, EnvVar: "DD_LOGS_ENABLED"},
+	{Key: "logs_config.use_port_443", Type: KeyTypeBool, EnvVar: "DD_LOGS_CONFIG_USE_PORT_443"},
+	{Key: "logs_config.open_files_limit", Type: KeyTypeInt, EnvVar: "DD_LOGS_CONFIG_OPEN_FILES_LIMIT"},
+	{Key: "tags", Type: KeyTypeList, EnvVar: "DD_TAGS"},
+	{Key: "tag_value_split_separator", Type: KeyTypeMap, EnvVar: "DD_TAG_VALUE_SPLIT_SEPARATOR"},
+	{Key: "forwarder_timeout", Type: KeyTypeDuration, EnvVar: "DD_FORWARDER_TIMEOUT"},
+	{Key: "proxy", Type: KeyTypeAny, EnvVar: "DD_PROXY"},
+	{Key: "apm_config.enabled", Type: KeyTypeBool},
+	{Key: "apm_config.additional_endpoints.*", Type: KeyTypeAny},
+}
+
+func issuesByKey(result *LintResult) map[string]LintIssue {
+	issues := make(map[string]LintIssue)
+	for _, issue := range result.Issues {
+		issues[issue.Key+"/"+issue.Source+"/"+issue.Kind] = issue
+	}
+	return issues
+}
+
+func TestLintValidConfig(t *testing.T) {
+	content := []byte(`
+api_key: abcdef
+log_level: WARN
+logs_enabled: true
+logs_config:
+  use_port_443: "yes"
+  open_files_limit: 100
+tags:
+  - env:prod
+tag_value_split_separator:
+  team: ","
+forwarder_timeout: 20
+proxy:
+  http: http://proxy
+apm_config:
+  enabled: true
+  additional_endpoints:
+    https://trace.agent.datadoghq.eu:
+      - apikey
+`)
+	environ := []string{"DD_LOGS_ENABLED=false", "DD_APM_ENABLED=true", "DD_SECRET_PASSWORD=secret", "PATH=/bin"}
+	result, err := lint(content, environ, testSchema)
+	require.NoError(t, err)
+	// "yes" isn't a valid boolean for the configuration
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, LintIssue{
+		Key:      "logs_config.use_port_443",
+		Source:   "file",
+		Kind:     LintInvalidType,
+		Severity: LintError,
+		Message:  "logs_config.use_port_443 must be a boolean, got yes",
+	}, result.Issues[0])
+	assert.Equal(t, 1, result.Errors)
+	assert.Equal(t, 0, result.Warnings)
+}
+
+func TestLintIssues(t *testing.T) {
+	content := []byte(`
+api_key: abcdef
+log_level: verbose
+log_enabled: true
+logs_enabled: [true]
+logs_config:
+  use_port443: true
+  open_files_limit: many
+tags: {env: prod}
+unknown_section:
+  key: value
+`)
+	environ := []string{
+		"DD_LOG_LEVEL=debug",
+		"DD_LOGS_CONFIG_OPEN_FILES_LIMIT=12.5",
+		"DD_LOG_ENABLED=true",
+		"DD_LOGS_ENABLD=true",
+		"DD_UNRELATED=1",
+	}
+	result, err := lint(content, environ, testSchema)
+	require.NoError(t, err)
+
+	issues := issuesByKey(result)
+	assert.Len(t, issues, 11)
+	assert.Equal(t, 5, result.Errors)
+	assert.Equal(t, 6, result.Warnings)
+
+	assert.Equal(t, LintError, issues["log_level/file/invalid_value"].Severity)
+	assert.Equal(t, "invalid value verbose for log_level, valid values are: trace, debug, info, warn, warning, error, critical, off",
+		issues["log_level/file/invalid_value"].Message)
+	assert.Contains(t, issues, "log_enabled/file/deprecated_key")
+	assert.Contains(t, issues, "log_enabled/env:DD_LOG_ENABLED/deprecated_key")
+	assert.Contains(t, issues, "logs_enabled/file/invalid_type")
+	assert.Contains(t, issues, "logs_config.open_files_limit/file/invalid_type")
+	assert.Contains(t, issues, "logs_config.open_files_limit/env:DD_LOGS_CONFIG_OPEN_FILES_LIMIT/invalid_type")
+	assert.Contains(t, issues, "tags/file/invalid_type")
+
+	typo := issues["logs_config.use_port443/file/unknown_key"]
+	assert.Equal(t, LintWarning, typo.Severity)
+	assert.Equal(t, "logs_config.use_port_443", typo.Suggestion)
+	assert.Equal(t, "unknown key logs_config.use_port443, it's ignored, did you mean logs_config.use_port_443?", typo.Message)
+
+	unknown := issues["unknown_section/file/unknown_key"]
+	assert.Empty(t, unknown.Suggestion)
+
+	envTypo := issues["DD_LOGS_ENABLD/env:DD_LOGS_ENABLD/unknown_env_var"]
+	assert.Equal(t, LintWarning, envTypo.Severity)
+	assert.Equal(t, "DD_LOGS_ENABLED", envTypo.Suggestion)
+	assert.Equal(t, "unknown environment variable DD_LOGS_ENABLD, it's ignored, did you mean DD_LOGS_ENABLED?", envTypo.Message)
+	assert.Empty(t, issues["DD_UNRELATED/env:DD_UNRELATED/unknown_env_var"].Suggestion)
+
+	// the issues are sorted by key
+	for i := 1; i < len(result.Issues); i++ {
+		assert.True(t, result.Issues[i-1].Key <= result.Issues[i].Key)
+	}
+}
+
+func TestLintSuggestsSections(t *testing.T) {
+	result, err := lint([]byte("log_config:\n  use_port_443: true\n"), nil, testSchema)
+	require.NoError(t, err)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "logs_config", result.Issues[0].Suggestion)
+}
+
+func TestLintInvalidYAML(t *testing.T) {
+	_, err := lint([]byte("api_key: [abc"), nil, testSchema)
+	assert.Error(t, err)
+}
+
+func TestLintFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "datadog.yaml")
+	require.NoError(t, ioutil.WriteFile(file, []byte("api_key: abc\ndogstatsd_port: port\nlogs_enabeld: true\n"), 0600))
+
+	result, err := Lint(file, nil)
+	require.NoError(t, err)
+	assert.Equal(t, file, result.File)
+	issues := issuesByKey(result)
+	assert.Contains(t, issues, "dogstatsd_port/file/invalid_type")
+	assert.Equal(t, "logs_enabled", issues["logs_enabeld/file/unknown_key"].Suggestion)
+
+	_, err = Lint(filepath.Join(dir, "missing.yaml"), nil)
+	assert.Error(t, err)
+}
+
+func TestSchema(t *testing.T) {
+	schema := make(map[string]KeySchema)
+	for _, ks := range Schema() {
+		schema[ks.Key] = ks
+	}
+
+	assert.Equal(t, KeyTypeString, schema["api_key"].Type)
+	assert.Equal(t, "DD_API_KEY", schema["api_key"].EnvVar)
+	assert.Equal(t, KeyTypeInt, schema["dogstatsd_port"].Type)
+	assert.Equal(t, 8125, schema["dogstatsd_port"].Default)
+	assert.Equal(t, KeyTypeBool, schema["logs_config.use_port_443"].Type)
+	assert.Equal(t, "DD_LOGS_CONFIG_USE_PORT_443", schema["logs_config.use_port_443"].EnvVar)
+	assert.Equal(t, KeyTypeList, schema["tags"].Type)
+	assert.Equal(t, KeyTypeMap, schema["tag_value_split_separator"].Type)
+	assert.Equal(t, keyEnums["log_level"], schema["log_level"].Enum)
+	assert.Equal(t, deprecatedKeys["log_enabled"], schema["log_enabled"].Deprecated)
+	// keys without a default aren't type checked
+	assert.Equal(t, KeyTypeAny, schema["site"].Type)
+	assert.Equal(t, "DD_SITE", schema["site"].EnvVar)
+	// keys that aren't bound to an env var, or bound to none, have no env var
+	assert.Empty(t, schema["process_config.scrub_args"].EnvVar)
+	assert.Contains(t, schema, "profiling.profile_dd_url")
+	assert.Empty(t, schema["profiling.profile_dd_url"].EnvVar)
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Types of the configuration keys
+const (
+	KeyTypeBool     = "boolean"
+	KeyTypeInt      = "integer"
+	KeyTypeFloat    = "number"
+	KeyTypeString   = "string"
+	KeyTypeList     = "list"
+	KeyTypeMap      = "map"
+	KeyTypeDuration = "duration"
+	KeyTypeAny      = "any" // keys without a default value, their type isn't checked
+)
+
+// KeySchema describes a configuration key
+type KeySchema struct {
+	Key        string      `json:"key"`
+	Type       string      `json:"type"`
+	Default    interface{} `json:"default,omitempty"`
+	EnvVar     string      `json:"env_var,omitempty"` // env var bound to the key, if any
+	Enum       []string    `json:"enum,omitempty"`
+	Deprecated string      `json:"deprecated,omitempty"` // what to do instead of using the key
+}
+
+// keyEnums lists the valid values of the keys that only accept a few of
+// them, they're compared case-insensitively
+var keyEnums = map[string][]string{
+	"log_level":                 {"trace", "debug", "info", "warn", "warning", "error", "critical", "off"},
+	"dogstatsd_tag_cardinality": {"low", "orchestrator", "high"},
+	"checks_tag_cardinality":    {"low", "orchestrator", "high"},
+	"python_version":            {"2", "3"},
+}
+
+// deprecatedKeys lists the keys that are still supported but shouldn't be
+// used anymore, with what to do instead
+var deprecatedKeys = map[string]string{
+	"log_enabled":                 "use logs_enabled instead",
+	"logs_config.dev_mode_no_ssl": "use logs_config.logs_no_ssl instead",
+}
+
+// Schema returns the schema of the configuration of the agent, generated
+// from the keys registered by InitConfig, sorted by key
+func Schema() []KeySchema {
+	c := NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+	InitConfig(c)
+	return schemaOf(c)
+}
+
+func schemaOf(c Config) []KeySchema {
+	var defaults map[string]interface{}
+	var envVars map[string]string
+	if sc, ok := c.(*safeConfig); ok {
+		sc.RLock()
+		defaults = make(map[string]interface{}, len(sc.defaults))
+		for key, value := range sc.defaults {
+			defaults[key] = value
+		}
+		sc.RUnlock()
+		envVars = sc.boundEnvVars()
+	}
+
+	keys := make(map[string]struct{})
+	for key := range c.GetKnownKeys() {
+		keys[key] = struct{}{}
+	}
+	for key := range envVars {
+		keys[key] = struct{}{}
+	}
+	for key := range deprecatedKeys {
+		keys[key] = struct{}{}
+	}
+
+	schema := make([]KeySchema, 0, len(keys))
+	for key := range keys {
+		ks := KeySchema{
+			Key:        key,
+			Type:       KeyTypeAny,
+			EnvVar:     envVars[key],
+			Enum:       keyEnums[key],
+			Deprecated: deprecatedKeys[key],
+		}
+		if value, found := defaults[key]; found {
+			ks.Type = typeOfDefault(value)
+			ks.Default = value
+		}
+		schema = append(schema, ks)
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Key < schema[j].Key })
+	return schema
+}
+
+// typeOfDefault returns the type of a key from its default value
+func typeOfDefault(value interface{}) string {
+	if value == nil {
+		return KeyTypeAny
+	}
+	if _, ok := value.(time.Duration); ok {
+		return KeyTypeDuration
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool:
+		return KeyTypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return KeyTypeInt
+	case reflect.Float32, reflect.Float64:
+		return KeyTypeFloat
+	case reflect.String:
+		return KeyTypeString
+	case reflect.Slice, reflect.Array:
+		return KeyTypeList
+	case reflect.Map:
+		return KeyTypeMap
+	default:
+		return KeyTypeAny
+	}
+}
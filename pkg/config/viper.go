@@ -26,6 +26,12 @@ type safeConfig struct {
 	sync.RWMutex
 	envPrefix     string
 	configEnvVars []string
+
+	// defaults records the default values, to tell the type of the keys
+	defaults map[string]interface{}
+	// envBindings records the env var bound to each key, as BindEnv names it
+	envBindings    map[string]string
+	envKeyReplacer *strings.Replacer
 }
 
 // Set wraps Viper for concurrent access
@@ -40,6 +46,10 @@ func (c *safeConfig) SetDefault(key string, value interface{}) {
 	c.Lock()
 	defer c.Unlock()
 	c.Viper.SetDefault(key, value)
+	if c.defaults == nil {
+		c.defaults = make(map[string]interface{})
+	}
+	c.defaults[strings.ToLower(key)] = value
 }
 
 // SetKnown adds a key to the set of known valid config keys
@@ -234,14 +244,49 @@ func (c *safeConfig) BindEnv(input ...string) error {
 		envVarName := strings.Join([]string{c.envPrefix, strings.ToUpper(key)}, "_")
 		c.configEnvVars = append(c.configEnvVars, envVarName)
 	}
+	if len(input) > 0 {
+		if c.envBindings == nil {
+			c.envBindings = make(map[string]string)
+		}
+		key := strings.ToLower(input[0])
+		switch {
+		case len(input) > 1:
+			c.envBindings[key] = input[1]
+		case c.envPrefix != "":
+			c.envBindings[key] = strings.ToUpper(c.envPrefix + "_" + key)
+		default:
+			c.envBindings[key] = strings.ToUpper(key)
+		}
+	}
 	return c.Viper.BindEnv(input...)
 }
 
-// SetEnvKeyReplacer wraps Viper for concurrent access
-func (c *safeConfig) SetEnvKeyReplacer(r *strings.Replacer) {
+// boundEnvVars returns the env var read for each key bound with BindEnv, the
+// way viper looks them up
+func (c *safeConfig) boundEnvVars() map[string]string {
 	c.RLock()
 	defer c.RUnlock()
+
+	envVars := make(map[string]string, len(c.envBindings))
+	for key, envVar := range c.envBindings {
+		if envVar == "" {
+			// bound to no env var, to only be set by the config file
+			continue
+		}
+		if c.envKeyReplacer != nil {
+			envVar = c.envKeyReplacer.Replace(envVar)
+		}
+		envVars[key] = envVar
+	}
+	return envVars
+}
+
+// SetEnvKeyReplacer wraps Viper for concurrent access
+func (c *safeConfig) SetEnvKeyReplacer(r *strings.Replacer) {
+	c.Lock()
+	defer c.Unlock()
 	c.Viper.SetEnvKeyReplacer(r)
+	c.envKeyReplacer = r
 }
 
 // UnmarshalKey wraps Viper for concurrent access
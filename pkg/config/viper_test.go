@@ -6,6 +6,7 @@
 package config
 
 import (
+	"strings"
 	"sync"
 	"testing"
 
@@ -86,3 +87,20 @@ func TestGetConfigEnvVars(t *testing.T) {
 	config.BindEnv("config_option", "DD_CONFIG_OPTION")
 	assert.NotContains(t, config.GetEnvVars(), "DD_CONFIG_OPTION")
 }
+
+func TestBoundEnvVars(t *testing.T) {
+	config := NewConfig("datadog", "DD", strings.NewReplacer(".", "_")).(*safeConfig)
+
+	config.BindEnv("app_key")
+	config.BindEnv("logs_config.run_path")
+	config.BindEnv("config_option", "DD_CONFIG_OPTION")
+	config.BindEnv("logs_config.dd_url", "")
+	config.BindEnvAndSetDefault("Mixed_Case", "value")
+
+	assert.Equal(t, map[string]string{
+		"app_key":              "DD_APP_KEY",
+		"logs_config.run_path": "DD_LOGS_CONFIG_RUN_PATH",
+		"config_option":        "DD_CONFIG_OPTION",
+		"mixed_case":           "DD_MIXED_CASE",
+	}, config.boundEnvVars())
+}
@@ -46,7 +46,7 @@ func enrichTags(tags []string, defaultHostname string, originTagsFunc func() []s
 
 		// currently only supported for pods
 		entity := kubelet.KubePodTaggerEntityPrefix + entityIDValue
-		entityTags, err := getTags(entity, tagger.DogstatsdCardinality)
+		entityTags, err := getTags(entity, tagger.DogstatsdCardinality())
 		if err != nil {
 			log.Tracef("Cannot get tags for entity %s: %s", entity, err)
 		} else {
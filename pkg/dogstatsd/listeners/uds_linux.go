@@ -14,6 +14,7 @@ import (
 
 	"golang.org/x/sys/unix"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
@@ -22,6 +23,7 @@ import (
 const (
 	pidToEntityCacheKeyPrefix = "pid_to_entity"
 	pidToEntityCacheDuration  = time.Minute
+	processEntityPrefix       = "process://"
 )
 
 // ErrNoContainerMatch is returned when no container ID can be matched
@@ -92,9 +94,14 @@ func getEntityForPID(pid int32) (string, error) {
 		cache.Cache.Set(key, entity, pidToEntityCacheDuration)
 		return entity, nil
 	case errNoContainerMatch:
-		// No runtime detected, cache the `NoOrigin` result
-		cache.Cache.Set(key, NoOrigin, pidToEntityCacheDuration)
-		return NoOrigin, nil
+		// No runtime detected, the process runs on the host. It is tagged by
+		// the process_env tagger collector when it is enabled.
+		entity = NoOrigin
+		if len(config.Datadog.GetStringMapString("process_env_as_tags")) > 0 {
+			entity = processEntityPrefix + strconv.Itoa(int(pid))
+		}
+		cache.Cache.Set(key, entity, pidToEntityCacheDuration)
+		return entity, nil
 	default:
 		// Other lookup error, retry next time
 		return NoOrigin, err
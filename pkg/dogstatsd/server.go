@@ -622,7 +622,7 @@ func FormatDebugStats(stats []byte) (string, error) {
 func findOriginTags(origin string) []string {
 	var tags []string
 	if origin != listeners.NoOrigin {
-		originTags, err := tagger.Tag(origin, tagger.DogstatsdCardinality)
+		originTags, err := tagger.Tag(origin, tagger.DogstatsdCardinality())
 		if err != nil {
 			log.Errorf(err.Error())
 		} else {
@@ -631,7 +631,7 @@ func findOriginTags(origin string) []string {
 	}
 
 	// Include orchestrator scope tags if the cardinality is set to orchestrator
-	if tagger.DogstatsdCardinality == collectors.OrchestratorCardinality {
+	if tagger.DogstatsdCardinality() == collectors.OrchestratorCardinality {
 		orchestratorScopeTags, err := tagger.OrchestratorScopeTag()
 		if err != nil {
 			log.Error(err.Error())
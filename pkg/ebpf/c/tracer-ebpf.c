@@ -478,6 +478,14 @@ static void update_tcp_stats(conn_tuple_t* t, tcp_stats_t stats) {
         __sync_fetch_and_add(&val->retransmits, stats.retransmits);
     }
 
+    if (stats.resets > 0) {
+        __sync_fetch_and_add(&val->resets, stats.resets);
+    }
+
+    if (stats.state_transitions > 0) {
+        val->state_transitions |= stats.state_transitions;
+    }
+
     if (stats.rtt > 0) {
         // For more information on the bit shift operations see:
         // https://elixir.bootlin.com/linux/v4.6/source/net/ipv4/tcp.c#L2686
@@ -581,7 +589,43 @@ static int handle_retransmit(struct sock* sk, tracer_status_t* status) {
         return 0;
     }
 
-    tcp_stats_t stats = {.retransmits = 1, .rtt = 0, .rtt_var = 0 };
+    tcp_stats_t stats = {.retransmits = 1, .rtt = 0, .rtt_var = 0, .resets = 0, .state_transitions = 0 };
+    update_tcp_stats(&t, stats);
+
+    // Update latest timestamp that we've seen - for connection expiration tracking
+    bpf_map_update_elem(&latest_ts, &zero, &ts, BPF_ANY);
+    return 0;
+}
+
+__attribute__((always_inline))
+static int handle_reset(struct sock* sk, tracer_status_t* status) {
+    conn_tuple_t t = {};
+    u64 ts = bpf_ktime_get_ns();
+    u64 zero = 0;
+
+    if (!read_conn_tuple(&t, status, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_stats_t stats = {.retransmits = 0, .rtt = 0, .rtt_var = 0, .resets = 1, .state_transitions = 0 };
+    update_tcp_stats(&t, stats);
+
+    // Update latest timestamp that we've seen - for connection expiration tracking
+    bpf_map_update_elem(&latest_ts, &zero, &ts, BPF_ANY);
+    return 0;
+}
+
+__attribute__((always_inline))
+static int handle_tcp_set_state(struct sock* sk, tracer_status_t* status, int state) {
+    conn_tuple_t t = {};
+    u64 ts = bpf_ktime_get_ns();
+    u64 zero = 0;
+
+    if (!read_conn_tuple(&t, status, sk, zero, CONN_TYPE_TCP)) {
+        return 0;
+    }
+
+    tcp_stats_t stats = {.retransmits = 0, .rtt = 0, .rtt_var = 0, .resets = 0, .state_transitions = (1 << state) };
     update_tcp_stats(&t, stats);
 
     // Update latest timestamp that we've seen - for connection expiration tracking
@@ -595,7 +639,7 @@ static void handle_tcp_stats(conn_tuple_t* t, tracer_status_t* status, struct so
     bpf_probe_read(&rtt, sizeof(rtt), ((char*)sk) + status->offset_rtt);
     bpf_probe_read(&rtt_var, sizeof(rtt_var), ((char*)sk) + status->offset_rtt_var);
 
-    tcp_stats_t stats = {.retransmits = 0, .rtt = rtt, .rtt_var = rtt_var };
+    tcp_stats_t stats = {.retransmits = 0, .rtt = rtt, .rtt_var = rtt_var, .resets = 0, .state_transitions = 0 };
     update_tcp_stats(t, stats);
     return;
 }
@@ -927,6 +971,42 @@ int kprobe__tcp_retransmit_skb(struct pt_regs* ctx) {
     return handle_retransmit(sk, status);
 }
 
+// tcp_reset is called when a RST segment is received on a socket
+SEC("kprobe/tcp_reset")
+int kprobe__tcp_reset(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    u64 zero = 0;
+    tracer_status_t* status = bpf_map_lookup_elem(&tracer_status, &zero);
+    if (status == NULL) {
+        return 0;
+    }
+    log_debug("kprobe/tcp_reset\n");
+
+    return handle_reset(sk, status);
+}
+
+// tcp_set_state is called on every state change of a TCP socket.
+// Only TCP_ESTABLISHED is recorded: it is reached by both ends of a connection once the handshake completes,
+// so a closed connection without it is a connection attempt which got refused or timed out.
+// TCP_CLOSE is not recorded since it is set after kprobe/tcp_close deleted the stats of the connection.
+SEC("kprobe/tcp_set_state")
+int kprobe__tcp_set_state(struct pt_regs* ctx) {
+    struct sock* sk = (struct sock*)PT_REGS_PARM1(ctx);
+    int state = (int)PT_REGS_PARM2(ctx);
+    if (state != TCP_ESTABLISHED) {
+        return 0;
+    }
+
+    u64 zero = 0;
+    tracer_status_t* status = bpf_map_lookup_elem(&tracer_status, &zero);
+    if (status == NULL) {
+        return 0;
+    }
+    log_debug("kprobe/tcp_set_state: state: %d\n", state);
+
+    return handle_tcp_set_state(sk, status, state);
+}
+
 SEC("kretprobe/inet_csk_accept")
 int kretprobe__inet_csk_accept(struct pt_regs* ctx) {
     struct sock* newsk = (struct sock*)PT_REGS_RC(ctx);
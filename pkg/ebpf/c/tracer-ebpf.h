@@ -58,6 +58,10 @@ typedef struct {
     __u32 retransmits;
     __u32 rtt;
     __u32 rtt_var;
+    // Number of RST segments received on the connection
+    __u32 resets;
+    // Bitmask of the TCP states the connection went through (1 << state), see kprobe/tcp_set_state
+    __u32 state_transitions;
 } tcp_stats_t;
 
 // Full data for a tcp connection
@@ -29,6 +29,21 @@ type Config struct {
 	// DNSTimeout determines the length of time to wait before considering a DNS Query to have timed out
 	DNSTimeout time.Duration
 
+	// CollectDNSDomains specifies whether the DNS stats should additionally be broken down by queried domain,
+	// with counts per response code and a latency distribution. It is relevant *only* when CollectDNSStats is enabled.
+	CollectDNSDomains bool
+
+	// MaxDNSDomains limits the number of distinct domains tracked between two client requests, the stats
+	// of additional domains are reported under network.DNSOtherDomain
+	MaxDNSDomains int
+
+	// EnableHTTPMonitoring specifies whether the tracer should enhance TCP connections with aggregated
+	// statistics about the plaintext HTTP/1.x transactions they carry
+	EnableHTTPMonitoring bool
+
+	// HTTPTimeout determines the length of time to wait for a response before considering an HTTP request to have timed out
+	HTTPTimeout time.Duration
+
 	// UDPConnTimeout determines the length of traffic inactivity between two (IP, port)-pairs before declaring a UDP
 	// connection as inactive.
 	// Note: As UDP traffic is technically "connection-less", for tracking, we consider a UDP connection to be traffic
@@ -121,5 +136,10 @@ func NewDefaultConfig() *Config {
 		CollectDNSStats:      false,
 		DNSTimeout:           15 * time.Second,
 		OffsetGuessThreshold: 400,
+		CollectDNSDomains:    false,
+		MaxDNSDomains:        1000,
+		// HTTP monitoring related configurations
+		EnableHTTPMonitoring: false,
+		HTTPTimeout:          15 * time.Second,
 	}
 }
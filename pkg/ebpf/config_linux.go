@@ -17,6 +17,8 @@ func (c *Config) EnabledKProbes(pre410Kernel bool) map[KProbeName]struct{} {
 		enabled[TCPClose] = struct{}{}
 		enabled[TCPCloseReturn] = struct{}{}
 		enabled[TCPRetransmit] = struct{}{}
+		enabled[TCPReset] = struct{}{}
+		enabled[TCPSetState] = struct{}{}
 		enabled[InetCskAcceptReturn] = struct{}{}
 		enabled[TCPv4DestroySock] = struct{}{}
 
@@ -74,9 +74,14 @@ type ConnStatsWithTimestamp C.conn_stats_ts_t
 __u32 retransmits;
 __u32 rtt;
 __u32 rtt_var;
+__u32 resets;
+__u32 state_transitions;
 */
 type TCPStats C.tcp_stats_t
 
+// tcpEstablished is the TCP_ESTABLISHED state of include/net/tcp_states.h
+const tcpEstablished = 1
+
 /*
 __u32 tcp_sent_miscounts;
 */
@@ -131,21 +136,28 @@ func connStats(t *ConnTuple, s *ConnStatsWithTimestamp, tcpStats *TCPStats) netw
 		dest = util.V6Address(uint64(t.daddr_l), uint64(t.daddr_h))
 	}
 
+	var established uint32
+	if tcpStats.state_transitions&(1<<tcpEstablished) != 0 {
+		established = 1
+	}
+
 	return network.ConnectionStats{
-		Pid:                  uint32(t.pid),
-		Type:                 connType(metadata),
-		Family:               family,
-		NetNS:                uint32(t.netns),
-		Source:               source,
-		Dest:                 dest,
-		SPort:                uint16(t.sport),
-		DPort:                uint16(t.dport),
-		MonotonicSentBytes:   uint64(s.sent_bytes),
-		MonotonicRecvBytes:   uint64(s.recv_bytes),
-		MonotonicRetransmits: uint32(tcpStats.retransmits),
-		RTT:                  uint32(tcpStats.rtt),
-		RTTVar:               uint32(tcpStats.rtt_var),
-		LastUpdateEpoch:      uint64(s.timestamp),
+		Pid:                     uint32(t.pid),
+		Type:                    connType(metadata),
+		Family:                  family,
+		NetNS:                   uint32(t.netns),
+		Source:                  source,
+		Dest:                    dest,
+		SPort:                   uint16(t.sport),
+		DPort:                   uint16(t.dport),
+		MonotonicSentBytes:      uint64(s.sent_bytes),
+		MonotonicRecvBytes:      uint64(s.recv_bytes),
+		MonotonicRetransmits:    uint32(tcpStats.retransmits),
+		MonotonicResets:         uint32(tcpStats.resets),
+		MonotonicTCPEstablished: established,
+		RTT:                     uint32(tcpStats.rtt),
+		RTTVar:                  uint32(tcpStats.rtt_var),
+		LastUpdateEpoch:         uint64(s.timestamp),
 	}
 }
 
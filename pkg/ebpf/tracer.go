@@ -22,7 +22,7 @@ import (
 
 var (
 	expvarEndpoints map[string]*expvar.Map
-	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns"}
+	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns", "http"}
 )
 
 func init() {
@@ -45,6 +45,8 @@ type Tracer struct {
 
 	reverseDNS network.ReverseDNS
 
+	httpMonitor network.HTTPMonitor
+
 	perfMap      *bpflib.PerfMap
 	batchManager *PerfBatchManager
 
@@ -67,6 +69,8 @@ type Tracer struct {
 	// to determine whether a connection is truly closed or not
 	expiredTCPConns int64
 	closedConns     int64
+	// Closed TCP connections which never got established, see network.ConnectionStats.IsFailedConnect
+	failedConnects int64
 
 	buffer     []network.ConnectionStats
 	bufferLock sync.Mutex
@@ -190,6 +194,8 @@ func NewTracer(config *Config) (*Tracer, error) {
 			config.CollectDNSStats,
 			config.CollectLocalDNS,
 			config.DNSTimeout,
+			config.CollectDNSDomains,
+			config.MaxDNSDomains,
 		); err == nil {
 			reverseDNS = snooper
 		} else {
@@ -197,6 +203,16 @@ func NewTracer(config *Config) (*Tracer, error) {
 		}
 	}
 
+	var httpMonitor network.HTTPMonitor = network.NewNullHTTPMonitor()
+	if config.EnableHTTPMonitoring && config.CollectTCPConns {
+		if snooper, err := network.NewHTTPSnooper(config.ProcRoot, config.HTTPTimeout); err == nil {
+			httpMonitor = snooper
+		} else {
+			reverseDNS.Close()
+			return nil, fmt.Errorf("error enabling HTTP traffic inspection: %s", err)
+		}
+	}
+
 	portMapping := network.NewPortMapping(config.ProcRoot, config.CollectTCPConns, config.CollectIPv6Conns)
 	udpPortMapping := network.NewPortMapping(config.ProcRoot, config.CollectTCPConns, config.CollectIPv6Conns)
 	if err := portMapping.ReadInitialState(); err != nil {
@@ -230,6 +246,7 @@ func NewTracer(config *Config) (*Tracer, error) {
 		portMapping:    portMapping,
 		udpPortMapping: udpPortMapping,
 		reverseDNS:     reverseDNS,
+		httpMonitor:    httpMonitor,
 		buffer:         make([]network.ConnectionStats, 0, 512),
 		buf:            &bytes.Buffer{},
 		conntracker:    conntracker,
@@ -352,6 +369,13 @@ func (t *Tracer) storeClosedConn(cs network.ConnectionStats) {
 	}
 
 	atomic.AddInt64(&t.closedConns, 1)
+	cs.MonotonicTCPClosed = 1
+	if cs.IsFailedConnect() {
+		cs.MonotonicConnectFailures = 1
+		// a refused connection attempt gets a RST, which is not a reset of an established connection
+		cs.MonotonicResets = 0
+		atomic.AddInt64(&t.failedConnects, 1)
+	}
 	cs.IPTranslation = t.conntracker.GetTranslationForConn(cs)
 	t.state.StoreClosedConnection(cs)
 	if cs.IPTranslation != nil {
@@ -361,6 +385,7 @@ func (t *Tracer) storeClosedConn(cs network.ConnectionStats) {
 
 func (t *Tracer) Stop() {
 	t.reverseDNS.Close()
+	t.httpMonitor.Close()
 	_ = t.m.Close()
 	t.perfMap.PollStop()
 	t.conntracker.Close()
@@ -382,6 +407,7 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 		t.buffer = make([]network.ConnectionStats, 0, cap(t.buffer)/2)
 	}
 
+	t.state.StoreHTTPStats(t.httpMonitor.GetHTTPStats())
 	conns := t.state.Connections(clientID, latestTime, latestConns, t.reverseDNS.GetDNSStats())
 	names := t.reverseDNS.Resolve(conns)
 	tm := t.getConnTelemetry(len(latestConns))
@@ -658,6 +684,7 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 	skipped := atomic.LoadInt64(&t.skippedConns)
 	expiredTCP := atomic.LoadInt64(&t.expiredTCPConns)
 	pidCollisions := atomic.LoadInt64(&t.pidCollisions)
+	failedConnects := atomic.LoadInt64(&t.failedConnects)
 
 	stateStats := t.state.GetStats()
 	conntrackStats := t.conntracker.GetStats()
@@ -671,10 +698,12 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 			"conn_valid_skipped":           skipped, // Skipped connections (e.g. Local DNS requests)
 			"expired_tcp_conns":            expiredTCP,
 			"pid_collisions":               pidCollisions,
+			"failed_connects":              failedConnects,
 		},
 		"ebpf":    t.getEbpfTelemetry(),
 		"kprobes": GetProbeStats(),
 		"dns":     t.reverseDNS.GetStats(),
+		"http":    t.httpMonitor.GetStats(),
 	}, nil
 }
 
@@ -686,6 +715,14 @@ func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, err
 	return t.state.DumpState(clientID), nil
 }
 
+// DebugDNSStats returns the per-domain DNS stats collected since the last client request, for debugging
+func (t *Tracer) DebugDNSStats() (map[string]network.DNSDomainStats, error) {
+	if !t.config.CollectDNSStats || !t.config.CollectDNSDomains {
+		return nil, fmt.Errorf("per-domain DNS stats are not enabled")
+	}
+	return t.reverseDNS.GetDNSDomainStats(), nil
+}
+
 // DebugNetworkMaps returns all connections stored in the BPF maps without modifications from network state
 func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	latestConns, _, err := t.getConnections(make([]network.ConnectionStats, 0))
@@ -449,6 +449,91 @@ func TestTCPRetransmit(t *testing.T) {
 	assert.Equal(t, addrPort(server.address), int(conn.DPort))
 }
 
+func TestTCPReset(t *testing.T) {
+	tr, err := NewTracer(NewDefaultConfig())
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	// Create TCP Server which resets the connection once it gets a message
+	server := NewTCPServer(func(c net.Conn) {
+		r := bufio.NewReader(c)
+		r.ReadBytes(byte('\n'))
+		// with a zero linger, closing the socket sends a RST
+		c.(*net.TCPConn).SetLinger(0)
+		c.Close()
+	})
+	doneChan := make(chan struct{})
+	server.Run(doneChan)
+	defer close(doneChan)
+
+	c, err := net.DialTimeout("tcp", server.address, time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Write(genPayload(clientMessageSize))
+	require.NoError(t, err)
+	// the read fails once the RST is received
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = bufio.NewReader(c).ReadBytes(byte('\n'))
+	require.Error(t, err)
+
+	connections := getConnections(t, tr)
+	conn, ok := findConnection(c.LocalAddr(), c.RemoteAddr(), connections)
+	require.True(t, ok)
+	assert.Equal(t, uint32(1), conn.MonotonicResets)
+	assert.Zero(t, conn.MonotonicConnectFailures)
+}
+
+func TestTCPConnectFailure(t *testing.T) {
+	tr, err := NewTracer(NewDefaultConfig())
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	// Reserve a port and close it so that nothing listens on it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = net.DialTimeout("tcp", addr, time.Second)
+	require.Error(t, err)
+
+	connections := getConnections(t, tr)
+	conns := searchConnections(connections, func(cs network.ConnectionStats) bool {
+		return cs.Type == network.TCP && int(cs.DPort) == addrPort(addr)
+	})
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].MonotonicConnectFailures)
+	assert.Equal(t, uint32(1), conns[0].MonotonicTCPClosed)
+	assert.Zero(t, conns[0].MonotonicTCPEstablished)
+	assert.Zero(t, conns[0].MonotonicResets)
+}
+
+func TestTCPConnectThenClose(t *testing.T) {
+	tr, err := NewTracer(NewDefaultConfig())
+	require.NoError(t, err)
+	defer tr.Stop()
+
+	server := NewTCPServer(func(c net.Conn) {
+		c.Close()
+	})
+	doneChan := make(chan struct{})
+	server.Run(doneChan)
+	defer close(doneChan)
+
+	// Connect and close without exchanging any byte, as done by health checks
+	c, err := net.DialTimeout("tcp", server.address, time.Second)
+	require.NoError(t, err)
+	c.Close()
+
+	connections := getConnections(t, tr)
+	conn, ok := findConnection(c.LocalAddr(), c.RemoteAddr(), connections)
+	require.True(t, ok)
+	assert.Zero(t, conn.MonotonicConnectFailures)
+	assert.Equal(t, uint32(1), conn.MonotonicTCPEstablished)
+	assert.Equal(t, uint32(1), conn.MonotonicTCPClosed)
+}
+
 func TestTCPRetransmitSharedSocket(t *testing.T) {
 	// Enable BPF-based system probe
 	tr, err := NewTracer(NewDefaultConfig())
@@ -30,6 +30,11 @@ func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, err
 	return nil, ErrNotImplemented
 }
 
+// DebugDNSStats is not implemented on this OS for Tracer
+func (t *Tracer) DebugDNSStats() (map[string]network.DNSDomainStats, error) {
+	return nil, ErrNotImplemented
+}
+
 // DebugNetworkMaps is not implemented on this OS for Tracer
 func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ErrNotImplemented
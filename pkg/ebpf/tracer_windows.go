@@ -153,6 +153,11 @@ func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, err
 	return nil, ErrNotImplemented
 }
 
+// DebugDNSStats returns the per-domain DNS stats collected since the last client request, for debugging
+func (t *Tracer) DebugDNSStats() (map[string]network.DNSDomainStats, error) {
+	return nil, ErrNotImplemented
+}
+
 // DebugNetworkMaps returns all connections stored in the maps without modifications from network state
 func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ErrNotImplemented
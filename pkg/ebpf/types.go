@@ -56,6 +56,12 @@ const (
 	// TCPRetransmit traces the return value for the tcp_retransmit_skb() system call
 	TCPRetransmit KProbeName = "kprobe/tcp_retransmit_skb"
 
+	// TCPReset traces the tcp_reset() function, called when a RST segment is received
+	TCPReset KProbeName = "kprobe/tcp_reset"
+
+	// TCPSetState traces the tcp_set_state() function, called on every state change of a TCP socket
+	TCPSetState KProbeName = "kprobe/tcp_set_state"
+
 	// InetCskAcceptReturn traces the return value for the inet_csk_accept syscall
 	InetCskAcceptReturn KProbeName = "kretprobe/inet_csk_accept"
 
@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/api/security"
@@ -63,6 +64,7 @@ var (
 	// Basically we allow many special chars while forbidding *
 	otherAPIKeysRx       = regexp.MustCompile(`api_key\s*:\s*[a-zA-Z0-9\\\/\^\]\[\(\){}!|%:;"~><=#@$_\-\+]+`)
 	otherAPIKeysReplacer = log.Replacer{
+		Name:  "other_api_keys",
 		Regex: otherAPIKeysRx,
 		ReplFunc: func(b []byte) []byte {
 			return []byte("api_key: ********")
@@ -70,6 +72,8 @@ var (
 	}
 )
 
+var archiveLock sync.Mutex
+
 // SearchPaths is just an alias for a map of strings
 type SearchPaths map[string]string
 
@@ -86,16 +90,35 @@ type filePermsInfo struct {
 
 // CreateArchive packages up the files
 func CreateArchive(local bool, distPath, pyChecksPath, logFilePath string) (string, error) {
+	return CreateArchiveWithOptions(local, distPath, pyChecksPath, logFilePath, ArchiveOptions{})
+}
+
+// CreateArchiveWithOptions packages up the files of the providers selected by the options
+func CreateArchiveWithOptions(local bool, distPath, pyChecksPath, logFilePath string, opts ArchiveOptions) (string, error) {
 	zipFilePath := getArchivePath()
 	confSearchPaths := SearchPaths{
 		"":        config.Datadog.GetString("confd_path"),
 		"dist":    filepath.Join(distPath, "conf.d"),
 		"checksd": pyChecksPath,
 	}
-	return createArchive(zipFilePath, local, confSearchPaths, logFilePath)
+	return createArchive(zipFilePath, local, confSearchPaths, logFilePath, opts)
 }
 
-func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths, logFilePath string) (string, error) {
+func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths, logFilePath string, opts ArchiveOptions) (string, error) {
+	// the files that were redacted are tracked globally, one flare at a time
+	archiveLock.Lock()
+	defer archiveLock.Unlock()
+
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	// don't create a flare that would miss some of the user-defined scrubbing
+	rules, err := loadScrubbingRules()
+	if err != nil {
+		return "", err
+	}
+
 	tempDir, err := createTempDir()
 	if err != nil {
 		return "", err
@@ -118,31 +141,6 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		if err != nil {
 			return "", err
 		}
-		// Can't reach the agent, mention it in those two files
-		err = writeStatusFile(tempDir, hostname, []byte("unable to get the status of the agent, is it running?"))
-		if err != nil {
-			return "", err
-		}
-		err = writeConfigCheck(tempDir, hostname, []byte("unable to get loaded checks config, is the agent running?"))
-		if err != nil {
-			return "", err
-		}
-	} else {
-		// Status informations are available, zip them up as the agent is running.
-		err = zipStatusFile(tempDir, hostname)
-		if err != nil {
-			log.Errorf("Could not zip status: %s", err)
-		}
-
-		err = zipConfigCheck(tempDir, hostname)
-		if err != nil {
-			log.Errorf("Could not zip config check: %s", err)
-		}
-
-		err = zipTaggerList(tempDir, hostname)
-		if err != nil {
-			log.Errorf("Could not zip tagger list: %s", err)
-		}
 	}
 
 	// auth token permissions info (only if existing)
@@ -150,101 +148,37 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		permsInfos.add(security.GetAuthTokenFilepath())
 	}
 
-	err = zipConfigFiles(tempDir, hostname, confSearchPaths, permsInfos)
-	if err != nil {
-		log.Errorf("Could not zip config: %s", err)
-	}
+	currentArchive.confSearchPaths = confSearchPaths
+	currentArchive.logFilePath = logFilePath
+	currentArchive.permsInfos = permsInfos
 
-	err = zipExpVar(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip exp var: %s", err)
-	}
-
-	if config.Datadog.GetBool("system_probe_config.enabled") {
-		err = zipSystemProbeStats(tempDir, hostname)
-		if err != nil {
-			log.Errorf("Could not zip system probe exp var stats: %s", err)
-		}
-	}
-
-	err = zipDiagnose(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip diagnose: %s", err)
-	}
-
-	err = zipRegistryJSON(tempDir, hostname)
-	if err != nil {
-		log.Warnf("Could not zip registry.json: %s", err)
+	trackRedactions()
+	providers := builtinProviders(local)
+	providers = append(providers, registeredProviders(providers)...)
+	m := runProviders(tempDir, hostname, providers, opts)
+	m.Local = local
+	report := newScrubReport(rules)
+	for f, counts := range stopTrackingRedactions(filepath.Join(tempDir, hostname)) {
+		report.add(f, counts)
 	}
 
-	err = zipVersionHistory(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip version-history.json: %s", err)
-	}
-
-	err = zipSecrets(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip secrets: %s", err)
-	}
-
-	err = zipEnvvars(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip env vars: %s", err)
-	}
-
-	err = zipHealth(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip health check: %s", err)
-	}
-
-	if config.Datadog.GetBool("telemetry.enabled") {
-		err = zipTelemetry(tempDir, hostname)
-		if err != nil {
-			log.Errorf("Could not collect telemetry metrics: %s", err)
-		}
-	}
-
-	err = zipStackTraces(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not collect go routine stack traces: %s", err)
-	}
-
-	if config.IsContainerized() {
-		err = zipDockerSelfInspect(tempDir, hostname)
-		if err != nil {
-			log.Errorf("Could not zip docker inspect: %s", err)
-		}
-	}
-
-	err = zipDockerPs(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip docker ps: %s", err)
-	}
-
-	err = zipTypeperfData(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not write typeperf data: %s", err)
-	}
-	err = zipCounterStrings(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not write counter strings: %s", err)
+	// gets files infos and write the permissions.log file
+	if err := permsInfos.commit(tempDir, hostname, os.ModePerm); err != nil {
+		log.Errorf("Could not write permissions.log file: %s", err)
 	}
 
-	// force a log flush before zipping them
-	log.Flush()
-	err = zipLogFiles(tempDir, hostname, logFilePath, permsInfos)
-	if err != nil {
-		log.Errorf("Could not zip logs: %s", err)
+	// the user-defined rules apply to every file, including the ones that
+	// weren't written through a redacting writer
+	if err := applyScrubbingRules(filepath.Join(tempDir, hostname), rules, report); err != nil {
+		return "", fmt.Errorf("could not apply the scrubbing rules: %v", err)
 	}
+	m.RedactedFiles = report.files()
 
-	err = zipInstallInfo(tempDir, hostname)
-	if err != nil {
-		log.Errorf("Could not zip install_info: %s", err)
+	if err := report.write(tempDir, hostname); err != nil {
+		log.Errorf("Could not write the flare scrub report: %s", err)
 	}
-
-	// gets files infos and write the permissions.log file
-	if err := permsInfos.commit(tempDir, hostname, os.ModePerm); err != nil {
-		log.Errorf("Could not write permissions.log file: %s", err)
+	if err := m.write(tempDir, hostname); err != nil {
+		log.Errorf("Could not write the flare manifest: %s", err)
 	}
 
 	err = archiver.Zip.Make(zipFilePath, []string{filepath.Join(tempDir, hostname)})
@@ -819,6 +753,7 @@ func newRedactingWriter(f string, p os.FileMode, buffered bool) (*RedactingWrite
 	// which has an "api_key" field in its YAML configuration.
 	// We add this replacer to scrub even those credentials.
 	w.RegisterReplacer(otherAPIKeysReplacer)
+	trackRedactingWriter(f, w)
 	return w, nil
 }
 
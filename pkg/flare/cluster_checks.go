@@ -108,6 +108,77 @@ func GetClusterChecks(w io.Writer) error {
 	return nil
 }
 
+// GetClusterChecksRebalancePlan dumps the moves the cost-aware rebalancing of
+// the cluster checks would do to the writer
+func GetClusterChecksRebalancePlan(w io.Writer) error {
+	urlstr := fmt.Sprintf("https://localhost:%v/api/v1/clusterchecks/rebalance", config.Datadog.GetInt("cluster_agent.cmd_port"))
+
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	if !config.Datadog.GetBool("cluster_checks.enabled") {
+		fmt.Fprintln(w, "Cluster-checks are not enabled")
+		return nil
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			fmt.Fprintln(w, fmt.Sprintf("The agent ran into an error while planning the rebalance: %s", string(r)))
+		} else {
+			fmt.Fprintln(w, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+		}
+		return err
+	}
+
+	var plan types.RebalancePlanResponse
+	if err = json.Unmarshal(r, &plan); err != nil {
+		return err
+	}
+
+	// Gracefully exit when dispatcher is not running
+	if len(plan.NotRunning) > 0 {
+		fmt.Fprintf(w, "Cluster-check dispatching logic not running: %s\n", plan.NotRunning)
+		return nil
+	}
+
+	if len(plan.Nodes) == 0 {
+		fmt.Fprintln(w, "No runner stats collected yet, advanced_dispatching_enabled must be true")
+		return nil
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("=== %s rebalance plan, not applied ===", color.BlueString("Simulated")))
+	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "\nNode\tCheck runners\tCost (ms)\tPlanned cost (ms)")
+	for _, n := range plan.Nodes {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\n", n.Name, n.CheckRunners, n.Cost, n.PlannedCost)
+	}
+	table.Flush()
+	fmt.Fprintln(w, "")
+
+	if len(plan.Moves) == 0 {
+		fmt.Fprintln(w, "The checks are balanced, no check would be moved")
+		return nil
+	}
+	fmt.Fprintln(w, fmt.Sprintf("=== %d checks would be moved ===", len(plan.Moves)))
+	table = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "\nCheck\tCheck ID\tCost (ms)\tFrom\tTo")
+	for _, m := range plan.Moves {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%s\n", m.CheckName, m.CheckID, m.Cost, m.Source, m.Destination)
+	}
+	table.Flush()
+
+	return nil
+}
+
 // GetEndpointsChecks dumps the endpointschecks dispatching state to the writer
 func GetEndpointsChecks(w io.Writer) error {
 	if !endpointschecksEnabled() {
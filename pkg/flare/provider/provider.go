@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package provider
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Provider returns the files a component adds to the flare, keyed by their
+// path relative to the directory of the provider in the flare. The content
+// of the files is scrubbed before being written to the flare.
+type Provider func() (map[string][]byte, error)
+
+// Catalog holds the providers adding content to the flare
+type Catalog map[string]Provider
+
+// Archive describes the flare being created to the built-in providers
+type Archive struct {
+	TempDir  string // temporary directory of the flare
+	Hostname string // directory of the host in TempDir, the built-in providers write at its root
+	Local    bool   // whether the flare is created by the command line because the agent can't be reached
+}
+
+// BuiltinProvider writes the content the agent ships in every flare at the
+// root of the flare directory, to keep its layout; new content should rather
+// come from a Provider. It returns ErrDisabled if its content isn't available.
+type BuiltinProvider func(a Archive) error
+
+// ErrDisabled is returned by the built-in providers whose content isn't available
+var ErrDisabled = errors.New("flare provider disabled")
+
+type builtinProvider struct {
+	name string
+	p    BuiltinProvider
+}
+
+var (
+	// DefaultCatalog holds every compiled-in provider
+	DefaultCatalog = make(Catalog)
+	builtins       []builtinProvider
+	catalogMutex   sync.RWMutex
+)
+
+// RegisterBuiltin registers a built-in provider. The built-in providers are run
+// in the order they're registered, before the other ones, which can't override them.
+func RegisterBuiltin(name string, p BuiltinProvider) {
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+
+	for i, b := range builtins {
+		if b.name == name {
+			log.Warnf("Built-in flare provider %s already registered, overriding it", name)
+			builtins[i].p = p
+			return
+		}
+	}
+	builtins = append(builtins, builtinProvider{name: name, p: p})
+}
+
+// BuiltinNames returns the names of the built-in providers in the order they're run
+func BuiltinNames() []string {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	names := make([]string, 0, len(builtins))
+	for _, b := range builtins {
+		names = append(names, b.name)
+	}
+	return names
+}
+
+// GetBuiltin returns the built-in provider registered with the given name
+func GetBuiltin(name string) (BuiltinProvider, bool) {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	for _, b := range builtins {
+		if b.name == name {
+			return b.p, true
+		}
+	}
+	return nil, false
+}
+
+// Register a provider that will be called when creating a flare, its files
+// are added under the `name` directory of the flare
+func Register(name string, p Provider) {
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+
+	if _, ok := DefaultCatalog[name]; ok {
+		log.Warnf("Flare provider %s already registered, overriding it", name)
+	}
+	DefaultCatalog[name] = p
+}
+
+// Names returns the sorted names of the registered providers
+func Names() []string {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	names := make([]string, 0, len(DefaultCatalog))
+	for name := range DefaultCatalog {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the provider registered with the given name
+func Get(name string) (Provider, bool) {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	p, ok := DefaultCatalog[name]
+	return p, ok
+}
@@ -0,0 +1,356 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package flare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/flare/provider"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const manifestFilename = "manifest.json"
+
+// ArchiveOptions selects the content of a flare archive
+type ArchiveOptions struct {
+	Include []string `json:"include,omitempty"`  // names of the providers to run, all of them if empty
+	Exclude []string `json:"exclude,omitempty"`  // names of the providers to skip
+	MaxSize int64    `json:"max_size,omitempty"` // maximum size of the collected files in bytes, 0 if it's not limited
+}
+
+// Validate returns an error if one of the providers included or excluded isn't
+// known by this process, see ProviderNames
+func (o ArchiveOptions) Validate() error {
+	known := make(map[string]bool)
+	for _, name := range ProviderNames() {
+		known[name] = true
+	}
+	for _, names := range [][]string{o.Include, o.Exclude} {
+		for _, name := range names {
+			if !known[name] {
+				return fmt.Errorf("unknown flare provider %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+func (o ArchiveOptions) selects(name string) bool {
+	for _, n := range o.Exclude {
+		if n == name {
+			return false
+		}
+	}
+	if len(o.Include) == 0 {
+		return true
+	}
+	for _, n := range o.Include {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveProvider adds content to the flare directory of the host
+type archiveProvider struct {
+	name string
+	run  func(tempDir, hostname string) error
+}
+
+// currentArchive holds the parameters of the flare being created that some
+// built-in providers need. Like the redacting writers, it's set one flare at
+// a time, while archiveLock is held.
+var currentArchive struct {
+	confSearchPaths SearchPaths
+	logFilePath     string
+	permsInfos      permissionsInfos
+}
+
+func init() {
+	provider.RegisterBuiltin("status", func(a provider.Archive) error {
+		if a.Local {
+			// Can't reach the agent, mention it in the file
+			return writeStatusFile(a.TempDir, a.Hostname, []byte("unable to get the status of the agent, is it running?"))
+		}
+		return zipStatusFile(a.TempDir, a.Hostname)
+	})
+	provider.RegisterBuiltin("config-check", func(a provider.Archive) error {
+		if a.Local {
+			return writeConfigCheck(a.TempDir, a.Hostname, []byte("unable to get loaded checks config, is the agent running?"))
+		}
+		return zipConfigCheck(a.TempDir, a.Hostname)
+	})
+	provider.RegisterBuiltin("tagger-list", func(a provider.Archive) error {
+		if a.Local {
+			return provider.ErrDisabled
+		}
+		return zipTaggerList(a.TempDir, a.Hostname)
+	})
+	provider.RegisterBuiltin("config", func(a provider.Archive) error {
+		return zipConfigFiles(a.TempDir, a.Hostname, currentArchive.confSearchPaths, currentArchive.permsInfos)
+	})
+	provider.RegisterBuiltin("expvar", builtin(zipExpVar, nil))
+	provider.RegisterBuiltin("system-probe", builtin(zipSystemProbeStats, func() bool {
+		return config.Datadog.GetBool("system_probe_config.enabled")
+	}))
+	provider.RegisterBuiltin("diagnose", builtin(zipDiagnose, nil))
+	provider.RegisterBuiltin("registry", builtin(zipRegistryJSON, nil))
+	provider.RegisterBuiltin("version-history", builtin(zipVersionHistory, nil))
+	provider.RegisterBuiltin("secrets", builtin(zipSecrets, nil))
+	provider.RegisterBuiltin("envvars", builtin(zipEnvvars, nil))
+	provider.RegisterBuiltin("health", builtin(zipHealth, nil))
+	provider.RegisterBuiltin("telemetry", builtin(zipTelemetry, func() bool {
+		return config.Datadog.GetBool("telemetry.enabled")
+	}))
+	provider.RegisterBuiltin("stack-traces", builtin(zipStackTraces, nil))
+	provider.RegisterBuiltin("docker-inspect", builtin(zipDockerSelfInspect, config.IsContainerized))
+	provider.RegisterBuiltin("docker-ps", builtin(zipDockerPs, nil))
+	provider.RegisterBuiltin("typeperf", builtin(zipTypeperfData, nil))
+	provider.RegisterBuiltin("counter-strings", builtin(zipCounterStrings, nil))
+	provider.RegisterBuiltin("logs", func(a provider.Archive) error {
+		// force a log flush before zipping them
+		log.Flush()
+		return zipLogFiles(a.TempDir, a.Hostname, currentArchive.logFilePath, currentArchive.permsInfos)
+	})
+	provider.RegisterBuiltin("install-info", builtin(zipInstallInfo, nil))
+}
+
+// builtin returns a built-in provider running zip, which is disabled when
+// enabled is set and returns false
+func builtin(zip func(tempDir, hostname string) error, enabled func() bool) provider.BuiltinProvider {
+	return func(a provider.Archive) error {
+		if enabled != nil && !enabled() {
+			return provider.ErrDisabled
+		}
+		return zip(a.TempDir, a.Hostname)
+	}
+}
+
+// builtinProviders returns the providers registered with provider.RegisterBuiltin,
+// in the order they're run
+func builtinProviders(local bool) []archiveProvider {
+	providers := []archiveProvider{}
+	for _, name := range provider.BuiltinNames() {
+		p, _ := provider.GetBuiltin(name)
+		providers = append(providers, archiveProvider{name: name, run: builtinProviderRunner(p, local)})
+	}
+	return providers
+}
+
+func builtinProviderRunner(p provider.BuiltinProvider, local bool) func(tempDir, hostname string) error {
+	return func(tempDir, hostname string) error {
+		return p(provider.Archive{TempDir: tempDir, Hostname: hostname, Local: local})
+	}
+}
+
+// registeredProviders returns the providers registered by the components of
+// the agent, their files are written under a directory named after them
+func registeredProviders(builtins []archiveProvider) []archiveProvider {
+	reserved := make(map[string]bool, len(builtins))
+	for _, p := range builtins {
+		reserved[p.name] = true
+	}
+
+	providers := []archiveProvider{}
+	for _, name := range provider.Names() {
+		if reserved[name] {
+			log.Warnf("Flare provider %s conflicts with a built-in one, ignoring it", name)
+			continue
+		}
+		p, _ := provider.Get(name)
+		providers = append(providers, archiveProvider{name: name, run: registeredProviderRunner(name, p)})
+	}
+	return providers
+}
+
+func registeredProviderRunner(name string, p provider.Provider) func(tempDir, hostname string) error {
+	return func(tempDir, hostname string) error {
+		files, err := p()
+		if err != nil {
+			return err
+		}
+
+		root := filepath.Join(tempDir, hostname, name)
+		for path, content := range files {
+			f := filepath.Join(root, filepath.Clean("/"+path))
+			if err := ensureParentDirsExist(f); err != nil {
+				return err
+			}
+			if err := writeRedacted(f, content); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func writeRedacted(f string, content []byte) error {
+	w, err := newRedactingWriter(f, os.ModePerm, true)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = w.Write(content)
+	return err
+}
+
+// ProviderNames returns the names of the providers that can be selected with
+// ArchiveOptions
+func ProviderNames() []string {
+	builtins := builtinProviders(false)
+	names := []string{}
+	for _, p := range append(builtins, registeredProviders(builtins)...) {
+		names = append(names, p.name)
+	}
+	return names
+}
+
+// manifest lists the content of a flare archive
+type manifest struct {
+	Local         bool               `json:"local"`
+	MaxSize       int64              `json:"max_size,omitempty"`
+	TotalSize     int64              `json:"total_size"`
+	Providers     []providerManifest `json:"providers"`
+	RedactedFiles []string           `json:"redacted_files"`
+}
+
+// providerManifest lists what a provider added to a flare archive
+type providerManifest struct {
+	Name    string   `json:"name"`
+	Files   []string `json:"files,omitempty"`
+	Size    int64    `json:"size"`
+	Skipped string   `json:"skipped,omitempty"` // reason why the provider didn't run or its files were dropped
+	Error   string   `json:"error,omitempty"`
+}
+
+// runProviders runs the providers selected by the options, and returns the
+// manifest of what they collected
+func runProviders(tempDir, hostname string, providers []archiveProvider, opts ArchiveOptions) manifest {
+	m := manifest{MaxSize: opts.MaxSize, Providers: []providerManifest{}}
+	root := filepath.Join(tempDir, hostname)
+
+	for _, p := range providers {
+		pm := providerManifest{Name: p.name}
+		switch {
+		case !opts.selects(p.name):
+			pm.Skipped = "not selected"
+		default:
+			before := listFiles(root)
+			if err := p.run(tempDir, hostname); err == provider.ErrDisabled {
+				pm.Skipped = "disabled"
+				break
+			} else if err != nil {
+				log.Errorf("Could not collect %s for the flare: %s", p.name, err)
+				pm.Error = err.Error()
+			}
+
+			for path, size := range listFiles(root) {
+				if _, found := before[path]; !found {
+					pm.Files = append(pm.Files, path)
+					pm.Size += size
+				}
+			}
+			sort.Strings(pm.Files)
+
+			if opts.MaxSize > 0 && m.TotalSize+pm.Size > opts.MaxSize {
+				for _, path := range pm.Files {
+					os.Remove(filepath.Join(root, path)) //nolint:errcheck
+				}
+				pm.Skipped = fmt.Sprintf("its %d bytes exceed the size limit", pm.Size)
+				pm.Files = nil
+				pm.Size = 0
+			}
+			m.TotalSize += pm.Size
+		}
+		m.Providers = append(m.Providers, pm)
+	}
+	return m
+}
+
+// listFiles returns the size of the files under root, keyed by their path
+// relative to root
+func listFiles(root string) map[string]int64 {
+	files := make(map[string]int64)
+	filepath.Walk(root, func(path string, f os.FileInfo, err error) error { //nolint:errcheck
+		if err != nil || f.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			files[filepath.ToSlash(rel)] = f.Size()
+		}
+		return nil
+	})
+	return files
+}
+
+func (m manifest) write(tempDir, hostname string) error {
+	c, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(tempDir, hostname, manifestFilename)
+	if err := ensureParentDirsExist(f); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f, c, os.ModePerm)
+}
+
+// redactingWriters records the redacting writers of the flare being
+// created, keyed by the file they write, to report the replacements made in
+// each file. It's nil when no flare is being created.
+var (
+	redactingWriters     map[string]*RedactingWriter
+	redactingWritersLock sync.Mutex
+)
+
+// trackRedactions starts recording the files written by redacting writers
+func trackRedactions() {
+	redactingWritersLock.Lock()
+	defer redactingWritersLock.Unlock()
+	redactingWriters = make(map[string]*RedactingWriter)
+}
+
+func trackRedactingWriter(f string, w *RedactingWriter) {
+	redactingWritersLock.Lock()
+	defer redactingWritersLock.Unlock()
+	if redactingWriters != nil {
+		redactingWriters[f] = w
+	}
+}
+
+// stopTrackingRedactions stops recording the files written by redacting
+// writers, and returns the replacements made in the ones under root, keyed
+// by their path relative to root
+func stopTrackingRedactions(root string) map[string]map[string]int {
+	redactingWritersLock.Lock()
+	defer redactingWritersLock.Unlock()
+
+	files := make(map[string]map[string]int)
+	for f, w := range redactingWriters {
+		counts := w.Replacements()
+		if len(counts) == 0 {
+			continue
+		}
+		if rel, err := filepath.Rel(root, f); err == nil && !strings.HasPrefix(rel, "..") {
+			if _, err := os.Stat(f); err == nil {
+				files[filepath.ToSlash(rel)] = counts
+			}
+		}
+	}
+	redactingWriters = nil
+	return files
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package flare
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/flare/provider"
+)
+
+func fileProvider(name, file string, size int) archiveProvider {
+	return archiveProvider{name: name, run: func(tempDir, hostname string) error {
+		f := filepath.Join(tempDir, hostname, file)
+		if err := ensureParentDirsExist(f); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(f, make([]byte, size), os.ModePerm)
+	}}
+}
+
+func TestArchiveOptionsSelects(t *testing.T) {
+	assert.True(t, ArchiveOptions{}.selects("logs"))
+	assert.False(t, ArchiveOptions{Exclude: []string{"logs"}}.selects("logs"))
+	assert.True(t, ArchiveOptions{Include: []string{"logs"}}.selects("logs"))
+	assert.False(t, ArchiveOptions{Include: []string{"status"}}.selects("logs"))
+	assert.False(t, ArchiveOptions{Include: []string{"logs"}, Exclude: []string{"logs"}}.selects("logs"))
+}
+
+func TestArchiveOptionsValidate(t *testing.T) {
+	assert.NoError(t, ArchiveOptions{}.Validate())
+	assert.NoError(t, ArchiveOptions{Include: []string{"status"}, Exclude: []string{"logs"}}.Validate())
+	assert.EqualError(t, ArchiveOptions{Include: []string{"unknown"}}.Validate(), `unknown flare provider "unknown"`)
+	assert.EqualError(t, ArchiveOptions{Exclude: []string{"unknown"}}.Validate(), `unknown flare provider "unknown"`)
+}
+
+func TestRunProviders(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "flare")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	providers := []archiveProvider{
+		fileProvider("status", "status.log", 10),
+		fileProvider("excluded", "excluded.log", 10),
+		{name: "disabled", run: func(string, string) error { return provider.ErrDisabled }},
+		{name: "failing", run: func(string, string) error { return errors.New("boom") }},
+		fileProvider("logs", "logs/agent.log", 100),
+		fileProvider("health", "health.yaml", 20),
+	}
+
+	m := runProviders(tempDir, "host", providers, ArchiveOptions{Exclude: []string{"excluded"}, MaxSize: 50})
+
+	assert.Equal(t, []providerManifest{
+		{Name: "status", Files: []string{"status.log"}, Size: 10},
+		{Name: "excluded", Skipped: "not selected"},
+		{Name: "disabled", Skipped: "disabled"},
+		{Name: "failing", Error: "boom"},
+		{Name: "logs", Skipped: "its 100 bytes exceed the size limit"},
+		{Name: "health", Files: []string{"health.yaml"}, Size: 20},
+	}, m.Providers)
+	assert.Equal(t, int64(30), m.TotalSize)
+
+	// the files of the providers over the size limit are dropped
+	assert.Equal(t, map[string]int64{"status.log": 10, "health.yaml": 20}, listFiles(filepath.Join(tempDir, "host")))
+}
+
+func TestRegisteredProvider(t *testing.T) {
+	provider.Register("test-component", func() (map[string][]byte, error) {
+		return map[string][]byte{
+			"state.yaml":        []byte("api_key: aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\n"),
+			"../../escape.yaml": []byte("state: ok\n"),
+		}, nil
+	})
+	provider.Register("status", func() (map[string][]byte, error) {
+		return map[string][]byte{"overridden.log": []byte("oops")}, nil
+	})
+	defer delete(provider.DefaultCatalog, "test-component")
+	defer delete(provider.DefaultCatalog, "status")
+
+	assert.Contains(t, ProviderNames(), "test-component")
+
+	zipFilePath := getArchivePath()
+	_, err := createArchive(zipFilePath, true, SearchPaths{}, "", ArchiveOptions{Include: []string{"test-component", "status"}})
+	require.NoError(t, err)
+	defer os.Remove(zipFilePath)
+
+	z, err := zip.OpenReader(zipFilePath)
+	require.NoError(t, err)
+	defer z.Close()
+
+	var m manifest
+	files := []string{}
+	for _, f := range z.File {
+		parts := strings.SplitN(filepath.ToSlash(f.Name), "/", 2)
+		if len(parts) < 2 || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		files = append(files, parts[1])
+		if parts[1] == manifestFilename {
+			r, err := f.Open()
+			require.NoError(t, err)
+			require.NoError(t, json.NewDecoder(r).Decode(&m))
+			r.Close()
+		}
+	}
+
+	// the files of the provider stay in its directory, and the built-in
+	// providers can't be overridden
+	assert.Contains(t, files, "test-component/state.yaml")
+	assert.Contains(t, files, "test-component/escape.yaml")
+	assert.NotContains(t, files, "status/overridden.log")
+
+	assert.True(t, m.Local)
+	assert.Equal(t, []string{"test-component/state.yaml"}, m.RedactedFiles)
+	for _, pm := range m.Providers {
+		switch pm.Name {
+		case "test-component":
+			assert.Equal(t, []string{"test-component/escape.yaml", "test-component/state.yaml"}, pm.Files)
+		case "status":
+			assert.Equal(t, []string{"status.log"}, pm.Files)
+		default:
+			assert.NotEmpty(t, pm.Skipped, pm.Name)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package flare
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	scrubReportFilename = "scrub_report.json"
+
+	defaultScrubbingReplacement = "********"
+)
+
+// scrubbingRuleConfig is a user-defined scrubbing rule from the
+// `flare_scrubbing_rules` setting, matching either a regex or the values of
+// YAML keys
+type scrubbingRuleConfig struct {
+	Name        string   `mapstructure:"name"`
+	Regex       string   `mapstructure:"regex"`
+	Replacement string   `mapstructure:"replacement"`
+	YAMLKeys    []string `mapstructure:"yaml_keys"`
+}
+
+// scrubReport lists the replacements made in the files of a flare archive
+type scrubReport struct {
+	Rules  []string                  `json:"rules"`  // names of the user-defined rules
+	Files  map[string]map[string]int `json:"files"`  // replacements made by each rule, per file
+	Totals map[string]int            `json:"totals"` // replacements made by each rule
+}
+
+// loadScrubbingRules returns the user-defined scrubbing rules
+func loadScrubbingRules() ([]log.Replacer, error) {
+	var rules []scrubbingRuleConfig
+	if err := config.Datadog.UnmarshalKey("flare_scrubbing_rules", &rules); err != nil {
+		return nil, fmt.Errorf("invalid flare_scrubbing_rules: %v", err)
+	}
+
+	replacers := make([]log.Replacer, 0, len(rules))
+	names := make(map[string]bool)
+	for i, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rule_%d", i)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("invalid flare_scrubbing_rules: duplicate rule name %q", name)
+		}
+		names[name] = true
+
+		switch {
+		case rule.Regex != "" && len(rule.YAMLKeys) > 0:
+			return nil, fmt.Errorf("invalid flare_scrubbing_rules: rule %q has both a regex and YAML keys", name)
+		case rule.Regex != "":
+			rx, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid flare_scrubbing_rules: rule %q: %v", name, err)
+			}
+			replacement := rule.Replacement
+			if replacement == "" {
+				replacement = defaultScrubbingReplacement
+			}
+			replacers = append(replacers, log.Replacer{Name: name, Regex: rx, Repl: []byte(replacement)})
+		case len(rule.YAMLKeys) > 0:
+			replacers = append(replacers, log.NewYAMLKeysReplacer(name, rule.YAMLKeys))
+		default:
+			return nil, fmt.Errorf("invalid flare_scrubbing_rules: rule %q has neither a regex nor YAML keys", name)
+		}
+	}
+	return replacers, nil
+}
+
+// applyScrubbingRules applies the rules to every file under root, and adds
+// the replacements they made to the report
+func applyScrubbingRules(root string, rules []log.Replacer, report *scrubReport) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return filepath.Walk(root, func(p string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		counts := make(map[string]int)
+		for _, rule := range rules {
+			content = rule.Replace(content, counts)
+		}
+		if len(counts) == 0 {
+			return nil
+		}
+
+		if err := ioutil.WriteFile(p, content, f.Mode()); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		report.add(filepath.ToSlash(rel), counts)
+		return nil
+	})
+}
+
+func newScrubReport(rules []log.Replacer) *scrubReport {
+	r := &scrubReport{
+		Rules:  []string{},
+		Files:  make(map[string]map[string]int),
+		Totals: make(map[string]int),
+	}
+	for _, rule := range rules {
+		r.Rules = append(r.Rules, rule.Name)
+	}
+	return r
+}
+
+func (r *scrubReport) add(file string, counts map[string]int) {
+	if _, found := r.Files[file]; !found {
+		r.Files[file] = make(map[string]int)
+	}
+	for name, n := range counts {
+		r.Files[file][name] += n
+		r.Totals[name] += n
+	}
+}
+
+// files returns the sorted paths of the files with replacements
+func (r *scrubReport) files() []string {
+	files := make([]string, 0, len(r.Files))
+	for f := range r.Files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (r *scrubReport) write(tempDir, hostname string) error {
+	c, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(tempDir, hostname, scrubReportFilename)
+	if err := ensureParentDirsExist(f); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f, c, os.ModePerm)
+}
+
+// DescribeArchive writes the list of the files of a flare archive to w, with
+// the replacements made in each of them
+func DescribeArchive(zipFilePath string, w io.Writer) error {
+	z, err := zip.OpenReader(zipFilePath)
+	if err != nil {
+		return err
+	}
+	defer z.Close()
+
+	report := newScrubReport(nil)
+	for _, f := range z.File {
+		if path.Base(f.Name) != scrubReportFilename {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = json.NewDecoder(r).Decode(report)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("invalid scrub report: %v", err)
+		}
+	}
+
+	var files int
+	var total uint64
+	for _, f := range z.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		files++
+		total += f.UncompressedSize64
+
+		// the paths of the report are relative to the host directory
+		name := filepath.ToSlash(f.Name)
+		rel := name
+		if i := strings.Index(name, "/"); i >= 0 {
+			rel = name[i+1:]
+		}
+
+		line := fmt.Sprintf("%s (%d bytes)", name, f.UncompressedSize64)
+		if counts, found := report.Files[rel]; found {
+			line += ", redacted: " + formatCounts(counts)
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "\n%d files, %d bytes\n", files, total)
+	if len(report.Totals) > 0 {
+		fmt.Fprintf(w, "Replacements: %s\n", formatCounts(report.Totals))
+	}
+	return nil
+}
+
+func formatCounts(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package flare
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestLoadScrubbingRules(t *testing.T) {
+	mockConfig := config.Mock()
+
+	rules, err := loadScrubbingRules()
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	mockConfig.Set("flare_scrubbing_rules", []map[string]interface{}{
+		{"name": "tokens", "regex": `itk_[a-z0-9]+`},
+		{"yaml_keys": []string{"dsn"}},
+	})
+	rules, err = loadScrubbingRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "tokens", rules[0].Name)
+	assert.Equal(t, []byte(defaultScrubbingReplacement), rules[0].Repl)
+	assert.Equal(t, "rule_1", rules[1].Name)
+
+	for _, invalid := range [][]map[string]interface{}{
+		{{"name": "bad", "regex": `(`}},
+		{{"name": "empty"}},
+		{{"name": "both", "regex": "a", "yaml_keys": []string{"b"}}},
+		{{"name": "dup", "regex": "a"}, {"name": "dup", "regex": "b"}},
+	} {
+		mockConfig.Set("flare_scrubbing_rules", invalid)
+		_, err = loadScrubbingRules()
+		assert.Error(t, err)
+	}
+}
+
+func TestApplyScrubbingRules(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("flare_scrubbing_rules", []map[string]interface{}{
+		{"name": "tokens", "regex": `itk_[a-z0-9]+`, "replacement": "itk_****"},
+		{"name": "dsn", "yaml_keys": []string{"dsn"}},
+	})
+	rules, err := loadScrubbingRules()
+	require.NoError(t, err)
+
+	root, err := ioutil.TempDir("", "flare")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "logs"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "logs", "agent.log"), []byte("token itk_abc1 and itk_def2\n"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "check.yaml"), []byte("dsn: postgres://db\nhost: db\n"), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "clean.log"), []byte("nothing here\n"), os.ModePerm))
+
+	report := newScrubReport(rules)
+	report.add("status.log", map[string]int{"api_key": 1})
+	require.NoError(t, applyScrubbingRules(root, rules, report))
+
+	content, err := ioutil.ReadFile(filepath.Join(root, "logs", "agent.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "token itk_**** and itk_****\n", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(root, "check.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "dsn: ********\nhost: db\n", string(content))
+
+	assert.Equal(t, []string{"tokens", "dsn"}, report.Rules)
+	assert.Equal(t, map[string]map[string]int{
+		"status.log":     {"api_key": 1},
+		"logs/agent.log": {"tokens": 2},
+		"check.yaml":     {"dsn": 1},
+	}, report.Files)
+	assert.Equal(t, map[string]int{"api_key": 1, "tokens": 2, "dsn": 1}, report.Totals)
+	assert.Equal(t, []string{"check.yaml", "logs/agent.log", "status.log"}, report.files())
+}
+
+func TestDescribeArchive(t *testing.T) {
+	config.Mock()
+
+	zipFilePath := getArchivePath()
+	_, err := createArchive(zipFilePath, true, SearchPaths{}, "", ArchiveOptions{Include: []string{"status"}})
+	require.NoError(t, err)
+	defer os.Remove(zipFilePath)
+
+	var b bytes.Buffer
+	require.NoError(t, DescribeArchive(zipFilePath, &b))
+	assert.Contains(t, b.String(), "/status.log (")
+	assert.Contains(t, b.String(), "/"+scrubReportFilename+" (")
+	assert.Contains(t, b.String(), "/"+manifestFilename+" (")
+}
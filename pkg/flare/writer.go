@@ -21,6 +21,7 @@ type RedactingWriter struct {
 	targetBuf *bufio.Writer
 	perm      os.FileMode
 	r         []log.Replacer
+	counts    map[string]int // number of replacements made by each replacer
 }
 
 //NewRedactingWriter instantiates a RedactingWriter to target with given permissions
@@ -78,17 +79,24 @@ func (f *RedactingWriter) Write(p []byte) (int, error) {
 		return 0, errors.New("No viable target defined")
 	}
 
-	cleaned, err := log.CredentialsCleanerBytes(p)
+	cleaned, counts, err := log.CredentialsCleanerBytesWithCounts(p)
 	if err != nil {
 		return 0, err
 	}
 
 	for _, r := range f.r {
 		if r.Regex != nil && r.ReplFunc != nil {
-			cleaned = r.Regex.ReplaceAllFunc(cleaned, r.ReplFunc)
+			cleaned = r.Replace(cleaned, counts)
 		}
 	}
 
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+	for name, n := range counts {
+		f.counts[name] += n
+	}
+
 	var n int
 	if buffered {
 		n, err = f.targetBuf.Write(cleaned)
@@ -103,6 +111,15 @@ func (f *RedactingWriter) Write(p []byte) (int, error) {
 	return len(p), err
 }
 
+//Replacements returns the number of replacements made so far by each replacer
+func (f *RedactingWriter) Replacements() map[string]int {
+	counts := make(map[string]int, len(f.counts))
+	for name, n := range f.counts {
+		counts[name] = n
+	}
+	return counts
+}
+
 //Truncate truncates the file of the target file to the specified size
 func (f *RedactingWriter) Truncate(size int64) error {
 	return f.target.Truncate(size)
@@ -52,6 +52,7 @@ type domainForwarder struct {
 	stopRetry               chan bool
 	stopConnectionReset     chan bool
 	workers                 []*Worker
+	workersLock             sync.Mutex // To control updates of the workers while they reset their connections
 	retryQueue              []Transaction
 	retryQueueLimit         int
 	connectionResetInterval time.Duration
@@ -155,9 +156,11 @@ func (f *domainForwarder) scheduleConnectionResets() {
 		select {
 		case <-ticker.C:
 			log.Debugf("Scheduling reset of connections used for domain: %q", f.domain)
+			f.workersLock.Lock()
 			for _, worker := range f.workers {
 				worker.ScheduleConnectionReset()
 			}
+			f.workersLock.Unlock()
 		case <-f.stopConnectionReset:
 			ticker.Stop()
 			return
@@ -171,10 +174,16 @@ func (f *domainForwarder) init() {
 	f.requeuedTransaction = make(chan Transaction, chanBufferSize)
 	f.stopRetry = make(chan bool)
 	f.stopConnectionReset = make(chan bool)
-	f.workers = []*Worker{}
+	f.setWorkers([]*Worker{})
 	f.retryQueue = []Transaction{}
 }
 
+func (f *domainForwarder) setWorkers(workers []*Worker) {
+	f.workersLock.Lock()
+	defer f.workersLock.Unlock()
+	f.workers = workers
+}
+
 // Start starts a domainForwarder.
 func (f *domainForwarder) Start() error {
 	// Lock so we can't stop a Forwarder while is starting
@@ -188,11 +197,7 @@ func (f *domainForwarder) Start() error {
 	// reset internal state to purge transactions from past starts
 	f.init()
 
-	for i := 0; i < f.numberOfWorkers; i++ {
-		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
-		w.Start()
-		f.workers = append(f.workers, w)
-	}
+	f.startWorkers(f.numberOfWorkers)
 	go f.handleFailedTransactions()
 	if f.connectionResetInterval != 0 {
 		go f.scheduleConnectionResets()
@@ -220,7 +225,7 @@ func (f *domainForwarder) Stop(purgeHighPrio bool) {
 	for _, w := range f.workers {
 		w.Stop(purgeHighPrio)
 	}
-	f.workers = []*Worker{}
+	f.setWorkers([]*Worker{})
 	f.retryQueue = []Transaction{}
 	close(f.highPrio)
 	close(f.lowPrio)
@@ -229,6 +234,38 @@ func (f *domainForwarder) Stop(purgeHighPrio bool) {
 	f.internalState = Stopped
 }
 
+func (f *domainForwarder) startWorkers(count int) {
+	workers := append([]*Worker{}, f.workers...)
+	for i := 0; i < count; i++ {
+		w := NewWorker(f.highPrio, f.lowPrio, f.requeuedTransaction, f.blockedList)
+		w.Start()
+		workers = append(workers, w)
+	}
+	f.setWorkers(workers)
+}
+
+// SetNumberOfWorkers starts or stops workers so that the domainForwarder
+// has the given number of workers. The transactions of the stopped workers
+// stay in the queues, to be processed by the remaining ones.
+func (f *domainForwarder) SetNumberOfWorkers(numberOfWorkers int) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.numberOfWorkers = numberOfWorkers
+	if f.internalState == Stopped {
+		return
+	}
+
+	if len(f.workers) < numberOfWorkers {
+		f.startWorkers(numberOfWorkers - len(f.workers))
+		return
+	}
+	for _, w := range f.workers[numberOfWorkers:] {
+		w.Stop(false)
+	}
+	f.setWorkers(f.workers[:numberOfWorkers])
+}
+
 func (f *domainForwarder) State() uint32 {
 	// Lock so we can't start/stop a Forwarder while getting its state
 	f.m.Lock()
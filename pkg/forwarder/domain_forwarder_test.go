@@ -79,6 +79,27 @@ func TestDomainForwarderStop_WithConnectionReset(t *testing.T) {
 	assert.Equal(t, Stopped, forwarder.State())
 }
 
+func TestDomainForwarderSetNumberOfWorkers(t *testing.T) {
+	forwarder := newDomainForwarder("test", 2, 10, 0)
+
+	// applied when the forwarder starts
+	forwarder.SetNumberOfWorkers(3)
+	assert.Len(t, forwarder.workers, 0)
+	require.Nil(t, forwarder.Start())
+	assert.Len(t, forwarder.workers, 3)
+
+	forwarder.SetNumberOfWorkers(5)
+	assert.Len(t, forwarder.workers, 5)
+	assert.Equal(t, 5, forwarder.numberOfWorkers)
+
+	forwarder.SetNumberOfWorkers(1)
+	assert.Len(t, forwarder.workers, 1)
+	assert.Equal(t, 1, forwarder.numberOfWorkers)
+
+	forwarder.Stop(false)
+	assert.Len(t, forwarder.workers, 0)
+}
+
 func TestDomainForwarderSubmitIfStopped(t *testing.T) {
 	forwarder := newDomainForwarder("test", 1, 10, 0)
 
@@ -194,6 +194,7 @@ type DefaultForwarder struct {
 
 	domainForwarders map[string]*domainForwarder
 	keysPerDomains   map[string][]string
+	keysLock         sync.RWMutex // To update the API keys while creating transactions
 	healthChecker    *forwarderHealth
 	internalState    uint32
 	m                sync.Mutex // To control Start/Stop races
@@ -241,11 +242,13 @@ func (f *DefaultForwarder) Start() error {
 	}
 
 	// log endpoints configuration
+	f.keysLock.RLock()
 	endpointLogs := make([]string, 0, len(f.keysPerDomains))
 	for domain, apiKeys := range f.keysPerDomains {
 		endpointLogs = append(endpointLogs, fmt.Sprintf("\"%s\" (%v api key(s))",
 			domain, len(apiKeys)))
 	}
+	f.keysLock.RUnlock()
 	log.Infof("Forwarder started, sending to %v endpoint(s) with %v worker(s) each: %s",
 		len(endpointLogs), f.NumberOfWorkers, strings.Join(endpointLogs, " ; "))
 
@@ -313,7 +316,59 @@ func (f *DefaultForwarder) State() uint32 {
 	return f.internalState
 }
 
+// SetNumberOfWorkers changes the number of workers sending the transactions
+// of each domain, without dropping the pending transactions
+func (f *DefaultForwarder) SetNumberOfWorkers(numberOfWorkers int) error {
+	if numberOfWorkers < 1 {
+		return fmt.Errorf("the forwarder needs at least one worker per domain, got %d", numberOfWorkers)
+	}
+
+	// Lock so we can't start/stop a Forwarder while updating its workers
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	f.NumberOfWorkers = numberOfWorkers
+	for _, df := range f.domainForwarders {
+		df.SetNumberOfWorkers(numberOfWorkers)
+	}
+	return nil
+}
+
+// UpdateAPIKey replaces an API key by a new one for every domain, the
+// transactions created afterwards are sent with the new key
+func (f *DefaultForwarder) UpdateAPIKey(oldKey, newKey string) {
+	f.keysLock.Lock()
+	f.keysPerDomains = replaceAPIKey(f.keysPerDomains, oldKey, newKey)
+	f.keysLock.Unlock()
+
+	f.m.Lock()
+	defer f.m.Unlock()
+	if f.healthChecker != nil {
+		f.healthChecker.updateAPIKey(oldKey, newKey)
+	}
+}
+
+// replaceAPIKey returns a copy of keysPerDomains where oldKey is replaced by newKey,
+// the original map is left untouched as it can be shared with other components
+func replaceAPIKey(keysPerDomains map[string][]string, oldKey, newKey string) map[string][]string {
+	updated := make(map[string][]string, len(keysPerDomains))
+	for domain, apiKeys := range keysPerDomains {
+		keys := make([]string, 0, len(apiKeys))
+		for _, apiKey := range apiKeys {
+			if apiKey == oldKey {
+				apiKey = newKey
+			}
+			keys = append(keys, apiKey)
+		}
+		updated[domain] = keys
+	}
+	return updated
+}
+
 func (f *DefaultForwarder) createHTTPTransactions(endpoint endpoint, payloads Payloads, apiKeyInQueryString bool, extra http.Header) []*HTTPTransaction {
+	f.keysLock.RLock()
+	defer f.keysLock.RUnlock()
+
 	transactions := make([]*HTTPTransaction, 0, len(payloads)*len(f.keysPerDomains))
 	for _, payload := range payloads {
 		for domain, apiKeys := range f.keysPerDomains {
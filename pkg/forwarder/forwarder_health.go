@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -54,6 +55,7 @@ type forwarderHealth struct {
 	timeout               time.Duration
 	keysPerDomains        map[string][]string
 	keysPerAPIEndpoint    map[string][]string
+	keysLock              sync.Mutex // To update the API keys while validating them
 	disableAPIKeyChecking bool
 	validationInterval    time.Duration
 }
@@ -62,6 +64,9 @@ func (fh *forwarderHealth) init() {
 	fh.stop = make(chan bool, 1)
 	fh.stopped = make(chan struct{})
 
+	fh.keysLock.Lock()
+	defer fh.keysLock.Unlock()
+
 	fh.keysPerAPIEndpoint = make(map[string][]string)
 	fh.computeDomainsURL()
 
@@ -141,6 +146,17 @@ func (fh *forwarderHealth) computeDomainsURL() {
 	}
 }
 
+// updateAPIKey replaces an API key by a new one, it is validated on the next check
+func (fh *forwarderHealth) updateAPIKey(oldKey, newKey string) {
+	fh.keysLock.Lock()
+	defer fh.keysLock.Unlock()
+
+	fh.keysPerDomains = replaceAPIKey(fh.keysPerDomains, oldKey, newKey)
+	if fh.keysPerAPIEndpoint != nil {
+		fh.keysPerAPIEndpoint = replaceAPIKey(fh.keysPerAPIEndpoint, oldKey, newKey)
+	}
+}
+
 func (fh *forwarderHealth) setAPIKeyStatus(apiKey string, domain string, status expvar.Var) {
 	if len(apiKey) > 5 {
 		apiKey = apiKey[len(apiKey)-5:]
@@ -196,7 +212,12 @@ func (fh *forwarderHealth) hasValidAPIKey() bool {
 	validKey := false
 	apiError := false
 
-	for domain, apiKeys := range fh.keysPerAPIEndpoint {
+	// The keys are validated without holding the lock as it makes HTTP calls
+	fh.keysLock.Lock()
+	keysPerAPIEndpoint := fh.keysPerAPIEndpoint
+	fh.keysLock.Unlock()
+
+	for domain, apiKeys := range keysPerAPIEndpoint {
 		for _, apiKey := range apiKeys {
 			v, err := fh.validateAPIKey(apiKey, domain)
 			if err != nil {
@@ -144,6 +144,25 @@ func TestCreateHTTPTransactions(t *testing.T) {
 	assert.Contains(t, transactions[3].Endpoint, "api_key=api-key-2")
 }
 
+func TestUpdateAPIKey(t *testing.T) {
+	forwarder := NewDefaultForwarder(NewOptions(keysPerDomains))
+
+	forwarder.UpdateAPIKey("api-key-2", "api-key-3")
+	assert.Equal(t, map[string][]string{
+		testVersionDomain: {"api-key-1", "api-key-3"},
+	}, forwarder.keysPerDomains)
+	assert.Equal(t, []string{"api-key-1", "api-key-3"}, forwarder.healthChecker.keysPerDomains[testDomain])
+	// the options given to the forwarder are not modified
+	assert.Equal(t, []string{"api-key-1", "api-key-2"}, keysPerDomains[testDomain])
+
+	endpoint := endpoint{"/api/foo", "foo"}
+	p1 := []byte("A payload")
+	transactions := forwarder.createHTTPTransactions(endpoint, Payloads{&p1}, false, make(http.Header))
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "api-key-1", transactions[0].Headers.Get("DD-Api-Key"))
+	assert.Equal(t, "api-key-3", transactions[1].Headers.Get("DD-Api-Key"))
+}
+
 func TestSendHTTPTransactions(t *testing.T) {
 	forwarder := NewDefaultForwarder(NewOptions(keysPerDomains))
 	endpoint := endpoint{"/api/foo", "foo"}
@@ -86,6 +86,11 @@ func (a *Agent) Start() {
 	starter.Start()
 }
 
+// SetProcessingRules replaces the global processing rules of the pipelines
+func (a *Agent) SetProcessingRules(processingRules []*config.ProcessingRule) {
+	a.pipelineProvider.SetProcessingRules(processingRules)
+}
+
 // Stop stops all the elements of the data pipeline
 // in the right order to prevent data loss
 func (a *Agent) Stop() {
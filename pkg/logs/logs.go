@@ -123,6 +123,21 @@ func Stop() {
 	log.Info("logs-agent stopped")
 }
 
+// SetGlobalProcessingRules validates the global processing rules, and applies
+// them to the logs processed by the running logs-agent.
+func SetGlobalProcessingRules(rules []*config.ProcessingRule) error {
+	if err := config.ValidateProcessingRules(rules); err != nil {
+		return err
+	}
+	if err := config.CompileProcessingRules(rules); err != nil {
+		return err
+	}
+	if IsAgentRunning() && agent != nil {
+		agent.SetProcessingRules(rules)
+	}
+	return nil
+}
+
 // IsAgentRunning returns true if the logs-agent is running.
 func IsAgentRunning() bool {
 	return status.Get().IsRunning
@@ -6,6 +6,7 @@
 package mock
 
 import (
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 )
@@ -28,6 +29,9 @@ func (p *mockProvider) Start() {}
 // Stop does nothing
 func (p *mockProvider) Stop() {}
 
+// SetProcessingRules does nothing
+func (p *mockProvider) SetProcessingRules(processingRules []*config.ProcessingRule) {}
+
 // NextPipelineChan returns the next pipeline
 func (p *mockProvider) NextPipelineChan() chan *message.Message {
 	return p.msgChan
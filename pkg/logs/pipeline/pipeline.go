@@ -76,6 +76,11 @@ func (p *Pipeline) Start() {
 	p.processor.Start()
 }
 
+// SetProcessingRules replaces the global processing rules of the pipeline
+func (p *Pipeline) SetProcessingRules(processingRules []*config.ProcessingRule) {
+	p.processor.SetProcessingRules(processingRules)
+}
+
 // Stop stops the pipeline
 func (p *Pipeline) Stop() {
 	p.processor.Stop()
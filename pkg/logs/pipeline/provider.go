@@ -6,6 +6,7 @@
 package pipeline
 
 import (
+	"sync"
 	"sync/atomic"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
@@ -20,6 +21,7 @@ type Provider interface {
 	Start()
 	Stop()
 	NextPipelineChan() chan *message.Message
+	SetProcessingRules(processingRules []*config.ProcessingRule)
 }
 
 // provider implements providing logic
@@ -33,6 +35,10 @@ type provider struct {
 	pipelines            []*Pipeline
 	currentPipelineIndex int32
 	destinationsContext  *client.DestinationsContext
+
+	// protects the processing rules and the pipelines from being updated
+	// while the pipelines start or stop
+	mu sync.Mutex
 }
 
 // NewProvider returns a new Provider
@@ -49,6 +55,9 @@ func NewProvider(numberOfPipelines int, auditor *auditor.Auditor, processingRule
 
 // Start initializes the pipelines
 func (p *provider) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	// This requires the auditor to be started before.
 	p.outputChan = p.auditor.Channel()
 
@@ -62,6 +71,9 @@ func (p *provider) Start() {
 // Stop stops all pipelines in parallel,
 // this call blocks until all pipelines are stopped
 func (p *provider) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	stopper := restart.NewParallelStopper()
 	for _, pipeline := range p.pipelines {
 		stopper.Add(pipeline)
@@ -71,6 +83,18 @@ func (p *provider) Stop() {
 	p.outputChan = nil
 }
 
+// SetProcessingRules replaces the global processing rules of the pipelines,
+// the ones started later use them too
+func (p *provider) SetProcessingRules(processingRules []*config.ProcessingRule) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.processingRules = processingRules
+	for _, pipeline := range p.pipelines {
+		pipeline.SetProcessingRules(processingRules)
+	}
+}
+
 // NextPipelineChan returns the next pipeline input channel
 func (p *provider) NextPipelineChan() chan *message.Message {
 	pipelinesLen := len(p.pipelines)
@@ -6,6 +6,8 @@
 package processor
 
 import (
+	"sync"
+
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
@@ -19,6 +21,7 @@ type Processor struct {
 	inputChan       chan *message.Message
 	outputChan      chan *message.Message
 	processingRules []*config.ProcessingRule
+	rulesLock       sync.RWMutex
 	encoder         Encoder
 	done            chan struct{}
 }
@@ -34,6 +37,14 @@ func New(inputChan, outputChan chan *message.Message, processingRules []*config.
 	}
 }
 
+// SetProcessingRules replaces the global processing rules applied to the
+// messages, the rules of the sources still apply.
+func (p *Processor) SetProcessingRules(processingRules []*config.ProcessingRule) {
+	p.rulesLock.Lock()
+	defer p.rulesLock.Unlock()
+	p.processingRules = processingRules
+}
+
 // Start starts the Processor.
 func (p *Processor) Start() {
 	go p.run()
@@ -74,7 +85,9 @@ func (p *Processor) run() {
 // and a copy of the message with some fields redacted, depending on config
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 	content := msg.Content
-	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
+	p.rulesLock.RLock()
+	rules := append(p.processingRules[:len(p.processingRules):len(p.processingRules)], msg.Origin.LogSource.Config.ProcessingRules...)
+	p.rulesLock.RUnlock()
 	for _, rule := range rules {
 		switch rule.Type {
 		case config.ExcludeAtMatch:
@@ -138,6 +138,24 @@ func TestTruncate(t *testing.T) {
 	assert.Equal(t, []byte("hello"), redactedMessage)
 }
 
+func TestSetProcessingRules(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{newProcessingRule("exclude_at_match", "", "world")}}
+	source := config.LogSource{Config: &config.LogsConfig{}}
+
+	shouldProcess, _ := p.applyRedactingRules(newMessage([]byte("hello world"), &source, ""))
+	assert.Equal(t, false, shouldProcess)
+
+	p.SetProcessingRules([]*config.ProcessingRule{newProcessingRule("mask_sequences", "[masked]", "world")})
+	shouldProcess, redactedMessage := p.applyRedactingRules(newMessage([]byte("hello world"), &source, ""))
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte("hello [masked]"), redactedMessage)
+
+	// the rules of the source still apply
+	source = newSource("exclude_at_match", "", "hello")
+	shouldProcess, _ = p.applyRedactingRules(newMessage([]byte("hello world"), &source, ""))
+	assert.Equal(t, false, shouldProcess)
+}
+
 func newProcessingRule(ruleType, replacePlaceholder, pattern string) *config.ProcessingRule {
 	return &config.ProcessingRule{
 		Type:               ruleType,
@@ -163,11 +163,11 @@ func (s *Scheduler) toSources(config integration.Config) ([]*logsConfig.LogSourc
 	var err error
 
 	switch config.Provider {
-	case names.File:
-		// config defined in a file
+	case names.File, names.KubeConfigMaps:
+		// config defined in a file or in a configmap
 		configs, err = logsConfig.ParseYAML(config.LogsConfig)
-	case names.Docker, names.Kubernetes:
-		// config attached to a docker label or a pod annotation
+	case names.Docker, names.Kubernetes, names.HTTP:
+		// config attached to a docker label or a pod annotation, or served by an HTTP endpoint
 		configs, err = logsConfig.ParseJSON(config.LogsConfig)
 	default:
 		// invalid provider
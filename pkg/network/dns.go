@@ -6,6 +6,7 @@ import "github.com/DataDog/datadog-agent/pkg/process/util"
 type ReverseDNS interface {
 	Resolve([]ConnectionStats) map[util.Address][]string
 	GetDNSStats() map[dnsKey]dnsStats
+	GetDNSDomainStats() map[string]DNSDomainStats
 	GetStats() map[string]int64
 	Close()
 }
@@ -25,6 +26,10 @@ func (nullReverseDNS) GetDNSStats() map[dnsKey]dnsStats {
 	return nil
 }
 
+func (nullReverseDNS) GetDNSDomainStats() map[string]DNSDomainStats {
+	return nil
+}
+
 func (nullReverseDNS) GetStats() map[string]int64 {
 	return map[string]int64{
 		"lookups":           0,
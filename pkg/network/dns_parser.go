@@ -27,9 +27,11 @@ type dnsParser struct {
 	tcpPayload      *tcpWithDNSSupport
 	dnsPayload      *layers.DNS
 	collectDNSStats bool
+	// collectDomains is only relevant when collectDNSStats is set
+	collectDomains bool
 }
 
-func newDNSParser(collectDNStats bool) *dnsParser {
+func newDNSParser(collectDNStats bool, collectDomains bool) *dnsParser {
 	ipv4Payload := &layers.IPv4{}
 	ipv6Payload := &layers.IPv6{}
 	udpPayload := &layers.UDP{}
@@ -53,6 +55,7 @@ func newDNSParser(collectDNStats bool) *dnsParser {
 		tcpPayload:      tcpPayload,
 		dnsPayload:      dnsPayload,
 		collectDNSStats: collectDNStats,
+		collectDomains:  collectDNStats && collectDomains,
 	}
 }
 
@@ -140,9 +143,15 @@ func (p *dnsParser) parseAnswerInto(
 	// Only consider responses
 	if !dns.QR {
 		pktInfo.pktType = Query
+		// the domain is recorded along with the query so that it is also
+		// known for queries which never get a response
+		if p.collectDomains {
+			pktInfo.domain = string(question.Name)
+		}
 		return nil
 	}
 
+	pktInfo.rCode = uint8(dns.ResponseCode)
 	if dns.ResponseCode != 0 {
 		pktInfo.pktType = FailedResponse
 		return nil
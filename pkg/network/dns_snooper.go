@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/google/gopacket/afpacket"
 	bpflib "github.com/iovisor/gobpf/elf"
+	"golang.org/x/net/bpf"
 )
 
 const (
@@ -53,6 +54,8 @@ func NewSocketFilterSnooper(
 	collectDNSStats bool,
 	collectLocalDNS bool,
 	dnsTimeout time.Duration,
+	collectDNSDomains bool,
+	maxDNSDomains int,
 ) (*SocketFilterSnooper, error) {
 
 	var (
@@ -74,11 +77,14 @@ func NewSocketFilterSnooper(
 	cache := newReverseDNSCache(dnsCacheSize, dnsCacheTTL, dnsCacheExpirationPeriod)
 	var statKeeper *dnsStatKeeper
 	if collectDNSStats {
-		statKeeper = newDNSStatkeeper(dnsTimeout)
+		if !collectDNSDomains {
+			maxDNSDomains = 0
+		}
+		statKeeper = newDNSStatkeeper(dnsTimeout, maxDNSDomains)
 	}
 	snooper := &SocketFilterSnooper{
 		source:          packetSrc,
-		parser:          newDNSParser(collectDNSStats),
+		parser:          newDNSParser(collectDNSStats, collectDNSDomains),
 		cache:           cache,
 		statKeeper:      statKeeper,
 		translation:     new(translation),
@@ -115,6 +121,14 @@ func (s *SocketFilterSnooper) GetDNSStats() map[dnsKey]dnsStats {
 	return s.statKeeper.GetAndResetAllStats()
 }
 
+// GetDNSDomainStats returns the per-domain stats of the queries answered since the last call to GetDNSStats
+func (s *SocketFilterSnooper) GetDNSDomainStats() map[string]DNSDomainStats {
+	if s.statKeeper == nil {
+		return nil
+	}
+	return s.statKeeper.GetDomainStats()
+}
+
 func (s *SocketFilterSnooper) GetStats() map[string]int64 {
 	stats := s.cache.Stats()
 	stats["socket_polls"] = atomic.LoadInt64(&s.polls)
@@ -123,6 +137,11 @@ func (s *SocketFilterSnooper) GetStats() map[string]int64 {
 	stats["packets_dropped"] = atomic.LoadInt64(&s.dropped)
 	stats["decoding_errors"] = atomic.LoadInt64(&s.decodingErrors)
 	stats["truncated_packets"] = atomic.LoadInt64(&s.truncatedPkts)
+	if s.statKeeper != nil {
+		for k, v := range s.statKeeper.GetStats() {
+			stats[k] = v
+		}
+	}
 
 	return stats
 }
@@ -250,16 +269,9 @@ type packetSource struct {
 }
 
 func newPacketSource(filter *bpflib.SocketFilter) (*packetSource, error) {
-	rawSocket, err := afpacket.NewTPacket(
-		afpacket.OptPollTimeout(1*time.Second),
-		// This setup will require ~4Mb that is mmap'd into the process virtual space
-		// More information here: https://www.kernel.org/doc/Documentation/networking/packet_mmap.txt
-		afpacket.OptFrameSize(4096),
-		afpacket.OptBlockSize(4096*128),
-		afpacket.OptNumBlocks(8),
-	)
+	rawSocket, err := newRawSocket()
 	if err != nil {
-		return nil, fmt.Errorf("error creating raw socket: %s", err)
+		return nil, err
 	}
 
 	// The underlying socket file descriptor is private, hence the use of reflection
@@ -277,9 +289,47 @@ func newPacketSource(filter *bpflib.SocketFilter) (*packetSource, error) {
 	}, nil
 }
 
+// newClassicBPFPacketSource returns a RAW_SOCKET filtered by a classic BPF program
+func newClassicBPFPacketSource(filter []bpf.Instruction) (*packetSource, error) {
+	program, err := bpf.Assemble(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error assembling socket filter: %s", err)
+	}
+
+	rawSocket, err := newRawSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rawSocket.SetBPF(program); err != nil {
+		rawSocket.Close()
+		return nil, fmt.Errorf("error attaching filter to socket: %s", err)
+	}
+
+	return &packetSource{TPacket: rawSocket}, nil
+}
+
+func newRawSocket() (*afpacket.TPacket, error) {
+	rawSocket, err := afpacket.NewTPacket(
+		afpacket.OptPollTimeout(1*time.Second),
+		// This setup will require ~4Mb that is mmap'd into the process virtual space
+		// More information here: https://www.kernel.org/doc/Documentation/networking/packet_mmap.txt
+		afpacket.OptFrameSize(4096),
+		afpacket.OptBlockSize(4096*128),
+		afpacket.OptNumBlocks(8),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating raw socket: %s", err)
+	}
+	return rawSocket, nil
+}
+
 func (p *packetSource) Close() {
-	if err := bpflib.DetachSocketFilter(p.socketFilter, p.socketFD); err != nil {
-		log.Errorf("error detaching socket filter: %s", err)
+	// classic BPF filters are released along with the socket
+	if p.socketFilter != nil {
+		if err := bpflib.DetachSocketFilter(p.socketFilter, p.socketFD); err != nil {
+			log.Errorf("error detaching socket filter: %s", err)
+		}
 	}
 
 	p.TPacket.Close()
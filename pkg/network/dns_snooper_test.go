@@ -53,6 +53,8 @@ func getSnooper(
 		collectStats,
 		collectLocalDNS,
 		dnsTimeout,
+		false,
+		0,
 	)
 	require.NoError(t, err)
 	return reverseDNS
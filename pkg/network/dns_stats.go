@@ -14,6 +14,129 @@ type dnsStats struct {
 	successLatencySum   uint64 // Stored in µs
 	failureLatencySum   uint64
 	timeouts            uint32
+
+	// byDomain is only set when per-domain stats are enabled
+	byDomain map[string]dnsDomainStats
+}
+
+// merge adds the stats of other to s. The per-domain stats are copied so
+// that s never shares them with other.
+func (s *dnsStats) merge(other dnsStats) {
+	s.successfulResponses += other.successfulResponses
+	s.failedResponses += other.failedResponses
+	s.successLatencySum += other.successLatencySum
+	s.failureLatencySum += other.failureLatencySum
+	s.timeouts += other.timeouts
+
+	if len(other.byDomain) == 0 {
+		return
+	}
+	if s.byDomain == nil {
+		s.byDomain = make(map[string]dnsDomainStats, len(other.byDomain))
+	}
+	for domain, ds := range other.byDomain {
+		prev := s.byDomain[domain]
+		prev.merge(ds)
+		s.byDomain[domain] = prev
+	}
+}
+
+// domain returns a copy of the stats of domain, to be stored back into s.byDomain
+func (s *dnsStats) domain(domain string) dnsDomainStats {
+	if s.byDomain == nil {
+		s.byDomain = make(map[string]dnsDomainStats)
+	}
+	return s.byDomain[domain]
+}
+
+const (
+	// numDNSRcodes is the number of response codes which fit in the 4 bits of the DNS header
+	numDNSRcodes = 16
+
+	// NumDNSLatencyBuckets is the number of buckets of the per-domain DNS latency distribution
+	NumDNSLatencyBuckets = 10
+
+	// DNSOtherDomain is the domain under which stats are reported once the maximum
+	// number of distinct domains has been reached
+	DNSOtherDomain = "*"
+)
+
+// DNSLatencyBucketBounds holds the inclusive upper bounds, in µs, of the DNS latency
+// buckets. The last bucket holds all the latencies above the last bound.
+var DNSLatencyBucketBounds = [NumDNSLatencyBuckets - 1]uint64{
+	1000, 2000, 5000, 10000, 20000, 50000, 100000, 200000, 500000,
+}
+
+// dnsDomainStats holds the outcome of the queries for a single domain
+type dnsDomainStats struct {
+	countByRcode      [numDNSRcodes]uint32
+	timeouts          uint32
+	successLatencySum uint64 // Stored in µs
+	failureLatencySum uint64 // Stored in µs
+	latencyBuckets    [NumDNSLatencyBuckets]uint32
+}
+
+func (s *dnsDomainStats) merge(other dnsDomainStats) {
+	for i, c := range other.countByRcode {
+		s.countByRcode[i] += c
+	}
+	s.timeouts += other.timeouts
+	s.successLatencySum += other.successLatencySum
+	s.failureLatencySum += other.failureLatencySum
+	for i, c := range other.latencyBuckets {
+		s.latencyBuckets[i] += c
+	}
+}
+
+func (s *dnsDomainStats) addResponse(rcode uint8, latency uint64) {
+	s.countByRcode[rcode%numDNSRcodes]++
+	if rcode == 0 {
+		s.successLatencySum += latency
+	} else {
+		s.failureLatencySum += latency
+	}
+
+	bucket := len(DNSLatencyBucketBounds)
+	for i, bound := range DNSLatencyBucketBounds {
+		if latency <= bound {
+			bucket = i
+			break
+		}
+	}
+	s.latencyBuckets[bucket]++
+}
+
+// export converts the stats to their public representation
+func (s dnsDomainStats) export() DNSDomainStats {
+	ds := DNSDomainStats{
+		Timeouts:          s.timeouts,
+		SuccessLatencySum: s.successLatencySum,
+		FailureLatencySum: s.failureLatencySum,
+		LatencyBuckets:    s.latencyBuckets,
+	}
+	for rcode, c := range s.countByRcode {
+		if c == 0 {
+			continue
+		}
+		if ds.CountByRcode == nil {
+			ds.CountByRcode = make(map[uint8]uint32)
+		}
+		ds.CountByRcode[uint8(rcode)] = c
+	}
+	return ds
+}
+
+// DNSDomainStats holds the outcome of the DNS queries for a single domain
+type DNSDomainStats struct {
+	// CountByRcode holds the number of responses for each response code (0 being NOERROR)
+	CountByRcode map[uint8]uint32
+	Timeouts     uint32
+	// SuccessLatencySum and FailureLatencySum hold the latencies of the responses
+	// with and without the NOERROR response code, in µs
+	SuccessLatencySum uint64
+	FailureLatencySum uint64
+	// LatencyBuckets holds the latency distribution of the responses, see DNSLatencyBucketBounds
+	LatencyBuckets [NumDNSLatencyBuckets]uint32
 }
 
 type dnsKey struct {
@@ -46,6 +169,8 @@ type dnsPacketInfo struct {
 	transactionID uint16
 	key           dnsKey
 	pktType       DNSPacketType
+	rCode         uint8  // only set for responses
+	domain        string // only set when per-domain stats are enabled
 }
 
 type stateKey struct {
@@ -53,23 +178,38 @@ type stateKey struct {
 	id  uint16
 }
 
+type dnsQuery struct {
+	start  uint64 // Stored in µs
+	domain string
+}
+
 type dnsStatKeeper struct {
 	mux              sync.Mutex
 	stats            map[dnsKey]dnsStats
-	state            map[stateKey]uint64
+	state            map[stateKey]dnsQuery
 	expirationPeriod time.Duration
 	exit             chan struct{}
 	maxSize          int // maximum size of the state map
 	deleteCount      int
+
+	// maxDomains is the maximum number of distinct domains tracked between two
+	// reads of the stats, per-domain stats are disabled when it is 0
+	maxDomains     int
+	domains        map[string]struct{}
+	domainsDropped int64
 }
 
-func newDNSStatkeeper(timeout time.Duration) *dnsStatKeeper {
+// newDNSStatkeeper returns a new dnsStatKeeper. When maxDomains is positive, stats are
+// additionally broken down by queried domain.
+func newDNSStatkeeper(timeout time.Duration, maxDomains int) *dnsStatKeeper {
 	statsKeeper := &dnsStatKeeper{
 		stats:            make(map[dnsKey]dnsStats),
-		state:            make(map[stateKey]uint64),
+		state:            make(map[stateKey]dnsQuery),
 		expirationPeriod: timeout,
 		exit:             make(chan struct{}),
 		maxSize:          MaxStateMapSize,
+		maxDomains:       maxDomains,
+		domains:          make(map[string]struct{}),
 	}
 
 	ticker := time.NewTicker(statsKeeper.expirationPeriod)
@@ -102,13 +242,13 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 
 		if _, ok := d.state[sk]; !ok {
-			d.state[sk] = microSecs(ts)
+			d.state[sk] = dnsQuery{start: microSecs(ts), domain: info.domain}
 		}
 		return
 	}
 
 	// If a response does not have a corresponding query entry, we discard it
-	start, ok := d.state[sk]
+	query, ok := d.state[sk]
 
 	if !ok {
 		return
@@ -117,12 +257,13 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	delete(d.state, sk)
 	d.deleteCount++
 
-	latency := microSecs(ts) - start
+	latency := microSecs(ts) - query.start
 
 	stats := d.stats[info.key]
 
 	// Note: time.Duration in the agent version of go (1.12.9) does not have the Microseconds method.
-	if latency > uint64(d.expirationPeriod.Microseconds()) {
+	timedOut := latency > uint64(d.expirationPeriod.Microseconds())
+	if timedOut {
 		stats.timeouts++
 	} else {
 		if info.pktType == SuccessfulResponse {
@@ -134,28 +275,92 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 	}
 
+	if d.maxDomains > 0 {
+		domain := d.domainKey(query.domain)
+		domainStats := stats.domain(domain)
+		if timedOut {
+			domainStats.timeouts++
+		} else {
+			domainStats.addResponse(info.rCode, latency)
+		}
+		stats.byDomain[domain] = domainStats
+	}
+
 	d.stats[info.key] = stats
 }
 
+// domainKey returns the key under which the stats of domain are stored, which is
+// DNSOtherDomain once the maximum number of distinct domains has been reached
+func (d *dnsStatKeeper) domainKey(domain string) string {
+	if _, ok := d.domains[domain]; ok {
+		return domain
+	}
+	if len(d.domains) >= d.maxDomains {
+		d.domainsDropped++
+		return DNSOtherDomain
+	}
+	d.domains[domain] = struct{}{}
+	return domain
+}
+
 func (d *dnsStatKeeper) GetAndResetAllStats() map[dnsKey]dnsStats {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	ret := d.stats
 	d.stats = make(map[dnsKey]dnsStats)
+	d.domains = make(map[string]struct{})
 	return ret
 }
 
+// GetDomainStats returns the stats of the queries answered since the last call to
+// GetAndResetAllStats, aggregated by domain
+func (d *dnsStatKeeper) GetDomainStats() map[string]DNSDomainStats {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	aggregated := make(map[string]dnsDomainStats)
+	for _, stats := range d.stats {
+		for domain, ds := range stats.byDomain {
+			prev := aggregated[domain]
+			prev.merge(ds)
+			aggregated[domain] = prev
+		}
+	}
+
+	ret := make(map[string]DNSDomainStats, len(aggregated))
+	for domain, ds := range aggregated {
+		ret[domain] = ds.export()
+	}
+	return ret
+}
+
+// GetStats returns telemetry about the per-domain stats
+func (d *dnsStatKeeper) GetStats() map[string]int64 {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return map[string]int64{
+		"domains_tracked": int64(len(d.domains)),
+		"domains_dropped": d.domainsDropped,
+	}
+}
+
 func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 	deleteThreshold := 5000
 	d.mux.Lock()
 	defer d.mux.Unlock()
 	threshold := microSecs(earliestTs)
 	for k, v := range d.state {
-		if v < threshold {
+		if v.start < threshold {
 			delete(d.state, k)
 			d.deleteCount++
 			stats := d.stats[k.key]
 			stats.timeouts++
+			if d.maxDomains > 0 {
+				domain := d.domainKey(v.domain)
+				domainStats := stats.domain(domain)
+				domainStats.timeouts++
+				stats.byDomain[domain] = domainStats
+			}
 			d.stats[k.key] = stats
 		}
 	}
@@ -165,7 +370,7 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 	}
 
 	// golang/go#20135 : maps do not shrink after elements removal (delete)
-	copied := make(map[stateKey]uint64, len(d.state))
+	copied := make(map[stateKey]dnsQuery, len(d.state))
 	for k, v := range d.state {
 		copied[k] = v
 	}
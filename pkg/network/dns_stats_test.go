@@ -24,7 +24,7 @@ func testLatency(
 	expectedFailureLatency uint64,
 	expectedTimeouts uint32,
 ) {
-	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 0)
 	key := dnsKey{
 		serverIP:   util.AddressFromString("8.8.8.8"),
 		clientIP:   util.AddressFromString("1.1.1.1"),
@@ -64,6 +64,95 @@ func TestTimeout(t *testing.T) {
 	testLatency(t, SuccessfulResponse, delta, 0, 0, 1)
 }
 
+func TestDomainStats(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 10)
+	defer sk.Close()
+
+	key := dnsKey{
+		serverIP:   util.AddressFromString("8.8.8.8"),
+		clientIP:   util.AddressFromString("1.1.1.1"),
+		clientPort: 1000,
+		protocol:   UDP,
+	}
+	then := time.Now()
+	for i, resp := range []struct {
+		domain  string
+		rCode   uint8
+		latency time.Duration
+	}{
+		{domain: "golang.org", rCode: 0, latency: 500 * time.Microsecond},
+		{domain: "golang.org", rCode: 0, latency: 3 * time.Millisecond},
+		{domain: "golang.org", rCode: 2, latency: 2 * time.Second},
+		{domain: "nope.example", rCode: 3, latency: time.Millisecond},
+	} {
+		id := uint16(i)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: Query, domain: resp.domain}, then)
+		pktType := SuccessfulResponse
+		if resp.rCode != 0 {
+			pktType = FailedResponse
+		}
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: pktType, rCode: resp.rCode}, then.Add(resp.latency))
+	}
+
+	// A query without any response
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 42, key: key, pktType: Query, domain: "nope.example"}, then)
+	sk.removeExpiredStates(then.Add(time.Second))
+
+	domains := sk.GetDomainStats()
+	require.Len(t, domains, 2)
+
+	golang := domains["golang.org"]
+	assert.Equal(t, map[uint8]uint32{0: 2, 2: 1}, golang.CountByRcode)
+	assert.Equal(t, uint64(3500), golang.SuccessLatencySum)
+	assert.Equal(t, uint64(2000000), golang.FailureLatencySum)
+	assert.Equal(t, uint32(1), golang.LatencyBuckets[0])
+	assert.Equal(t, uint32(1), golang.LatencyBuckets[2])
+	assert.Equal(t, uint32(1), golang.LatencyBuckets[NumDNSLatencyBuckets-1])
+	assert.Zero(t, golang.Timeouts)
+
+	nope := domains["nope.example"]
+	assert.Equal(t, map[uint8]uint32{3: 1}, nope.CountByRcode)
+	assert.Equal(t, uint32(1), nope.LatencyBuckets[0])
+	assert.Equal(t, uint32(1), nope.Timeouts)
+
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	assert.Equal(t, uint32(2), stats[key].successfulResponses)
+	assert.Equal(t, uint32(2), stats[key].failedResponses)
+	assert.Equal(t, uint32(1), stats[key].timeouts)
+	assert.Len(t, stats[key].byDomain, 2)
+}
+
+func TestDomainStatsLimit(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs*time.Second, 2)
+	defer sk.Close()
+
+	key := dnsKey{
+		serverIP:   util.AddressFromString("8.8.8.8"),
+		clientIP:   util.AddressFromString("1.1.1.1"),
+		clientPort: 1000,
+		protocol:   UDP,
+	}
+	then := time.Now()
+	for i, domain := range []string{"a.org", "b.org", "c.org", "d.org", "a.org"} {
+		id := uint16(i)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: Query, domain: domain}, then)
+		sk.ProcessPacketInfo(dnsPacketInfo{transactionID: id, key: key, pktType: SuccessfulResponse}, then)
+	}
+
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	byDomain := stats[key].byDomain
+	require.Len(t, byDomain, 3)
+	assert.Equal(t, uint32(2), byDomain["a.org"].countByRcode[0])
+	assert.Equal(t, uint32(1), byDomain["b.org"].countByRcode[0])
+	assert.Equal(t, uint32(2), byDomain[DNSOtherDomain].countByRcode[0])
+	assert.Equal(t, int64(2), sk.GetStats()["domains_dropped"])
+
+	// The distinct domains are reset along with the stats
+	assert.Equal(t, int64(0), sk.GetStats()["domains_tracked"])
+}
+
 func BenchmarkStats(b *testing.B) {
 	key := dnsKey{
 		serverIP:   util.AddressFromString("8.8.8.8"),
@@ -85,7 +174,7 @@ func BenchmarkStats(b *testing.B) {
 			b.ResetTimer()
 			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
-				sk := newDNSStatkeeper(1000 * time.Second)
+				sk := newDNSStatkeeper(1000*time.Second, 0)
 				for j := 0; j < numPackets; j++ {
 					sk.ProcessPacketInfo(packets[j], ts)
 				}
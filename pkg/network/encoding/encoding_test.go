@@ -7,8 +7,11 @@ import (
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/sketches-go/ddsketch"
+	"github.com/DataDog/sketches-go/ddsketch/pb/sketchpb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestSerialization(t *testing.T) {
@@ -24,6 +27,8 @@ func TestSerialization(t *testing.T) {
 				LastUpdateEpoch:      50,
 				MonotonicRetransmits: 201,
 				LastRetransmits:      201,
+				LastTCPEstablished:   1,
+				LastTCPClosed:        1,
 				Pid:                  6000,
 				NetNS:                7,
 				SPort:                1000,
@@ -48,13 +53,15 @@ func TestSerialization(t *testing.T) {
 	out := &model.Connections{
 		Conns: []*model.Connection{
 			{
-				Laddr:             &model.Addr{Ip: "10.1.1.1", Port: int32(1000)},
-				Raddr:             &model.Addr{Ip: "10.2.2.2", Port: int32(9000)},
-				LastBytesSent:     2,
-				LastBytesReceived: 101,
-				LastRetransmits:   201,
-				Pid:               int32(6000),
-				NetNS:             7,
+				Laddr:              &model.Addr{Ip: "10.1.1.1", Port: int32(1000)},
+				Raddr:              &model.Addr{Ip: "10.2.2.2", Port: int32(9000)},
+				LastBytesSent:      2,
+				LastBytesReceived:  101,
+				LastRetransmits:    201,
+				LastTcpEstablished: 1,
+				LastTcpClosed:      1,
+				Pid:                int32(6000),
+				NetNS:              7,
 				IpTranslation: &model.IPTranslation{
 					ReplSrcIP:   "20.1.1.1",
 					ReplDstIP:   "20.1.1.1",
@@ -72,6 +79,17 @@ func TestSerialization(t *testing.T) {
 		},
 	}
 
+	// default values are emitted in JSON, so empty collections are decoded as empty rather than nil
+	jsonConn := *out.Conns[0]
+	jsonConn.DnsCountByRcode = map[uint32]uint32{}
+	jsonConn.DnsStatsByDomain = map[int32]*model.DNSStats{}
+	jsonConn.HttpStatsByPath = map[string]*model.HTTPStats{}
+	jsonOut := &model.Connections{
+		Conns:   []*model.Connection{&jsonConn},
+		Dns:     out.Dns,
+		Domains: []string{},
+	}
+
 	t.Run("requesting application/json serialization", func(t *testing.T) {
 		assert := assert.New(t)
 		marshaler := GetMarshaler("application/json")
@@ -83,7 +101,7 @@ func TestSerialization(t *testing.T) {
 		unmarshaler := GetUnmarshaler("application/json")
 		result, err := unmarshaler.Unmarshal(blob)
 		require.NoError(t, err)
-		assert.Equal(out, result)
+		assert.Equal(jsonOut, result)
 	})
 
 	t.Run("requesting empty serialization", func(t *testing.T) {
@@ -98,7 +116,7 @@ func TestSerialization(t *testing.T) {
 		unmarshaler := GetUnmarshaler("")
 		result, err := unmarshaler.Unmarshal(blob)
 		require.NoError(t, err)
-		assert.Equal(out, result)
+		assert.Equal(jsonOut, result)
 	})
 
 	t.Run("requesting application/protobuf serialization", func(t *testing.T) {
@@ -128,7 +146,7 @@ func TestSerialization(t *testing.T) {
 		unmarshaler := GetUnmarshaler("application/json")
 		result, err := unmarshaler.Unmarshal(blob)
 		require.NoError(t, err)
-		assert.Equal(out, result)
+		assert.Equal(jsonOut, result)
 	})
 
 	t.Run("render default values with application/json", func(t *testing.T) {
@@ -155,3 +173,140 @@ func TestSerialization(t *testing.T) {
 		}
 	})
 }
+
+func TestDNSStatsByDomainSerialization(t *testing.T) {
+	in := &network.Connections{
+		Conns: []network.ConnectionStats{
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("8.8.8.8"),
+				SPort:  1000,
+				DPort:  53,
+				Type:   network.UDP,
+				Family: network.AFINET,
+				DNSStatsByDomain: map[string]network.DNSDomainStats{
+					"golang.org": {
+						CountByRcode:      map[uint8]uint32{0: 2, 3: 1},
+						SuccessLatencySum: 300,
+						FailureLatencySum: 50,
+					},
+				},
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("8.8.4.4"),
+				SPort:  1001,
+				DPort:  53,
+				Type:   network.UDP,
+				Family: network.AFINET,
+				DNSStatsByDomain: map[string]network.DNSDomainStats{
+					"golang.org":   {CountByRcode: map[uint8]uint32{0: 1}, SuccessLatencySum: 100},
+					"nope.example": {Timeouts: 2},
+				},
+			},
+		},
+	}
+
+	for _, ctype := range []string{ContentTypeJSON, ContentTypeProtobuf} {
+		t.Run(ctype, func(t *testing.T) {
+			blob, err := GetMarshaler(ctype).Marshal(in)
+			require.NoError(t, err)
+
+			result, err := GetUnmarshaler(ctype).Unmarshal(blob)
+			require.NoError(t, err)
+			require.Len(t, result.Conns, 2)
+			require.ElementsMatch(t, []string{"golang.org", "nope.example"}, result.Domains)
+
+			// resolve the domain indexes of each connection
+			byDomain := func(c *model.Connection) map[string]*model.DNSStats {
+				res := make(map[string]*model.DNSStats)
+				for idx, stats := range c.DnsStatsByDomain {
+					require.True(t, int(idx) < len(result.Domains))
+					res[result.Domains[idx]] = stats
+				}
+				return res
+			}
+
+			first := byDomain(result.Conns[0])
+			require.Len(t, first, 1)
+			require.Contains(t, first, "golang.org")
+			assert.Equal(t, map[uint32]uint32{0: 2, 3: 1}, first["golang.org"].DnsCountByRcode)
+			assert.Equal(t, uint64(300), first["golang.org"].DnsSuccessLatencySum)
+			assert.Equal(t, uint64(50), first["golang.org"].DnsFailureLatencySum)
+
+			second := byDomain(result.Conns[1])
+			require.Len(t, second, 2)
+			assert.Equal(t, map[uint32]uint32{0: 1}, second["golang.org"].DnsCountByRcode)
+			assert.Equal(t, uint32(2), second["nope.example"].DnsTimeouts)
+		})
+	}
+}
+
+func TestHTTPSerialization(t *testing.T) {
+	latencies, err := ddsketch.LogCollapsingLowestDenseDDSketch(network.HTTPLatencyRelativeAccuracy, 1024)
+	require.NoError(t, err)
+	for _, l := range []float64{200, 300, 1000} {
+		require.NoError(t, latencies.Add(l))
+	}
+
+	in := &network.Connections{
+		Conns: []network.ConnectionStats{
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.2.2.2"),
+				Pid:    6000,
+				SPort:  1000,
+				DPort:  8080,
+				Type:   network.TCP,
+				Family: network.AFINET,
+				HTTPStats: []network.HTTPStats{
+					{Path: "/users/*", Requests: 2},
+					{Path: "/users/*", StatusClass: 200, Requests: 3, LatencySum: 1500, Latencies: latencies},
+					{Path: "/users/*", StatusClass: 500, Requests: 1, LatencySum: 200, Latencies: latencies},
+				},
+			},
+			{
+				Source: util.AddressFromString("10.1.1.1"),
+				Dest:   util.AddressFromString("10.3.3.3"),
+				SPort:  1001,
+				DPort:  53,
+				Type:   network.UDP,
+				Family: network.AFINET,
+			},
+		},
+	}
+
+	for _, ctype := range []string{ContentTypeJSON, ContentTypeProtobuf} {
+		t.Run(ctype, func(t *testing.T) {
+			blob, err := GetMarshaler(ctype).Marshal(in)
+			require.NoError(t, err)
+
+			result, err := GetUnmarshaler(ctype).Unmarshal(blob)
+			require.NoError(t, err)
+			require.Len(t, result.Conns, 2)
+			assert.Empty(t, result.Conns[1].HttpStatsByPath)
+
+			require.Contains(t, result.Conns[0].HttpStatsByPath, "/users/*")
+			byStatus := result.Conns[0].HttpStatsByPath["/users/*"].StatsByResponseStatus
+			require.Len(t, byStatus, 5)
+			for status, data := range byStatus {
+				switch model.HTTPResponseStatus(status) {
+				case model.HTTPResponseStatus_Success:
+					assert.Equal(t, uint32(3), data.Count)
+				case model.HTTPResponseStatus_ServerErr:
+					assert.Equal(t, uint32(1), data.Count)
+				default:
+					assert.Zero(t, data.Count)
+					assert.Empty(t, data.Latencies)
+					continue
+				}
+
+				var pb sketchpb.DDSketch
+				require.NoError(t, proto.Unmarshal(data.Latencies, &pb))
+				sketch, err := latencies.FromProto(&pb)
+				require.NoError(t, err)
+				assert.Equal(t, latencies.GetCount(), sketch.GetCount())
+			}
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"google.golang.org/protobuf/proto"
 )
 
-// FormatConnection converts a ConnectionStats into an model.Connection
-func FormatConnection(conn network.ConnectionStats) *model.Connection {
+// FormatConnection converts a ConnectionStats into an model.Connection.
+// The DNS stats are keyed by the index of their domain in domainSet, which
+// gets the domains it doesn't hold yet, see FormatDomains.
+func FormatConnection(conn network.ConnectionStats, domainSet map[string]int) *model.Connection {
 	return &model.Connection{
 		Pid:                    int32(conn.Pid),
 		Laddr:                  formatAddr(conn.Source, conn.SPort),
@@ -17,6 +21,8 @@ func FormatConnection(conn network.ConnectionStats) *model.Connection {
 		LastBytesSent:          conn.LastSentBytes,
 		LastBytesReceived:      conn.LastRecvBytes,
 		LastRetransmits:        conn.LastRetransmits,
+		LastTcpEstablished:     conn.LastTCPEstablished,
+		LastTcpClosed:          conn.LastTCPClosed,
 		Rtt:                    conn.RTT,
 		RttVar:                 conn.RTTVar,
 		Direction:              formatDirection(conn.Direction),
@@ -28,9 +34,24 @@ func FormatConnection(conn network.ConnectionStats) *model.Connection {
 		DnsTimeouts:            conn.DNSTimeouts,
 		DnsSuccessLatencySum:   conn.DNSSuccessLatencySum,
 		DnsFailureLatencySum:   conn.DNSFailureLatencySum,
+		DnsStatsByDomain:       formatDNSStatsByDomain(conn.DNSStatsByDomain, domainSet),
+		HttpStatsByPath:        formatHTTPStats(conn.HTTPStats),
 	}
 }
 
+// FormatDomains returns the domains of domainSet ordered by index
+func FormatDomains(domainSet map[string]int) []string {
+	if len(domainSet) == 0 {
+		return nil
+	}
+
+	domains := make([]string, len(domainSet))
+	for domain, idx := range domainSet {
+		domains[idx] = domain
+	}
+	return domains
+}
+
 // FormatDNS converts a map[util.Address][]string to a map using IPs string representation
 func FormatDNS(dns map[util.Address][]string) map[string]*model.DNSEntry {
 	if dns == nil {
@@ -62,6 +83,74 @@ func FormatTelemetry(tel *network.ConnectionsTelemetry) *model.ConnectionsTeleme
 	}
 }
 
+func formatDNSStatsByDomain(stats map[string]network.DNSDomainStats, domainSet map[string]int) map[int32]*model.DNSStats {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	byDomain := make(map[int32]*model.DNSStats, len(stats))
+	for domain, s := range stats {
+		idx, ok := domainSet[domain]
+		if !ok {
+			idx = len(domainSet)
+			domainSet[domain] = idx
+		}
+
+		var countByRcode map[uint32]uint32
+		if len(s.CountByRcode) > 0 {
+			countByRcode = make(map[uint32]uint32, len(s.CountByRcode))
+			for rcode, count := range s.CountByRcode {
+				countByRcode[uint32(rcode)] = count
+			}
+		}
+		byDomain[int32(idx)] = &model.DNSStats{
+			DnsTimeouts:          s.Timeouts,
+			DnsSuccessLatencySum: s.SuccessLatencySum,
+			DnsFailureLatencySum: s.FailureLatencySum,
+			DnsCountByRcode:      countByRcode,
+		}
+	}
+	return byDomain
+}
+
+// formatHTTPStats groups the HTTP stats of a connection by path, with one entry per
+// response status class. Requests which timed out have no status class, they are
+// left out of the payload.
+func formatHTTPStats(stats []network.HTTPStats) map[string]*model.HTTPStats {
+	var byPath map[string]*model.HTTPStats
+	for _, s := range stats {
+		if s.StatusClass < 100 || s.StatusClass > 500 || s.Latencies == nil {
+			continue
+		}
+
+		latencies, err := proto.Marshal(s.Latencies.ToProto())
+		if err != nil {
+			log.Debugf("error encoding HTTP latencies: %s", err)
+			continue
+		}
+
+		if byPath == nil {
+			byPath = make(map[string]*model.HTTPStats)
+		}
+		pathStats, ok := byPath[s.Path]
+		if !ok {
+			pathStats = &model.HTTPStats{
+				StatsByResponseStatus: make([]*model.HTTPStats_Data, len(model.HTTPResponseStatus_name)),
+			}
+			for i := range pathStats.StatsByResponseStatus {
+				pathStats.StatsByResponseStatus[i] = &model.HTTPStats_Data{}
+			}
+			byPath[s.Path] = pathStats
+		}
+		// the response status enum starts at 1XX
+		pathStats.StatsByResponseStatus[s.StatusClass/100-1] = &model.HTTPStats_Data{
+			Count:     s.Requests,
+			Latencies: latencies,
+		}
+	}
+	return byPath
+}
+
 func formatAddr(addr util.Address, port uint16) *model.Addr {
 	if addr == nil {
 		return nil
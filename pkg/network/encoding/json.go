@@ -16,11 +16,17 @@ type jsonSerializer struct {
 }
 
 func (j jsonSerializer) Marshal(conns *network.Connections) ([]byte, error) {
+	domainSet := make(map[string]int)
 	agentConns := make([]*model.Connection, len(conns.Conns))
 	for i, conn := range conns.Conns {
-		agentConns[i] = FormatConnection(conn)
+		agentConns[i] = FormatConnection(conn, domainSet)
+	}
+	payload := &model.Connections{
+		Conns:     agentConns,
+		Dns:       FormatDNS(conns.DNS),
+		Telemetry: FormatTelemetry(conns.Telemetry),
+		Domains:   FormatDomains(domainSet),
 	}
-	payload := &model.Connections{Conns: agentConns, Dns: FormatDNS(conns.DNS), Telemetry: FormatTelemetry(conns.Telemetry)}
 	writer := new(bytes.Buffer)
 	err := j.marshaller.Marshal(writer, payload)
 	return writer.Bytes(), err
@@ -12,16 +12,18 @@ const ContentTypeProtobuf = "application/protobuf"
 type protoSerializer struct{}
 
 func (protoSerializer) Marshal(conns *network.Connections) ([]byte, error) {
+	domainSet := make(map[string]int)
 	agentConns := make([]*model.Connection, len(conns.Conns))
 
 	for i, conn := range conns.Conns {
-		agentConns[i] = FormatConnection(conn)
+		agentConns[i] = FormatConnection(conn, domainSet)
 	}
 
 	payload := &model.Connections{
 		Conns:     agentConns,
 		Dns:       FormatDNS(conns.DNS),
 		Telemetry: FormatTelemetry(conns.Telemetry),
+		Domains:   FormatDomains(domainSet),
 	}
 
 	return proto.Marshal(payload)
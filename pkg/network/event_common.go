@@ -105,6 +105,23 @@ type ConnectionStats struct {
 	MonotonicRetransmits uint32
 	LastRetransmits      uint32
 
+	// MonotonicConnectFailures counts the connection attempts for this tuple which never got established
+	// (refused or timed out), see IsFailedConnect
+	MonotonicConnectFailures uint32
+	LastConnectFailures      uint32
+
+	// MonotonicTCPEstablished counts the connections for this tuple which went through the TCP_ESTABLISHED state
+	MonotonicTCPEstablished uint32
+	LastTCPEstablished      uint32
+
+	// MonotonicTCPClosed counts the connections for this tuple which got closed
+	MonotonicTCPClosed uint32
+	LastTCPClosed      uint32
+
+	// MonotonicResets counts the RST segments received by the established connections for this tuple
+	MonotonicResets uint32
+	LastResets      uint32
+
 	RTT    uint32 // Stored in µs
 	RTTVar uint32
 
@@ -123,6 +140,20 @@ type ConnectionStats struct {
 	DNSTimeouts            uint32
 	DNSSuccessLatencySum   uint64
 	DNSFailureLatencySum   uint64
+	DNSStatsByDomain       map[string]DNSDomainStats
+	HTTPStats              []HTTPStats
+}
+
+// IsFailedConnect returns whether a closed TCP connection never got established, i.e. whether
+// the connection attempt was refused or timed out. A connection which completed its handshake
+// goes through the TCP_ESTABLISHED state, even if it gets closed without exchanging any byte.
+// The bytes are checked as well since the connections established before the tracer started
+// have no recorded state transition.
+func (c ConnectionStats) IsFailedConnect() bool {
+	return c.Type == TCP &&
+		c.MonotonicTCPEstablished == 0 &&
+		c.MonotonicSentBytes == 0 &&
+		c.MonotonicRecvBytes == 0
 }
 
 // IPTranslation can be associated with a connection to show the connection is NAT'd
@@ -231,6 +262,13 @@ func ConnectionSummary(c ConnectionStats, names map[util.Address][]string) strin
 			time.Duration(c.RTT)*time.Microsecond,
 			time.Duration(c.RTTVar)*time.Microsecond,
 		)
+		if c.MonotonicConnectFailures > 0 || c.MonotonicResets > 0 {
+			str += fmt.Sprintf(
+				", %d failed connects (+%d), %d resets (+%d)",
+				c.MonotonicConnectFailures, c.LastConnectFailures,
+				c.MonotonicResets, c.LastResets,
+			)
+		}
 	}
 
 	return str
@@ -117,3 +117,21 @@ func TestConnStatsByteKey(t *testing.T) {
 		assert.NotEqual(t, keyA, keyB)
 	}
 }
+
+func TestIsFailedConnect(t *testing.T) {
+	conn := ConnectionStats{Type: TCP}
+	assert.True(t, conn.IsFailedConnect())
+
+	// a connection closed right after its handshake, as done by health checks
+	established := conn
+	established.MonotonicTCPEstablished = 1
+	assert.False(t, established.IsFailedConnect())
+
+	withData := conn
+	withData.MonotonicSentBytes = 1
+	assert.False(t, withData.IsFailedConnect())
+
+	udp := conn
+	udp.Type = UDP
+	assert.False(t, udp.IsFailedConnect())
+}
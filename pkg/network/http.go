@@ -0,0 +1,53 @@
+package network
+
+import "github.com/DataDog/sketches-go/ddsketch"
+
+// HTTPMonitor collects statistics about the plaintext HTTP traffic of the host
+type HTTPMonitor interface {
+	GetHTTPStats() map[httpKey]map[httpStatsKey]httpStats
+	GetStats() map[string]int64
+	Close()
+}
+
+// HTTPStats holds the aggregated HTTP transactions of a connection for a given
+// path template and response status class
+type HTTPStats struct {
+	// Path is the templated path of the requests, e.g. /users/*/orders
+	Path string
+	// StatusClass is the class of the response code (100, 200, ..., 500), or 0
+	// for requests which didn't get a response before timing out
+	StatusClass uint16
+	Requests    uint32
+	LatencySum  uint64 // Stored in µs
+	// Latencies is the distribution of the response times in µs, it is nil
+	// for requests which timed out
+	Latencies *ddsketch.DDSketch
+}
+
+// NewNullHTTPMonitor returns a dummy implementation of HTTPMonitor
+func NewNullHTTPMonitor() HTTPMonitor {
+	return nullHTTPMonitor{}
+}
+
+type nullHTTPMonitor struct{}
+
+func (nullHTTPMonitor) GetHTTPStats() map[httpKey]map[httpStatsKey]httpStats {
+	return nil
+}
+
+func (nullHTTPMonitor) GetStats() map[string]int64 {
+	return map[string]int64{
+		"packets_processed": 0,
+		"packets_captured":  0,
+		"packets_dropped":   0,
+		"socket_polls":      0,
+		"decoding_errors":   0,
+		"transactions":      0,
+		"timeouts":          0,
+		"stats_dropped":     0,
+	}
+}
+
+func (nullHTTPMonitor) Close() {}
+
+var _ HTTPMonitor = nullHTTPMonitor{}
@@ -0,0 +1,85 @@
+// +build linux_bpf
+
+package network
+
+import (
+	"errors"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var errHTTPSkippedPayload = errors.New("the packet does not contain an HTTP request or status line")
+
+type httpParser struct {
+	decoder     *gopacket.DecodingLayerParser
+	layers      []gopacket.LayerType
+	ipv4Payload *layers.IPv4
+	ipv6Payload *layers.IPv6
+	tcpPayload  *layers.TCP
+}
+
+func newHTTPParser() *httpParser {
+	ipv4Payload := &layers.IPv4{}
+	ipv6Payload := &layers.IPv6{}
+	tcpPayload := &layers.TCP{}
+
+	stack := []gopacket.DecodingLayer{
+		&layers.Ethernet{},
+		ipv4Payload,
+		ipv6Payload,
+		tcpPayload,
+		&gopacket.Payload{},
+	}
+
+	return &httpParser{
+		decoder:     gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, stack...),
+		ipv4Payload: ipv4Payload,
+		ipv6Payload: ipv6Payload,
+		tcpPayload:  tcpPayload,
+	}
+}
+
+// ParseInto extracts the HTTP request or response line from the TCP segment
+// held by data. Only the beginning of each message is relevant for us, so
+// segments which don't start with a request or a status line are skipped.
+func (p *httpParser) ParseInto(data []byte, pktInfo *httpPacketInfo) error {
+	err := p.decoder.DecodeLayers(data, &p.layers)
+	if err != nil {
+		return err
+	}
+
+	// If there is a TCP layer with a payload, it is the last layer
+	if len(p.layers) < 2 || p.layers[len(p.layers)-1] != gopacket.LayerTypePayload {
+		return errHTTPSkippedPayload
+	}
+
+	payload := p.tcpPayload.LayerPayload()
+	var srcIP, dstIP util.Address
+	for _, layer := range p.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			srcIP = util.AddressFromNetIP(p.ipv4Payload.SrcIP)
+			dstIP = util.AddressFromNetIP(p.ipv4Payload.DstIP)
+		case layers.LayerTypeIPv6:
+			srcIP = util.AddressFromNetIP(p.ipv6Payload.SrcIP)
+			dstIP = util.AddressFromNetIP(p.ipv6Payload.DstIP)
+		}
+	}
+	srcPort, dstPort := uint16(p.tcpPayload.SrcPort), uint16(p.tcpPayload.DstPort)
+
+	if path, ok := parseHTTPRequestLine(payload); ok {
+		pktInfo.pktType = HTTPRequest
+		pktInfo.path = pathTemplate(path)
+		pktInfo.key = httpKey{clientIP: srcIP, clientPort: srcPort, serverIP: dstIP, serverPort: dstPort}
+		return nil
+	}
+	if status, ok := parseHTTPStatusLine(payload); ok {
+		pktInfo.pktType = HTTPResponse
+		pktInfo.statusCode = status
+		pktInfo.key = httpKey{clientIP: dstIP, clientPort: dstPort, serverIP: srcIP, serverPort: srcPort}
+		return nil
+	}
+	return errHTTPSkippedPayload
+}
@@ -0,0 +1,149 @@
+package network
+
+import (
+	"bytes"
+	"strings"
+)
+
+var (
+	httpMethods = [][]byte{
+		[]byte("GET "),
+		[]byte("POST "),
+		[]byte("PUT "),
+		[]byte("DELETE "),
+		[]byte("HEAD "),
+		[]byte("OPTIONS "),
+		[]byte("PATCH "),
+		[]byte("CONNECT "),
+		[]byte("TRACE "),
+	}
+	httpVersionPrefix = []byte("HTTP/1.")
+)
+
+const (
+	// maxPathSegments is the number of path segments kept in a path template,
+	// the remaining ones are collapsed into a wildcard
+	maxPathSegments = 8
+	// pathWildcard replaces the variable parts of a path
+	pathWildcard = "*"
+)
+
+// parseHTTPRequestLine returns the path of the request if payload starts with
+// an HTTP/1.x request line, e.g. "GET /users/42?foo=bar HTTP/1.1"
+func parseHTTPRequestLine(payload []byte) ([]byte, bool) {
+	var rest []byte
+	for _, m := range httpMethods {
+		if bytes.HasPrefix(payload, m) {
+			rest = payload[len(m):]
+			break
+		}
+	}
+	if rest == nil {
+		return nil, false
+	}
+
+	end := bytes.IndexByte(rest, ' ')
+	if end <= 0 {
+		return nil, false
+	}
+	if !bytes.HasPrefix(rest[end+1:], httpVersionPrefix) {
+		return nil, false
+	}
+	return rest[:end], true
+}
+
+// parseHTTPStatusLine returns the status code of the response if payload
+// starts with an HTTP/1.x status line, e.g. "HTTP/1.1 404 Not Found"
+func parseHTTPStatusLine(payload []byte) (uint16, bool) {
+	// "HTTP/1.x NNN"
+	if len(payload) < len(httpVersionPrefix)+5 || !bytes.HasPrefix(payload, httpVersionPrefix) {
+		return 0, false
+	}
+	code := payload[len(httpVersionPrefix)+2 : len(httpVersionPrefix)+5]
+	if payload[len(httpVersionPrefix)+1] != ' ' {
+		return 0, false
+	}
+	var status uint16
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		status = status*10 + uint16(c-'0')
+	}
+	if status < 100 || status > 599 {
+		return 0, false
+	}
+	return status, true
+}
+
+// httpStatusClass returns the class of an HTTP status code, e.g. 400 for 404
+func httpStatusClass(status uint16) uint16 {
+	return (status / 100) * 100
+}
+
+// pathTemplate strips the query string from path and replaces its variable
+// segments (numbers, UUIDs, hexadecimal hashes...) with a wildcard, so that
+// requests can be aggregated with a bounded cardinality
+func pathTemplate(path []byte) string {
+	if i := bytes.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	if len(path) == 0 || path[0] != '/' {
+		// absolute-form or asterisk-form requests, e.g. proxies or OPTIONS *
+		return pathWildcard
+	}
+
+	var b strings.Builder
+	segments := 0
+	for len(path) > 0 {
+		// skip the leading slash
+		path = path[1:]
+		end := bytes.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		segment := path[:end]
+		path = path[end:]
+
+		b.WriteByte('/')
+		segments++
+		if segments > maxPathSegments {
+			b.WriteString(pathWildcard)
+			break
+		}
+		if isVariableSegment(segment) {
+			b.WriteString(pathWildcard)
+		} else {
+			b.Write(segment)
+		}
+	}
+	return b.String()
+}
+
+// isVariableSegment returns true if the path segment looks like an identifier
+// rather than a fixed part of a route
+func isVariableSegment(segment []byte) bool {
+	if len(segment) == 0 {
+		return false
+	}
+	digits, hex, other := 0, 0, 0
+	for _, c := range segment {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			hex++
+		case c == '-' || c == '_':
+		default:
+			other++
+		}
+	}
+	if other > 0 {
+		// words containing some digits, e.g. v2 or user-42, are only
+		// considered variable when mostly made of digits
+		return digits*2 > len(segment)
+	}
+	// numeric IDs, UUIDs and hashes, but not short words made of a-f
+	// letters like "feed"
+	return digits > 0 && (hex == 0 || digits+hex >= 8)
+}
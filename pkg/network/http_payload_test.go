@@ -0,0 +1,76 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHTTPRequestLine(t *testing.T) {
+	for _, tc := range []struct {
+		payload string
+		path    string
+		ok      bool
+	}{
+		{payload: "GET /users/42?foo=bar HTTP/1.1\r\nHost: example.com\r\n\r\n", path: "/users/42?foo=bar", ok: true},
+		{payload: "POST / HTTP/1.0\r\n", path: "/", ok: true},
+		{payload: "OPTIONS * HTTP/1.1\r\n", path: "*", ok: true},
+		{payload: "GET /users HTTP/2.0\r\n", ok: false},
+		{payload: "GET  HTTP/1.1\r\n", ok: false},
+		{payload: "FETCH /users HTTP/1.1\r\n", ok: false},
+		{payload: "HTTP/1.1 200 OK\r\n", ok: false},
+		{payload: "", ok: false},
+	} {
+		path, ok := parseHTTPRequestLine([]byte(tc.payload))
+		assert.Equal(t, tc.ok, ok, tc.payload)
+		assert.Equal(t, tc.path, string(path), tc.payload)
+	}
+}
+
+func TestParseHTTPStatusLine(t *testing.T) {
+	for _, tc := range []struct {
+		payload string
+		status  uint16
+		ok      bool
+	}{
+		{payload: "HTTP/1.1 200 OK\r\n", status: 200, ok: true},
+		{payload: "HTTP/1.0 404 Not Found\r\n", status: 404, ok: true},
+		{payload: "HTTP/1.1 503", status: 503, ok: true},
+		{payload: "HTTP/1.1 20", ok: false},
+		{payload: "HTTP/1.1 2x0 OK\r\n", ok: false},
+		{payload: "HTTP/1.1 700 Unknown\r\n", ok: false},
+		{payload: "HTTP/2 200\r\n", ok: false},
+		{payload: "GET / HTTP/1.1\r\n", ok: false},
+	} {
+		status, ok := parseHTTPStatusLine([]byte(tc.payload))
+		assert.Equal(t, tc.ok, ok, tc.payload)
+		assert.Equal(t, tc.status, status, tc.payload)
+	}
+}
+
+func TestHTTPStatusClass(t *testing.T) {
+	assert.EqualValues(t, 100, httpStatusClass(101))
+	assert.EqualValues(t, 200, httpStatusClass(204))
+	assert.EqualValues(t, 400, httpStatusClass(404))
+	assert.EqualValues(t, 500, httpStatusClass(599))
+}
+
+func TestPathTemplate(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/":                              "/",
+		"/users":                         "/users",
+		"/users/":                        "/users/",
+		"/users/42":                      "/users/*",
+		"/users/42/orders/1337?sort=asc": "/users/*/orders/*",
+		"/api/v2/feed#top":               "/api/v2/feed",
+		"/items/123e4567-e89b-12d3-a456-426614174000": "/items/*",
+		"/commits/3f1c9a7e2b4d6058":                   "/commits/*",
+		"/files/report-2020":                          "/files/report-2020",
+		"/files/2020-10-18":                           "/files/*",
+		"/a/b/c/d/e/f/g/h/i/j":                        "/a/b/c/d/e/f/g/h/*",
+		"*":                                           "*",
+		"http://example.com/users/42":                 "*",
+	} {
+		assert.Equal(t, expected, pathTemplate([]byte(path)), path)
+	}
+}
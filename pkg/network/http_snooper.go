@@ -0,0 +1,206 @@
+// +build linux_bpf
+
+package network
+
+import (
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"golang.org/x/net/bpf"
+)
+
+var _ HTTPMonitor = &HTTPSnooper{}
+
+// HTTPSnooper is a plaintext HTTP/1.x traffic snooper built on top of a RAW_SOCKET.
+// It matches requests and responses exchanged over each connection and aggregates
+// them by path template and status class.
+type HTTPSnooper struct {
+	source     *packetSource
+	parser     *httpParser
+	statKeeper *httpStatKeeper
+	exit       chan struct{}
+	wg         sync.WaitGroup
+
+	// packet telemetry
+	captured       int64
+	processed      int64
+	dropped        int64
+	polls          int64
+	decodingErrors int64
+}
+
+// NewHTTPSnooper returns a new HTTPSnooper
+func NewHTTPSnooper(rootPath string, timeout time.Duration) (*HTTPSnooper, error) {
+	var (
+		packetSrc *packetSource
+		srcErr    error
+	)
+
+	// Create the RAW_SOCKET inside the root network namespace
+	nsErr := util.WithRootNS(rootPath, func() {
+		packetSrc, srcErr = newClassicBPFPacketSource(tcpPayloadFilter)
+	})
+	if nsErr != nil {
+		return nil, nsErr
+	}
+	if srcErr != nil {
+		return nil, srcErr
+	}
+
+	snooper := &HTTPSnooper{
+		source:     packetSrc,
+		parser:     newHTTPParser(),
+		statKeeper: newHTTPStatKeeper(timeout),
+		exit:       make(chan struct{}),
+	}
+
+	// Start consuming packets
+	snooper.wg.Add(1)
+	go func() {
+		snooper.pollPackets()
+		snooper.wg.Done()
+	}()
+
+	// Start polling socket stats
+	snooper.wg.Add(1)
+	go func() {
+		snooper.pollStats()
+		snooper.wg.Done()
+	}()
+
+	return snooper, nil
+}
+
+// GetHTTPStats returns the HTTP aggregates collected since the last call
+func (s *HTTPSnooper) GetHTTPStats() map[httpKey]map[httpStatsKey]httpStats {
+	return s.statKeeper.GetAndResetAllStats()
+}
+
+// GetStats returns telemetry about the snooper
+func (s *HTTPSnooper) GetStats() map[string]int64 {
+	stats := s.statKeeper.GetStats()
+	stats["socket_polls"] = atomic.LoadInt64(&s.polls)
+	stats["packets_processed"] = atomic.LoadInt64(&s.processed)
+	stats["packets_captured"] = atomic.LoadInt64(&s.captured)
+	stats["packets_dropped"] = atomic.LoadInt64(&s.dropped)
+	stats["decoding_errors"] = atomic.LoadInt64(&s.decodingErrors)
+	return stats
+}
+
+// Close terminates the HTTP traffic snooper as well as the underlying socket
+func (s *HTTPSnooper) Close() {
+	close(s.exit)
+	s.wg.Wait()
+	s.source.Close()
+	s.statKeeper.Close()
+}
+
+// processPacket retrieves the HTTP request or status line from the received packet data.
+// The underlying packet data can't be referenced after this method call since the
+// underlying memory content gets invalidated by `afpacket`.
+func (s *HTTPSnooper) processPacket(data []byte) {
+	ts := time.Now() // record the timestamp before we do any processing
+	pktInfo := httpPacketInfo{}
+
+	if err := s.parser.ParseInto(data, &pktInfo); err != nil {
+		if err != errHTTPSkippedPayload {
+			atomic.AddInt64(&s.decodingErrors, 1)
+			log.Tracef("error decoding HTTP payload: %v", err)
+		}
+		return
+	}
+
+	s.statKeeper.ProcessPacketInfo(pktInfo, ts)
+}
+
+func (s *HTTPSnooper) pollPackets() {
+	for {
+		data, _, err := s.source.ZeroCopyReadPacketData()
+
+		// Properly synchronizes termination process
+		select {
+		case <-s.exit:
+			return
+		default:
+		}
+
+		if err == nil {
+			s.processPacket(data)
+			continue
+		}
+
+		// Immediately retry for EAGAIN
+		if err == syscall.EAGAIN {
+			continue
+		}
+
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (s *HTTPSnooper) pollStats() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	var (
+		prevPolls     int64
+		prevProcessed int64
+		prevCaptured  int64
+		prevDropped   int64
+	)
+
+	for {
+		select {
+		case <-ticker.C:
+			sourceStats, _ := s.source.Stats()
+			_, socketStats, err := s.source.SocketStats()
+			if err != nil {
+				log.Errorf("error polling socket stats: %s", err)
+				continue
+			}
+
+			atomic.AddInt64(&s.polls, sourceStats.Polls-prevPolls)
+			atomic.AddInt64(&s.processed, sourceStats.Packets-prevProcessed)
+			atomic.AddInt64(&s.captured, int64(socketStats.Packets())-prevCaptured)
+			atomic.AddInt64(&s.dropped, int64(socketStats.Drops())-prevDropped)
+
+			prevPolls = sourceStats.Polls
+			prevProcessed = sourceStats.Packets
+			prevCaptured = int64(socketStats.Packets())
+			prevDropped = int64(socketStats.Drops())
+		case <-s.exit:
+			return
+		}
+	}
+}
+
+// tcpPayloadFilter is a classic BPF program only accepting TCP segments which may
+// carry the beginning of an HTTP message. For IPv4, segments without the PSH flag
+// (e.g. handshakes and pure ACKs) are filtered out. It is equivalent to the
+// following tcpdump expression:
+//   (ip and tcp and tcp[tcpflags] & tcp-push != 0) or (ip6 and ip6[6] == 6)
+var tcpPayloadFilter = []bpf.Instruction{
+	// load the ethertype
+	bpf.LoadAbsolute{Off: 12, Size: 2},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x0800, SkipFalse: 5},
+	// IPv4: check the protocol is TCP
+	bpf.LoadAbsolute{Off: 23, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 7},
+	// load the IPv4 header length into X, then check the PSH flag of the TCP header
+	bpf.LoadMemShift{Off: 14},
+	bpf.LoadIndirect{Off: 14 + 13, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpBitsSet, Val: 0x08, SkipTrue: 3, SkipFalse: 4},
+	// IPv6: check the next header is TCP
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: 3},
+	bpf.LoadAbsolute{Off: 20, Size: 1},
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 1},
+	// accept the packet (truncated to the frame size)
+	bpf.RetConstant{Val: 0xffff},
+	// drop the packet
+	bpf.RetConstant{Val: 0},
+}
@@ -0,0 +1,242 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/sketches-go/ddsketch"
+)
+
+// httpKey identifies the connection an HTTP transaction belongs to, oriented
+// from the client to the server
+type httpKey struct {
+	clientIP   util.Address
+	serverIP   util.Address
+	clientPort uint16
+	serverPort uint16
+}
+
+type httpStatsKey struct {
+	path        string
+	statusClass uint16
+}
+
+type httpStats struct {
+	requests   uint32
+	latencySum uint64             // Stored in µs
+	latencies  *ddsketch.DDSketch // Stored in µs, nil if all the requests timed out
+}
+
+// merge adds the transactions of other to s. The latencies of other are copied
+// since the same stats can be merged into the stats of several clients.
+func (s *httpStats) merge(other httpStats) {
+	s.requests += other.requests
+	s.latencySum += other.latencySum
+	if other.latencies == nil {
+		return
+	}
+	if s.latencies == nil {
+		s.latencies = other.latencies.Copy()
+		return
+	}
+	if err := s.latencies.MergeWith(other.latencies); err != nil {
+		log.Debugf("error merging HTTP latencies: %s", err)
+	}
+}
+
+// HTTPPacketType tells us whether the packet holds the beginning of a request or of a response
+type HTTPPacketType uint8
+
+const (
+	// HTTPRequest means the packet starts with an HTTP request line
+	HTTPRequest HTTPPacketType = iota
+	// HTTPResponse means the packet starts with an HTTP status line
+	HTTPResponse
+)
+
+const (
+	// HTTPLatencyRelativeAccuracy is the relative accuracy of the latency distributions
+	HTTPLatencyRelativeAccuracy = 0.01
+	// MaxHTTPStateMapSize limits the number of in-flight requests tracked at any given time
+	MaxHTTPStateMapSize = 10000
+	// MaxHTTPStatsMapSize limits the number of (connection, path, status class) aggregates kept between two reads
+	MaxHTTPStatsMapSize = 50000
+)
+
+type httpPacketInfo struct {
+	key        httpKey
+	pktType    HTTPPacketType
+	path       string // only set for requests
+	statusCode uint16 // only set for responses
+}
+
+type httpTransaction struct {
+	start uint64 // Stored in µs
+	path  string
+}
+
+type httpStatKeeper struct {
+	mux          sync.Mutex
+	stats        map[httpKey]map[httpStatsKey]httpStats
+	numStats     int
+	state        map[httpKey]httpTransaction
+	timeout      time.Duration
+	exit         chan struct{}
+	maxStateSize int
+	maxStatsSize int
+	deleteCount  int
+
+	// telemetry
+	transactions int64
+	timeouts     int64
+	statsDropped int64
+}
+
+func newHTTPStatKeeper(timeout time.Duration) *httpStatKeeper {
+	keeper := &httpStatKeeper{
+		stats:        make(map[httpKey]map[httpStatsKey]httpStats),
+		state:        make(map[httpKey]httpTransaction),
+		timeout:      timeout,
+		exit:         make(chan struct{}),
+		maxStateSize: MaxHTTPStateMapSize,
+		maxStatsSize: MaxHTTPStatsMapSize,
+	}
+
+	ticker := time.NewTicker(keeper.timeout)
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				keeper.removeExpiredStates(now.Add(-keeper.timeout))
+			case <-keeper.exit:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return keeper
+}
+
+// ProcessPacketInfo matches responses with the request previously seen on the
+// same connection. HTTP/1.x clients wait for a response before sending the next
+// request on a connection (pipelining is very rarely enabled), so a single
+// in-flight request is tracked per connection.
+func (h *httpStatKeeper) ProcessPacketInfo(info httpPacketInfo, ts time.Time) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if info.pktType == HTTPRequest {
+		if _, ok := h.state[info.key]; !ok && len(h.state) >= h.maxStateSize {
+			return
+		}
+		// a new request on the same connection supersedes a request which
+		// didn't get a response
+		h.state[info.key] = httpTransaction{start: microSecs(ts), path: info.path}
+		return
+	}
+
+	// If a response does not have a corresponding request, we discard it
+	tx, ok := h.state[info.key]
+	if !ok {
+		return
+	}
+	delete(h.state, info.key)
+	h.deleteCount++
+
+	latency := microSecs(ts) - tx.start
+	if latency > uint64(h.timeout.Microseconds()) {
+		h.add(info.key, httpStatsKey{path: tx.path}, 0)
+		h.timeouts++
+		return
+	}
+	h.add(info.key, httpStatsKey{path: tx.path, statusClass: httpStatusClass(info.statusCode)}, latency)
+	h.transactions++
+}
+
+func (h *httpStatKeeper) add(key httpKey, sk httpStatsKey, latency uint64) {
+	byPath := h.stats[key]
+	st, ok := byPath[sk]
+	if !ok {
+		if h.numStats >= h.maxStatsSize {
+			h.statsDropped++
+			return
+		}
+		if byPath == nil {
+			byPath = make(map[httpStatsKey]httpStats)
+			h.stats[key] = byPath
+		}
+		h.numStats++
+	}
+	st.requests++
+	st.latencySum += latency
+	// the latency of timed out requests is not meaningful
+	if sk.statusClass != 0 {
+		if st.latencies == nil {
+			st.latencies = newHTTPLatencySketch()
+		}
+		if err := st.latencies.Add(float64(latency)); err != nil {
+			log.Debugf("error recording HTTP latency: %s", err)
+		}
+	}
+	byPath[sk] = st
+}
+
+func newHTTPLatencySketch() *ddsketch.DDSketch {
+	// the parameters are constants, this can't fail
+	sketch, _ := ddsketch.LogCollapsingLowestDenseDDSketch(HTTPLatencyRelativeAccuracy, 1024)
+	return sketch
+}
+
+// GetAndResetAllStats returns the aggregates collected since the last call
+func (h *httpStatKeeper) GetAndResetAllStats() map[httpKey]map[httpStatsKey]httpStats {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	ret := h.stats
+	h.stats = make(map[httpKey]map[httpStatsKey]httpStats)
+	h.numStats = 0
+	return ret
+}
+
+// GetStats returns telemetry about the transactions processed so far
+func (h *httpStatKeeper) GetStats() map[string]int64 {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return map[string]int64{
+		"transactions":  h.transactions,
+		"timeouts":      h.timeouts,
+		"stats_dropped": h.statsDropped,
+	}
+}
+
+func (h *httpStatKeeper) removeExpiredStates(earliestTs time.Time) {
+	deleteThreshold := 5000
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	threshold := microSecs(earliestTs)
+	for k, v := range h.state {
+		if v.start < threshold {
+			delete(h.state, k)
+			h.deleteCount++
+			h.add(k, httpStatsKey{path: v.path}, 0)
+			h.timeouts++
+		}
+	}
+
+	if h.deleteCount < deleteThreshold {
+		return
+	}
+
+	// golang/go#20135 : maps do not shrink after elements removal (delete)
+	copied := make(map[httpKey]httpTransaction, len(h.state))
+	for k, v := range h.state {
+		copied[k] = v
+	}
+	h.state = copied
+	h.deleteCount = 0
+}
+
+func (h *httpStatKeeper) Close() {
+	h.exit <- struct{}{}
+}
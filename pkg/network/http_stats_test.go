@@ -0,0 +1,125 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+const httpTimeout = 10 * time.Second
+
+var testHTTPKey = httpKey{
+	clientIP:   util.AddressFromString("10.0.0.1"),
+	clientPort: 50000,
+	serverIP:   util.AddressFromString("10.0.0.2"),
+	serverPort: 8080,
+}
+
+func TestHTTPTransaction(t *testing.T) {
+	sk := newHTTPStatKeeper(httpTimeout)
+	defer sk.Close()
+
+	then := time.Now()
+	sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPRequest, path: "/users/*"}, then)
+	assert.Empty(t, sk.GetAndResetAllStats())
+
+	sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPResponse, statusCode: 404}, then.Add(time.Millisecond))
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, testHTTPKey)
+	assert.Equal(t, map[httpStatsKey]httpStats{
+		{path: "/users/*", statusClass: 400}: {requests: 1, latencySum: 1000},
+	}, withoutLatencies(stats[testHTTPKey]))
+	assert.Equal(t, float64(1), stats[testHTTPKey][httpStatsKey{path: "/users/*", statusClass: 400}].latencies.GetCount())
+
+	// stats are reset after being read
+	assert.Empty(t, sk.GetAndResetAllStats())
+	assert.Equal(t, int64(1), sk.GetStats()["transactions"])
+}
+
+func TestHTTPResponseWithoutRequest(t *testing.T) {
+	sk := newHTTPStatKeeper(httpTimeout)
+	defer sk.Close()
+
+	sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPResponse, statusCode: 200}, time.Now())
+	assert.Empty(t, sk.GetAndResetAllStats())
+}
+
+func TestHTTPAggregation(t *testing.T) {
+	sk := newHTTPStatKeeper(httpTimeout)
+	defer sk.Close()
+
+	then := time.Now()
+	for i, status := range []uint16{200, 204, 500} {
+		start := then.Add(time.Duration(i) * time.Second)
+		sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPRequest, path: "/"}, start)
+		sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPResponse, statusCode: status}, start.Add(2*time.Millisecond))
+	}
+
+	stats := sk.GetAndResetAllStats()
+	assert.Equal(t, map[httpStatsKey]httpStats{
+		{path: "/", statusClass: 200}: {requests: 2, latencySum: 4000},
+		{path: "/", statusClass: 500}: {requests: 1, latencySum: 2000},
+	}, withoutLatencies(stats[testHTTPKey]))
+
+	latencies := stats[testHTTPKey][httpStatsKey{path: "/", statusClass: 200}].latencies
+	require.NotNil(t, latencies)
+	assert.Equal(t, float64(2), latencies.GetCount())
+	median, err := latencies.GetValueAtQuantile(0.5)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 2000, median, HTTPLatencyRelativeAccuracy)
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	sk := newHTTPStatKeeper(httpTimeout)
+	defer sk.Close()
+
+	then := time.Now()
+	sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPRequest, path: "/slow"}, then)
+	sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPResponse, statusCode: 200}, then.Add(httpTimeout+time.Second))
+
+	// Late responses are recorded as timeouts
+	stats := sk.GetAndResetAllStats()
+	assert.Equal(t, map[httpStatsKey]httpStats{
+		{path: "/slow"}: {requests: 1},
+	}, stats[testHTTPKey])
+
+	// Requests without any response are expired
+	sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPRequest, path: "/slow"}, then)
+	sk.removeExpiredStates(then.Add(time.Second))
+	stats = sk.GetAndResetAllStats()
+	assert.Equal(t, map[httpStatsKey]httpStats{
+		{path: "/slow"}: {requests: 1},
+	}, stats[testHTTPKey])
+	assert.Equal(t, int64(2), sk.GetStats()["timeouts"])
+}
+
+func TestHTTPStatsLimit(t *testing.T) {
+	sk := newHTTPStatKeeper(httpTimeout)
+	sk.maxStatsSize = 1
+	defer sk.Close()
+
+	then := time.Now()
+	for _, path := range []string{"/a", "/b"} {
+		sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPRequest, path: path}, then)
+		sk.ProcessPacketInfo(httpPacketInfo{key: testHTTPKey, pktType: HTTPResponse, statusCode: 200}, then)
+	}
+
+	stats := sk.GetAndResetAllStats()
+	assert.Len(t, stats[testHTTPKey], 1)
+	assert.Equal(t, int64(1), sk.GetStats()["stats_dropped"])
+}
+
+// withoutLatencies returns a copy of stats without the latency distributions,
+// which can't be compared directly
+func withoutLatencies(stats map[httpStatsKey]httpStats) map[httpStatsKey]httpStats {
+	res := make(map[httpStatsKey]httpStats, len(stats))
+	for k, v := range stats {
+		v.latencies = nil
+		res[k] = v
+	}
+	return res
+}
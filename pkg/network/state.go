@@ -2,6 +2,7 @@ package network
 
 import (
 	"bytes"
+	"sort"
 	"sync"
 	"time"
 
@@ -34,6 +35,10 @@ type State interface {
 	// StoreClosedConnection stores a new closed connection
 	StoreClosedConnection(conn ConnectionStats)
 
+	// StoreHTTPStats stores the latest HTTP aggregates, to be attached to the connections
+	// returned by the next call to Connections for each client
+	StoreHTTPStats(stats map[httpKey]map[httpStatsKey]httpStats)
+
 	// RemoveClient stops tracking stateful data for a given client
 	RemoveClient(clientID string)
 
@@ -58,12 +63,17 @@ type telemetry struct {
 	timeSyncCollisions int64
 	dnsStatsDropped    int64
 	dnsPidCollisions   int64
+	httpStatsDropped   int64
 }
 
 type stats struct {
-	totalSent        uint64
-	totalRecv        uint64
-	totalRetransmits uint32
+	totalSent            uint64
+	totalRecv            uint64
+	totalRetransmits     uint32
+	totalConnectFailures uint32
+	totalResets          uint32
+	totalTCPEstablished  uint32
+	totalTCPClosed       uint32
 }
 
 type client struct {
@@ -72,6 +82,7 @@ type client struct {
 	closedConnections map[string]ConnectionStats
 	stats             map[string]*stats
 	dnsStats          map[dnsKey]dnsStats
+	httpStats         map[httpKey]map[httpStatsKey]httpStats
 }
 
 type networkState struct {
@@ -88,6 +99,7 @@ type networkState struct {
 	maxClosedConns int
 	maxClientStats int
 	maxDNSStats    int
+	maxHTTPStats   int
 }
 
 // NewState creates a new network state
@@ -99,6 +111,7 @@ func NewState(clientExpiry time.Duration, maxClosedConns, maxClientStats int, ma
 		maxClosedConns: maxClosedConns,
 		maxClientStats: maxClientStats,
 		maxDNSStats:    maxDNSStats,
+		maxHTTPStats:   MaxHTTPStatsMapSize,
 		buf:            &bytes.Buffer{},
 	}
 }
@@ -143,6 +156,10 @@ func (ns *networkState) Connections(
 			c.LastSentBytes = 0
 			c.LastRecvBytes = 0
 			c.LastRetransmits = 0
+			c.LastConnectFailures = 0
+			c.LastResets = 0
+			c.LastTCPEstablished = 0
+			c.LastTCPClosed = 0
 		}
 
 		ns.determineConnectionIntraHost(latestConns)
@@ -150,6 +167,7 @@ func (ns *networkState) Connections(
 			ns.storeDNSStats(dnsStats)
 			ns.addDNSStats(id, latestConns)
 		}
+		ns.addHTTPStats(id, latestConns)
 		return latestConns
 	}
 
@@ -174,6 +192,7 @@ func (ns *networkState) Connections(
 		ns.storeDNSStats(dnsStats)
 		ns.addDNSStats(id, conns)
 	}
+	ns.addHTTPStats(id, conns)
 	return conns
 }
 
@@ -202,6 +221,12 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 			conn.DNSTimeouts = dnsStats.timeouts
 			conn.DNSSuccessLatencySum = dnsStats.successLatencySum
 			conn.DNSFailureLatencySum = dnsStats.failureLatencySum
+			if len(dnsStats.byDomain) > 0 {
+				conn.DNSStatsByDomain = make(map[string]DNSDomainStats, len(dnsStats.byDomain))
+				for domain, ds := range dnsStats.byDomain {
+					conn.DNSStatsByDomain[domain] = ds.export()
+				}
+			}
 		}
 		seen[key] = struct{}{}
 	}
@@ -210,6 +235,54 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 	ns.clients[id].dnsStats = make(map[dnsKey]dnsStats)
 }
 
+func (ns *networkState) addHTTPStats(id string, conns []ConnectionStats) {
+	client := ns.clients[id]
+	if len(client.httpStats) == 0 {
+		return
+	}
+
+	for i := range conns {
+		conn := &conns[i]
+		if conn.Type != TCP {
+			continue
+		}
+
+		// the host may be either the client or the server of the HTTP transactions
+		key := httpKey{clientIP: conn.Source, clientPort: conn.SPort, serverIP: conn.Dest, serverPort: conn.DPort}
+		byPath, ok := client.httpStats[key]
+		if !ok {
+			key = httpKey{clientIP: conn.Dest, clientPort: conn.DPort, serverIP: conn.Source, serverPort: conn.SPort}
+			if byPath, ok = client.httpStats[key]; !ok {
+				continue
+			}
+		}
+
+		conn.HTTPStats = make([]HTTPStats, 0, len(byPath))
+		for sk, st := range byPath {
+			conn.HTTPStats = append(conn.HTTPStats, HTTPStats{
+				Path:        sk.path,
+				StatusClass: sk.statusClass,
+				Requests:    st.requests,
+				LatencySum:  st.latencySum,
+				Latencies:   st.latencies,
+			})
+		}
+		sort.Slice(conn.HTTPStats, func(i, j int) bool {
+			a, b := conn.HTTPStats[i], conn.HTTPStats[j]
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			return a.StatusClass < b.StatusClass
+		})
+		// stats are only attached once, even if the connection is reported
+		// several times (e.g. closed and active again)
+		delete(client.httpStats, key)
+	}
+
+	// flush the HTTP stats
+	client.httpStats = make(map[httpKey]map[httpStatsKey]httpStats)
+}
+
 // getConnsByKey returns a mapping of byte-key -> connection for easier access + manipulation
 func getConnsByKey(conns []ConnectionStats, buf *bytes.Buffer) map[string]*ConnectionStats {
 	connsByKey := make(map[string]*ConnectionStats, len(conns))
@@ -248,6 +321,10 @@ func (ns *networkState) StoreClosedConnection(conn ConnectionStats) {
 			prev.MonotonicSentBytes += conn.MonotonicSentBytes
 			prev.MonotonicRecvBytes += conn.MonotonicRecvBytes
 			prev.MonotonicRetransmits += conn.MonotonicRetransmits
+			prev.MonotonicConnectFailures += conn.MonotonicConnectFailures
+			prev.MonotonicResets += conn.MonotonicResets
+			prev.MonotonicTCPEstablished += conn.MonotonicTCPEstablished
+			prev.MonotonicTCPClosed += conn.MonotonicTCPClosed
 			// Also update the timestamp
 			prev.LastUpdateEpoch = conn.LastUpdateEpoch
 			client.closedConnections[string(key)] = prev
@@ -264,19 +341,45 @@ func (ns *networkState) StoreClosedConnection(conn ConnectionStats) {
 func (ns *networkState) storeDNSStats(stats map[dnsKey]dnsStats) {
 	for key, dns := range stats {
 		for _, client := range ns.clients {
-			// If we've seen DNS stats for this key already, lets combine the two
-			if prev, ok := client.dnsStats[key]; ok {
-				prev.successfulResponses += dns.successfulResponses
-				prev.failedResponses += dns.failedResponses
-				prev.timeouts += dns.timeouts
-				prev.successLatencySum += dns.successLatencySum
-				prev.failureLatencySum += dns.failureLatencySum
-				client.dnsStats[key] = prev
-			} else if len(client.dnsStats) >= ns.maxDNSStats {
+			prev, ok := client.dnsStats[key]
+			if !ok && len(client.dnsStats) >= ns.maxDNSStats {
 				ns.telemetry.dnsStatsDropped++
 				continue
-			} else {
-				client.dnsStats[key] = dns
+			}
+			// If we've seen DNS stats for this key already, lets combine the two.
+			// Stats are always merged into a zero value otherwise, so that clients
+			// don't share the per-domain stats.
+			prev.merge(dns)
+			client.dnsStats[key] = prev
+		}
+	}
+}
+
+// StoreHTTPStats stores latest HTTP stats for all clients
+func (ns *networkState) StoreHTTPStats(stats map[httpKey]map[httpStatsKey]httpStats) {
+	if len(stats) == 0 {
+		return
+	}
+
+	ns.Lock()
+	defer ns.Unlock()
+
+	for key, byPath := range stats {
+		for _, client := range ns.clients {
+			prevByPath, ok := client.httpStats[key]
+			if !ok {
+				if len(client.httpStats) >= ns.maxHTTPStats {
+					ns.telemetry.httpStatsDropped++
+					continue
+				}
+				prevByPath = make(map[httpStatsKey]httpStats, len(byPath))
+				client.httpStats[key] = prevByPath
+			}
+			// If we've seen HTTP stats for this key already, lets combine the two
+			for sk, st := range byPath {
+				prev := prevByPath[sk]
+				prev.merge(st)
+				prevByPath[sk] = prev
 			}
 		}
 	}
@@ -293,6 +396,7 @@ func (ns *networkState) newClient(clientID string) (*client, bool) {
 		stats:             map[string]*stats{},
 		closedConnections: map[string]ConnectionStats{},
 		dnsStats:          map[dnsKey]dnsStats{},
+		httpStats:         map[httpKey]map[httpStatsKey]httpStats{},
 	}
 	ns.clients[clientID] = c
 	return c, false
@@ -320,6 +424,10 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 				closedConn.MonotonicSentBytes += activeConn.MonotonicSentBytes
 				closedConn.MonotonicRecvBytes += activeConn.MonotonicRecvBytes
 				closedConn.MonotonicRetransmits += activeConn.MonotonicRetransmits
+				closedConn.MonotonicConnectFailures += activeConn.MonotonicConnectFailures
+				closedConn.MonotonicResets += activeConn.MonotonicResets
+				closedConn.MonotonicTCPEstablished += activeConn.MonotonicTCPEstablished
+				closedConn.MonotonicTCPClosed += activeConn.MonotonicTCPClosed
 
 				ns.createStatsForKey(client, key)
 				ns.updateConnWithStatWithActiveConn(client, key, *activeConn, &closedConn)
@@ -328,6 +436,10 @@ func (ns *networkState) mergeConnections(id string, active map[string]*Connectio
 				// The monotonic counters will be the sum of all connections that cross our interval start + finish.
 				if stats, ok := client.stats[key]; ok {
 					stats.totalRetransmits = activeConn.MonotonicRetransmits
+					stats.totalConnectFailures = activeConn.MonotonicConnectFailures
+					stats.totalResets = activeConn.MonotonicResets
+					stats.totalTCPEstablished = activeConn.MonotonicTCPEstablished
+					stats.totalTCPClosed = activeConn.MonotonicTCPClosed
 					stats.totalSent = activeConn.MonotonicSentBytes
 					stats.totalRecv = activeConn.MonotonicRecvBytes
 				}
@@ -373,15 +485,27 @@ func (ns *networkState) updateConnWithStatWithActiveConn(client *client, key str
 		closed.LastSentBytes = closed.MonotonicSentBytes - st.totalSent
 		closed.LastRecvBytes = closed.MonotonicRecvBytes - st.totalRecv
 		closed.LastRetransmits = closed.MonotonicRetransmits - st.totalRetransmits
+		closed.LastConnectFailures = closed.MonotonicConnectFailures - st.totalConnectFailures
+		closed.LastResets = closed.MonotonicResets - st.totalResets
+		closed.LastTCPEstablished = closed.MonotonicTCPEstablished - st.totalTCPEstablished
+		closed.LastTCPClosed = closed.MonotonicTCPClosed - st.totalTCPClosed
 
 		// Update stats object with latest values
 		st.totalSent = active.MonotonicSentBytes
 		st.totalRecv = active.MonotonicRecvBytes
 		st.totalRetransmits = active.MonotonicRetransmits
+		st.totalConnectFailures = active.MonotonicConnectFailures
+		st.totalResets = active.MonotonicResets
+		st.totalTCPEstablished = active.MonotonicTCPEstablished
+		st.totalTCPClosed = active.MonotonicTCPClosed
 	} else {
 		closed.LastSentBytes = closed.MonotonicSentBytes
 		closed.LastRecvBytes = closed.MonotonicRecvBytes
 		closed.LastRetransmits = closed.MonotonicRetransmits
+		closed.LastConnectFailures = closed.MonotonicConnectFailures
+		closed.LastResets = closed.MonotonicResets
+		closed.LastTCPEstablished = closed.MonotonicTCPEstablished
+		closed.LastTCPClosed = closed.MonotonicTCPClosed
 	}
 }
 
@@ -393,26 +517,44 @@ func (ns *networkState) updateConnWithStats(client *client, key string, c *Conne
 		c.LastSentBytes = c.MonotonicSentBytes - st.totalSent
 		c.LastRecvBytes = c.MonotonicRecvBytes - st.totalRecv
 		c.LastRetransmits = c.MonotonicRetransmits - st.totalRetransmits
+		c.LastConnectFailures = c.MonotonicConnectFailures - st.totalConnectFailures
+		c.LastResets = c.MonotonicResets - st.totalResets
+		c.LastTCPEstablished = c.MonotonicTCPEstablished - st.totalTCPEstablished
+		c.LastTCPClosed = c.MonotonicTCPClosed - st.totalTCPClosed
 
 		// Update stats object with latest values
 		st.totalSent = c.MonotonicSentBytes
 		st.totalRecv = c.MonotonicRecvBytes
 		st.totalRetransmits = c.MonotonicRetransmits
+		st.totalConnectFailures = c.MonotonicConnectFailures
+		st.totalResets = c.MonotonicResets
+		st.totalTCPEstablished = c.MonotonicTCPEstablished
+		st.totalTCPClosed = c.MonotonicTCPClosed
 	} else {
 		c.LastSentBytes = c.MonotonicSentBytes
 		c.LastRecvBytes = c.MonotonicRecvBytes
 		c.LastRetransmits = c.MonotonicRetransmits
+		c.LastConnectFailures = c.MonotonicConnectFailures
+		c.LastResets = c.MonotonicResets
+		c.LastTCPEstablished = c.MonotonicTCPEstablished
+		c.LastTCPClosed = c.MonotonicTCPClosed
 	}
 }
 
 // handleStatsUnderflow checks if we are going to have an underflow when computing last stats and if it's the case it resets the stats to avoid it
 func (ns *networkState) handleStatsUnderflow(key string, st *stats, c *ConnectionStats) {
-	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits {
+	if c.MonotonicSentBytes < st.totalSent || c.MonotonicRecvBytes < st.totalRecv || c.MonotonicRetransmits < st.totalRetransmits ||
+		c.MonotonicConnectFailures < st.totalConnectFailures || c.MonotonicResets < st.totalResets ||
+		c.MonotonicTCPEstablished < st.totalTCPEstablished || c.MonotonicTCPClosed < st.totalTCPClosed {
 		ns.telemetry.statsResets++
 		log.Debugf("Stats reset triggered for key:%s, stats:%+v, connection:%+v", BeautifyKey(key), *st, *c)
 		st.totalSent = 0
 		st.totalRecv = 0
 		st.totalRetransmits = 0
+		st.totalConnectFailures = 0
+		st.totalResets = 0
+		st.totalTCPEstablished = 0
+		st.totalTCPClosed = 0
 	}
 }
 
@@ -464,6 +606,7 @@ func (ns *networkState) RemoveConnections(keys []string) {
 		s += " [%d closed connections dropped]"
 		s += " [%d dns stats dropped]"
 		s += " [%d DNS pid collisions]"
+		s += " [%d http stats dropped]"
 		s += " [%d time sync collisions]"
 		log.Warnf(s,
 			ns.telemetry.unorderedConns,
@@ -472,6 +615,7 @@ func (ns *networkState) RemoveConnections(keys []string) {
 			ns.telemetry.closedConnDropped,
 			ns.telemetry.dnsStatsDropped,
 			ns.telemetry.dnsPidCollisions,
+			ns.telemetry.httpStatsDropped,
 			ns.telemetry.timeSyncCollisions)
 	}
 
@@ -502,6 +646,7 @@ func (ns *networkState) GetStats() map[string]interface{} {
 			"time_sync_collisions": ns.telemetry.timeSyncCollisions,
 			"dns_stats_dropped":    ns.telemetry.dnsStatsDropped,
 			"dns_pid_collisions":   ns.telemetry.dnsPidCollisions,
+			"http_stats_dropped":   ns.telemetry.httpStatsDropped,
 		},
 		"current_time":       time.Now().Unix(),
 		"latest_bpf_time_ns": ns.latestTimeEpoch,
@@ -517,9 +662,13 @@ func (ns *networkState) DumpState(clientID string) map[string]interface{} {
 	if client, ok := ns.clients[clientID]; ok {
 		for connKey, s := range client.stats {
 			data[BeautifyKey(connKey)] = map[string]uint64{
-				"total_sent":        s.totalSent,
-				"total_recv":        s.totalRecv,
-				"total_retransmits": uint64(s.totalRetransmits),
+				"total_sent":             s.totalSent,
+				"total_recv":             s.totalRecv,
+				"total_retransmits":      uint64(s.totalRetransmits),
+				"total_connect_failures": uint64(s.totalConnectFailures),
+				"total_resets":           uint64(s.totalResets),
+				"total_tcp_established":  uint64(s.totalTCPEstablished),
+				"total_tcp_closed":       uint64(s.totalTCPClosed),
 			}
 		}
 	}
@@ -319,6 +319,133 @@ func TestLastStats(t *testing.T) {
 	assert.Equal(t, conn3.MonotonicRetransmits, conns[0].MonotonicRetransmits)
 }
 
+func TestConnectFailures(t *testing.T) {
+	client := "client"
+	state := newDefaultState()
+
+	conn := ConnectionStats{
+		Pid:                      123,
+		Type:                     TCP,
+		Family:                   AFINET,
+		Source:                   util.AddressFromString("10.0.0.1"),
+		Dest:                     util.AddressFromString("10.0.0.2"),
+		SPort:                    31890,
+		DPort:                    443,
+		MonotonicConnectFailures: 1,
+	}
+
+	// Register the client
+	assert.Len(t, state.Connections(client, latestEpochTime(), nil, nil), 0)
+
+	// Two failed attempts for the same tuple are folded together
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+
+	conns := state.Connections(client, latestEpochTime(), nil, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(2), conns[0].MonotonicConnectFailures)
+	assert.Equal(t, uint32(2), conns[0].LastConnectFailures)
+
+	// A failed attempt followed by a successful one for the same tuple
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+	active := conn
+	active.MonotonicConnectFailures = 0
+	active.MonotonicSentBytes = 10
+	active.LastUpdateEpoch = latestEpochTime()
+
+	conns = state.Connections(client, latestEpochTime(), []ConnectionStats{active}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].LastConnectFailures)
+	assert.Equal(t, uint64(10), conns[0].LastSentBytes)
+
+	// Failures are only reported once
+	active.MonotonicSentBytes = 15
+	active.LastUpdateEpoch = latestEpochTime()
+	conns = state.Connections(client, latestEpochTime(), []ConnectionStats{active}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(0), conns[0].LastConnectFailures)
+	assert.Equal(t, uint64(5), conns[0].LastSentBytes)
+}
+
+func TestTCPStateTransitions(t *testing.T) {
+	client := "client"
+	state := newDefaultState()
+
+	conn := ConnectionStats{
+		Pid:                     123,
+		Type:                    TCP,
+		Family:                  AFINET,
+		Source:                  util.AddressFromString("10.0.0.1"),
+		Dest:                    util.AddressFromString("10.0.0.2"),
+		SPort:                   31890,
+		DPort:                   443,
+		MonotonicTCPEstablished: 1,
+	}
+
+	// Register the client
+	assert.Len(t, state.Connections(client, latestEpochTime(), nil, nil), 0)
+
+	conn.LastUpdateEpoch = latestEpochTime()
+	conns := state.Connections(client, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].LastTCPEstablished)
+	assert.Equal(t, uint32(0), conns[0].LastTCPClosed)
+
+	// The connection got established during the previous interval, only its close is reported
+	conn.MonotonicTCPClosed = 1
+	conn.LastUpdateEpoch = latestEpochTime()
+	state.StoreClosedConnection(conn)
+	conns = state.Connections(client, latestEpochTime(), nil, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(0), conns[0].LastTCPEstablished)
+	assert.Equal(t, uint32(1), conns[0].LastTCPClosed)
+}
+
+func TestResets(t *testing.T) {
+	client1 := "1"
+	client2 := "2"
+	state := newDefaultState()
+
+	conn := ConnectionStats{
+		Pid:             123,
+		Type:            TCP,
+		Family:          AFINET,
+		Source:          util.AddressFromString("10.0.0.1"),
+		Dest:            util.AddressFromString("10.0.0.2"),
+		SPort:           31890,
+		DPort:           443,
+		MonotonicResets: 1,
+	}
+
+	// Register the clients
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil), 0)
+
+	conns := state.Connections(client1, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].LastResets)
+
+	conn.MonotonicResets = 3
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(2), conns[0].LastResets)
+
+	// client 2 didn't collect the first value
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(3), conns[0].LastResets)
+
+	// A decreasing counter triggers a stats reset rather than an underflow
+	conn.MonotonicResets = 1
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{conn}, nil)
+	require.Len(t, conns, 1)
+	assert.Equal(t, uint32(1), conns[0].LastResets)
+	assert.Equal(t, int64(1), state.(*networkState).telemetry.statsResets)
+}
+
 func TestLastStatsForClosedConnection(t *testing.T) {
 	clientID := "1"
 	state := newDefaultState()
@@ -1209,6 +1336,122 @@ func TestDNSStatsPIDCollisions(t *testing.T) {
 	assert.Equal(t, int64(1), state.(*networkState).telemetry.dnsPidCollisions)
 }
 
+func TestDNSStatsByDomain(t *testing.T) {
+	c := ConnectionStats{
+		Pid:    123,
+		Type:   UDP,
+		Family: AFINET,
+		Source: util.AddressFromString("10.0.0.1"),
+		Dest:   util.AddressFromString("8.8.8.8"),
+		SPort:  1000,
+		DPort:  53,
+	}
+
+	dKey := dnsKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
+	newStats := func() map[dnsKey]dnsStats {
+		ds := dnsDomainStats{failureLatencySum: 100}
+		ds.countByRcode[3] = 1
+		return map[dnsKey]dnsStats{
+			dKey: {failedResponses: 1, byDomain: map[string]dnsDomainStats{"golang.org": ds}},
+		}
+	}
+
+	client1 := "client1"
+	client2 := "client2"
+	state := newDefaultState()
+
+	// Register the clients
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil), 0)
+
+	c.LastUpdateEpoch = latestEpochTime()
+	conns := state.Connections(client1, latestEpochTime(), []ConnectionStats{c}, newStats())
+	require.Len(t, conns, 1)
+	require.Contains(t, conns[0].DNSStatsByDomain, "golang.org")
+	assert.Equal(t, map[uint8]uint32{3: 1}, conns[0].DNSStatsByDomain["golang.org"].CountByRcode)
+
+	// The 2nd client should get accumulated stats, without the first
+	// client's stats being affected
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{c}, newStats())
+	require.Len(t, conns, 1)
+	assert.Equal(t, map[uint8]uint32{3: 2}, conns[0].DNSStatsByDomain["golang.org"].CountByRcode)
+	assert.Equal(t, uint64(200), conns[0].DNSStatsByDomain["golang.org"].FailureLatencySum)
+	assert.EqualValues(t, 2, conns[0].DNSFailedResponses)
+}
+
+func TestHTTPStatsWithMultipleClients(t *testing.T) {
+	c := ConnectionStats{
+		Pid:    123,
+		Type:   TCP,
+		Family: AFINET,
+		Source: util.AddressFromString("10.0.0.1"),
+		Dest:   util.AddressFromString("10.0.0.2"),
+		SPort:  8080,
+		DPort:  50000,
+	}
+
+	// the host is the server of the HTTP transactions
+	hKey := httpKey{clientIP: c.Dest, clientPort: c.DPort, serverIP: c.Source, serverPort: c.SPort}
+	newStats := func() map[httpKey]map[httpStatsKey]httpStats {
+		users, root := newHTTPLatencySketch(), newHTTPLatencySketch()
+		require.NoError(t, users.Add(100))
+		require.NoError(t, users.Add(200))
+		require.NoError(t, root.Add(50))
+		return map[httpKey]map[httpStatsKey]httpStats{
+			hKey: {
+				{path: "/users/*", statusClass: 200}: {requests: 2, latencySum: 300, latencies: users},
+				{path: "/", statusClass: 500}:        {requests: 1, latencySum: 50, latencies: root},
+			},
+		}
+	}
+
+	client1 := "client1"
+	client2 := "client2"
+	state := newDefaultState()
+
+	// Register the clients
+	assert.Len(t, state.Connections(client1, latestEpochTime(), nil, nil), 0)
+	assert.Len(t, state.Connections(client2, latestEpochTime(), nil, nil), 0)
+
+	state.StoreHTTPStats(newStats())
+	c.LastUpdateEpoch = latestEpochTime()
+	conns := state.Connections(client1, latestEpochTime(), []ConnectionStats{c}, nil)
+	require.Len(t, conns, 1)
+	require.Len(t, conns[0].HTTPStats, 2)
+	assert.Equal(t, float64(1), conns[0].HTTPStats[0].Latencies.GetCount())
+	assert.Equal(t, float64(2), conns[0].HTTPStats[1].Latencies.GetCount())
+	assert.Equal(t, []HTTPStats{
+		{Path: "/", StatusClass: 500, Requests: 1, LatencySum: 50},
+		{Path: "/users/*", StatusClass: 200, Requests: 2, LatencySum: 300},
+	}, withoutHTTPLatencies(conns[0].HTTPStats))
+
+	// Stats are flushed once they have been returned to a client
+	conns = state.Connections(client1, latestEpochTime(), []ConnectionStats{c}, nil)
+	require.Len(t, conns, 1)
+	assert.Empty(t, conns[0].HTTPStats)
+
+	// The 2nd client should get accumulated stats
+	state.StoreHTTPStats(newStats())
+	conns = state.Connections(client2, latestEpochTime(), []ConnectionStats{c}, nil)
+	require.Len(t, conns, 1)
+	require.Len(t, conns[0].HTTPStats, 2)
+	assert.Equal(t, float64(2), conns[0].HTTPStats[0].Latencies.GetCount())
+	assert.Equal(t, float64(4), conns[0].HTTPStats[1].Latencies.GetCount())
+	assert.Equal(t, []HTTPStats{
+		{Path: "/", StatusClass: 500, Requests: 2, LatencySum: 100},
+		{Path: "/users/*", StatusClass: 200, Requests: 4, LatencySum: 600},
+	}, withoutHTTPLatencies(conns[0].HTTPStats))
+}
+
+func withoutHTTPLatencies(stats []HTTPStats) []HTTPStats {
+	res := make([]HTTPStats, len(stats))
+	for i, s := range stats {
+		s.Latencies = nil
+		res[i] = s
+	}
+	return res
+}
+
 func generateRandConnections(n int) []ConnectionStats {
 	cs := make([]ConnectionStats, 0, n)
 	for i := 0; i < n; i++ {
@@ -94,8 +94,14 @@ type AgentConfig struct {
 	OffsetGuessThreshold           uint64
 
 	// DNS stats configuration
-	CollectDNSStats bool
-	DNSTimeout      time.Duration
+	CollectDNSStats   bool
+	DNSTimeout        time.Duration
+	CollectDNSDomains bool
+	MaxDNSDomains     int
+
+	// HTTP monitoring configuration
+	EnableHTTPMonitoring bool
+	HTTPTimeout          time.Duration
 
 	// Orchestrator collection configuration
 	OrchestrationCollectionEnabled bool
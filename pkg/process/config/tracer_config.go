@@ -47,11 +47,20 @@ func SysProbeConfigFromConfig(cfg *AgentConfig) *ebpf.Config {
 
 	tracerConfig.CollectLocalDNS = cfg.CollectLocalDNS
 	tracerConfig.CollectDNSStats = cfg.CollectDNSStats
+	tracerConfig.CollectDNSDomains = cfg.CollectDNSDomains
+	if cfg.MaxDNSDomains > 0 {
+		tracerConfig.MaxDNSDomains = cfg.MaxDNSDomains
+	}
 
 	if to := cfg.DNSTimeout; to > 0 {
 		tracerConfig.DNSTimeout = cfg.DNSTimeout
 	}
 
+	tracerConfig.EnableHTTPMonitoring = cfg.EnableHTTPMonitoring
+	if to := cfg.HTTPTimeout; to > 0 {
+		tracerConfig.HTTPTimeout = cfg.HTTPTimeout
+	}
+
 	tracerConfig.MaxTrackedConnections = cfg.MaxTrackedConnections
 	tracerConfig.ProcRoot = util.GetProcRoot()
 	tracerConfig.BPFDebug = cfg.SysProbeBPFDebug
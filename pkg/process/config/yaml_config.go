@@ -47,10 +47,19 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 
 	a.CollectLocalDNS = config.Datadog.GetBool(key(spNS, "collect_local_dns"))
 	a.CollectDNSStats = config.Datadog.GetBool(key(spNS, "collect_dns_stats"))
+	a.CollectDNSDomains = config.Datadog.GetBool(key(spNS, "collect_dns_domains"))
+	if config.Datadog.IsSet(key(spNS, "max_dns_domains")) {
+		a.MaxDNSDomains = config.Datadog.GetInt(key(spNS, "max_dns_domains"))
+	}
 	if config.Datadog.IsSet(key(spNS, "dns_timeout_in_s")) {
 		a.DNSTimeout = config.Datadog.GetDuration(key(spNS, "dns_timeout_in_s")) * time.Second
 	}
 
+	a.EnableHTTPMonitoring = config.Datadog.GetBool(key(spNS, "enable_http_monitoring"))
+	if config.Datadog.IsSet(key(spNS, "http_timeout_in_s")) {
+		a.HTTPTimeout = config.Datadog.GetDuration(key(spNS, "http_timeout_in_s")) * time.Second
+	}
+
 	if config.Datadog.GetBool(key(spNS, "enabled")) {
 		a.EnabledChecks = append(a.EnabledChecks, "connections")
 		if !a.Enabled {
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build secrets
+
+package secrets
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// builtinHandleSeparator separates the name of a built-in backend from the
+	// reference of the secret in a handle, e.g. 'file@/path/to/secret'
+	builtinHandleSeparator = "@"
+	// envSecretPrefix is the prefix of the environment variables the env backend can read
+	envSecretPrefix = "DD_SECRET_"
+)
+
+// builtinBackend fetches the value of a secret from its reference
+type builtinBackend func(ref string) (string, error)
+
+var builtinBackends = map[string]builtinBackend{
+	"file":       readFileSecret,
+	"k8s_secret": readK8sSecret,
+	"env":        readEnvSecret,
+}
+
+// getBuiltinBackend returns the built-in backend and the secret reference of
+// a handle. The handles without a known prefix are fetched by the
+// secret_backend_command.
+func getBuiltinBackend(handle string) (builtinBackend, string, bool) {
+	if !secretBuiltinBackendsEnabled {
+		return nil, "", false
+	}
+
+	parts := strings.SplitN(handle, builtinHandleSeparator, 2)
+	if len(parts) != 2 {
+		return nil, "", false
+	}
+	backend, found := builtinBackends[parts[0]]
+	if !found {
+		return nil, "", false
+	}
+	return backend, parts[1], true
+}
+
+// readFileSecret reads a secret from a file of the secret_backend_file_root directory
+func readFileSecret(path string) (string, error) {
+	if secretFileRoot == "" {
+		return "", fmt.Errorf("secret_backend_file_root is not set, secrets can't be read from files")
+	}
+	return readSecretFile(secretFileRoot, path)
+}
+
+// readSecretFile reads a secret from a file under the root directory, which
+// must have the same rights than the secret_backend_command except for the
+// execution one
+func readSecretFile(root, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("secret file path '%s' is not absolute", path)
+	}
+
+	// the symlinks are resolved for the file not to be outside of the root
+	// directory, the Kubernetes secret volumes are made of symlinks
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret files directory '%s': %s", root, err)
+	}
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret file '%s': %s", path, err)
+	}
+	rel, err := filepath.Rel(resolvedRoot, resolvedPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("secret file '%s' is not in the '%s' directory", path, root)
+	}
+
+	if err := checkFileRights(resolvedPath); err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(resolvedPath)
+	if err != nil {
+		return "", fmt.Errorf("could not open secret file: %s", err)
+	}
+	defer f.Close()
+
+	// read one more byte than allowed to detect the files that are too large
+	value, err := ioutil.ReadAll(io.LimitReader(f, int64(SecretBackendOutputMaxSize)+1))
+	if err != nil {
+		return "", fmt.Errorf("could not read secret file '%s': %s", path, err)
+	}
+	if len(value) > SecretBackendOutputMaxSize {
+		return "", fmt.Errorf("secret file '%s' is too large: exceeded %d bytes", path, SecretBackendOutputMaxSize)
+	}
+
+	// most editors and tools add a trailing newline to the files they write
+	return strings.TrimRight(string(value), "\r\n"), nil
+}
+
+// readK8sSecret reads a key of a Kubernetes secret mounted as a volume under
+// the secret_backend_k8s_secrets_root directory, with a '<namespace>/<name>/<key>'
+// reference
+func readK8sSecret(ref string) (string, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid Kubernetes secret reference '%s', expected '<namespace>/<name>/<key>'", ref)
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid Kubernetes secret reference '%s', expected '<namespace>/<name>/<key>'", ref)
+		}
+	}
+	return readSecretFile(secretK8sSecretsRoot, filepath.Join(secretK8sSecretsRoot, parts[0], parts[1], parts[2]))
+}
+
+// readEnvSecret reads a secret from an environment variable of the agent,
+// only the variables dedicated to secrets can be read
+func readEnvSecret(name string) (string, error) {
+	if !strings.HasPrefix(name, envSecretPrefix) {
+		return "", fmt.Errorf("environment variable '%s' can't be read, its name must start with '%s'", name, envSecretPrefix)
+	}
+	value, found := os.LookupEnv(name)
+	if !found {
+		return "", fmt.Errorf("environment variable '%s' is not set", name)
+	}
+	if len(value) > SecretBackendOutputMaxSize {
+		return "", fmt.Errorf("environment variable '%s' is too large: exceeded %d bytes", name, SecretBackendOutputMaxSize)
+	}
+	return value, nil
+}
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build secrets,!windows
+
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecretFile(t *testing.T, path string, content string, mode os.FileMode) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), mode))
+	// the umask may have removed some rights
+	require.NoError(t, os.Chmod(path, mode))
+}
+
+func enableBuiltinBackends(root string) func() {
+	InitBuiltinBackends(true, root, root)
+	return func() {
+		InitBuiltinBackends(false, "", "/etc/secret-volumes")
+		runCommand = execCommand
+	}
+}
+
+func TestReadFileSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "password")
+	writeSecretFile(t, path, "password1\n", 0600)
+
+	// the file backend is disabled without a root directory
+	_, err = readFileSecret(path)
+	assert.EqualError(t, err, "secret_backend_file_root is not set, secrets can't be read from files")
+
+	defer enableBuiltinBackends(dir)()
+	value, err := readFileSecret(path)
+	require.NoError(t, err)
+	assert.Equal(t, "password1", value)
+
+	writeSecretFile(t, path, "password1", 0640)
+	_, err = readFileSecret(path)
+	assert.EqualError(t, err, "invalid secret file '"+path+"', 'groups' or 'others' have rights on it")
+
+	writeSecretFile(t, path, "password1", 0604)
+	_, err = readFileSecret(path)
+	assert.EqualError(t, err, "invalid secret file '"+path+"', 'groups' or 'others' have rights on it")
+
+	_, err = readFileSecret("password")
+	assert.EqualError(t, err, "secret file path 'password' is not absolute")
+
+	_, err = readFileSecret(filepath.Join(dir, "unknown"))
+	assert.NotNil(t, err)
+}
+
+func TestReadFileSecretOutsideRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	defer enableBuiltinBackends(root)()
+
+	outside := filepath.Join(dir, "password")
+	writeSecretFile(t, outside, "password1", 0600)
+	writeSecretFile(t, filepath.Join(root, "password"), "password2", 0600)
+
+	_, err = readFileSecret(outside)
+	assert.EqualError(t, err, "secret file '"+outside+"' is not in the '"+root+"' directory")
+	_, err = readFileSecret(filepath.Join(root, "..", "password"))
+	assert.NotNil(t, err)
+
+	// the symlinks can't point outside of the root directory
+	link := filepath.Join(root, "link")
+	require.NoError(t, os.Symlink(outside, link))
+	_, err = readFileSecret(link)
+	assert.EqualError(t, err, "secret file '"+link+"' is not in the '"+root+"' directory")
+
+	value, err := readFileSecret(filepath.Join(root, "password"))
+	require.NoError(t, err)
+	assert.Equal(t, "password2", value)
+}
+
+func TestReadFileSecretTooLarge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer enableBuiltinBackends(dir)()
+	defer func(size int) { SecretBackendOutputMaxSize = size }(SecretBackendOutputMaxSize)
+	SecretBackendOutputMaxSize = 10
+
+	path := filepath.Join(dir, "password")
+	writeSecretFile(t, path, strings.Repeat("a", 10), 0600)
+	_, err = readFileSecret(path)
+	require.NoError(t, err)
+
+	writeSecretFile(t, path, strings.Repeat("a", 11), 0600)
+	_, err = readFileSecret(path)
+	assert.EqualError(t, err, "secret file '"+path+"' is too large: exceeded 10 bytes")
+}
+
+func TestReadK8sSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer enableBuiltinBackends(dir)()
+
+	writeSecretFile(t, filepath.Join(dir, "default", "redis", "password"), "password1", 0600)
+	value, err := readK8sSecret("default/redis/password")
+	require.NoError(t, err)
+	assert.Equal(t, "password1", value)
+
+	for _, ref := range []string{"redis/password", "default/redis/password/extra", "default/../password", "default//password"} {
+		_, err = readK8sSecret(ref)
+		assert.NotNil(t, err, ref)
+	}
+}
+
+func TestReadEnvSecret(t *testing.T) {
+	os.Setenv("DD_SECRET_TEST", "password1")
+	defer os.Unsetenv("DD_SECRET_TEST")
+	os.Setenv("DD_TEST_SECRET", "password2")
+	defer os.Unsetenv("DD_TEST_SECRET")
+
+	value, err := readEnvSecret("DD_SECRET_TEST")
+	require.NoError(t, err)
+	assert.Equal(t, "password1", value)
+
+	_, err = readEnvSecret("DD_SECRET_UNKNOWN")
+	assert.EqualError(t, err, "environment variable 'DD_SECRET_UNKNOWN' is not set")
+
+	_, err = readEnvSecret("DD_TEST_SECRET")
+	assert.EqualError(t, err, "environment variable 'DD_TEST_SECRET' can't be read, its name must start with 'DD_SECRET_'")
+}
+
+func TestFetchSecretValuesBuiltinBackends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer enableBuiltinBackends(dir)()
+
+	path := filepath.Join(dir, "password")
+	writeSecretFile(t, path, "password1", 0600)
+	os.Setenv("DD_SECRET_TEST", "password2")
+	defer os.Unsetenv("DD_SECRET_TEST")
+
+	// only the handles without a built-in backend are sent to the command
+	runCommand = func(payload string) ([]byte, error) {
+		assert.Contains(t, payload, `"secrets":["handle3","unknown@handle4"]`)
+		return []byte(`{"handle3":{"value":"password3"},"unknown@handle4":{"value":"password4"}}`), nil
+	}
+
+	values, err := fetchSecretValues([]string{"file@" + path, "env@DD_SECRET_TEST", "handle3", "unknown@handle4"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"file@" + path:       "password1",
+		"env@DD_SECRET_TEST": "password2",
+		"handle3":            "password3",
+		"unknown@handle4":    "password4",
+	}, values)
+
+	// the command is not run when all the secrets have a built-in backend
+	runCommand = func(string) ([]byte, error) {
+		require.Fail(t, "secret_backend_command should not be run")
+		return nil, nil
+	}
+	values, err = fetchSecretValues([]string{"env@DD_SECRET_TEST"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env@DD_SECRET_TEST": "password2"}, values)
+
+	_, err = fetchSecretValues([]string{"env@DD_SECRET_UNKNOWN"})
+	assert.EqualError(t, err, "an error occurred while decrypting 'env@DD_SECRET_UNKNOWN': environment variable 'DD_SECRET_UNKNOWN' is not set")
+}
+
+func TestFetchSecretValuesBuiltinBackendsDisabled(t *testing.T) {
+	defer func() { runCommand = execCommand }()
+
+	runCommand = func(payload string) ([]byte, error) {
+		assert.Contains(t, payload, `"secrets":["env@DD_TEST_SECRET"]`)
+		return []byte(`{"env@DD_TEST_SECRET":{"value":"password1"}}`), nil
+	}
+
+	values, err := fetchSecretValues([]string{"env@DD_TEST_SECRET"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env@DD_TEST_SECRET": "password1"}, values)
+}
+
+func TestDecryptWithoutBuiltinBackends(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer enableBuiltinBackends(dir)()
+	defer func() {
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+	}()
+
+	os.Setenv("DD_SECRET_TEST", "password1")
+	defer os.Unsetenv("DD_SECRET_TEST")
+	conf := []byte("password: ENC[env@DD_SECRET_TEST]\n")
+
+	_, err = DecryptWithoutBuiltinBackends(conf, "redis")
+	assert.EqualError(t, err, "secret 'env@DD_SECRET_TEST' uses a built-in backend, which can't be used by the configurations of redis")
+
+	decrypted, err := Decrypt(conf, "redis")
+	require.NoError(t, err)
+	assert.Equal(t, "password: password1\n", string(decrypted))
+
+	// the cached value isn't given to the untrusted configurations either
+	_, err = DecryptWithoutBuiltinBackends(conf, "redis")
+	assert.NotNil(t, err)
+
+	runCommand = func(string) ([]byte, error) {
+		return []byte(`{"handle1":{"value":"password2"}}`), nil
+	}
+	decrypted, err = DecryptWithoutBuiltinBackends([]byte("password: ENC[handle1]\n"), "redis")
+	require.NoError(t, err)
+	assert.Equal(t, "password: password2\n", string(decrypted))
+}
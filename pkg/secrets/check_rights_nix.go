@@ -44,3 +44,27 @@ func checkRights(path string) error {
 
 	return nil
 }
+
+// checkFileRights checks that a secret file read by a built-in backend is
+// owned by the user running the agent, and that 'groups' and 'others' don't
+// have any rights on it
+func checkFileRights(path string) error {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return fmt.Errorf("invalid secret file '%s': can't stat it: %s", path, err)
+	}
+
+	if stat.Mode&(syscall.S_IRWXG|syscall.S_IRWXO) != 0 {
+		return fmt.Errorf("invalid secret file '%s', 'groups' or 'others' have rights on it", path)
+	}
+
+	usr, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("can't query current user UID")
+	}
+	if fmt.Sprintf("%d", stat.Uid) != usr.Uid {
+		return fmt.Errorf("invalid secret file: '%s' isn't owned by the user running the agent: name '%s', UID %s", path, usr.Username, usr.Uid)
+	}
+
+	return nil
+}
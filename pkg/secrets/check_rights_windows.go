@@ -155,3 +155,9 @@ func checkRights(filename string) error {
 	}
 	return nil
 }
+
+// checkFileRights checks that a secret file read by a built-in backend has the
+// same access controls than the secretBackendCommand
+func checkFileRights(filename string) error {
+	return checkRights(filename)
+}
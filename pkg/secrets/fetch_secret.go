@@ -36,6 +36,10 @@ func (b *limitBuffer) Write(p []byte) (n int, err error) {
 }
 
 func execCommand(inputPayload string) ([]byte, error) {
+	if secretBackendCommand == "" {
+		return nil, fmt.Errorf("no secret_backend_command set to fetch the secrets without a built-in backend")
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(),
 		time.Duration(secretBackendTimeout)*time.Second)
 	defer cancel()
@@ -82,7 +86,71 @@ var runCommand = execCommand
 // fetchSecret receives a list of secrets name to fetch, exec a custom
 // executable to fetch the actual secrets and returns them. Origin should be
 // the name of the configuration where the secret was referenced.
+// The cache is only locked to be updated once the secrets are fetched.
 func fetchSecret(secretsHandle []string, origin string) (map[string]string, error) {
+	res, err := fetchSecretValues(secretsHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	secretLock.Lock()
+	defer secretLock.Unlock()
+	for handle, value := range res {
+		// keep track of place where a handle was found
+		if origins, found := secretOrigin[handle]; found {
+			origins.Add(origin)
+		} else {
+			secretOrigin[handle] = common.NewStringSet(origin)
+		}
+		// the secret may have been fetched or refreshed meanwhile
+		if cached, found := secretCache[handle]; found {
+			res[handle] = cached
+			continue
+		}
+		// add it to the cache
+		secretCache[handle] = value
+	}
+	return res, nil
+}
+
+// fetchSecretValues fetches the secrets from their built-in backend, or runs
+// the secret_backend_command for the others, without updating the cache
+func fetchSecretValues(secretsHandle []string) (map[string]string, error) {
+	res := map[string]string{}
+	commandHandles := []string{}
+	for _, handle := range secretsHandle {
+		backend, ref, ok := getBuiltinBackend(handle)
+		if !ok {
+			commandHandles = append(commandHandles, handle)
+			continue
+		}
+
+		value, err := backend(ref)
+		if err != nil {
+			return nil, fmt.Errorf("an error occurred while decrypting '%s': %s", handle, err)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("decrypted secret for '%s' is empty", handle)
+		}
+		res[handle] = value
+	}
+
+	if len(commandHandles) == 0 {
+		return res, nil
+	}
+
+	commandSecrets, err := fetchCommandSecretValues(commandHandles)
+	if err != nil {
+		return nil, err
+	}
+	for handle, value := range commandSecrets {
+		res[handle] = value
+	}
+	return res, nil
+}
+
+// fetchCommandSecretValues runs the secret_backend_command to fetch the secrets
+func fetchCommandSecretValues(secretsHandle []string) (map[string]string, error) {
 	payload := map[string]interface{}{
 		"version": PayloadVersion,
 		"secrets": secretsHandle,
@@ -117,10 +185,6 @@ func fetchSecret(secretsHandle []string, origin string) (map[string]string, erro
 			return nil, fmt.Errorf("decrypted secret for '%s' is empty", sec)
 		}
 
-		// add it to the cache
-		secretCache[sec] = v.Value
-		// keep track of place where a handle was found
-		secretOrigin[sec] = common.NewStringSet(origin)
 		res[sec] = v.Value
 	}
 	return res, nil
@@ -9,32 +9,66 @@ import (
 	"fmt"
 	"io"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
 )
 
+// SecretChange is a secret whose value changed when the secrets were refreshed
+type SecretChange struct {
+	Handle  string
+	Origins []string
+	// Keys are the settings the secret was decrypted into, by origin
+	Keys     map[string][]string
+	OldValue string
+	NewValue string
+}
+
+// RefreshCallback is called with the secrets whose value changed when the secrets were refreshed
+type RefreshCallback func(changes []SecretChange)
+
+// SecretRefreshInfo is an entry of the audit trail of the secrets refreshes,
+// it lists the handles that changed and where they were found, never their values
+type SecretRefreshInfo struct {
+	Time    time.Time
+	Changed map[string][]string
+	Error   string
+}
+
 // SecretInfo export troubleshooting information about the decrypted secrets
 type SecretInfo struct {
-	ExecutablePath string
-	Rights         string
-	RightDetails   string
-	UnixOwner      string
-	UnixGroup      string
-	SecretsHandles map[string][]string
+	ExecutablePath  string
+	Rights          string
+	RightDetails    string
+	UnixOwner       string
+	UnixGroup       string
+	BuiltinBackends []string
+	SecretsHandles  map[string][]string
+	Refreshes       []SecretRefreshInfo
 }
 
 // Print output a SecretInfo to a io.Writer
 func (si *SecretInfo) Print(w io.Writer) {
 	fmt.Fprintf(w, "=== Checking executable rights ===\n")
-	fmt.Fprintf(w, "Executable path: %s\n", si.ExecutablePath)
+	if si.ExecutablePath == "" {
+		fmt.Fprintf(w, "No secret_backend_command set\n")
+	} else {
+		fmt.Fprintf(w, "Executable path: %s\n", si.ExecutablePath)
 
-	fmt.Fprintf(w, "Check Rights: %s\n", si.Rights)
+		fmt.Fprintf(w, "Check Rights: %s\n", si.Rights)
 
-	fmt.Fprintf(w, "\nRights Detail:\n")
-	fmt.Fprintf(w, "%s\n", si.RightDetails)
+		fmt.Fprintf(w, "\nRights Detail:\n")
+		fmt.Fprintf(w, "%s\n", si.RightDetails)
 
-	if runtime.GOOS != "windows" {
-		fmt.Fprintf(w, "Owner username: %s\n", si.UnixOwner)
-		fmt.Fprintf(w, "Group name: %s\n", si.UnixGroup)
+		if runtime.GOOS != "windows" {
+			fmt.Fprintf(w, "Owner username: %s\n", si.UnixOwner)
+			fmt.Fprintf(w, "Group name: %s\n", si.UnixGroup)
+		}
+	}
+
+	if len(si.BuiltinBackends) != 0 {
+		fmt.Fprintf(w, "\n=== Built-in backends ===\n")
+		fmt.Fprintf(w, "Enabled: %s\n", strings.Join(si.BuiltinBackends, ", "))
 	}
 
 	fmt.Fprintf(w, "\n=== Secrets stats ===\n")
@@ -43,4 +77,24 @@ func (si *SecretInfo) Print(w io.Writer) {
 	for handle, origins := range si.SecretsHandles {
 		fmt.Fprintf(w, "- %s: from %s\n", handle, strings.Join(origins, ", "))
 	}
+
+	if len(si.Refreshes) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n=== Secrets refreshes ===\n")
+	for _, refresh := range si.Refreshes {
+		if refresh.Error != "" {
+			fmt.Fprintf(w, "%s: error: %s\n", refresh.Time.Format(time.RFC3339), refresh.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %d secret(s) changed\n", refresh.Time.Format(time.RFC3339), len(refresh.Changed))
+		handles := make([]string, 0, len(refresh.Changed))
+		for handle := range refresh.Changed {
+			handles = append(handles, handle)
+		}
+		sort.Strings(handles)
+		for _, handle := range handles {
+			fmt.Fprintf(w, "- %s: from %s\n", handle, strings.Join(refresh.Changed[handle], ", "))
+		}
+	}
 }
@@ -11,6 +11,7 @@ package secrets
 
 import (
 	"fmt"
+	"time"
 )
 
 // SecretBackendOutputMaxSize defines max size of the JSON output from a secrets reader backend
@@ -19,12 +20,34 @@ var SecretBackendOutputMaxSize = 1024 * 1024
 // Init placeholder when compiled without the 'secrets' build tag
 func Init(command string, arguments []string, timeout int, maxSize int) {}
 
+// InitBuiltinBackends placeholder when compiled without the 'secrets' build tag
+func InitBuiltinBackends(enabled bool, fileRoot string, k8sSecretsRoot string) {}
+
 // Decrypt encrypted secrets are not available on windows
 func Decrypt(data []byte, origin string) ([]byte, error) {
 	return data, nil
 }
 
+// DecryptWithoutBuiltinBackends encrypted secrets are not available on windows
+func DecryptWithoutBuiltinBackends(data []byte, origin string) ([]byte, error) {
+	return data, nil
+}
+
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
 	return nil, fmt.Errorf("Secret feature is not available in this version of the agent")
 }
+
+// RegisterRefreshCallback placeholder when compiled without the 'secrets' build tag
+func RegisterRefreshCallback(callback RefreshCallback) {}
+
+// Refresh is not available when compiled without the 'secrets' build tag
+func Refresh() ([]string, error) {
+	return nil, fmt.Errorf("Secret feature is not available in this version of the agent")
+}
+
+// StartRefreshRoutine placeholder when compiled without the 'secrets' build tag
+func StartRefreshRoutine(interval time.Duration) {}
+
+// StopRefreshRoutine placeholder when compiled without the 'secrets' build tag
+func StopRefreshRoutine() {}
@@ -9,7 +9,10 @@ package secrets
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -17,22 +20,46 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+const (
+	// maxRefreshAuditEntries is the number of refreshes kept in the audit trail
+	maxRefreshAuditEntries = 20
+)
+
 var (
+	// secretLock protects the cache, the origins and the refresh audit trail
+	secretLock  sync.Mutex
 	secretCache map[string]string
 	// list of handles and where they were found
 	secretOrigin map[string]common.StringSet
+	// settings each handle was decrypted into, by handle and origin
+	secretKeys map[string]map[string]common.StringSet
 
 	secretBackendCommand   string
 	secretBackendArguments []string
 	secretBackendTimeout   = 5
 
+	// secretBuiltinBackendsEnabled enables the built-in backends selected by the handle prefix
+	secretBuiltinBackendsEnabled bool
+	// secretFileRoot is the directory of the files read by the file backend, disabled if empty
+	secretFileRoot string
+	// secretK8sSecretsRoot is the directory where the Kubernetes secrets are mounted
+	secretK8sSecretsRoot = "/etc/secret-volumes"
+
 	// SecretBackendOutputMaxSize defines max size of the JSON output from a secrets reader backend
 	SecretBackendOutputMaxSize = 1024 * 1024
 )
 
+var (
+	refreshCallbacksLock sync.Mutex
+	refreshCallbacks     []RefreshCallback
+	refreshAudit         []SecretRefreshInfo
+	refreshStop          chan struct{}
+)
+
 func init() {
 	secretCache = make(map[string]string)
 	secretOrigin = make(map[string]common.StringSet)
+	secretKeys = make(map[string]map[string]common.StringSet)
 }
 
 // Init initializes the command and other options of the secrets package. Since
@@ -45,6 +72,19 @@ func Init(command string, arguments []string, timeout int, maxSize int) {
 	SecretBackendOutputMaxSize = maxSize
 }
 
+// InitBuiltinBackends enables the built-in secret backends, selected by the
+// prefix of the handles: 'file@', 'k8s_secret@' and 'env@'
+func InitBuiltinBackends(enabled bool, fileRoot string, k8sSecretsRoot string) {
+	secretBuiltinBackendsEnabled = enabled
+	secretFileRoot = fileRoot
+	secretK8sSecretsRoot = k8sSecretsRoot
+}
+
+// isEnabled returns whether secrets can be fetched, from the secret_backend_command or a built-in backend
+func isEnabled() bool {
+	return secretBackendCommand != "" || secretBuiltinBackendsEnabled
+}
+
 type walkerCallback func(string) (string, error)
 
 func walkSlice(data []interface{}, callback walkerCallback) error {
@@ -109,6 +149,36 @@ func walk(data *interface{}, callback walkerCallback) error {
 	return nil
 }
 
+// collectSecretKeys records the settings of the configuration holding each
+// handle, as dot-separated keys. The handles nested in a list are recorded
+// with the key of the setting holding the list.
+func collectSecretKeys(data interface{}, path []string, inList bool, origin string) {
+	switch v := data.(type) {
+	case string:
+		if ok, handle := isEnc(v); ok {
+			if secretKeys[handle] == nil {
+				secretKeys[handle] = make(map[string]common.StringSet)
+			}
+			if secretKeys[handle][origin] == nil {
+				secretKeys[handle][origin] = common.NewStringSet()
+			}
+			secretKeys[handle][origin].Add(strings.Join(path, "."))
+		}
+	case map[interface{}]interface{}:
+		for k, value := range v {
+			if inList {
+				collectSecretKeys(value, path, inList, origin)
+				continue
+			}
+			collectSecretKeys(value, append(path[:len(path):len(path)], strings.ToLower(fmt.Sprint(k))), inList, origin)
+		}
+	case []interface{}:
+		for _, value := range v {
+			collectSecretKeys(value, path, true, origin)
+		}
+	}
+}
+
 func isEnc(str string) (bool, string) {
 	// trimming space and tabs
 	str = strings.Trim(str, " 	")
@@ -124,7 +194,18 @@ var secretFetcher = fetchSecret
 // Decrypt replaces all encrypted secrets in data by executing
 // "secret_backend_command" once if all secrets aren't present in the cache.
 func Decrypt(data []byte, origin string) ([]byte, error) {
-	if data == nil || secretBackendCommand == "" {
+	return decrypt(data, origin, true)
+}
+
+// DecryptWithoutBuiltinBackends decrypts the configurations coming from
+// sources that the agent doesn't trust to read its files and environment,
+// like container labels: the handles of the built-in backends are refused.
+func DecryptWithoutBuiltinBackends(data []byte, origin string) ([]byte, error) {
+	return decrypt(data, origin, false)
+}
+
+func decrypt(data []byte, origin string, allowBuiltinBackends bool) ([]byte, error) {
+	if data == nil || !isEnabled() {
 		return data, nil
 	}
 
@@ -134,12 +215,18 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 		return nil, fmt.Errorf("could not Unmarshal config: %s", err)
 	}
 
+	secretLock.Lock()
+	collectSecretKeys(config, nil, false, origin)
+
 	// First we collect all new handles in the config
 	newHandles := []string{}
 	haveSecret := false
 	err = walk(&config, func(str string) (string, error) {
 		if ok, handle := isEnc(str); ok {
 			haveSecret = true
+			if _, _, builtin := getBuiltinBackend(handle); builtin && !allowBuiltinBackends {
+				return str, fmt.Errorf("secret '%s' uses a built-in backend, which can't be used by the configurations of %s", handle, origin)
+			}
 			// Check if we already know this secret
 			if secret, ok := secretCache[handle]; ok {
 				log.Debugf("Secret '%s' was retrieved from cache", handle)
@@ -151,6 +238,7 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 		}
 		return str, nil
 	})
+	secretLock.Unlock()
 	if err != nil {
 		return nil, err
 	}
@@ -162,6 +250,7 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 
 	// check if any new secrets need to be fetch
 	if len(newHandles) != 0 {
+		// The secret_backend_command may be slow, the cache is not locked while it runs
 		secrets, err := secretFetcher(newHandles, origin)
 		if err != nil {
 			return nil, err
@@ -171,7 +260,7 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 		err = walk(&config, func(str string) (string, error) {
 			if ok, handle := isEnc(str); ok {
 				if secret, ok := secrets[handle]; ok {
-					log.Debugf("Secret '%s' was retrieved from executable", handle)
+					log.Debugf("Secret '%s' was retrieved from its backend", handle)
 					return secret, nil
 				}
 				// This should never happen since fetchSecret will return an error
@@ -194,15 +283,162 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
-	if secretBackendCommand == "" {
+	if !isEnabled() {
 		return nil, fmt.Errorf("No secret_backend_command set: secrets feature is not enabled")
 	}
 	info := &SecretInfo{ExecutablePath: secretBackendCommand}
-	info.populateRights()
+	if secretBackendCommand != "" {
+		info.populateRights()
+	}
+	if secretBuiltinBackendsEnabled {
+		for name := range builtinBackends {
+			info.BuiltinBackends = append(info.BuiltinBackends, name)
+		}
+		sort.Strings(info.BuiltinBackends)
+	}
+
+	secretLock.Lock()
+	defer secretLock.Unlock()
 
 	info.SecretsHandles = map[string][]string{}
 	for handle, originNames := range secretOrigin {
 		info.SecretsHandles[handle] = originNames.GetAll()
 	}
+	info.Refreshes = append([]SecretRefreshInfo{}, refreshAudit...)
 	return info, nil
 }
+
+// testing purpose
+var secretRefresher = fetchSecretValues
+
+// RegisterRefreshCallback registers a callback called with the secrets whose
+// value changed after each refresh
+func RegisterRefreshCallback(callback RefreshCallback) {
+	refreshCallbacksLock.Lock()
+	defer refreshCallbacksLock.Unlock()
+	refreshCallbacks = append(refreshCallbacks, callback)
+}
+
+// Refresh fetches again all the secrets decrypted so far, updates the cache
+// with their new values and notifies the registered callbacks of the changes.
+// It returns the handles of the secrets that changed.
+func Refresh() ([]string, error) {
+	if !isEnabled() {
+		return nil, fmt.Errorf("No secret_backend_command set: secrets feature is not enabled")
+	}
+
+	secretLock.Lock()
+	handles := make([]string, 0, len(secretCache))
+	for handle := range secretCache {
+		handles = append(handles, handle)
+	}
+	secretLock.Unlock()
+
+	if len(handles) == 0 {
+		return []string{}, nil
+	}
+	sort.Strings(handles)
+
+	// The secret_backend_command may be slow, the cache is not locked while it runs
+	values, err := secretRefresher(handles)
+
+	secretLock.Lock()
+	audit := SecretRefreshInfo{
+		Time:    time.Now(),
+		Changed: map[string][]string{},
+	}
+	var changes []SecretChange
+	if err != nil {
+		audit.Error = err.Error()
+	} else {
+		for _, handle := range handles {
+			oldValue, found := secretCache[handle]
+			newValue := values[handle]
+			if !found || oldValue == newValue {
+				continue
+			}
+			secretCache[handle] = newValue
+			origins := secretOrigin[handle].GetAll()
+			keys := make(map[string][]string, len(secretKeys[handle]))
+			for origin, originKeys := range secretKeys[handle] {
+				keys[origin] = originKeys.GetAll()
+			}
+			changes = append(changes, SecretChange{
+				Handle:   handle,
+				Origins:  origins,
+				Keys:     keys,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+			audit.Changed[handle] = origins
+		}
+	}
+	refreshAudit = append(refreshAudit, audit)
+	if len(refreshAudit) > maxRefreshAuditEntries {
+		refreshAudit = refreshAudit[len(refreshAudit)-maxRefreshAuditEntries:]
+	}
+	secretLock.Unlock()
+
+	if err != nil {
+		return nil, fmt.Errorf("could not refresh secrets: %s", err)
+	}
+
+	changed := make([]string, 0, len(changes))
+	for _, change := range changes {
+		log.Infof("Secret '%s' changed, updating it in %s", change.Handle, strings.Join(change.Origins, ", "))
+		changed = append(changed, change.Handle)
+	}
+	if len(changes) == 0 {
+		return changed, nil
+	}
+
+	// The callbacks are called without holding the cache lock, they may decrypt configurations
+	refreshCallbacksLock.Lock()
+	callbacks := append([]RefreshCallback{}, refreshCallbacks...)
+	refreshCallbacksLock.Unlock()
+	for _, callback := range callbacks {
+		callback(changes)
+	}
+
+	return changed, nil
+}
+
+// StartRefreshRoutine refreshes the secrets every interval until StopRefreshRoutine is called
+func StartRefreshRoutine(interval time.Duration) {
+	if !isEnabled() || interval <= 0 {
+		return
+	}
+
+	refreshCallbacksLock.Lock()
+	defer refreshCallbacksLock.Unlock()
+	if refreshStop != nil {
+		return
+	}
+	refreshStop = make(chan struct{})
+
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := Refresh(); err != nil {
+					log.Errorf("Error while refreshing secrets: %s", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(refreshStop)
+	log.Infof("Refreshing secrets every %s", interval)
+}
+
+// StopRefreshRoutine stops refreshing the secrets periodically
+func StopRefreshRoutine() {
+	refreshCallbacksLock.Lock()
+	defer refreshCallbacksLock.Unlock()
+	if refreshStop != nil {
+		close(refreshStop)
+		refreshStop = nil
+	}
+}
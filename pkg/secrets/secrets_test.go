@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/common"
 	"github.com/stretchr/testify/assert"
@@ -261,6 +262,38 @@ func TestDecryptSecretFullCache(t *testing.T) {
 	assert.Equal(t, testConfDecrypted, newConf)
 }
 
+func TestDecryptDoesNotLockCacheWhileFetching(t *testing.T) {
+	secretBackendCommand = "some_command"
+	defer func() {
+		secretBackendCommand = ""
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+		secretFetcher = fetchSecret
+	}()
+
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		locked := make(chan struct{})
+		go func() {
+			secretLock.Lock()
+			secretLock.Unlock()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+		case <-time.After(time.Second):
+			assert.Fail(t, "the cache is locked while the secrets are fetched")
+		}
+		return map[string]string{
+			"pass1": "password1",
+			"pass2": "password2",
+		}, nil
+	}
+
+	newConf, err := Decrypt(testConf, "test")
+	require.Nil(t, err)
+	assert.Equal(t, testConfDecrypted, newConf)
+}
+
 func TestDebugInfo(t *testing.T) {
 	secretBackendCommand = "some_command"
 
@@ -300,3 +333,166 @@ func TestDebugInfo(t *testing.T) {
 		"pass3": {"test2"},
 	}, handles)
 }
+
+func TestRefresh(t *testing.T) {
+	secretBackendCommand = "some_command"
+
+	secretCache["pass1"] = "password1"
+	secretCache["pass2"] = "password2"
+	secretOrigin["pass1"] = common.NewStringSet("test")
+	secretOrigin["pass2"] = common.NewStringSet("test", "test2")
+	secretKeys["pass2"] = map[string]common.StringSet{"test": common.NewStringSet("api_key")}
+	defer func() {
+		secretBackendCommand = ""
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+		secretKeys = map[string]map[string]common.StringSet{}
+		secretRefresher = fetchSecretValues
+		refreshCallbacks = nil
+		refreshAudit = nil
+	}()
+
+	secretRefresher = func(secrets []string) (map[string]string, error) {
+		assert.Equal(t, []string{"pass1", "pass2"}, secrets)
+		return map[string]string{
+			"pass1": "password1",
+			"pass2": "new_password2",
+		}, nil
+	}
+
+	var notified []SecretChange
+	RegisterRefreshCallback(func(changes []SecretChange) {
+		notified = append(notified, changes...)
+	})
+
+	changed, err := Refresh()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"pass2"}, changed)
+	assert.Equal(t, "new_password2", secretCache["pass2"])
+
+	require.Len(t, notified, 1)
+	sort.Strings(notified[0].Origins)
+	assert.Equal(t, SecretChange{
+		Handle:   "pass2",
+		Origins:  []string{"test", "test2"},
+		Keys:     map[string][]string{"test": {"api_key"}},
+		OldValue: "password2",
+		NewValue: "new_password2",
+	}, notified[0])
+
+	// nothing changed since the last refresh: the callbacks are not called
+	notified = nil
+	changed, err = Refresh()
+	require.Nil(t, err)
+	assert.Empty(t, changed)
+	assert.Empty(t, notified)
+
+	info, err := GetDebugInfo()
+	require.Nil(t, err)
+	require.Len(t, info.Refreshes, 2)
+	assert.Equal(t, map[string][]string{"pass2": {"test", "test2"}}, sortedChanges(info.Refreshes[0].Changed))
+	assert.Empty(t, info.Refreshes[1].Changed)
+}
+
+func TestRefreshError(t *testing.T) {
+	secretBackendCommand = "some_command"
+
+	secretCache["pass1"] = "password1"
+	secretOrigin["pass1"] = common.NewStringSet("test")
+	defer func() {
+		secretBackendCommand = ""
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+		secretRefresher = fetchSecretValues
+		refreshCallbacks = nil
+		refreshAudit = nil
+	}()
+
+	secretRefresher = func(secrets []string) (map[string]string, error) {
+		return nil, fmt.Errorf("some error")
+	}
+	RegisterRefreshCallback(func(changes []SecretChange) {
+		require.Fail(t, "callback called after a failed refresh")
+	})
+
+	_, err := Refresh()
+	require.NotNil(t, err)
+
+	// the previous values are kept
+	assert.Equal(t, "password1", secretCache["pass1"])
+
+	info, err := GetDebugInfo()
+	require.Nil(t, err)
+	require.Len(t, info.Refreshes, 1)
+	assert.Equal(t, "some error", info.Refreshes[0].Error)
+}
+
+func TestRefreshNoSecret(t *testing.T) {
+	secretBackendCommand = "some_command"
+	defer func() { secretBackendCommand = "" }()
+
+	changed, err := Refresh()
+	require.Nil(t, err)
+	assert.NotNil(t, changed)
+	assert.Empty(t, changed)
+}
+
+func TestDecryptSecretKeys(t *testing.T) {
+	secretBackendCommand = "some_command"
+	defer func() {
+		secretBackendCommand = ""
+		secretCache = map[string]string{}
+		secretOrigin = map[string]common.StringSet{}
+		secretKeys = map[string]map[string]common.StringSet{}
+		secretFetcher = fetchSecret
+	}()
+
+	secretFetcher = func(secrets []string, origin string) (map[string]string, error) {
+		res := map[string]string{}
+		for _, handle := range secrets {
+			res[handle] = "value_" + handle
+			secretCache[handle] = res[handle]
+			secretOrigin[handle] = common.NewStringSet(origin)
+		}
+		return res, nil
+	}
+
+	_, err := Decrypt([]byte(`---
+api_key: ENC[key]
+Logs_Config:
+  api_key: ENC[key]
+  additional_endpoints:
+  - api_key: ENC[other]
+other_setting: ENC[other]
+`), "datadog.yaml")
+	require.Nil(t, err)
+	_, err = Decrypt(testConf, "test")
+	require.Nil(t, err)
+
+	keys := map[string]map[string][]string{}
+	for handle, byOrigin := range secretKeys {
+		keys[handle] = map[string][]string{}
+		for origin, originKeys := range byOrigin {
+			keys[handle][origin] = originKeys.GetAll()
+			sort.Strings(keys[handle][origin])
+		}
+	}
+	assert.Equal(t, map[string]map[string][]string{
+		"key":   {"datadog.yaml": {"api_key", "logs_config.api_key"}},
+		"other": {"datadog.yaml": {"logs_config.additional_endpoints", "other_setting"}},
+		"pass1": {"test": {"instances"}},
+		"pass2": {"test": {"instances"}},
+	}, keys)
+}
+
+func TestRefreshNoCommand(t *testing.T) {
+	_, err := Refresh()
+	require.NotNil(t, err)
+}
+
+func sortedChanges(changes map[string][]string) map[string][]string {
+	for _, origins := range changes {
+		sort.Strings(origins)
+	}
+	return changes
+}
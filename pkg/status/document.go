@@ -0,0 +1,381 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package status
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// DocumentSchemaVersion is the version of the schema of Document. It's
+// increased on every change of the schema that isn't backward compatible:
+// removing or renaming a field, or changing its type.
+const DocumentSchemaVersion = 1
+
+// Document is the machine-readable status of the agent
+type Document struct {
+	SchemaVersion int              `json:"schema_version"`
+	AgentVersion  string           `json:"agent_version"`
+	Flavor        string           `json:"flavor"`
+	Hostname      string           `json:"hostname"`
+	PID           int              `json:"pid"`
+	GoVersion     string           `json:"go_version"`
+	StartTime     time.Time        `json:"start_time"`
+	Time          time.Time        `json:"time"`
+	Health        HealthSection    `json:"health"`
+	Collector     CollectorSection `json:"collector"`
+	Forwarder     ForwarderSection `json:"forwarder"`
+	Dogstatsd     DogstatsdSection `json:"dogstatsd"`
+	Logs          LogsSection      `json:"logs"`
+	APM           APMSection       `json:"apm"`
+	JMX           JMXSection       `json:"jmx"`
+}
+
+// HealthSection lists the healthy and unhealthy components of the agent
+type HealthSection struct {
+	Healthy   []string `json:"healthy"`
+	Unhealthy []string `json:"unhealthy"`
+}
+
+// CollectorSection holds the status of the check runner
+type CollectorSection struct {
+	Workers       int64            `json:"workers"`
+	RunningChecks int64            `json:"running_checks"`
+	Runs          int64            `json:"runs"`
+	Errors        int64            `json:"errors"`
+	Warnings      int64            `json:"warnings"`
+	Timeouts      int64            `json:"timeouts"`
+	Checks        []CheckInstance  `json:"checks"`
+	LoaderErrors  []CheckLoadError `json:"loader_errors"`
+}
+
+// CheckInstance holds the status of a check instance
+type CheckInstance struct {
+	Name                   string   `json:"name"`
+	ID                     string   `json:"id"`
+	Version                string   `json:"version"`
+	ConfigSource           string   `json:"config_source"`
+	TotalRuns              uint64   `json:"total_runs"`
+	TotalErrors            uint64   `json:"total_errors"`
+	TotalWarnings          uint64   `json:"total_warnings"`
+	TotalTimeouts          uint64   `json:"total_timeouts"`
+	MetricSamples          int64    `json:"metric_samples"`
+	Events                 int64    `json:"events"`
+	ServiceChecks          int64    `json:"service_checks"`
+	AverageExecutionTimeMs int64    `json:"average_execution_time_ms"`
+	LastExecutionTimeMs    int64    `json:"last_execution_time_ms"`
+	LastSuccessTimestamp   int64    `json:"last_success_timestamp"`
+	LastError              string   `json:"last_error"`
+	LastWarnings           []string `json:"last_warnings"`
+}
+
+// CheckLoadError is an error that occurred when loading a check
+type CheckLoadError struct {
+	Check  string `json:"check"`
+	Loader string `json:"loader"`
+	Error  string `json:"error"`
+}
+
+// ForwarderSection holds the status of the forwarder
+type ForwarderSection struct {
+	APIKeyStatus   map[string]string `json:"api_key_status"`
+	Success        int64             `json:"success"`
+	Errors         int64             `json:"errors"`
+	Dropped        int64             `json:"dropped"`
+	DroppedOnInput int64             `json:"dropped_on_input"`
+	Retried        int64             `json:"retried"`
+	Requeued       int64             `json:"requeued"`
+	RetryQueueSize int64             `json:"retry_queue_size"`
+}
+
+// DogstatsdSection holds the status of the DogStatsD server
+type DogstatsdSection struct {
+	Enabled                 bool  `json:"enabled"`
+	MetricPackets           int64 `json:"metric_packets"`
+	MetricParseErrors       int64 `json:"metric_parse_errors"`
+	EventPackets            int64 `json:"event_packets"`
+	EventParseErrors        int64 `json:"event_parse_errors"`
+	ServiceCheckPackets     int64 `json:"service_check_packets"`
+	ServiceCheckParseErrors int64 `json:"service_check_parse_errors"`
+}
+
+// LogsSection holds the status of the logs agent
+type LogsSection struct {
+	Enabled   bool             `json:"enabled"`
+	Running   bool             `json:"running"`
+	Endpoints []string         `json:"endpoints"`
+	Metrics   map[string]int64 `json:"metrics"`
+	Errors    []string         `json:"errors"`
+	Warnings  []string         `json:"warnings"`
+}
+
+// APMSection holds the status of the trace-agent, as reported by its debug
+// endpoint
+type APMSection struct {
+	Enabled   bool   `json:"enabled"`
+	Reachable bool   `json:"reachable"`
+	Port      int    `json:"port"`
+	PID       int    `json:"pid"`
+	Version   string `json:"version"`
+	Error     string `json:"error"`
+}
+
+// JMXSection holds the status of JMXFetch, the JMX checks are given with
+// their number of instances
+type JMXSection struct {
+	InitializedChecks map[string]int `json:"initialized_checks"`
+	FailedChecks      map[string]int `json:"failed_checks"`
+	StartupError      string         `json:"startup_error"`
+	Timestamp         int64          `json:"timestamp"`
+}
+
+// apmStatusTimeout is the timeout of the requests to the trace-agent
+var apmStatusTimeout = time.Second
+
+// GetDocument returns the machine-readable status of the agent
+func GetDocument() *Document {
+	hostname, _ := util.GetHostname()
+
+	h := health.GetReady()
+	doc := &Document{
+		SchemaVersion: DocumentSchemaVersion,
+		AgentVersion:  version.AgentVersion,
+		Flavor:        flavor.GetFlavor(),
+		Hostname:      hostname,
+		PID:           os.Getpid(),
+		GoVersion:     runtime.Version(),
+		StartTime:     startTime,
+		Time:          time.Now(),
+		Health: HealthSection{
+			Healthy:   nonNilStrings(h.Healthy),
+			Unhealthy: nonNilStrings(h.Unhealthy),
+		},
+		Collector: getCollectorSection(),
+		Forwarder: getForwarderSection(),
+		Dogstatsd: getDogstatsdSection(),
+		Logs:      getLogsSection(),
+		APM:       getAPMSection(),
+		JMX:       getJMXSection(),
+	}
+	sort.Strings(doc.Health.Healthy)
+	sort.Strings(doc.Health.Unhealthy)
+	return doc
+}
+
+// unmarshalExpvar decodes the JSON value of an expvar, if it's published
+func unmarshalExpvar(name string, v interface{}) {
+	if e := expvar.Get(name); e != nil {
+		json.Unmarshal([]byte(e.String()), v) //nolint:errcheck
+	}
+}
+
+func getCollectorSection() CollectorSection {
+	var runner struct {
+		Workers       int64
+		RunningChecks int64
+		Runs          int64
+		Errors        int64
+		Warnings      int64
+		Timeouts      int64
+		Checks        map[string]map[string]check.Stats
+	}
+	unmarshalExpvar("runner", &runner)
+
+	section := CollectorSection{
+		Workers:       runner.Workers,
+		RunningChecks: runner.RunningChecks,
+		Runs:          runner.Runs,
+		Errors:        runner.Errors,
+		Warnings:      runner.Warnings,
+		Timeouts:      runner.Timeouts,
+		Checks:        []CheckInstance{},
+		LoaderErrors:  []CheckLoadError{},
+	}
+	for _, instances := range runner.Checks {
+		for id, s := range instances {
+			section.Checks = append(section.Checks, CheckInstance{
+				Name:                   s.CheckName,
+				ID:                     id,
+				Version:                s.CheckVersion,
+				ConfigSource:           s.CheckConfigSource,
+				TotalRuns:              s.TotalRuns,
+				TotalErrors:            s.TotalErrors,
+				TotalWarnings:          s.TotalWarnings,
+				TotalTimeouts:          s.TotalTimeouts,
+				MetricSamples:          s.MetricSamples,
+				Events:                 s.Events,
+				ServiceChecks:          s.ServiceChecks,
+				AverageExecutionTimeMs: s.AverageExecutionTime,
+				LastExecutionTimeMs:    s.LastExecutionTime,
+				LastSuccessTimestamp:   s.LastSuccessDate,
+				LastError:              s.LastError,
+				LastWarnings:           nonNilStrings(s.LastWarnings),
+			})
+		}
+	}
+	sort.Slice(section.Checks, func(i, j int) bool { return section.Checks[i].ID < section.Checks[j].ID })
+
+	var scheduler struct {
+		LoaderErrors map[string]map[string]string
+	}
+	unmarshalExpvar("CheckScheduler", &scheduler)
+	for name, loaders := range scheduler.LoaderErrors {
+		for loader, err := range loaders {
+			section.LoaderErrors = append(section.LoaderErrors, CheckLoadError{Check: name, Loader: loader, Error: err})
+		}
+	}
+	sort.Slice(section.LoaderErrors, func(i, j int) bool {
+		a, b := section.LoaderErrors[i], section.LoaderErrors[j]
+		return a.Check < b.Check || (a.Check == b.Check && a.Loader < b.Loader)
+	})
+
+	return section
+}
+
+func getForwarderSection() ForwarderSection {
+	var forwarder struct {
+		APIKeyStatus map[string]string
+		Transactions struct {
+			Success        int64
+			Errors         int64
+			Dropped        int64
+			DroppedOnInput int64
+			Retried        int64
+			Requeued       int64
+			RetryQueueSize int64
+		}
+	}
+	unmarshalExpvar("forwarder", &forwarder)
+
+	section := ForwarderSection{
+		APIKeyStatus:   forwarder.APIKeyStatus,
+		Success:        forwarder.Transactions.Success,
+		Errors:         forwarder.Transactions.Errors,
+		Dropped:        forwarder.Transactions.Dropped,
+		DroppedOnInput: forwarder.Transactions.DroppedOnInput,
+		Retried:        forwarder.Transactions.Retried,
+		Requeued:       forwarder.Transactions.Requeued,
+		RetryQueueSize: forwarder.Transactions.RetryQueueSize,
+	}
+	if section.APIKeyStatus == nil {
+		section.APIKeyStatus = map[string]string{}
+	}
+	return section
+}
+
+func getDogstatsdSection() DogstatsdSection {
+	var dogstatsd struct {
+		MetricPackets           int64
+		MetricParseErrors       int64
+		EventPackets            int64
+		EventParseErrors        int64
+		ServiceCheckPackets     int64
+		ServiceCheckParseErrors int64
+	}
+	unmarshalExpvar("dogstatsd", &dogstatsd)
+
+	return DogstatsdSection{
+		Enabled:                 config.Datadog.GetBool("use_dogstatsd"),
+		MetricPackets:           dogstatsd.MetricPackets,
+		MetricParseErrors:       dogstatsd.MetricParseErrors,
+		EventPackets:            dogstatsd.EventPackets,
+		EventParseErrors:        dogstatsd.EventParseErrors,
+		ServiceCheckPackets:     dogstatsd.ServiceCheckPackets,
+		ServiceCheckParseErrors: dogstatsd.ServiceCheckParseErrors,
+	}
+}
+
+func getLogsSection() LogsSection {
+	s := logs.GetStatus()
+	section := LogsSection{
+		Enabled:   config.Datadog.GetBool("logs_enabled"),
+		Running:   s.IsRunning,
+		Endpoints: nonNilStrings(s.Endpoints),
+		Metrics:   s.StatusMetrics,
+		Errors:    nonNilStrings(s.Errors),
+		Warnings:  nonNilStrings(s.Warnings),
+	}
+	if section.Metrics == nil {
+		section.Metrics = map[string]int64{}
+	}
+	return section
+}
+
+func getAPMSection() APMSection {
+	section := APMSection{
+		Enabled: config.Datadog.GetBool("apm_config.enabled"),
+		Port:    config.Datadog.GetInt("apm_config.receiver_port"),
+	}
+	if !section.Enabled {
+		return section
+	}
+
+	client := http.Client{Timeout: apmStatusTimeout}
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%d/debug/vars", section.Port))
+	if err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	defer resp.Body.Close()
+
+	var vars struct {
+		PID     int `json:"pid"`
+		Version struct {
+			Version string
+		} `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		section.Error = err.Error()
+		return section
+	}
+	section.Reachable = true
+	section.PID = vars.PID
+	section.Version = vars.Version.Version
+	return section
+}
+
+func getJMXSection() JMXSection {
+	s := GetJMXStatus()
+	return JMXSection{
+		InitializedChecks: countJMXInstances(s.ChecksStatus.InitializedChecks),
+		FailedChecks:      countJMXInstances(s.ChecksStatus.FailedChecks),
+		StartupError:      GetJMXStartupError().LastError,
+		Timestamp:         s.Timestamp,
+	}
+}
+
+func countJMXInstances(checks map[string]interface{}) map[string]int {
+	counts := make(map[string]int, len(checks))
+	for name, instances := range checks {
+		if list, ok := instances.([]interface{}); ok {
+			counts[name] = len(list)
+		} else {
+			counts[name] = 1
+		}
+	}
+	return counts
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package status
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	promNamespace = "datadog_agent"
+	// promServerTimeout leaves time to build the status document, which
+	// queries the trace-agent
+	promServerTimeout = 10 * time.Second
+)
+
+var (
+	promInfo = prometheus.NewDesc(promNamespace+"_info",
+		"Version and flavor of the agent, always 1.", []string{"version", "flavor", "hostname"}, nil)
+	promStartTime = prometheus.NewDesc(promNamespace+"_start_time_seconds",
+		"Start time of the agent since unix epoch in seconds.", nil, nil)
+	promHealthy = prometheus.NewDesc(promNamespace+"_component_healthy",
+		"Whether a component of the agent is healthy.", []string{"component"}, nil)
+
+	promRunningChecks = prometheus.NewDesc(promNamespace+"_collector_running_checks",
+		"Number of checks currently running.", nil, nil)
+	promCheckRuns = prometheus.NewDesc(promNamespace+"_check_runs_total",
+		"Number of runs of a check instance.", []string{"check", "check_id"}, nil)
+	promCheckErrors = prometheus.NewDesc(promNamespace+"_check_errors_total",
+		"Number of runs of a check instance that failed.", []string{"check", "check_id"}, nil)
+	promCheckWarnings = prometheus.NewDesc(promNamespace+"_check_warnings_total",
+		"Number of warnings raised by a check instance.", []string{"check", "check_id"}, nil)
+	promCheckTimeouts = prometheus.NewDesc(promNamespace+"_check_timeouts_total",
+		"Number of runs of a check instance that timed out.", []string{"check", "check_id"}, nil)
+	promCheckExecutionTime = prometheus.NewDesc(promNamespace+"_check_last_execution_seconds",
+		"Duration of the last run of a check instance.", []string{"check", "check_id"}, nil)
+	promLoaderErrors = prometheus.NewDesc(promNamespace+"_check_loader_errors",
+		"Number of checks that couldn't be loaded.", nil, nil)
+
+	promTransactions = prometheus.NewDesc(promNamespace+"_forwarder_transactions_total",
+		"Number of transactions of the forwarder by outcome.", []string{"outcome"}, nil)
+	promRetryQueueSize = prometheus.NewDesc(promNamespace+"_forwarder_retry_queue_size",
+		"Number of transactions waiting to be retried.", nil, nil)
+
+	promDogstatsdPackets = prometheus.NewDesc(promNamespace+"_dogstatsd_packets_total",
+		"Number of packets received by DogStatsD by type.", []string{"type"}, nil)
+	promDogstatsdParseErrors = prometheus.NewDesc(promNamespace+"_dogstatsd_parse_errors_total",
+		"Number of packets DogStatsD couldn't parse by type.", []string{"type"}, nil)
+
+	promLogsRunning = prometheus.NewDesc(promNamespace+"_logs_running",
+		"Whether the logs agent is running.", nil, nil)
+	promLogsErrors = prometheus.NewDesc(promNamespace+"_logs_errors",
+		"Number of errors reported by the logs agent.", nil, nil)
+	promAPMReachable = prometheus.NewDesc(promNamespace+"_apm_reachable",
+		"Whether the trace-agent answered the status request.", nil, nil)
+	promJMXChecks = prometheus.NewDesc(promNamespace+"_jmx_checks",
+		"Number of JMX checks by state.", []string{"state"}, nil)
+)
+
+// documentCollector exports the health and the key counters of a status
+// document as prometheus metrics
+type documentCollector struct {
+	get func() *Document
+}
+
+// Describe implements prometheus.Collector
+func (c documentCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		promInfo, promStartTime, promHealthy,
+		promRunningChecks, promCheckRuns, promCheckErrors, promCheckWarnings, promCheckTimeouts, promCheckExecutionTime, promLoaderErrors,
+		promTransactions, promRetryQueueSize,
+		promDogstatsdPackets, promDogstatsdParseErrors,
+		promLogsRunning, promLogsErrors, promAPMReachable, promJMXChecks,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c documentCollector) Collect(ch chan<- prometheus.Metric) {
+	doc := c.get()
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...)
+	}
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...)
+	}
+
+	gauge(promInfo, 1, doc.AgentVersion, doc.Flavor, doc.Hostname)
+	gauge(promStartTime, float64(doc.StartTime.Unix()))
+	for _, component := range doc.Health.Healthy {
+		gauge(promHealthy, 1, component)
+	}
+	for _, component := range doc.Health.Unhealthy {
+		gauge(promHealthy, 0, component)
+	}
+
+	gauge(promRunningChecks, float64(doc.Collector.RunningChecks))
+	for _, c := range doc.Collector.Checks {
+		counter(promCheckRuns, float64(c.TotalRuns), c.Name, c.ID)
+		counter(promCheckErrors, float64(c.TotalErrors), c.Name, c.ID)
+		counter(promCheckWarnings, float64(c.TotalWarnings), c.Name, c.ID)
+		counter(promCheckTimeouts, float64(c.TotalTimeouts), c.Name, c.ID)
+		gauge(promCheckExecutionTime, float64(c.LastExecutionTimeMs)/1000, c.Name, c.ID)
+	}
+	gauge(promLoaderErrors, float64(len(doc.Collector.LoaderErrors)))
+
+	f := doc.Forwarder
+	counter(promTransactions, float64(f.Success), "success")
+	counter(promTransactions, float64(f.Errors), "error")
+	counter(promTransactions, float64(f.Dropped), "dropped")
+	counter(promTransactions, float64(f.DroppedOnInput), "dropped_on_input")
+	counter(promTransactions, float64(f.Retried), "retried")
+	counter(promTransactions, float64(f.Requeued), "requeued")
+	gauge(promRetryQueueSize, float64(f.RetryQueueSize))
+
+	if d := doc.Dogstatsd; d.Enabled {
+		counter(promDogstatsdPackets, float64(d.MetricPackets), "metric")
+		counter(promDogstatsdPackets, float64(d.EventPackets), "event")
+		counter(promDogstatsdPackets, float64(d.ServiceCheckPackets), "service_check")
+		counter(promDogstatsdParseErrors, float64(d.MetricParseErrors), "metric")
+		counter(promDogstatsdParseErrors, float64(d.EventParseErrors), "event")
+		counter(promDogstatsdParseErrors, float64(d.ServiceCheckParseErrors), "service_check")
+	}
+
+	if doc.Logs.Enabled {
+		gauge(promLogsRunning, boolToFloat(doc.Logs.Running))
+		gauge(promLogsErrors, float64(len(doc.Logs.Errors)))
+	}
+	if doc.APM.Enabled {
+		gauge(promAPMReachable, boolToFloat(doc.APM.Reachable))
+	}
+	gauge(promJMXChecks, float64(len(doc.JMX.InitializedChecks)), "initialized")
+	gauge(promJMXChecks, float64(len(doc.JMX.FailedChecks)), "failed")
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// PrometheusHandler serves the health and the key counters of the status of
+// the agent in the prometheus text format
+func PrometheusHandler() http.Handler {
+	return prometheusHandler(GetDocument)
+}
+
+func prometheusHandler(get func() *Document) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(documentCollector{get: get})
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ServePrometheus starts an http server listening on addr, serving the
+// prometheus export of the status on its `/status/metrics` path. It returns
+// an error if the setup failed, or runs the server in a goroutine. Stop the
+// server by cancelling the passed context.
+func ServePrometheus(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	servePrometheus(ctx, ln, GetDocument)
+	return nil
+}
+
+func servePrometheus(ctx context.Context, ln net.Listener, get func() *Document) {
+	mux := http.NewServeMux()
+	mux.Handle("/status/metrics", prometheusHandler(get))
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadTimeout:       promServerTimeout,
+		ReadHeaderTimeout: promServerTimeout,
+		WriteTimeout:      promServerTimeout,
+	}
+
+	go srv.Serve(ln) //nolint:errcheck
+	go func() {
+		<-ctx.Done()
+		timeout, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(timeout) //nolint:errcheck
+	}()
+}
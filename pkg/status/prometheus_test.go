@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package status
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	doc := &Document{
+		AgentVersion: "7.20.0",
+		Flavor:       "agent",
+		Hostname:     "myhost",
+		StartTime:    time.Unix(1590000000, 0),
+		Health:       HealthSection{Healthy: []string{"forwarder"}, Unhealthy: []string{"aggregator"}},
+		Collector: CollectorSection{
+			RunningChecks: 2,
+			Checks: []CheckInstance{
+				{Name: "cpu", ID: "cpu", TotalRuns: 10, TotalErrors: 1, LastExecutionTimeMs: 1500},
+			},
+		},
+		Forwarder: ForwarderSection{Success: 42, Dropped: 3, RetryQueueSize: 5},
+		Dogstatsd: DogstatsdSection{Enabled: true, MetricPackets: 100, MetricParseErrors: 2},
+		Logs:      LogsSection{Enabled: false},
+		JMX:       JMXSection{InitializedChecks: map[string]int{"kafka": 2}},
+	}
+
+	rec := httptest.NewRecorder()
+	prometheusHandler(func() *Document { return doc }).ServeHTTP(rec, httptest.NewRequest("GET", "/status/metrics", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	require.NoError(t, err)
+	out := string(body)
+
+	assert.Contains(t, out, `datadog_agent_info{flavor="agent",hostname="myhost",version="7.20.0"} 1`)
+	assert.Contains(t, out, `datadog_agent_start_time_seconds 1.59e+09`)
+	assert.Contains(t, out, `datadog_agent_component_healthy{component="forwarder"} 1`)
+	assert.Contains(t, out, `datadog_agent_component_healthy{component="aggregator"} 0`)
+	assert.Contains(t, out, `datadog_agent_collector_running_checks 2`)
+	assert.Contains(t, out, "# TYPE datadog_agent_check_runs_total counter")
+	assert.Contains(t, out, `datadog_agent_check_runs_total{check="cpu",check_id="cpu"} 10`)
+	assert.Contains(t, out, `datadog_agent_check_errors_total{check="cpu",check_id="cpu"} 1`)
+	assert.Contains(t, out, `datadog_agent_check_last_execution_seconds{check="cpu",check_id="cpu"} 1.5`)
+	assert.Contains(t, out, `datadog_agent_forwarder_transactions_total{outcome="success"} 42`)
+	assert.Contains(t, out, `datadog_agent_forwarder_transactions_total{outcome="dropped"} 3`)
+	assert.Contains(t, out, `datadog_agent_forwarder_retry_queue_size 5`)
+	assert.Contains(t, out, `datadog_agent_dogstatsd_packets_total{type="metric"} 100`)
+	assert.Contains(t, out, `datadog_agent_dogstatsd_parse_errors_total{type="metric"} 2`)
+	assert.Contains(t, out, `datadog_agent_jmx_checks{state="initialized"} 1`)
+
+	// disabled components aren't reported
+	assert.NotContains(t, out, "datadog_agent_logs_running")
+	assert.NotContains(t, out, "datadog_agent_apm_reachable")
+}
+
+func TestServePrometheus(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	servePrometheus(ctx, ln, func() *Document { return &Document{AgentVersion: "7.20.0"} })
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/status/metrics")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `datadog_agent_info{flavor="",hostname="",version="7.20.0"} 1`)
+
+	// the server stops with its context
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, err := http.Get("http://" + ln.Addr().String() + "/status/metrics")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentSchemaIsPublished(t *testing.T) {
+	schema, err := DocumentSchema()
+	require.NoError(t, err)
+
+	published, err := ioutil.ReadFile(filepath.Join("schema", fmt.Sprintf("status-v%d.json", DocumentSchemaVersion)))
+	require.NoError(t, err, "the schema of a new version must be published")
+	assert.Equal(t, string(published), string(schema)+"\n",
+		"the published schema is outdated, bump DocumentSchemaVersion if the change isn't backward compatible")
+}
+
+func TestDocumentSchemaMatchesDocument(t *testing.T) {
+	raw, err := DocumentSchema()
+	require.NoError(t, err)
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &schema))
+
+	doc := &Document{
+		Health:    HealthSection{Healthy: []string{}, Unhealthy: []string{}},
+		Collector: CollectorSection{Checks: []CheckInstance{{LastWarnings: []string{}}}, LoaderErrors: []CheckLoadError{{}}},
+		Forwarder: ForwarderSection{APIKeyStatus: map[string]string{}},
+		Logs:      LogsSection{Endpoints: []string{}, Metrics: map[string]int64{}, Errors: []string{}, Warnings: []string{}},
+		JMX:       JMXSection{InitializedChecks: map[string]int{}, FailedChecks: map[string]int{}},
+	}
+	raw, err = json.Marshal(doc)
+	require.NoError(t, err)
+	var encoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &encoded))
+
+	assertMatchesSchema(t, "", schema, encoded)
+}
+
+// assertMatchesSchema checks that the objects of v have the properties
+// required by the schema, and no others
+func assertMatchesSchema(t *testing.T, path string, schema map[string]interface{}, v interface{}) {
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		require.True(t, ok, "%s isn't an object", path)
+		properties, ok := schema["properties"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		assert.Len(t, obj, len(properties), "%s doesn't have the properties of the schema", path)
+		for _, name := range schema["required"].([]interface{}) {
+			value, found := obj[name.(string)]
+			if assert.True(t, found, "%s.%s is missing", path, name) {
+				assertMatchesSchema(t, path+"."+name.(string), properties[name.(string)].(map[string]interface{}), value)
+			}
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		require.True(t, ok, "%s isn't an array", path)
+		for i, item := range items {
+			assertMatchesSchema(t, fmt.Sprintf("%s[%d]", path, i), schema["items"].(map[string]interface{}), item)
+		}
+	}
+}
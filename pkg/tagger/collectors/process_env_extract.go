@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+)
+
+// listPids returns the pids of the processes of procfs
+func listPids(procRoot string) ([]int, error) {
+	entries, err := ioutil.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	pids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+// readProcessEnv returns the environment variables of the process, as KEY=value entries
+func readProcessEnv(procRoot string, pid int) ([]string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	for _, entry := range bytes.Split(content, []byte{0}) {
+		if len(entry) > 0 {
+			env = append(env, string(entry))
+		}
+	}
+	return env, nil
+}
+
+// processExtractEnvironmentVariables extracts the env, version and service
+// standard tags, and the environment variables mapped to tags
+func processExtractEnvironmentVariables(env []string, envAsTags map[string]string) ([]string, []string, []string, []string) {
+	tags := utils.NewTagList()
+
+	for _, envEntry := range env {
+		envSplit := strings.SplitN(envEntry, "=", 2)
+		if len(envSplit) != 2 {
+			continue
+		}
+		envName, envValue := envSplit[0], envSplit[1]
+
+		switch envName {
+		case envVarEnv:
+			tags.AddStandard(tagKeyEnv, envValue)
+		case envVarVersion:
+			tags.AddStandard(tagKeyVersion, envValue)
+		case envVarService:
+			tags.AddStandard(tagKeyService, envValue)
+		default:
+			if tagName, found := envAsTags[strings.ToLower(envName)]; found {
+				tags.AddAuto(tagName, envValue)
+			}
+		}
+	}
+
+	return tags.Compute()
+}
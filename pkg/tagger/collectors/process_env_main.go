@@ -0,0 +1,274 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processEnvCollectorName = "process_env"
+	processEntityPrefix     = "process://"
+	// The tags of exited processes are kept for late metrics
+	processEnvExpiry = time.Minute
+)
+
+// ProcessEnvCollector tags the processes of the host with the values of their
+// allowlisted environment variables, read from procfs. As the environment of a
+// process doesn't change, it is only read once per process.
+// The tags of the processes running in a container are also attached to the
+// container, merged with the ones of the other processes of the container.
+type ProcessEnvCollector struct {
+	sync.Mutex
+	infoOut           chan<- []*TagInfo
+	procRoot          string
+	envAsTags         map[string]string
+	expire            *utils.Expire
+	containerIDForPID func(pid int) (string, error)
+	seen              map[int]struct{}           // processes of the last pull
+	tagged            map[string]*processEnvTags // processes with tags, by entity
+	containers        map[string]*TagInfo        // tags sent for the containers, by entity
+}
+
+// processEnvTags holds the tags of a process, and the entity of its container if any
+type processEnvTags struct {
+	info      *TagInfo
+	container string
+}
+
+// Detect checks that environment variables are mapped to tags
+func (c *ProcessEnvCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
+	envAsTags := retrieveMappingFromConfig("process_env_as_tags")
+	if len(envAsTags) == 0 {
+		return NoCollection, fmt.Errorf("process_env_as_tags is not set")
+	}
+
+	expire, err := utils.NewExpire(processEnvExpiry)
+	if err != nil {
+		return NoCollection, err
+	}
+
+	c.init(out, config.Datadog.GetString("procfs_path"), envAsTags, expire, providers.ContainerImpl().ContainerIDForPID)
+	return PullCollection, nil
+}
+
+func (c *ProcessEnvCollector) init(out chan<- []*TagInfo, procRoot string, envAsTags map[string]string, expire *utils.Expire, containerIDForPID func(int) (string, error)) {
+	c.infoOut = out
+	c.procRoot = procRoot
+	c.envAsTags = envAsTags
+	c.expire = expire
+	c.containerIDForPID = containerIDForPID
+	c.seen = make(map[int]struct{})
+	c.tagged = make(map[string]*processEnvTags)
+	c.containers = make(map[string]*TagInfo)
+}
+
+// Pull tags the new processes, and deletes the entities of the processes which
+// exited for longer than the expiry. The tags of the containers are updated
+// accordingly.
+func (c *ProcessEnvCollector) Pull() error {
+	c.Lock()
+	defer c.Unlock()
+
+	pids, err := listPids(c.procRoot)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	seen := make(map[int]struct{}, len(pids))
+	var updates []*TagInfo
+	for _, pid := range pids {
+		seen[pid] = struct{}{}
+		entity := buildProcessEntity(pid)
+
+		if _, found := c.seen[pid]; found {
+			if _, found := c.tagged[entity]; found {
+				c.expire.Update(entity, now)
+			}
+			continue
+		}
+
+		info, err := c.fetchForPid(pid)
+		if err != nil {
+			// processes of other users can't be read without privileges
+			log.Tracef("Cannot read the environment of process %d: %s", pid, err)
+			continue
+		}
+		if info == nil {
+			continue
+		}
+		c.tagged[entity] = &processEnvTags{info: info, container: c.containerEntity(pid)}
+		c.expire.Update(entity, now)
+		updates = append(updates, info)
+	}
+	c.seen = seen
+
+	expired, err := c.expire.ComputeExpires()
+	if err != nil {
+		return err
+	}
+	for _, entity := range expired {
+		delete(c.tagged, entity)
+		updates = append(updates, &TagInfo{
+			Source:       processEnvCollectorName,
+			Entity:       entity,
+			DeleteEntity: true,
+		})
+	}
+	updates = append(updates, c.updateContainers()...)
+
+	if len(updates) > 0 {
+		c.infoOut <- updates
+	}
+	return nil
+}
+
+// Fetch reads the environment of a process on-demand (cache miss)
+func (c *ProcessEnvCollector) Fetch(entity string) ([]string, []string, []string, error) {
+	if !strings.HasPrefix(entity, processEntityPrefix) {
+		return nil, nil, nil, errors.NewNotFound(entity)
+	}
+	pid, err := strconv.Atoi(strings.TrimPrefix(entity, processEntityPrefix))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid process entity %s", entity)
+	}
+
+	info, err := c.fetchForPid(pid)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if info == nil {
+		return nil, nil, nil, errors.NewNotFound(entity)
+	}
+	c.infoOut <- []*TagInfo{info}
+	return info.LowCardTags, info.OrchestratorCardTags, info.HighCardTags, nil
+}
+
+// fetchForPid returns the tags of the process, nil if it has none
+func (c *ProcessEnvCollector) fetchForPid(pid int) (*TagInfo, error) {
+	env, err := readProcessEnv(c.procRoot, pid)
+	if err != nil {
+		return nil, err
+	}
+
+	low, orchestrator, high, standard := processExtractEnvironmentVariables(env, c.envAsTags)
+	if len(low)+len(orchestrator)+len(high) == 0 {
+		return nil, nil
+	}
+	return &TagInfo{
+		Source:               processEnvCollectorName,
+		Entity:               buildProcessEntity(pid),
+		LowCardTags:          low,
+		OrchestratorCardTags: orchestrator,
+		HighCardTags:         high,
+		StandardTags:         standard,
+	}, nil
+}
+
+// containerEntity returns the entity of the container of the process, or an
+// empty string for the processes of the host
+func (c *ProcessEnvCollector) containerEntity(pid int) string {
+	cID, err := c.containerIDForPID(pid)
+	if err != nil {
+		log.Tracef("Cannot get the container of process %d: %s", pid, err)
+		return ""
+	}
+	if cID == "" {
+		return ""
+	}
+	return containers.BuildTaggerEntityName(cID)
+}
+
+// updateContainers merges the tags of the processes by container, and returns
+// the updates of the containers whose tags changed
+func (c *ProcessEnvCollector) updateContainers() []*TagInfo {
+	current := make(map[string]*TagInfo)
+	for _, p := range c.tagged {
+		if p.container == "" {
+			continue
+		}
+		info, found := current[p.container]
+		if !found {
+			info = &TagInfo{
+				Source:               processEnvCollectorName,
+				Entity:               p.container,
+				LowCardTags:          []string{},
+				OrchestratorCardTags: []string{},
+				HighCardTags:         []string{},
+				StandardTags:         []string{},
+			}
+			current[p.container] = info
+		}
+		info.LowCardTags = append(info.LowCardTags, p.info.LowCardTags...)
+		info.OrchestratorCardTags = append(info.OrchestratorCardTags, p.info.OrchestratorCardTags...)
+		info.HighCardTags = append(info.HighCardTags, p.info.HighCardTags...)
+		info.StandardTags = append(info.StandardTags, p.info.StandardTags...)
+	}
+
+	var updates []*TagInfo
+	for entity, info := range current {
+		info.LowCardTags = sortedUniq(info.LowCardTags)
+		info.OrchestratorCardTags = sortedUniq(info.OrchestratorCardTags)
+		info.HighCardTags = sortedUniq(info.HighCardTags)
+		info.StandardTags = sortedUniq(info.StandardTags)
+		if previous, found := c.containers[entity]; found && sameTags(previous, info) {
+			continue
+		}
+		updates = append(updates, info)
+	}
+	for entity := range c.containers {
+		if _, found := current[entity]; !found {
+			updates = append(updates, &TagInfo{
+				Source:       processEnvCollectorName,
+				Entity:       entity,
+				DeleteEntity: true,
+			})
+		}
+	}
+	c.containers = current
+	return updates
+}
+
+// sortedUniq sorts tags and removes the duplicates, so that the merged tags of
+// a container can be compared between pulls
+func sortedUniq(tags []string) []string {
+	sort.Strings(tags)
+	uniq := tags[:0]
+	for _, tag := range tags {
+		if len(uniq) > 0 && tag == uniq[len(uniq)-1] {
+			continue
+		}
+		uniq = append(uniq, tag)
+	}
+	return uniq
+}
+
+func buildProcessEntity(pid int) string {
+	return processEntityPrefix + strconv.Itoa(pid)
+}
+
+func processEnvFactory() Collector {
+	return &ProcessEnvCollector{}
+}
+
+func init() {
+	registerCollector(processEnvCollectorName, processEnvFactory, NodeRuntime)
+}
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package collectors
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+)
+
+func writeProcessEnv(t *testing.T, procRoot, pid string, env string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, pid), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(procRoot, pid, "environ"), []byte(env), 0644))
+}
+
+func TestProcessEnvCollectorPull(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "procfs")
+	require.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+
+	writeProcessEnv(t, procRoot, "1", "PATH=/bin\x00TEAM=core\x00DD_ENV=prod\x00")
+	writeProcessEnv(t, procRoot, "2", "PATH=/bin\x00")
+	writeProcessEnv(t, procRoot, "3", "TEAM=payments\x00DEPLOYMENT_ID=42\x00DD_SERVICE=web\x00")
+	writeProcessEnv(t, procRoot, "4", "TEAM=payments\x00DEPLOYMENT_ID=43\x00")
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "net"), 0755))
+
+	expire, err := utils.NewExpire(100 * time.Millisecond)
+	require.NoError(t, err)
+	out := make(chan []*TagInfo, 10)
+	c := &ProcessEnvCollector{}
+	// processes 3 and 4 run in the same container
+	containerIDForPID := func(pid int) (string, error) {
+		if pid == 3 || pid == 4 {
+			return "abc", nil
+		}
+		return "", nil
+	}
+	c.init(out, procRoot, map[string]string{"team": "team", "deployment_id": "+deployment_id"}, expire, containerIDForPID)
+
+	require.NoError(t, c.Pull())
+	updates := <-out
+	require.Len(t, updates, 4)
+	byEntity := map[string]*TagInfo{}
+	for _, info := range updates {
+		byEntity[info.Entity] = info
+	}
+	assertTagInfoEqual(t, &TagInfo{
+		Source:               processEnvCollectorName,
+		Entity:               "process://1",
+		LowCardTags:          []string{"team:core", "env:prod"},
+		OrchestratorCardTags: []string{},
+		HighCardTags:         []string{},
+		StandardTags:         []string{"env:prod"},
+	}, byEntity["process://1"])
+	assertTagInfoEqual(t, &TagInfo{
+		Source:               processEnvCollectorName,
+		Entity:               "process://3",
+		LowCardTags:          []string{"team:payments", "service:web"},
+		OrchestratorCardTags: []string{},
+		HighCardTags:         []string{"deployment_id:42"},
+		StandardTags:         []string{"service:web"},
+	}, byEntity["process://3"])
+	assertTagInfoEqual(t, &TagInfo{
+		Source:               processEnvCollectorName,
+		Entity:               "container_id://abc",
+		LowCardTags:          []string{"service:web", "team:payments"},
+		OrchestratorCardTags: []string{},
+		HighCardTags:         []string{"deployment_id:42", "deployment_id:43"},
+		StandardTags:         []string{"service:web"},
+	}, byEntity["container_id://abc"])
+
+	low, _, _, err := c.Fetch("process://1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"team:core", "env:prod"}, low)
+	<-out
+	_, _, _, err = c.Fetch("process://2")
+	assert.True(t, errors.IsNotFound(err))
+	_, _, _, err = c.Fetch("container_id://foo")
+	assert.True(t, errors.IsNotFound(err))
+	assert.Len(t, out, 0)
+
+	// process 3 exited, its entity is deleted once expired and the tags of
+	// the container only come from process 4
+	require.NoError(t, os.RemoveAll(filepath.Join(procRoot, "3")))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, c.Pull())
+	updates = <-out
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:       processEnvCollectorName,
+			Entity:       "process://3",
+			DeleteEntity: true,
+		},
+		{
+			Source:               processEnvCollectorName,
+			Entity:               "container_id://abc",
+			LowCardTags:          []string{"team:payments"},
+			OrchestratorCardTags: []string{},
+			HighCardTags:         []string{"deployment_id:43"},
+			StandardTags:         []string{},
+		},
+	}, updates)
+
+	// the container entity is deleted along with its last process
+	require.NoError(t, os.RemoveAll(filepath.Join(procRoot, "4")))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, c.Pull())
+	updates = <-out
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:       processEnvCollectorName,
+			Entity:       "process://4",
+			DeleteEntity: true,
+		},
+		{
+			Source:       processEnvCollectorName,
+			Entity:       "container_id://abc",
+			DeleteEntity: true,
+		},
+	}, updates)
+
+	// known processes aren't read again
+	writeProcessEnv(t, procRoot, "1", "TEAM=other\x00")
+	require.NoError(t, c.Pull())
+	assert.Len(t, out, 0)
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package collectors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// tagFile is the content of a tag file, see the tag_files_dir setting of config_template.yaml
+type tagFile struct {
+	Entities []tagFileEntity `yaml:"entities"`
+}
+
+// tagFileEntity holds the tags of an entity, Tags being low cardinality ones
+type tagFileEntity struct {
+	Entity              string     `yaml:"entity"`
+	Tags                []string   `yaml:"tags"`
+	OrchestratorTags    []string   `yaml:"orchestrator_tags"`
+	HighCardinalityTags []string   `yaml:"high_cardinality_tags"`
+	ExpiresAt           *time.Time `yaml:"expires_at"`
+}
+
+// expired returns whether the tags of the entity expired
+func (e *tagFileEntity) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// parseTagFile reads the entities of a tag file
+func parseTagFile(path string) ([]tagFileEntity, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f tagFile
+	if err := yaml.UnmarshalStrict(content, &f); err != nil {
+		return nil, err
+	}
+	for i, e := range f.Entities {
+		if e.Entity == "" {
+			return nil, fmt.Errorf("entity %d has no entity name", i)
+		}
+	}
+	return f.Entities, nil
+}
+
+// mergeTagFileEntities merges the tags of the entities which aren't expired,
+// the entities being defined in several files getting the tags of all of them
+func mergeTagFileEntities(files map[string][]tagFileEntity, now time.Time) map[string]*TagInfo {
+	// Iterate the files in order for the tags to be stable
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	infos := make(map[string]*TagInfo)
+	for _, path := range paths {
+		for _, e := range files[path] {
+			if e.expired(now) {
+				continue
+			}
+			info, found := infos[e.Entity]
+			if !found {
+				info = &TagInfo{
+					Source: tagFilesCollectorName,
+					Entity: e.Entity,
+				}
+				infos[e.Entity] = info
+			}
+			info.LowCardTags = append(info.LowCardTags, e.Tags...)
+			info.OrchestratorCardTags = append(info.OrchestratorCardTags, e.OrchestratorTags...)
+			info.HighCardTags = append(info.HighCardTags, e.HighCardinalityTags...)
+		}
+	}
+
+	// Sort the tags for them to be compared with the ones of the last pull
+	for _, info := range infos {
+		sort.Strings(info.LowCardTags)
+		sort.Strings(info.OrchestratorCardTags)
+		sort.Strings(info.HighCardTags)
+	}
+	return infos
+}
+
+// sameTags returns whether two TagInfo of the same entity hold the same tags
+func sameTags(a, b *TagInfo) bool {
+	return equalStrings(a.LowCardTags, b.LowCardTags) &&
+		equalStrings(a.OrchestratorCardTags, b.OrchestratorCardTags) &&
+		equalStrings(a.HighCardTags, b.HighCardTags)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package collectors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	tagFilesCollectorName = "tag_files"
+)
+
+// TagFilesCollector reads the tags of entities from the YAML files of a
+// directory, dropped by provisioning systems. A file is only parsed again once
+// modified, the tags of the entities are deleted once they expire or once they
+// are removed from the files.
+type TagFilesCollector struct {
+	sync.Mutex
+	infoOut chan<- []*TagInfo
+	dir     string
+	files   map[string]*tagFileState
+	current map[string]*TagInfo // tags sent to the store by entity
+}
+
+type tagFileState struct {
+	modTime  time.Time
+	size     int64
+	entities []tagFileEntity
+}
+
+// Detect checks that the tag files directory is set
+func (c *TagFilesCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
+	dir := config.Datadog.GetString("tag_files_dir")
+	if dir == "" {
+		return NoCollection, fmt.Errorf("tag_files_dir is not set")
+	}
+
+	c.init(out, dir)
+	return PullCollection, nil
+}
+
+func (c *TagFilesCollector) init(out chan<- []*TagInfo, dir string) {
+	c.infoOut = out
+	c.dir = dir
+	c.files = make(map[string]*tagFileState)
+	c.current = make(map[string]*TagInfo)
+}
+
+// Pull parses the new and modified files, and sends the entities whose tags changed
+func (c *TagFilesCollector) Pull() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.readFiles(); err != nil {
+		return err
+	}
+
+	files := make(map[string][]tagFileEntity, len(c.files))
+	for path, state := range c.files {
+		files[path] = state.entities
+	}
+	infos := mergeTagFileEntities(files, time.Now())
+
+	var updates []*TagInfo
+	for entity, info := range infos {
+		if previous, found := c.current[entity]; found && sameTags(previous, info) {
+			continue
+		}
+		updates = append(updates, info)
+	}
+	for entity := range c.current {
+		if _, found := infos[entity]; !found {
+			updates = append(updates, &TagInfo{
+				Source:       tagFilesCollectorName,
+				Entity:       entity,
+				DeleteEntity: true,
+			})
+		}
+	}
+	c.current = infos
+
+	sort.Slice(updates, func(i, j int) bool {
+		return updates[i].Entity < updates[j].Entity
+	})
+	if len(updates) > 0 {
+		c.infoOut <- updates
+	}
+	return nil
+}
+
+// readFiles parses the files created or modified since the last pull. The
+// previous entities of a file are kept if it can't be parsed, as it may be
+// being written.
+func (c *TagFilesCollector) readFiles() error {
+	entries, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// directory not provisioned yet, or removed
+			c.files = make(map[string]*tagFileState)
+			return nil
+		}
+		return err
+	}
+
+	present := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		present[path] = struct{}{}
+
+		state, found := c.files[path]
+		if found && state.modTime.Equal(entry.ModTime()) && state.size == entry.Size() {
+			continue
+		}
+		if !found {
+			state = &tagFileState{}
+			c.files[path] = state
+		}
+		state.modTime = entry.ModTime()
+		state.size = entry.Size()
+
+		entities, err := parseTagFile(path)
+		if err != nil {
+			log.Errorf("Cannot parse tag file %s: %s", path, err)
+			continue
+		}
+		state.entities = entities
+	}
+
+	for path := range c.files {
+		if _, found := present[path]; !found {
+			delete(c.files, path)
+		}
+	}
+	return nil
+}
+
+// Fetch returns the tags of an entity from the files parsed by the last pull
+func (c *TagFilesCollector) Fetch(entity string) ([]string, []string, []string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	info, found := c.current[entity]
+	if !found {
+		return nil, nil, nil, errors.NewNotFound(entity)
+	}
+	return info.LowCardTags, info.OrchestratorCardTags, info.HighCardTags, nil
+}
+
+func tagFilesFactory() Collector {
+	return &TagFilesCollector{}
+}
+
+func init() {
+	registerCollector(tagFilesCollectorName, tagFilesFactory, NodeOrchestrator)
+}
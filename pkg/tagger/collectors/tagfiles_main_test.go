@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package collectors
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTagFile(t *testing.T, path, content string) {
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	// make sure the modification is detected on filesystems with a coarse mtime
+	modTime := time.Now().Add(time.Duration(len(content)) * time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestTagFilesCollectorPull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tag_files")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	out := make(chan []*TagInfo, 10)
+	c := &TagFilesCollector{}
+	c.init(out, dir)
+
+	writeTagFile(t, filepath.Join(dir, "team.yaml"), `
+entities:
+  - entity: container_id://foo
+    tags: ["team:payments"]
+    high_cardinality_tags: ["deployment_id:42"]
+  - entity: process://1
+    tags: ["team:core"]
+  - entity: process://2
+    tags: ["team:expired"]
+    expires_at: 2020-01-01T00:00:00Z
+`)
+	writeTagFile(t, filepath.Join(dir, "owner.yml"), `
+entities:
+  - entity: container_id://foo
+    tags: ["owner:alice"]
+`)
+	writeTagFile(t, filepath.Join(dir, "ignored.txt"), "not a tag file")
+
+	require.NoError(t, c.Pull())
+	updates := <-out
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:       tagFilesCollectorName,
+			Entity:       "container_id://foo",
+			LowCardTags:  []string{"owner:alice", "team:payments"},
+			HighCardTags: []string{"deployment_id:42"},
+		},
+		{
+			Source:      tagFilesCollectorName,
+			Entity:      "process://1",
+			LowCardTags: []string{"team:core"},
+		},
+	}, updates)
+
+	low, _, high, err := c.Fetch("container_id://foo")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"owner:alice", "team:payments"}, low)
+	assert.Equal(t, []string{"deployment_id:42"}, high)
+	_, _, _, err = c.Fetch("process://2")
+	assert.Error(t, err)
+
+	// nothing changed
+	require.NoError(t, c.Pull())
+	assert.Len(t, out, 0)
+
+	// invalid files keep their previous entities
+	writeTagFile(t, filepath.Join(dir, "owner.yml"), "entities: [")
+	require.NoError(t, c.Pull())
+	assert.Len(t, out, 0)
+
+	writeTagFile(t, filepath.Join(dir, "team.yaml"), `
+entities:
+  - entity: container_id://foo
+    tags: ["team:checkout"]
+`)
+	require.NoError(t, c.Pull())
+	updates = <-out
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:      tagFilesCollectorName,
+			Entity:      "container_id://foo",
+			LowCardTags: []string{"owner:alice", "team:checkout"},
+		},
+		{
+			Source:       tagFilesCollectorName,
+			Entity:       "process://1",
+			DeleteEntity: true,
+		},
+	}, updates)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "team.yaml")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "owner.yml")))
+	require.NoError(t, c.Pull())
+	updates = <-out
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:       tagFilesCollectorName,
+			Entity:       "container_id://foo",
+			DeleteEntity: true,
+		},
+	}, updates)
+}
+
+func TestParseTagFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tag_files")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tags.yaml")
+
+	writeTagFile(t, path, "entities: [{tags: [foo:bar]}]")
+	_, err = parseTagFile(path)
+	assert.EqualError(t, err, "entity 0 has no entity name")
+
+	writeTagFile(t, path, "entities: [{entity: process://1, unknown: field}]")
+	_, err = parseTagFile(path)
+	assert.Error(t, err)
+
+	writeTagFile(t, path, "entities: [{entity: process://1, orchestrator_tags: [task:1], expires_at: 2020-01-01T00:00:00Z}]")
+	entities, err := parseTagFile(path)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	assert.Equal(t, []string{"task:1"}, entities[0].OrchestratorTags)
+	assert.True(t, entities[0].expired(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, entities[0].expired(time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)))
+}
@@ -9,24 +9,56 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// Component is the interface of the taggers backing the global functions: the
+// local Tagger, or a remote tagger streaming the entities of the core agent
+type Component interface {
+	Stop() error
+	Tag(entity string, cardinality collectors.TagCardinality) ([]string, error)
+	Standard(entity string) ([]string, error)
+	GetEntityHash(entity string) string
+	List(cardinality collectors.TagCardinality) response.TaggerListResponse
+}
+
+// Make sure Tagger implements the Component interface
+var _ Component = &Tagger{}
+
 // defaultTagger is the shared tagger instance backing the global Tag and Init functions
-var defaultTagger *Tagger
+var defaultTagger Component
 var initOnce sync.Once
 
 // ChecksCardinality defines the cardinality of tags we should send for check metrics
 // this can still be overridden when calling get_tags in python checks.
 var ChecksCardinality collectors.TagCardinality
 
-// DogstatsdCardinality defines the cardinality of tags we should send for metrics from
+// dogstatsdCardinality defines the cardinality of tags we should send for metrics from
+// dogstatsd. It can be changed at runtime, so it's only accessed atomically.
+var dogstatsdCardinality int32
+
+// DogstatsdCardinality returns the cardinality of tags we should send for metrics from
 // dogstatsd.
-var DogstatsdCardinality collectors.TagCardinality
+func DogstatsdCardinality() collectors.TagCardinality {
+	return collectors.TagCardinality(atomic.LoadInt32(&dogstatsdCardinality))
+}
+
+// SetDogstatsdCardinality changes the cardinality of tags we should send for metrics
+// from dogstatsd, valid values are low, orchestrator and high.
+func SetDogstatsdCardinality(cardinality string) error {
+	c, err := stringToTagCardinality(cardinality)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&dogstatsdCardinality, int32(c))
+	return nil
+}
 
 // Init must be called once config is available, call it in your cmd
 func Init() {
@@ -40,16 +72,42 @@ func Init() {
 			log.Warnf("failed to parse check tag cardinality, defaulting to low. Error: %s", err)
 			ChecksCardinality = collectors.LowCardinality
 		}
-		DogstatsdCardinality, err = stringToTagCardinality(dsdCard)
-		if err != nil {
+		if err = SetDogstatsdCardinality(dsdCard); err != nil {
 			log.Warnf("failed to parse dogstatsd tag cardinality, defaulting to low. Error: %s", err)
-			DogstatsdCardinality = collectors.LowCardinality
+			atomic.StoreInt32(&dogstatsdCardinality, int32(collectors.LowCardinality))
 		}
 
-		defaultTagger.Init(collectors.DefaultCatalog)
+		// remote taggers are started by SetDefaultTagger's callers
+		if t, ok := defaultTagger.(*Tagger); ok {
+			t.Init(collectors.DefaultCatalog)
+		}
 	})
 }
 
+// SetDefaultTagger replaces the local tagger backing the global functions, by
+// a remote tagger in the agents which don't collect tags themselves. It must be
+// called before Init.
+func SetDefaultTagger(t Component) {
+	defaultTagger = t
+}
+
+// Subscribe returns a channel receiving batches of events about the entities
+// of the local tagger, see Tagger.Subscribe
+func Subscribe(cardinality collectors.TagCardinality) (chan []types.EntityEvent, error) {
+	t, ok := defaultTagger.(*Tagger)
+	if !ok {
+		return nil, fmt.Errorf("only the local tagger can be subscribed to")
+	}
+	return t.Subscribe(cardinality), nil
+}
+
+// Unsubscribe stops sending events to the channel returned by Subscribe
+func Unsubscribe(ch chan []types.EntityEvent) {
+	if t, ok := defaultTagger.(*Tagger); ok {
+		t.Unsubscribe(ch)
+	}
+}
+
 // Tag queries the defaultTagger to get entity tags from cache or sources.
 // It can return tags at high cardinality (with tags about individual containers),
 // or at orchestrator cardinality (pod/task level)
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/DataDog/datadog-agent/cmd/agent/api/pb"
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// grpcMaxRecvMsgSize allows the first response, holding all the entities, to be larger than the 4MB default
+	grpcMaxRecvMsgSize   = 64 * 1024 * 1024
+	maxReconnectInterval = 5 * time.Minute
+)
+
+// Make sure Tagger implements the tagger Component interface
+var _ tagger.Component = &Tagger{}
+
+// Tagger holds a copy of the entities of the tagger of the core agent, kept
+// up to date by its gRPC stream. It doesn't collect any tags itself.
+type Tagger struct {
+	store *tagStore
+
+	conn   *grpc.ClientConn
+	client pb.AgentClient
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewTagger returns a new remote Tagger, Start must be called to connect to the core agent
+func NewTagger() *Tagger {
+	return &Tagger{
+		store: newTagStore(),
+	}
+}
+
+// Start connects to the IPC API of the core agent and streams its entities
+// until Stop is called, reconnecting and resyncing the entities on errors
+func (t *Tagger) Start() error {
+	if err := util.SetAuthToken(); err != nil {
+		return fmt.Errorf("unable to get the session token: %s", err)
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+
+	// The IPC certificate is self-signed, the session token authenticates the agent
+	creds := credentials.NewTLS(&tls.Config{
+		InsecureSkipVerify: true,
+	})
+	t.conn, err = grpc.DialContext(
+		t.ctx,
+		net.JoinHostPort(ipcAddress, config.Datadog.GetString("cmd_port")),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(grpcMaxRecvMsgSize)),
+	)
+	if err != nil {
+		t.cancel()
+		return err
+	}
+	t.client = pb.NewAgentClient(t.conn)
+
+	go t.run()
+
+	return nil
+}
+
+// Stop closes the connection to the core agent
+func (t *Tagger) Stop() error {
+	if t.cancel == nil {
+		return nil
+	}
+	t.cancel()
+	return t.conn.Close()
+}
+
+// Tag returns the tags of the entity up to the cardinality, the entities not
+// streamed by the core agent yet don't have any tags
+func (t *Tagger) Tag(entity string, cardinality collectors.TagCardinality) ([]string, error) {
+	e := t.store.get(entity)
+	if e == nil {
+		return nil, nil
+	}
+	return e.GetTags(cardinality), nil
+}
+
+// Standard returns the standard tags (env, version, service) of the entity
+func (t *Tagger) Standard(entity string) ([]string, error) {
+	e := t.store.get(entity)
+	if e == nil {
+		return nil, nil
+	}
+	return e.StandardTags, nil
+}
+
+// GetEntityHash returns the hash of the tags of the entity
+func (t *Tagger) GetEntityHash(entity string) string {
+	e := t.store.get(entity)
+	if e == nil {
+		return ""
+	}
+	return e.Hash
+}
+
+// List returns the entities streamed by the core agent
+func (t *Tagger) List(cardinality collectors.TagCardinality) response.TaggerListResponse {
+	r := response.TaggerListResponse{
+		Entities: make(map[string]response.TaggerListEntity),
+	}
+	for _, e := range t.store.list() {
+		r.Entities[e.ID] = response.TaggerListEntity{
+			Sources: []string{"remote"},
+			Tags:    e.GetTags(cardinality),
+		}
+	}
+	return r
+}
+
+// run streams the entities of the core agent until the context is cancelled
+func (t *Tagger) run() {
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxInterval = maxReconnectInterval
+	expBackoff.MaxElapsedTime = 0 // retry forever
+
+	for {
+		err := t.stream(expBackoff)
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		wait := expBackoff.NextBackOff()
+		log.Warnf("Lost the tagger stream of the core agent, reconnecting in %s: %s", wait, err)
+		select {
+		case <-time.After(wait):
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// stream receives the responses of a single stream, the first one holding all
+// the entities of the core agent
+func (t *Tagger) stream(expBackoff backoff.BackOff) error {
+	ctx := metadata.NewOutgoingContext(t.ctx, metadata.MD{
+		"authorization": []string{"Bearer " + util.GetAuthToken()},
+	})
+
+	// Entities are filtered by cardinality when they are looked up
+	stream, err := t.client.TaggerStreamEntities(ctx, &pb.StreamTagsRequest{
+		Cardinality: pb.TagCardinality_HIGH,
+	})
+	if err != nil {
+		return err
+	}
+
+	resync := true
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		if resync {
+			log.Infof("Connected to the tagger stream of the core agent, %d entities received", len(resp.Events))
+			t.store.replace(resp.Events)
+			expBackoff.Reset()
+			resync = false
+			continue
+		}
+
+		t.store.apply(resp.Events)
+	}
+}
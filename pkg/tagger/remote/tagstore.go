@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package remote
+
+import (
+	"sync"
+
+	pb "github.com/DataDog/datadog-agent/cmd/agent/api/pb"
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// tagStore holds the entities streamed by the core agent
+type tagStore struct {
+	sync.RWMutex
+	store map[string]*types.Entity
+}
+
+func newTagStore() *tagStore {
+	return &tagStore{
+		store: make(map[string]*types.Entity),
+	}
+}
+
+// get returns the entity, entities are never modified once stored
+func (s *tagStore) get(id string) *types.Entity {
+	s.RLock()
+	defer s.RUnlock()
+	return s.store[id]
+}
+
+func (s *tagStore) list() []*types.Entity {
+	s.RLock()
+	defer s.RUnlock()
+
+	entities := make([]*types.Entity, 0, len(s.store))
+	for _, e := range s.store {
+		entities = append(entities, e)
+	}
+	return entities
+}
+
+// replace drops the known entities for the ones of the events, to resync the
+// store with the core agent when connecting to its stream
+func (s *tagStore) replace(events []*pb.StreamTagsEvent) {
+	store := make(map[string]*types.Entity, len(events))
+	for _, event := range events {
+		if event.Type == pb.EventType_DELETED || event.Entity == nil {
+			continue
+		}
+		store[event.Entity.Id] = fromPbEntity(event.Entity)
+	}
+
+	s.Lock()
+	s.store = store
+	s.Unlock()
+}
+
+// apply updates the store with the events of the stream
+func (s *tagStore) apply(events []*pb.StreamTagsEvent) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, event := range events {
+		if event.Entity == nil {
+			continue
+		}
+		switch event.Type {
+		case pb.EventType_ADDED, pb.EventType_MODIFIED:
+			s.store[event.Entity.Id] = fromPbEntity(event.Entity)
+		case pb.EventType_DELETED:
+			delete(s.store, event.Entity.Id)
+		default:
+			log.Debugf("Ignoring tagger event of unknown type %d for entity %s", event.Type, event.Entity.Id)
+		}
+	}
+}
+
+func fromPbEntity(entity *pb.Entity) *types.Entity {
+	return &types.Entity{
+		ID:                          entity.Id,
+		Hash:                        entity.Hash,
+		HighCardinalityTags:         entity.HighCardinalityTags,
+		OrchestratorCardinalityTags: entity.OrchestratorCardinalityTags,
+		LowCardinalityTags:          entity.LowCardinalityTags,
+		StandardTags:                entity.StandardTags,
+	}
+}
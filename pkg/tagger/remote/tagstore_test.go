@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/cmd/agent/api/pb"
+)
+
+func TestTagStoreApply(t *testing.T) {
+	s := newTagStore()
+
+	s.apply([]*pb.StreamTagsEvent{
+		{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: "foo", Hash: "1", LowCardinalityTags: []string{"low"}}},
+		{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: "bar", Hash: "2"}},
+	})
+	require.NotNil(t, s.get("foo"))
+	assert.Equal(t, "1", s.get("foo").Hash)
+	assert.NotNil(t, s.get("bar"))
+
+	s.apply([]*pb.StreamTagsEvent{
+		{Type: pb.EventType_MODIFIED, Entity: &pb.Entity{Id: "foo", Hash: "3", LowCardinalityTags: []string{"low", "low2"}}},
+		{Type: pb.EventType_DELETED, Entity: &pb.Entity{Id: "bar"}},
+	})
+	require.NotNil(t, s.get("foo"))
+	assert.Equal(t, "3", s.get("foo").Hash)
+	assert.Equal(t, []string{"low", "low2"}, s.get("foo").LowCardinalityTags)
+	assert.Nil(t, s.get("bar"))
+	assert.Len(t, s.list(), 1)
+}
+
+func TestTagStoreReplace(t *testing.T) {
+	s := newTagStore()
+	s.apply([]*pb.StreamTagsEvent{
+		{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: "deleted-while-disconnected"}},
+		{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: "foo", Hash: "1"}},
+	})
+
+	// the first response of a new stream holds all the entities of the core agent
+	s.replace([]*pb.StreamTagsEvent{
+		{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: "foo", Hash: "2"}},
+		{Type: pb.EventType_ADDED, Entity: &pb.Entity{Id: "bar"}},
+	})
+	assert.Nil(t, s.get("deleted-while-disconnected"))
+	require.NotNil(t, s.get("foo"))
+	assert.Equal(t, "2", s.get("foo").Hash)
+	assert.NotNil(t, s.get("bar"))
+	assert.Len(t, s.list(), 2)
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package server
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/DataDog/datadog-agent/cmd/agent/api/pb"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Server streams the entities of the local tagger to the other agents
+type Server struct{}
+
+// NewServer returns a new Server
+func NewServer() *Server {
+	return &Server{}
+}
+
+// TaggerStreamEntities subscribes to the tagger and streams its entity events
+// until the client disconnects. The first response holds all the known entities.
+func (s *Server) TaggerStreamEntities(in *pb.StreamTagsRequest, out pb.Agent_TaggerStreamEntitiesServer) error {
+	cardinality, err := pbToTagCardinality(in.Cardinality)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ch, err := tagger.Subscribe(cardinality)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer tagger.Unsubscribe(ch)
+
+	for {
+		select {
+		case events, ok := <-ch:
+			if !ok {
+				// the client will reconnect and receive all the entities again
+				return status.Error(codes.Aborted, "client not keeping up with the tagger events")
+			}
+
+			resp := &pb.StreamTagsResponse{
+				Events: make([]*pb.StreamTagsEvent, 0, len(events)),
+			}
+			for _, event := range events {
+				resp.Events = append(resp.Events, toPbEvent(event))
+			}
+
+			if err := out.Send(resp); err != nil {
+				log.Debugf("Unable to send tagger events: %s", err)
+				return err
+			}
+		case <-out.Context().Done():
+			return nil
+		}
+	}
+}
+
+func pbToTagCardinality(cardinality pb.TagCardinality) (collectors.TagCardinality, error) {
+	switch cardinality {
+	case pb.TagCardinality_LOW:
+		return collectors.LowCardinality, nil
+	case pb.TagCardinality_ORCHESTRATOR:
+		return collectors.OrchestratorCardinality, nil
+	case pb.TagCardinality_HIGH:
+		return collectors.HighCardinality, nil
+	}
+	return collectors.LowCardinality, fmt.Errorf("unknown tag cardinality %d", cardinality)
+}
+
+func toPbEvent(event types.EntityEvent) *pb.StreamTagsEvent {
+	var eventType pb.EventType
+	switch event.EventType {
+	case types.EventTypeAdded:
+		eventType = pb.EventType_ADDED
+	case types.EventTypeModified:
+		eventType = pb.EventType_MODIFIED
+	case types.EventTypeDeleted:
+		eventType = pb.EventType_DELETED
+	}
+
+	return &pb.StreamTagsEvent{
+		Type: eventType,
+		Entity: &pb.Entity{
+			Id:                          event.Entity.ID,
+			Hash:                        event.Entity.Hash,
+			HighCardinalityTags:         event.Entity.HighCardinalityTags,
+			OrchestratorCardinalityTags: event.Entity.OrchestratorCardinalityTags,
+			LowCardinalityTags:          event.Entity.LowCardinalityTags,
+			StandardTags:                event.Entity.StandardTags,
+		},
+	}
+}
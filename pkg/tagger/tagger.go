@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/retry"
 )
@@ -318,6 +319,18 @@ func (t *Tagger) List(cardinality collectors.TagCardinality) response.TaggerList
 	return r
 }
 
+// Subscribe returns a channel receiving batches of entity events with the tags
+// up to the cardinality, starting with a batch of all the known entities. The
+// channel is closed if the subscriber doesn't keep up, it must then subscribe again.
+func (t *Tagger) Subscribe(cardinality collectors.TagCardinality) chan []types.EntityEvent {
+	return t.tagStore.subscribe(cardinality)
+}
+
+// Unsubscribe stops sending events to the channel returned by Subscribe
+func (t *Tagger) Unsubscribe(ch chan []types.EntityEvent) {
+	t.tagStore.unsubscribe(ch)
+}
+
 // copyArray makes sure the tagger does not return internal slices
 // that could be modified by others, by explicitly copying the slice
 // contents to a new slice. As strings are references, the size of
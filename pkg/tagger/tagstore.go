@@ -11,8 +11,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 )
 
+// subscriberBufferSize is the number of event batches a subscriber can lag
+// behind before being dropped
+const subscriberBufferSize = 100
+
 // entityTags holds the tag information for a given entity
 type entityTags struct {
 	sync.RWMutex
@@ -34,13 +39,15 @@ type tagStore struct {
 	storeMutex    sync.RWMutex
 	store         map[string]*entityTags
 	toDeleteMutex sync.RWMutex
-	toDelete      map[string]struct{} // set emulation
+	toDelete      map[string]struct{}                                    // set emulation
+	subscribers   map[chan []types.EntityEvent]collectors.TagCardinality // protected by storeMutex
 }
 
 func newTagStore() *tagStore {
 	return &tagStore{
-		store:    make(map[string]*entityTags),
-		toDelete: make(map[string]struct{}),
+		store:       make(map[string]*entityTags),
+		toDelete:    make(map[string]struct{}),
+		subscribers: make(map[chan []types.EntityEvent]collectors.TagCardinality),
 	}
 }
 
@@ -75,9 +82,32 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 		s.store[info.Entity] = storedTags
 	}
 
-	storedTags.Lock()
-	defer storedTags.Unlock()
-	_, found := storedTags.lowCardTags[info.Source]
+	var previousHash string
+	if exist && len(s.subscribers) > 0 {
+		_, _, previousHash = storedTags.get(collectors.HighCardinality)
+	}
+
+	if err := storedTags.setSourceTags(info); err != nil {
+		return err
+	}
+
+	if len(s.subscribers) > 0 {
+		entity := storedTags.toEntity(info.Entity)
+		if !exist {
+			s.notify([]types.EntityEvent{{EventType: types.EventTypeAdded, Entity: entity}})
+		} else if entity.Hash != previousHash {
+			s.notify([]types.EntityEvent{{EventType: types.EventTypeModified, Entity: entity}})
+		}
+	}
+
+	return nil
+}
+
+// setSourceTags replaces the tags of the source of the TagInfo
+func (e *entityTags) setSourceTags(info *collectors.TagInfo) error {
+	e.Lock()
+	defer e.Unlock()
+	_, found := e.lowCardTags[info.Source]
 	if found && info.CacheMiss {
 		// check if the source tags is already present for this entry
 		// Only check once since we always write all cardinality tag levels.
@@ -85,15 +115,69 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 		log.Tracef("processTagInfo err: %v", err)
 		return err
 	}
-	storedTags.lowCardTags[info.Source] = info.LowCardTags
-	storedTags.orchestratorCardTags[info.Source] = info.OrchestratorCardTags
-	storedTags.highCardTags[info.Source] = info.HighCardTags
-	storedTags.standardTags[info.Source] = info.StandardTags
-	storedTags.cacheValid = false
+	e.lowCardTags[info.Source] = info.LowCardTags
+	e.orchestratorCardTags[info.Source] = info.OrchestratorCardTags
+	e.highCardTags[info.Source] = info.HighCardTags
+	e.standardTags[info.Source] = info.StandardTags
+	e.cacheValid = false
 
 	return nil
 }
 
+// subscribe returns a channel receiving batches of entity events, with the
+// tags up to the cardinality. The first batch holds all the known entities.
+// The channel is closed if the subscriber doesn't keep up with the events.
+func (s *tagStore) subscribe(cardinality collectors.TagCardinality) chan []types.EntityEvent {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	events := make([]types.EntityEvent, 0, len(s.store))
+	for entityID, storedTags := range s.store {
+		events = append(events, types.EntityEvent{
+			EventType: types.EventTypeAdded,
+			Entity:    storedTags.toEntity(entityID).Filter(cardinality),
+		})
+	}
+
+	ch := make(chan []types.EntityEvent, subscriberBufferSize)
+	ch <- events
+	s.subscribers[ch] = cardinality
+
+	return ch
+}
+
+// unsubscribe stops sending events to the channel and closes it
+func (s *tagStore) unsubscribe(ch chan []types.EntityEvent) {
+	s.storeMutex.Lock()
+	defer s.storeMutex.Unlock()
+
+	if _, found := s.subscribers[ch]; found {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// notify sends the events to the subscribers, storeMutex must be held
+func (s *tagStore) notify(events []types.EntityEvent) {
+	for ch, cardinality := range s.subscribers {
+		filtered := make([]types.EntityEvent, 0, len(events))
+		for _, event := range events {
+			filtered = append(filtered, types.EntityEvent{
+				EventType: event.EventType,
+				Entity:    event.Entity.Filter(cardinality),
+			})
+		}
+
+		select {
+		case ch <- filtered:
+		default:
+			log.Warnf("Tagger subscriber not keeping up with the events, dropping it")
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
 func computeTagsHash(tags []string) string {
 	hash := ""
 	if len(tags) > 0 {
@@ -121,9 +205,19 @@ func (s *tagStore) prune() error {
 
 	s.storeMutex.Lock()
 	defer s.storeMutex.Unlock()
+	var events []types.EntityEvent
 	for entity := range s.toDelete {
+		if _, found := s.store[entity]; found && len(s.subscribers) > 0 {
+			events = append(events, types.EntityEvent{
+				EventType: types.EventTypeDeleted,
+				Entity:    types.Entity{ID: entity},
+			})
+		}
 		delete(s.store, entity)
 	}
+	if len(events) > 0 {
+		s.notify(events)
+	}
 
 	log.Debugf("pruned %d removed entities, %d remaining", len(s.toDelete), len(s.store))
 
@@ -169,6 +263,29 @@ func (e *entityTags) getStandard() []string {
 	return tags
 }
 
+// toEntity returns the tags of the entity by cardinality
+func (e *entityTags) toEntity(entityID string) types.Entity {
+	// fill the cache
+	e.get(collectors.HighCardinality)
+
+	e.RLock()
+	defer e.RUnlock()
+
+	var standardTags []string
+	for _, tags := range e.standardTags {
+		standardTags = append(standardTags, tags...)
+	}
+
+	return types.Entity{
+		ID:                          entityID,
+		Hash:                        e.tagsHash,
+		LowCardinalityTags:          copyArray(e.cachedLow),
+		OrchestratorCardinalityTags: copyArray(e.cachedOrchestrator[len(e.cachedLow):]),
+		HighCardinalityTags:         copyArray(e.cachedAll[len(e.cachedOrchestrator):]),
+		StandardTags:                standardTags,
+	}
+}
+
 type tagPriority struct {
 	tag         string                       // full tag
 	priority    collectors.CollectorPriority // collector priority
@@ -6,13 +6,16 @@
 package tagger
 
 import (
+	"fmt"
 	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 )
 
 type StoreTestSuite struct {
@@ -207,6 +210,90 @@ func (s *StoreTestSuite) TestPrune() {
 
 }
 
+func (s *StoreTestSuite) TestSubscribe() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:       "source1",
+		Entity:       "existing",
+		LowCardTags:  []string{"low"},
+		HighCardTags: []string{"high"},
+	})
+
+	ch := s.store.subscribe(collectors.LowCardinality)
+	defer s.store.unsubscribe(ch)
+
+	// all the known entities, filtered by cardinality
+	events := <-ch
+	require.Len(s.T(), events, 1)
+	assert.Equal(s.T(), types.EventTypeAdded, events[0].EventType)
+	assert.Equal(s.T(), "existing", events[0].Entity.ID)
+	assert.Equal(s.T(), []string{"low"}, events[0].Entity.LowCardinalityTags)
+	assert.Empty(s.T(), events[0].Entity.HighCardinalityTags)
+
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "new",
+		LowCardTags: []string{"low"},
+	})
+	events = <-ch
+	require.Len(s.T(), events, 1)
+	assert.Equal(s.T(), types.EventTypeAdded, events[0].EventType)
+	assert.Equal(s.T(), "new", events[0].Entity.ID)
+
+	// same tags, no event
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "new",
+		LowCardTags: []string{"low"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source2",
+		Entity:      "new",
+		LowCardTags: []string{"low2"},
+	})
+	events = <-ch
+	require.Len(s.T(), events, 1)
+	assert.Equal(s.T(), types.EventTypeModified, events[0].EventType)
+	assert.ElementsMatch(s.T(), []string{"low", "low2"}, events[0].Entity.LowCardinalityTags)
+
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:       "source1",
+		Entity:       "existing",
+		DeleteEntity: true,
+	})
+	s.store.prune()
+	events = <-ch
+	require.Len(s.T(), events, 1)
+	assert.Equal(s.T(), types.EventTypeDeleted, events[0].EventType)
+	assert.Equal(s.T(), "existing", events[0].Entity.ID)
+
+	assert.Len(s.T(), ch, 0)
+}
+
+func (s *StoreTestSuite) TestSubscriberNotKeepingUp() {
+	ch := s.store.subscribe(collectors.LowCardinality)
+
+	for i := 0; i <= subscriberBufferSize; i++ {
+		s.store.processTagInfo(&collectors.TagInfo{
+			Source:      "source1",
+			Entity:      fmt.Sprintf("entity%d", i),
+			LowCardTags: []string{"low"},
+		})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.Equal(s.T(), subscriberBufferSize, received)
+
+	s.store.storeMutex.RLock()
+	assert.Len(s.T(), s.store.subscribers, 0)
+	s.store.storeMutex.RUnlock()
+
+	// no-op once dropped
+	s.store.unsubscribe(ch)
+}
+
 func TestStoreSuite(t *testing.T) {
 	suite.Run(t, &StoreTestSuite{})
 }
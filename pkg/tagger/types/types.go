@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package types
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+// EventType is the type of an entity event
+type EventType int
+
+const (
+	// EventTypeAdded is sent for new entities, and for every known entity
+	// when subscribing to the tagger
+	EventTypeAdded EventType = iota
+	// EventTypeModified is sent when the tags of an entity changed
+	EventTypeModified
+	// EventTypeDeleted is sent when an entity is pruned from the tagger
+	EventTypeDeleted
+)
+
+// Entity is an entity and its tags, by cardinality
+type Entity struct {
+	ID                          string
+	Hash                        string
+	HighCardinalityTags         []string
+	OrchestratorCardinalityTags []string
+	LowCardinalityTags          []string
+	StandardTags                []string
+}
+
+// EntityEvent is an event of the tagger about an entity, only the ID of
+// the entity is set for deleted entities
+type EntityEvent struct {
+	EventType EventType
+	Entity    Entity
+}
+
+// GetTags returns the tags of the entity up to the cardinality
+func (e *Entity) GetTags(cardinality collectors.TagCardinality) []string {
+	tags := make([]string, 0, len(e.LowCardinalityTags)+len(e.OrchestratorCardinalityTags)+len(e.HighCardinalityTags))
+	tags = append(tags, e.LowCardinalityTags...)
+	if cardinality == collectors.OrchestratorCardinality || cardinality == collectors.HighCardinality {
+		tags = append(tags, e.OrchestratorCardinalityTags...)
+	}
+	if cardinality == collectors.HighCardinality {
+		tags = append(tags, e.HighCardinalityTags...)
+	}
+	return tags
+}
+
+// Filter returns a copy of the entity without the tags above the cardinality
+func (e Entity) Filter(cardinality collectors.TagCardinality) Entity {
+	switch cardinality {
+	case collectors.LowCardinality:
+		e.OrchestratorCardinalityTags = nil
+		e.HighCardinalityTags = nil
+	case collectors.OrchestratorCardinality:
+		e.HighCardinalityTags = nil
+	}
+	return e
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+func TestEntityGetTags(t *testing.T) {
+	e := Entity{
+		ID:                          "foo",
+		LowCardinalityTags:          []string{"low"},
+		OrchestratorCardinalityTags: []string{"orch"},
+		HighCardinalityTags:         []string{"high"},
+	}
+
+	assert.Equal(t, []string{"low"}, e.GetTags(collectors.LowCardinality))
+	assert.Equal(t, []string{"low", "orch"}, e.GetTags(collectors.OrchestratorCardinality))
+	assert.Equal(t, []string{"low", "orch", "high"}, e.GetTags(collectors.HighCardinality))
+}
+
+func TestEntityFilter(t *testing.T) {
+	e := Entity{
+		ID:                          "foo",
+		Hash:                        "hash",
+		LowCardinalityTags:          []string{"low"},
+		OrchestratorCardinalityTags: []string{"orch"},
+		HighCardinalityTags:         []string{"high"},
+		StandardTags:                []string{"env:prod"},
+	}
+
+	low := e.Filter(collectors.LowCardinality)
+	assert.Equal(t, "foo", low.ID)
+	assert.Equal(t, "hash", low.Hash)
+	assert.Equal(t, []string{"low"}, low.LowCardinalityTags)
+	assert.Nil(t, low.OrchestratorCardinalityTags)
+	assert.Nil(t, low.HighCardinalityTags)
+	assert.Equal(t, []string{"env:prod"}, low.StandardTags)
+
+	orch := e.Filter(collectors.OrchestratorCardinality)
+	assert.Equal(t, []string{"orch"}, orch.OrchestratorCardinalityTags)
+	assert.Nil(t, orch.HighCardinalityTags)
+
+	assert.Equal(t, e, e.Filter(collectors.HighCardinality))
+	// the entity itself isn't modified
+	assert.Equal(t, []string{"high"}, e.HighCardinalityTags)
+}
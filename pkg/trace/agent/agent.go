@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/event"
 	"github.com/DataDog/datadog-agent/pkg/trace/filters"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
@@ -166,15 +167,15 @@ func (a *Agent) Process(t *api.Trace, sublayerCalculator *stats.SublayerCalculat
 		log.Debugf("Trace rejected by blacklister. root: %v", root)
 		atomic.AddInt64(&ts.TracesFiltered, 1)
 		atomic.AddInt64(&ts.SpansFiltered, int64(len(t.Spans)))
+		if a.Receiver.Inspector != nil {
+			// the trace is dropped, it's only sanitized to be recorded
+			a.sanitize(t.Spans)
+			a.inspectTrace(t.Spans, root, inspect.DecisionFiltered, "blacklister")
+		}
 		return
 	}
 
-	// Extra sanitization steps of the trace.
-	for _, span := range t.Spans {
-		a.obfuscator.Obfuscate(span)
-		Truncate(span)
-	}
-	a.Replacer.Replace(t.Spans)
+	a.sanitize(t.Spans)
 
 	{
 		// this section sets up any necessary tags on the root:
@@ -202,7 +203,7 @@ func (a *Agent) Process(t *api.Trace, sublayerCalculator *stats.SublayerCalculat
 		Sublayers:     make(map[*pb.Span][]stats.SublayerValue),
 	}
 
-	sampledSpans, sampled := a.sample(ts, pt)
+	sampledSpans, sampled, decider := a.sample(ts, pt)
 
 	subtraces := stats.ExtractSubtraces(t.Spans, root)
 	for _, subtrace := range subtraces {
@@ -224,14 +225,50 @@ func (a *Agent) Process(t *api.Trace, sublayerCalculator *stats.SublayerCalculat
 		Env:       pt.Env,
 	}
 
+	decision := inspect.DecisionDropped
+	if sampled {
+		decision = inspect.DecisionKept
+	}
+	a.inspectTrace(t.Spans, root, decision, decider)
+
 	if sampled {
 		a.Out <- sampledSpans
 	}
 }
 
+// sanitize runs the extra sanitization steps of the trace: obfuscation,
+// truncation and tag replacement.
+func (a *Agent) sanitize(t pb.Trace) {
+	for _, span := range t {
+		a.obfuscator.Obfuscate(span)
+		Truncate(span)
+	}
+	a.Replacer.Replace(t)
+}
+
+// inspectTrace records the trace in the receiver's inspection buffer, if enabled.
+// The trace must have been sanitized already.
+func (a *Agent) inspectTrace(t pb.Trace, root *pb.Span, decision inspect.Decision, decider string) {
+	if a.Receiver.Inspector == nil {
+		return
+	}
+	priority, hasPriority := sampler.GetSamplingPriority(root)
+	a.Receiver.Inspector.Add(inspect.Record{
+		Received:    time.Now(),
+		TraceID:     root.TraceID,
+		Service:     root.Service,
+		Resource:    root.Resource,
+		Priority:    int(priority),
+		HasPriority: hasPriority,
+		Decision:    decision,
+		Sampler:     decider,
+		Spans:       t,
+	})
+}
+
 // sample decides whether the trace will be kept and extracts any APM events
-// from it.
-func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (*writer.SampledSpans, bool) {
+// from it. It also returns the name of the sampler which took the decision.
+func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (*writer.SampledSpans, bool, string) {
 	priority, hasPriority := sampler.GetSamplingPriority(pt.Root)
 
 	// Depending on the sampling priority, count that trace differently.
@@ -250,11 +287,11 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (*writer.SampledSpa
 	atomic.AddInt64(stat, 1)
 
 	if priority < 0 {
-		return nil, false
+		return nil, false, samplerNamePriority
 	}
 
 	var ss writer.SampledSpans
-	sampled, rate := a.runSamplers(pt, hasPriority)
+	sampled, rate, decider := a.runSamplers(pt, hasPriority)
 	if sampled {
 		sampler.AddGlobalRate(pt.Root, rate)
 		ss.Trace = pt.Trace
@@ -266,12 +303,25 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (*writer.SampledSpa
 	atomic.AddInt64(&ts.EventsExtracted, int64(numExtracted))
 	atomic.AddInt64(&ts.EventsSampled, int64(len(events)))
 
-	return &ss, !ss.Empty()
+	if !sampled && len(events) > 0 {
+		decider = samplerNameEvents
+	}
+
+	return &ss, !ss.Empty(), decider
 }
 
+// Names of the samplers, as reported by the inspection buffer.
+const (
+	samplerNamePriority  = "priority"
+	samplerNameErrors    = "errors"
+	samplerNameException = "exception"
+	samplerNameScore     = "score"
+	samplerNameEvents    = "events"
+)
+
 // runSamplers runs all the agent's samplers on pt and returns the sampling decision
-// along with the sampling rate.
-func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) (bool, float64) {
+// along with the sampling rate and the name of the sampler which took the decision.
+func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) (bool, float64, string) {
 	if hasPriority {
 		return a.samplePriorityTrace(pt)
 	}
@@ -281,25 +331,31 @@ func (a *Agent) runSamplers(pt ProcessedTrace, hasPriority bool) (bool, float64)
 // samplePriorityTrace samples traces with priority set on them. PrioritySampler and
 // ErrorSampler are run in parallel. The ExceptionSampler catches traces with rare top-level
 // or measured spans that are not caught by PrioritySampler and ErrorSampler.
-func (a *Agent) samplePriorityTrace(pt ProcessedTrace) (sampled bool, rate float64) {
+func (a *Agent) samplePriorityTrace(pt ProcessedTrace) (sampled bool, rate float64, decider string) {
 	sampledPriority, ratePriority := a.PrioritySampler.Add(pt)
 	if traceContainsError(pt.Trace) {
 		sampledError, rateError := a.ErrorsScoreSampler.Add(pt)
-		return sampledError || sampledPriority, sampler.CombineRates(ratePriority, rateError)
+		decider = samplerNamePriority
+		if sampledError && !sampledPriority {
+			decider = samplerNameErrors
+		}
+		return sampledError || sampledPriority, sampler.CombineRates(ratePriority, rateError), decider
 	}
 	if sampled := a.ExceptionSampler.Add(pt.Env, pt.Root, pt.Trace); sampled {
-		return sampled, 1
+		return sampled, 1, samplerNameException
 	}
-	return sampledPriority, ratePriority
+	return sampledPriority, ratePriority, samplerNamePriority
 }
 
 // sampleNoPriorityTrace samples traces with no priority set on them. The traces
 // get sampled by either the score sampler or the error sampler if they have an error.
-func (a *Agent) sampleNoPriorityTrace(pt ProcessedTrace) (sampled bool, rate float64) {
+func (a *Agent) sampleNoPriorityTrace(pt ProcessedTrace) (sampled bool, rate float64, decider string) {
 	if traceContainsError(pt.Trace) {
-		return a.ErrorsScoreSampler.Add(pt)
+		sampled, rate = a.ErrorsScoreSampler.Add(pt)
+		return sampled, rate, samplerNameErrors
 	}
-	return a.ScoreSampler.Add(pt)
+	sampled, rate = a.ScoreSampler.Add(pt)
+	return sampled, rate, samplerNameScore
 }
 
 func traceContainsError(trace pb.Trace) bool {
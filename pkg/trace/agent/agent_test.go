@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/event"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
@@ -141,6 +142,37 @@ func TestProcess(t *testing.T) {
 		assert.EqualValues(2, want.SpansFiltered)
 	})
 
+	t.Run("Inspector", func(t *testing.T) {
+		// Ensures that traces are recorded once sanitized, including filtered ones
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Ignore["resource"] = []string{"^INSERT.*"}
+		cfg.InspectBufferSize = 10
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewAgent(ctx, cfg)
+		defer cancel()
+
+		now := time.Now()
+		for _, resource := range []string{"SELECT name FROM people WHERE age = 42", "INSERT INTO db VALUES (1, 2, 3)"} {
+			agnt.Process(&api.Trace{
+				Spans: pb.Trace{{
+					Resource: resource,
+					Type:     "sql",
+					Start:    now.Add(-time.Second).UnixNano(),
+					Duration: (500 * time.Millisecond).Nanoseconds(),
+				}},
+				Source: &info.Tags{},
+			}, stats.NewSublayerCalculator())
+		}
+
+		records, _ := agnt.Receiver.Inspector.Since(0, inspect.Filter{}, 0)
+		assert := assert.New(t)
+		assert.Len(records, 2)
+		assert.Equal("SELECT name FROM people WHERE age = ?", records[0].Spans[0].Meta["sql.query"])
+		assert.Equal(inspect.DecisionFiltered, records[1].Decision)
+		assert.Equal("INSERT INTO db VALUES ( ? )", records[1].Spans[0].Meta["sql.query"])
+	})
+
 	t.Run("ContainerTags", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -332,7 +364,7 @@ func TestSampling(t *testing.T) {
 				sampler.SetSamplingPriority(pt.Root, 1)
 			}
 
-			sampled, rate := a.runSamplers(pt, tt.hasPriority)
+			sampled, rate, _ := a.runSamplers(pt, tt.hasPriority)
 			assert.EqualValues(t, tt.wantRate, rate)
 			assert.EqualValues(t, tt.wantSampled, sampled)
 		})
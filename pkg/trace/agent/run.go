@@ -7,6 +7,7 @@ package agent
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
@@ -17,9 +18,11 @@ import (
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/remote"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/flags"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
@@ -67,6 +70,13 @@ func Run(ctx context.Context) {
 		return
 	}
 
+	if flag.Arg(0) == "inspect" {
+		if err := inspect.Run(os.Stdout, cfg, flag.Args()[1:]); err != nil {
+			osutil.Exitf("Failed to inspect traces: %s", err)
+		}
+		return
+	}
+
 	if err := coreconfig.SetupLogger(
 		coreconfig.LoggerName("TRACE"),
 		cfg.LogLevel,
@@ -124,6 +134,14 @@ func Run(ctx context.Context) {
 
 	rand.Seed(time.Now().UTC().UnixNano())
 
+	if coreconfig.Datadog.GetBool("apm_config.remote_tagger") {
+		t := remote.NewTagger()
+		if err := t.Start(); err != nil {
+			log.Errorf("Unable to start the remote tagger, collecting tags locally: %s", err)
+		} else {
+			tagger.SetDefaultTagger(t)
+		}
+	}
 	tagger.Init()
 	defer tagger.Stop()
 
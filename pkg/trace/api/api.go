@@ -34,6 +34,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/inspect"
 	"github.com/DataDog/datadog-agent/pkg/trace/logutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/metrics/timing"
@@ -64,6 +65,11 @@ const (
 	// Traces: msgpack/JSON (Content-Type) slice of traces + returns service sampling ratios
 	// Services: deprecated
 	v04 Version = "v0.4"
+	// v05
+	// Traces: msgpack only, string table followed by traces whose spans are arrays
+	// referencing the table, returns service sampling ratios.
+	// Services: deprecated
+	v05 Version = "v0.5"
 )
 
 // HTTPReceiver is a collector that uses HTTP protocol and just holds
@@ -71,11 +77,17 @@ const (
 type HTTPReceiver struct {
 	Stats       *info.ReceiverStats
 	RateLimiter *rateLimiter
+	// Inspector holds the most recently processed traces. It is nil when
+	// trace inspection is disabled.
+	Inspector *inspect.Buffer
 
 	out     chan *Trace
 	conf    *config.AgentConfig
 	dynConf *sampler.DynamicConfig
 	server  *http.Server
+	// debugServer serves the inspection buffer on localhost, it's nil when
+	// trace inspection is disabled.
+	debugServer *http.Server
 
 	debug               bool
 	rateLimiterResponse int // HTTP status code when refusing
@@ -93,6 +105,7 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 	return &HTTPReceiver{
 		Stats:       info.NewReceiverStats(),
 		RateLimiter: newRateLimiter(),
+		Inspector:   inspect.NewBuffer(conf.InspectBufferSize),
 		out:         out,
 
 		conf:    conf,
@@ -121,6 +134,7 @@ func (r *HTTPReceiver) Start() {
 	mux.HandleFunc("/v0.3/services", r.handleWithVersion(v03, r.handleServices))
 	mux.HandleFunc("/v0.4/traces", r.handleWithVersion(v04, r.handleTraces))
 	mux.HandleFunc("/v0.4/services", r.handleWithVersion(v04, r.handleServices))
+	mux.HandleFunc("/v0.5/traces", r.handleWithVersion(v05, r.handleTraces))
 	mux.Handle("/profiling/v1/input", r.profileProxyHandler())
 
 	timeout := 5 * time.Second
@@ -158,6 +172,8 @@ func (r *HTTPReceiver) Start() {
 		log.Infof("Listening for traces at unix://%s", path)
 	}
 
+	r.startDebugServer()
+
 	go r.RateLimiter.Run()
 
 	go func() {
@@ -203,6 +219,30 @@ func (r *HTTPReceiver) attachDebugHandlers(mux *http.ServeMux) {
 	}))
 }
 
+// startDebugServer serves the debugging endpoints that expose trace data on
+// localhost only, as opposed to the receiver which may be reachable from
+// other hosts.
+func (r *HTTPReceiver) startDebugServer() {
+	if r.Inspector == nil || r.conf.DebugServerPort <= 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle(inspect.Path, r.Inspector)
+	r.debugServer = &http.Server{Handler: mux}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", r.conf.DebugServerPort)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Errorf("Error creating debug server listener, trace inspection won't be served: %v", err)
+		return
+	}
+	go func() {
+		defer watchdog.LogOnPanic()
+		r.debugServer.Serve(ln)
+	}()
+	log.Infof("Debug server listening at http://%s", addr)
+}
+
 // listenUnix returns a net.Listener listening on the given "unix" socket path.
 func (r *HTTPReceiver) listenUnix(path string) (net.Listener, error) {
 	fi, err := os.Stat(path)
@@ -255,6 +295,11 @@ func (r *HTTPReceiver) Stop() error {
 	if err := r.server.Shutdown(ctx); err != nil {
 		return err
 	}
+	if r.debugServer != nil {
+		if err := r.debugServer.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
 	r.wg.Wait()
 	close(r.out)
 	return nil
@@ -267,6 +312,11 @@ func (r *HTTPReceiver) handleWithVersion(v Version, f func(Version, http.Respons
 			httpFormatError(w, v, fmt.Errorf("unsupported media type: %q", mediaType))
 			return
 		}
+		if mediaType := getMediaType(req); mediaType != "application/msgpack" && v == v05 {
+			// v0.5 is only available as msgpack
+			httpFormatError(w, v, fmt.Errorf("unsupported media type: %q", mediaType))
+			return
+		}
 
 		req.Body = NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
 
@@ -337,6 +387,13 @@ func (r *HTTPReceiver) decodeTraces(v Version, req *http.Request) (pb.Traces, er
 		}
 		return tracesFromSpans(spans), nil
 	}
+	if v == v05 {
+		var traces pb.Traces
+		if err := traces.DecodeMsgDictionary(msgp.NewReader(req.Body)); err != nil {
+			return nil, err
+		}
+		return traces, nil
+	}
 	var traces pb.Traces
 	if err := decodeRequest(req, &traces); err != nil {
 		return nil, err
@@ -348,7 +405,7 @@ func (r *HTTPReceiver) replyOK(v Version, w http.ResponseWriter) {
 	switch v {
 	case v01, v02, v03:
 		httpOK(w)
-	case v04:
+	case v04, v05:
 		httpRateByService(w, r.dynConf)
 	}
 }
@@ -327,6 +327,55 @@ func TestReceiverMsgpackDecoder(t *testing.T) {
 	}
 }
 
+func TestReceiverV05(t *testing.T) {
+	assert := assert.New(t)
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	server := httptest.NewServer(http.HandlerFunc(r.handleWithVersion(v05, r.handleTraces)))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	assert.NoError(testutil.GetTestTraces(1, 1, false).EncodeMsgDictionary(msgp.NewWriter(&buf)))
+
+	t.Run("msgpack", func(t *testing.T) {
+		resp, err := http.Post(server.URL, "application/msgpack", bytes.NewReader(buf.Bytes()))
+		assert.NoError(err)
+		defer resp.Body.Close()
+		assert.Equal(200, resp.StatusCode)
+
+		select {
+		case rt := <-r.out:
+			assert.Len(rt.Spans, 1)
+			span := rt.Spans[0]
+			assert.Equal(uint64(42), span.TraceID)
+			assert.Equal(uint64(52), span.SpanID)
+			assert.Equal("fennel_is_amazing", span.Service)
+			assert.Equal("something_that_should_be_a_metric", span.Name)
+			assert.Equal("NOT touched because it is going to be hashed", span.Resource)
+			assert.Equal("192.168.0.1", span.Meta["http.host"])
+			assert.Equal(41.99, span.Metrics["http.monitor"])
+		case <-time.After(time.Second):
+			t.Fatalf("no data received")
+		}
+
+		var tr traceResponse
+		assert.NoError(json.NewDecoder(resp.Body).Decode(&tr), "the answer should be a valid JSON")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		resp, err := http.Post(server.URL, "application/json", bytes.NewReader(buf.Bytes()))
+		assert.NoError(err)
+		resp.Body.Close()
+		assert.Equal(415, resp.StatusCode)
+	})
+
+	t.Run("v0.4 payload", func(t *testing.T) {
+		resp, err := http.Post(server.URL, "application/msgpack", bytes.NewReader(msgpTraces(t, testutil.GetTestTraces(1, 1, false))))
+		assert.NoError(err)
+		resp.Body.Close()
+		assert.Equal(400, resp.StatusCode)
+	})
+}
+
 func TestReceiverDecodingError(t *testing.T) {
 	assert := assert.New(t)
 	conf := newTestReceiverConfig()
@@ -213,6 +213,12 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if k := "apm_config.max_payload_size"; config.Datadog.IsSet(k) {
 		c.MaxRequestBytes = config.Datadog.GetInt64(k)
 	}
+	if k := "apm_config.inspect_buffer_size"; config.Datadog.IsSet(k) {
+		c.InspectBufferSize = config.Datadog.GetInt(k)
+	}
+	if k := "apm_config.debug.port"; config.Datadog.IsSet(k) {
+		c.DebugServerPort = config.Datadog.GetInt(k)
+	}
 
 	if config.Datadog.IsSet("apm_config.replace_tags") {
 		rt := make([]*ReplaceRule, 0)
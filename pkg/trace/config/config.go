@@ -78,6 +78,13 @@ type AgentConfig struct {
 	ReceiverTimeout int
 	MaxRequestBytes int64 // specifies the maximum allowed request size for incoming trace payloads
 
+	// InspectBufferSize is the number of recently processed traces kept in memory
+	// and exposed on the /debug/traces endpoint. 0 disables the buffer.
+	InspectBufferSize int
+	// DebugServerPort is the port of the server listening on localhost for the
+	// debugging endpoints which expose trace data, such as /debug/traces.
+	DebugServerPort int
+
 	// Writers
 	StatsWriter             *WriterConfig
 	TraceWriter             *WriterConfig
@@ -137,6 +144,8 @@ func New() *AgentConfig {
 		ReceiverPort:    8126,
 		MaxRequestBytes: 50 * 1024 * 1024, // 50MB
 
+		DebugServerPort: 5012,
+
 		StatsWriter:             new(WriterConfig),
 		TraceWriter:             new(WriterConfig),
 		ConnectionResetInterval: 0, // disabled
@@ -48,6 +48,8 @@ func loadEnv() {
 		{"DD_APM_MAX_MEMORY", "apm_config.max_memory"},
 		{"DD_APM_MAX_CPU_PERCENT", "apm_config.max_cpu_percent"},
 		{"DD_APM_RECEIVER_SOCKET", "apm_config.receiver_socket"},
+		{"DD_APM_INSPECT_BUFFER_SIZE", "apm_config.inspect_buffer_size"},
+		{"DD_APM_DEBUG_PORT", "apm_config.debug.port"},
 	} {
 		if v := os.Getenv(override.env); v != "" {
 			config.Datadog.Set(override.key, v)
@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package inspect keeps a bounded history of the traces recently processed by
+// the trace-agent, along with the sampling decision that was taken for each of
+// them, and exposes it for local debugging purposes.
+package inspect
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// Decision describes what happened to a trace once it went through the agent.
+type Decision string
+
+const (
+	// DecisionKept is used for traces which were kept by one of the samplers.
+	DecisionKept Decision = "kept"
+	// DecisionDropped is used for traces which were dropped by the samplers.
+	DecisionDropped Decision = "dropped"
+	// DecisionFiltered is used for traces which were rejected before sampling.
+	DecisionFiltered Decision = "filtered"
+)
+
+// Record holds a trace along with its sampling metadata.
+type Record struct {
+	// Seq is a monotonically increasing sequence number which allows clients
+	// to only retrieve records they haven't seen yet.
+	Seq uint64 `json:"seq"`
+	// Received is the time at which the trace was processed.
+	Received time.Time `json:"received"`
+	// TraceID is the ID of the trace.
+	TraceID uint64 `json:"trace_id"`
+	// Service and Resource are those of the root span.
+	Service  string `json:"service"`
+	Resource string `json:"resource"`
+	// Priority is the sampling priority set by the client, if HasPriority is true.
+	Priority    int  `json:"priority"`
+	HasPriority bool `json:"has_priority"`
+	// Decision is the outcome of the sampling, and Sampler the name of the
+	// component which took it.
+	Decision Decision `json:"decision"`
+	Sampler  string   `json:"sampler"`
+	// Spans holds the spans of the trace.
+	Spans pb.Trace `json:"spans"`
+}
+
+// Filter selects records. Empty fields match any record.
+type Filter struct {
+	Service  string
+	Resource string
+	TraceID  uint64
+}
+
+// Match returns true if the record r satisfies the filter.
+func (f Filter) Match(r *Record) bool {
+	if f.TraceID != 0 && f.TraceID != r.TraceID {
+		return false
+	}
+	if f.Service != "" && !r.hasService(f.Service) {
+		return false
+	}
+	if f.Resource != "" && f.Resource != r.Resource {
+		return false
+	}
+	return true
+}
+
+// hasService returns true if any of the spans of the record belongs to service.
+func (r *Record) hasService(service string) bool {
+	if r.Service == service {
+		return true
+	}
+	for _, s := range r.Spans {
+		if s.Service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// Buffer is a fixed size ring buffer of records. It is safe for concurrent use.
+// A nil *Buffer is valid and discards everything which is added to it.
+type Buffer struct {
+	mu      sync.RWMutex
+	records []Record
+	next    int    // index of the next slot to write to
+	seq     uint64 // sequence number of the last record added
+}
+
+// NewBuffer returns a buffer keeping the last size records. It returns nil
+// when size is not positive, which disables recording.
+func NewBuffer(size int) *Buffer {
+	if size <= 0 {
+		return nil
+	}
+	return &Buffer{records: make([]Record, 0, size)}
+}
+
+// Add adds r to the buffer, evicting the oldest record if the buffer is full.
+// The sequence number of r is assigned by the buffer.
+func (b *Buffer) Add(r Record) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	r.Seq = b.seq
+	if len(b.records) < cap(b.records) {
+		b.records = append(b.records, r)
+		return
+	}
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+}
+
+// Since returns, oldest first, the records with a sequence number greater than
+// seq matching f. When limit is positive, only the most recent limit records
+// are returned. The second return value is the sequence number of the last
+// record added to the buffer, to be used as seq in subsequent calls.
+func (b *Buffer) Since(seq uint64, f Filter, limit int) ([]Record, uint64) {
+	if b == nil {
+		return nil, 0
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var out []Record
+	n := len(b.records)
+	for i := 0; i < n; i++ {
+		r := &b.records[(b.next+i)%n]
+		if r.Seq <= seq || !f.Match(r) {
+			continue
+		}
+		out = append(out, *r)
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out, b.seq
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package inspect
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+func testRecord(traceID uint64, service, resource string) Record {
+	return Record{
+		TraceID:  traceID,
+		Service:  service,
+		Resource: resource,
+		Decision: DecisionKept,
+		Sampler:  "priority",
+		Spans: pb.Trace{
+			{TraceID: traceID, SpanID: 1, Service: service, Name: "http.request", Resource: resource, Duration: 100},
+			{TraceID: traceID, SpanID: 2, ParentID: 1, Service: "db", Name: "sql.query", Resource: "SELECT", Start: 10, Duration: 20},
+			{TraceID: traceID, SpanID: 3, ParentID: 2, Service: "db", Name: "sql.fetch", Start: 12, Duration: 5, Error: 1},
+		},
+	}
+}
+
+func TestBuffer(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		b := NewBuffer(0)
+		assert.Nil(t, b)
+		b.Add(testRecord(1, "web", "GET /"))
+		records, last := b.Since(0, Filter{}, 0)
+		assert.Empty(t, records)
+		assert.EqualValues(t, 0, last)
+	})
+
+	t.Run("eviction", func(t *testing.T) {
+		assert := assert.New(t)
+		b := NewBuffer(3)
+		for i := uint64(1); i <= 5; i++ {
+			b.Add(testRecord(i, "web", "GET /"))
+		}
+		records, last := b.Since(0, Filter{}, 0)
+		assert.EqualValues(5, last)
+		assert.Len(records, 3)
+		for i, r := range records {
+			assert.EqualValues(i+3, r.Seq)
+			assert.EqualValues(i+3, r.TraceID)
+		}
+
+		records, _ = b.Since(4, Filter{}, 0)
+		assert.Len(records, 1)
+		assert.EqualValues(5, records[0].TraceID)
+
+		records, _ = b.Since(0, Filter{}, 2)
+		assert.Len(records, 2)
+		assert.EqualValues(4, records[0].TraceID)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		assert := assert.New(t)
+		b := NewBuffer(10)
+		b.Add(testRecord(1, "web", "GET /"))
+		b.Add(testRecord(2, "api", "POST /users"))
+		b.Add(testRecord(3, "web", "GET /users"))
+
+		records, _ := b.Since(0, Filter{Service: "web"}, 0)
+		assert.Len(records, 2)
+		records, _ = b.Since(0, Filter{Service: "db"}, 0)
+		assert.Len(records, 3, "non-root spans should match too")
+		records, _ = b.Since(0, Filter{Service: "web", Resource: "GET /users"}, 0)
+		assert.Len(records, 1)
+		records, _ = b.Since(0, Filter{TraceID: 2}, 0)
+		assert.Len(records, 1)
+		assert.Equal("api", records[0].Service)
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBuffer(10)
+	b.Add(testRecord(1, "web", "GET /"))
+	b.Add(testRecord(2, "api", "POST /users"))
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	resp, err := c.Fetch(Filter{Service: "api"}, 0, 0)
+	assert.NoError(err)
+	assert.EqualValues(2, resp.LastSeq)
+	assert.Len(resp.Records, 1)
+	assert.EqualValues(2, resp.Records[0].TraceID)
+	assert.Len(resp.Records[0].Spans, 3)
+
+	resp, err = c.Fetch(Filter{}, 2, 0)
+	assert.NoError(err)
+	assert.Empty(resp.Records)
+
+	var disabled *Buffer
+	srv2 := httptest.NewServer(disabled)
+	defer srv2.Close()
+	_, err = NewClient(srv2.URL).Fetch(Filter{}, 0, 0)
+	assert.Error(err)
+}
+
+func TestPrint(t *testing.T) {
+	r := testRecord(42, "web", "GET /")
+	r.Seq = 7
+	var buf bytes.Buffer
+	Print(&buf, &r)
+	out := buf.String()
+	assert.Contains(t, out, "#7 ")
+	assert.Contains(t, out, "trace_id=42")
+	assert.Contains(t, out, "kept by priority (3 spans)")
+	assert.Contains(t, out, "\n  - web http.request \"GET /\" 100ns\n")
+	assert.Contains(t, out, "\n    - db sql.query \"SELECT\" 20ns\n")
+	assert.Contains(t, out, "\n      ! db sql.fetch \"\" 5ns\n")
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package inspect
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+// Path is the path of the inspection endpoint on the debug server.
+const Path = "/debug/traces"
+
+// Run implements the `inspect` subcommand: it queries a running trace-agent
+// and prints the traces it recently processed. args are the command line
+// arguments following the subcommand name.
+func Run(w io.Writer, conf *config.AgentConfig, args []string) error {
+	var (
+		f        Filter
+		follow   bool
+		asJSON   bool
+		limit    int
+		interval time.Duration
+	)
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	fs.StringVar(&f.Service, "service", "", "Only show traces containing spans from this service")
+	fs.StringVar(&f.Resource, "resource", "", "Only show traces with this root resource")
+	fs.Uint64Var(&f.TraceID, "trace-id", 0, "Only show the trace with this ID")
+	fs.BoolVar(&follow, "f", false, "Keep polling the agent and print new traces as they come in")
+	fs.BoolVar(&asJSON, "json", false, "Print records as JSON instead of span trees")
+	fs.IntVar(&limit, "n", 10, "Number of most recent traces to print initially (0 for all)")
+	fs.DurationVar(&interval, "interval", time.Second, "Polling interval when following")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	c := NewClient(fmt.Sprintf("http://127.0.0.1:%d%s", conf.DebugServerPort, Path))
+	var since uint64
+	for {
+		resp, err := c.Fetch(f, since, limit)
+		if err != nil {
+			return err
+		}
+		for i := range resp.Records {
+			if err := write(w, &resp.Records[i], asJSON); err != nil {
+				return err
+			}
+		}
+		if !follow {
+			return nil
+		}
+		since = resp.LastSeq
+		limit = 0
+		time.Sleep(interval)
+	}
+}
+
+func write(w io.Writer, r *Record, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(r)
+	}
+	Print(w, r)
+	return nil
+}
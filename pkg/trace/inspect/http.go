@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package inspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Response is the payload returned by the inspection endpoint.
+type Response struct {
+	// LastSeq is the sequence number of the most recent record in the buffer.
+	LastSeq uint64   `json:"last_seq"`
+	Records []Record `json:"records"`
+}
+
+// ServeHTTP serves the content of the buffer as JSON. The following query
+// string parameters are supported: service, resource, trace_id, since (only
+// return records with a greater sequence number) and limit.
+func (b *Buffer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if b == nil {
+		http.Error(w, "trace inspection is disabled, set apm_config.inspect_buffer_size to enable it", http.StatusNotFound)
+		return
+	}
+	f, since, limit, err := parseQuery(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	records, last := b.Since(since, f, limit)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(Response{LastSeq: last, Records: records}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func parseQuery(q url.Values) (f Filter, since uint64, limit int, err error) {
+	f.Service = q.Get("service")
+	f.Resource = q.Get("resource")
+	if v := q.Get("trace_id"); v != "" {
+		if f.TraceID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return f, 0, 0, fmt.Errorf("trace_id must be an unsigned integer: %v", err)
+		}
+	}
+	if v := q.Get("since"); v != "" {
+		if since, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return f, 0, 0, fmt.Errorf("since must be an unsigned integer: %v", err)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			return f, 0, 0, fmt.Errorf("limit must be an integer: %v", err)
+		}
+	}
+	return f, since, limit, nil
+}
+
+// Client queries the inspection endpoint of a running trace-agent.
+type Client struct {
+	// URL is the address of the inspection endpoint.
+	URL string
+
+	http http.Client
+}
+
+// NewClient returns a client querying the endpoint at the given URL.
+func NewClient(url string) *Client {
+	return &Client{
+		URL:  url,
+		http: http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Fetch returns the records matching f with a sequence number greater than since.
+func (c *Client) Fetch(f Filter, since uint64, limit int) (*Response, error) {
+	q := url.Values{}
+	if f.Service != "" {
+		q.Set("service", f.Service)
+	}
+	if f.Resource != "" {
+		q.Set("resource", f.Resource)
+	}
+	if f.TraceID != 0 {
+		q.Set("trace_id", strconv.FormatUint(f.TraceID, 10))
+	}
+	if since > 0 {
+		q.Set("since", strconv.FormatUint(since, 10))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	resp, err := c.http.Get(c.URL + "?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s returned %s: %s", c.URL, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var out Response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// Print writes a human readable representation of r to w, with its spans
+// laid out as a tree.
+func Print(w io.Writer, r *Record) {
+	priority := "none"
+	if r.HasPriority {
+		priority = fmt.Sprintf("%d", r.Priority)
+	}
+	fmt.Fprintf(w, "#%d %s trace_id=%d service=%q resource=%q priority=%s %s by %s (%d spans)\n",
+		r.Seq, r.Received.Format(time.RFC3339), r.TraceID, r.Service, r.Resource,
+		priority, r.Decision, r.Sampler, len(r.Spans))
+
+	children := traceutil.ChildrenMap(r.Spans)
+	byID := make(map[uint64]struct{}, len(r.Spans))
+	for _, s := range r.Spans {
+		byID[s.SpanID] = struct{}{}
+	}
+	var roots []*pb.Span
+	for _, s := range r.Spans {
+		// spans with an unknown parent are displayed as roots too, which
+		// happens with partial or distributed traces.
+		if _, ok := byID[s.ParentID]; s.ParentID == 0 || !ok {
+			roots = append(roots, s)
+		}
+	}
+	sortByStart(roots)
+	for _, s := range roots {
+		printSpan(w, s, children, 1)
+	}
+}
+
+func printSpan(w io.Writer, s *pb.Span, children map[uint64][]*pb.Span, depth int) {
+	marker := "-"
+	if s.Error != 0 {
+		marker = "!"
+	}
+	fmt.Fprintf(w, "%s%s %s %s %q %s\n", strings.Repeat("  ", depth), marker, s.Service, s.Name, s.Resource, time.Duration(s.Duration))
+	kids := children[s.SpanID]
+	sortByStart(kids)
+	for _, c := range kids {
+		printSpan(w, c, children, depth+1)
+	}
+}
+
+func sortByStart(spans []*pb.Span) {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+}
@@ -0,0 +1,300 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package pb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// dictionarySpanFields is the number of elements in a span of a v0.5 payload.
+const dictionarySpanFields = 12
+
+// maxPreallocSize is the maximum number of elements allocated upfront for an
+// array or a map of a v0.5 payload. Their size comes from the payload, bigger
+// ones grow as their elements are decoded, so that a small payload announcing
+// a huge array can't force a huge allocation.
+const maxPreallocSize = 1024
+
+// preallocSize returns the number of elements to allocate upfront for an
+// array or a map of sz elements.
+func preallocSize(sz uint32) int {
+	if sz > maxPreallocSize {
+		return maxPreallocSize
+	}
+	return int(sz)
+}
+
+// DecodeMsgDictionary decodes a v0.5 payload. Such a payload is an array of two
+// elements: a string table, followed by the traces. Each span is encoded as an
+// array of 12 elements, in this order:
+//
+//   service (string index), name (string index), resource (string index),
+//   trace_id, span_id, parent_id, start, duration, error,
+//   meta (map of string index to string index),
+//   metrics (map of string index to float64), type (string index)
+//
+// All strings are held by the table and shared by the decoded spans, which
+// avoids allocating each occurrence of a service name or tag key separately.
+func (t *Traces) DecodeMsgDictionary(dc *msgp.Reader) error {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if sz != 2 {
+		return fmt.Errorf("v0.5 payload: expected an array of 2 elements, got %d", sz)
+	}
+	dict, err := decodeStringTable(dc)
+	if err != nil {
+		return err
+	}
+	ntraces, err := dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if cap(*t) >= preallocSize(ntraces) {
+		*t = (*t)[:0]
+	} else {
+		*t = make(Traces, 0, preallocSize(ntraces))
+	}
+	for i := uint32(0); i < ntraces; i++ {
+		nspans, err := dc.ReadArrayHeader()
+		if err != nil {
+			return err
+		}
+		trace := make(Trace, 0, preallocSize(nspans))
+		for j := uint32(0); j < nspans; j++ {
+			span := &Span{}
+			if err := span.decodeMsgDictionary(dc, dict); err != nil {
+				return err
+			}
+			trace = append(trace, span)
+		}
+		*t = append(*t, trace)
+	}
+	return nil
+}
+
+// decodeStringTable reads the string table at the beginning of a v0.5 payload.
+func decodeStringTable(dc *msgp.Reader) ([]string, error) {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return nil, err
+	}
+	dict := make([]string, 0, preallocSize(sz))
+	for i := uint32(0); i < sz; i++ {
+		str, err := parseString(dc)
+		if err != nil {
+			return nil, err
+		}
+		dict = append(dict, str)
+	}
+	return dict, nil
+}
+
+// errInvalidStringIndex is returned when a span references a string which is
+// not in the table.
+var errInvalidStringIndex = errors.New("string index out of range")
+
+// parseStringIndex reads an index in the string table and returns the
+// corresponding string.
+func parseStringIndex(dc *msgp.Reader, dict []string) (string, error) {
+	idx, err := parseUint64(dc)
+	if err != nil {
+		return "", err
+	}
+	if idx >= uint64(len(dict)) {
+		return "", errInvalidStringIndex
+	}
+	return dict[idx], nil
+}
+
+func (z *Span) decodeMsgDictionary(dc *msgp.Reader, dict []string) error {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if sz != dictionarySpanFields {
+		return fmt.Errorf("expected an array of %d elements, got %d", dictionarySpanFields, sz)
+	}
+	if z.Service, err = parseStringIndex(dc, dict); err != nil {
+		return err
+	}
+	if z.Name, err = parseStringIndex(dc, dict); err != nil {
+		return err
+	}
+	if z.Resource, err = parseStringIndex(dc, dict); err != nil {
+		return err
+	}
+	if z.TraceID, err = parseUint64(dc); err != nil {
+		return err
+	}
+	if z.SpanID, err = parseUint64(dc); err != nil {
+		return err
+	}
+	if z.ParentID, err = parseUint64(dc); err != nil {
+		return err
+	}
+	if z.Start, err = parseInt64(dc); err != nil {
+		return err
+	}
+	if z.Duration, err = parseInt64(dc); err != nil {
+		return err
+	}
+	if z.Error, err = parseInt32(dc); err != nil {
+		return err
+	}
+	nmeta, err := dc.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	if nmeta > 0 {
+		z.Meta = make(map[string]string, preallocSize(nmeta))
+	}
+	for i := uint32(0); i < nmeta; i++ {
+		k, err := parseStringIndex(dc, dict)
+		if err != nil {
+			return err
+		}
+		v, err := parseStringIndex(dc, dict)
+		if err != nil {
+			return err
+		}
+		z.Meta[k] = v
+	}
+	nmetrics, err := dc.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	if nmetrics > 0 {
+		z.Metrics = make(map[string]float64, preallocSize(nmetrics))
+	}
+	for i := uint32(0); i < nmetrics; i++ {
+		k, err := parseStringIndex(dc, dict)
+		if err != nil {
+			return err
+		}
+		v, err := parseFloat64(dc)
+		if err != nil {
+			return err
+		}
+		z.Metrics[k] = v
+	}
+	if z.Type, err = parseStringIndex(dc, dict); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EncodeMsgDictionary encodes t as a v0.5 payload, as described in
+// DecodeMsgDictionary. It is the counterpart of what tracers send and is
+// mostly useful for testing.
+func (t Traces) EncodeMsgDictionary(en *msgp.Writer) error {
+	var (
+		dict  []string
+		index = make(map[string]uint32)
+	)
+	ref := func(s string) uint32 {
+		if i, ok := index[s]; ok {
+			return i
+		}
+		i := uint32(len(dict))
+		index[s] = i
+		dict = append(dict, s)
+		return i
+	}
+	// the string table comes first in the payload, so spans are
+	// walked once beforehand to build it.
+	for _, trace := range t {
+		for _, s := range trace {
+			ref(s.Service)
+			ref(s.Name)
+			ref(s.Resource)
+			for k, v := range s.Meta {
+				ref(k)
+				ref(v)
+			}
+			for k := range s.Metrics {
+				ref(k)
+			}
+			ref(s.Type)
+		}
+	}
+	if err := en.WriteArrayHeader(2); err != nil {
+		return err
+	}
+	if err := en.WriteArrayHeader(uint32(len(dict))); err != nil {
+		return err
+	}
+	for _, s := range dict {
+		if err := en.WriteString(s); err != nil {
+			return err
+		}
+	}
+	if err := en.WriteArrayHeader(uint32(len(t))); err != nil {
+		return err
+	}
+	for _, trace := range t {
+		if err := en.WriteArrayHeader(uint32(len(trace))); err != nil {
+			return err
+		}
+		for _, s := range trace {
+			if err := s.encodeMsgDictionary(en, ref); err != nil {
+				return err
+			}
+		}
+	}
+	return en.Flush()
+}
+
+func (z *Span) encodeMsgDictionary(en *msgp.Writer, ref func(string) uint32) error {
+	if err := en.WriteArrayHeader(dictionarySpanFields); err != nil {
+		return err
+	}
+	for _, s := range []string{z.Service, z.Name, z.Resource} {
+		if err := en.WriteUint32(ref(s)); err != nil {
+			return err
+		}
+	}
+	for _, v := range []uint64{z.TraceID, z.SpanID, z.ParentID} {
+		if err := en.WriteUint64(v); err != nil {
+			return err
+		}
+	}
+	for _, v := range []int64{z.Start, z.Duration} {
+		if err := en.WriteInt64(v); err != nil {
+			return err
+		}
+	}
+	if err := en.WriteInt32(z.Error); err != nil {
+		return err
+	}
+	if err := en.WriteMapHeader(uint32(len(z.Meta))); err != nil {
+		return err
+	}
+	for k, v := range z.Meta {
+		if err := en.WriteUint32(ref(k)); err != nil {
+			return err
+		}
+		if err := en.WriteUint32(ref(v)); err != nil {
+			return err
+		}
+	}
+	if err := en.WriteMapHeader(uint32(len(z.Metrics))); err != nil {
+		return err
+	}
+	for k, v := range z.Metrics {
+		if err := en.WriteUint32(ref(k)); err != nil {
+			return err
+		}
+		if err := en.WriteFloat64(v); err != nil {
+			return err
+		}
+	}
+	return en.WriteUint32(ref(z.Type))
+}
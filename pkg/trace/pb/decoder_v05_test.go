@@ -0,0 +1,151 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package pb
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// dictionaryTestTraces returns n traces of size spans each, with the kind of
+// repetition found in real payloads.
+func dictionaryTestTraces(n, size int) Traces {
+	traces := make(Traces, n)
+	for i := range traces {
+		trace := make(Trace, size)
+		for j := range trace {
+			trace[j] = &Span{
+				Service:  fmt.Sprintf("service-%d", j%3),
+				Name:     "http.request",
+				Resource: fmt.Sprintf("GET /users/%d", j%5),
+				TraceID:  uint64(i + 1),
+				SpanID:   uint64(j + 1),
+				ParentID: uint64(j),
+				Start:    1548931840954169000 + int64(j),
+				Duration: 100200,
+				Error:    int32(j % 2),
+				Meta: map[string]string{
+					"http.method": "GET",
+					"http.url":    fmt.Sprintf("/users/%d", j%5),
+					"env":         "prod",
+				},
+				Metrics: map[string]float64{
+					"_sampling_priority_v1": 1,
+					"_dd.measured":          float64(j % 2),
+				},
+				Type: "web",
+			}
+		}
+		traces[i] = trace
+	}
+	return traces
+}
+
+func encodeDictionary(t testing.TB, traces Traces) []byte {
+	var buf bytes.Buffer
+	en := msgp.NewWriter(&buf)
+	if err := traces.EncodeMsgDictionary(en); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeMsgDictionary(t *testing.T) {
+	t.Run("roundtrip", func(t *testing.T) {
+		assert := assert.New(t)
+		want := dictionaryTestTraces(3, 7)
+		var got Traces
+		err := got.DecodeMsgDictionary(msgp.NewReader(bytes.NewReader(encodeDictionary(t, want))))
+		assert.NoError(err)
+		assert.Equal(want, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var got Traces
+		err := got.DecodeMsgDictionary(msgp.NewReader(bytes.NewReader(encodeDictionary(t, Traces{}))))
+		assert.NoError(t, err)
+		assert.Len(t, got, 0)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for name, payload := range map[string][]byte{
+			"not an array": {0x2a},
+			"wrong size":   {0x91, 0x90},
+			// ["a"], [[[5, ...]]]: service index 5 is out of range
+			"bad index": {0x92, 0x91, 0xa1, 'a', 0x91, 0x91, 0x9c, 0x05},
+			// ["a"], [[[0]]]: span has a single element
+			"short span": {0x92, 0x91, 0xa1, 'a', 0x91, 0x91, 0x91, 0x00},
+			"truncated":  encodeDictionary(t, dictionaryTestTraces(1, 2))[:40],
+			// sizes announced by the headers are only allocated as the
+			// elements are decoded
+			"huge string table": {0x92, 0xdd, 0xff, 0xff, 0xff, 0xff},
+			"huge traces":       {0x92, 0x90, 0xdd, 0xff, 0xff, 0xff, 0xff},
+			"huge trace":        {0x92, 0x90, 0x91, 0xdd, 0xff, 0xff, 0xff, 0xff},
+		} {
+			t.Run(name, func(t *testing.T) {
+				var got Traces
+				assert.Error(t, got.DecodeMsgDictionary(msgp.NewReader(bytes.NewReader(payload))))
+			})
+		}
+	})
+
+	t.Run("string reuse", func(t *testing.T) {
+		var got Traces
+		err := got.DecodeMsgDictionary(msgp.NewReader(bytes.NewReader(encodeDictionary(t, dictionaryTestTraces(1, 2)))))
+		assert.NoError(t, err)
+		// both spans share the same underlying string, not a copy of it
+		assert.True(t, stringDataPtr(got[0][0].Name) == stringDataPtr(got[0][1].Name))
+	})
+}
+
+func stringDataPtr(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func BenchmarkDecodeMsgDictionary(b *testing.B) {
+	for _, size := range []int{1, 10, 100} {
+		traces := dictionaryTestTraces(10, size)
+		v04 := encodeV04(b, traces)
+		v05 := encodeDictionary(b, traces)
+
+		b.Run(fmt.Sprintf("v0.4/%d", size), func(b *testing.B) {
+			b.SetBytes(int64(len(v04)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var t Traces
+				if err := msgp.Decode(bytes.NewReader(v04), &t); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("v0.5/%d", size), func(b *testing.B) {
+			b.SetBytes(int64(len(v05)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var t Traces
+				if err := t.DecodeMsgDictionary(msgp.NewReader(bytes.NewReader(v05))); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func encodeV04(t testing.TB, traces Traces) []byte {
+	var buf bytes.Buffer
+	if err := msgp.Encode(&buf, traces); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -239,6 +239,17 @@ func getInformerFactoryWithOption(options informers.SharedInformerOption) (infor
 	return informers.NewSharedInformerFactoryWithOptions(client, resyncPeriodSeconds*time.Second, options), nil
 }
 
+// NewInformerFactoryWithOptions returns a new informer factory, not shared with the APIClient,
+// for components that need informers filtered on namespace or labels and start them on their own.
+func NewInformerFactoryWithOptions(options ...informers.SharedInformerOption) (informers.SharedInformerFactory, error) {
+	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
+	client, err := getKubeClient(0) // No timeout for the Informers, to allow long watch.
+	if err != nil {
+		return nil, fmt.Errorf("could not get apiserver client: %v", err)
+	}
+	return informers.NewSharedInformerFactoryWithOptions(client, resyncPeriodSeconds*time.Second, options...), nil
+}
+
 func (c *APIClient) connect() error {
 	var err error
 	c.Cl, err = getKubeClient(time.Duration(c.timeoutSeconds) * time.Second)
@@ -44,7 +44,7 @@ type DatadogClient interface {
 // ProcessorInterface is used to easily mock the interface for testing
 type ProcessorInterface interface {
 	UpdateExternalMetrics(emList map[string]custommetrics.ExternalMetricValue) map[string]custommetrics.ExternalMetricValue
-	QueryExternalMetric(queries []string) (map[string]Point, error)
+	QueryExternalMetric(queries []string, timeWindow time.Duration) (map[string]Point, error)
 	ProcessEMList(emList []custommetrics.ExternalMetricValue) map[string]custommetrics.ExternalMetricValue
 }
 
@@ -128,7 +128,7 @@ func (p *Processor) UpdateExternalMetrics(emList map[string]custommetrics.Extern
 		batch = append(batch, q)
 	}
 
-	metrics, err := p.QueryExternalMetric(batch)
+	metrics, err := p.QueryExternalMetric(batch, 0)
 	if len(metrics) == 0 && err != nil {
 		log.Errorf("Error getting metrics from Datadog: %v", err.Error())
 		// If no metrics can be retrieved from Datadog in a given list, we need to invalidate them
@@ -158,15 +158,19 @@ func (p *Processor) UpdateExternalMetrics(emList map[string]custommetrics.Extern
 	return updated
 }
 
-// queryExternalMetric queries Datadog to validate the availability and value of one or more external metrics
+// QueryExternalMetric queries Datadog to validate the availability and value of one or more external metrics
+// over the last `timeWindow`, defaulting to `external_metrics_provider.bucket_size` when it's not set.
 // Also updates the rate limits statistics as a result of the query.
-func (p *Processor) QueryExternalMetric(queries []string) (processed map[string]Point, err error) {
+func (p *Processor) QueryExternalMetric(queries []string, timeWindow time.Duration) (processed map[string]Point, err error) {
 	processed = make(map[string]Point)
 	if len(queries) == 0 {
 		return processed, nil
 	}
 
 	bucketSize := config.Datadog.GetInt64("external_metrics_provider.bucket_size")
+	if timeWindow > 0 {
+		bucketSize = int64(timeWindow.Seconds())
+	}
 	chunks := makeChunks(queries)
 	log.Tracef("List of batches %v", chunks)
 
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
 
 	"github.com/stretchr/testify/assert"
@@ -348,7 +349,7 @@ func TestValidateExternalMetricsBatching(t *testing.T) {
 			}
 			p := &Processor{datadogClient: datadogClient}
 
-			_, err := p.QueryExternalMetric(tt.in)
+			_, err := p.QueryExternalMetric(tt.in, 0)
 			if err != nil || tt.err != nil {
 				assert.Contains(t, err.Error(), tt.err.Error())
 			}
@@ -357,6 +358,42 @@ func TestValidateExternalMetricsBatching(t *testing.T) {
 	}
 }
 
+func TestQueryExternalMetricTimeWindow(t *testing.T) {
+	bucketSize := config.Datadog.GetInt64("external_metrics_provider.bucket_size")
+	tests := []struct {
+		desc       string
+		timeWindow time.Duration
+		expected   int64
+	}{
+		{
+			desc:       "default time window",
+			timeWindow: 0,
+			expected:   bucketSize,
+		},
+		{
+			desc:       "custom time window",
+			timeWindow: 15 * time.Minute,
+			expected:   900,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("#%d %s", i, tt.desc), func(t *testing.T) {
+			var window int64
+			datadogClient := &fakeDatadogClient{
+				queryMetricsFunc: func(from, to int64, query string) ([]datadog.Series, error) {
+					window = to - from
+					return nil, nil
+				},
+			}
+			p := &Processor{datadogClient: datadogClient}
+
+			p.QueryExternalMetric([]string{"avg:foo{*}"}, tt.timeWindow)
+			// The bounds of the window are computed from two calls to time.Now()
+			assert.InDelta(t, tt.expected, window, 1)
+		})
+	}
+}
+
 func lambdaMakeChunks(numChunks int, chunkToExpand custommetrics.ExternalMetricValue) []string {
 	expanded := make([]string, 0, numChunks)
 	for i := 0; i <= numChunks; i++ {
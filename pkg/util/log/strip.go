@@ -17,6 +17,7 @@ import (
 
 // Replacer structure to store regex matching and replacement functions
 type Replacer struct {
+	Name     string // Name of the replacer in the scrubbing reports
 	Regex    *regexp.Regexp
 	Hints    []string // If any of these hints do not exist in the line, then we know the regex wont match either
 	Repl     []byte
@@ -29,35 +30,42 @@ var singleLineReplacers, multiLineReplacers []Replacer
 
 func init() {
 	apiKeyReplacer := Replacer{
+		Name:  "api_key",
 		Regex: regexp.MustCompile(`\b[a-fA-F0-9]{27}([a-fA-F0-9]{5})\b`),
 		Repl:  []byte(`***************************$1`),
 	}
 	appKeyReplacer := Replacer{
+		Name:  "app_key",
 		Regex: regexp.MustCompile(`\b[a-fA-F0-9]{35}([a-fA-F0-9]{5})\b`),
 		Repl:  []byte(`***********************************$1`),
 	}
 	// URI Generic Syntax
 	// https://tools.ietf.org/html/rfc3986
 	uriPasswordReplacer := Replacer{
+		Name:  "uri_password",
 		Regex: regexp.MustCompile(`([A-Za-z][A-Za-z0-9+-.]+\:\/\/|\b)([^\:]+)\:([^\s]+)\@`),
 		Repl:  []byte(`$1$2:********@`),
 	}
 	passwordReplacer := Replacer{
+		Name:  "password",
 		Regex: matchYAMLKeyPart(`(pass(word)?|pwd)`),
 		Hints: []string{"pass", "pwd"},
 		Repl:  []byte(`$1 ********`),
 	}
 	tokenReplacer := Replacer{
+		Name:  "token",
 		Regex: matchYAMLKeyEnding(`token`),
 		Hints: []string{"token"},
 		Repl:  []byte(`$1 ********`),
 	}
 	snmpReplacer := Replacer{
+		Name:  "snmp",
 		Regex: matchYAMLKey(`(community_string|authKey|privKey)`),
 		Hints: []string{"community_string", "authKey", "privKey"},
 		Repl:  []byte(`$1 ********`),
 	}
 	certReplacer := Replacer{
+		Name:  "certificate",
 		Regex: matchCert(),
 		Hints: []string{"BEGIN"},
 		Repl:  []byte(`********`),
@@ -91,16 +99,43 @@ func matchCert() *regexp.Regexp {
 	)
 }
 
+// NewYAMLKeysReplacer returns a replacer scrubbing the values of the given
+// YAML keys, which are matched literally
+func NewYAMLKeysReplacer(name string, keys []string) Replacer {
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+	return Replacer{
+		Name:  name,
+		Regex: matchYAMLKey(fmt.Sprintf("(%s)", strings.Join(quoted, "|"))),
+		Hints: keys,
+		Repl:  []byte(`$1 ********`),
+	}
+}
+
 // AddStrippedKeys allows configuration keys cleaned up
 func AddStrippedKeys(strippedKeys []string) {
 	if len(strippedKeys) > 0 {
-		configReplacer := Replacer{
-			Regex: matchYAMLKey(fmt.Sprintf("(%s)", strings.Join(strippedKeys, "|"))),
-			Hints: strippedKeys,
-			Repl:  []byte(`$1 ********`),
+		singleLineReplacers = append(singleLineReplacers, NewYAMLKeysReplacer("stripped_keys", strippedKeys))
+	}
+}
+
+// Replace applies the replacer to b. If counts isn't nil, the number of
+// replacements is added to the entry of the replacer.
+func (r Replacer) Replace(b []byte, counts map[string]int) []byte {
+	if r.Regex == nil {
+		return b
+	}
+	if counts != nil {
+		if n := len(r.Regex.FindAllIndex(b, -1)); n > 0 {
+			counts[r.Name] += n
 		}
-		singleLineReplacers = append(singleLineReplacers, configReplacer)
 	}
+	if r.ReplFunc != nil {
+		return r.Regex.ReplaceAllFunc(b, r.ReplFunc)
+	}
+	return r.Regex.ReplaceAll(b, r.Repl)
 }
 
 // CredentialsCleanerFile scrubs credentials from file in path
@@ -110,16 +145,24 @@ func CredentialsCleanerFile(filePath string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return credentialsCleaner(file)
+	return credentialsCleaner(file, nil)
 }
 
 // CredentialsCleanerBytes scrubs credentials from slice of bytes
 func CredentialsCleanerBytes(file []byte) ([]byte, error) {
 	r := bytes.NewReader(file)
-	return credentialsCleaner(r)
+	return credentialsCleaner(r, nil)
 }
 
-func credentialsCleaner(file io.Reader) ([]byte, error) {
+// CredentialsCleanerBytesWithCounts scrubs credentials from slice of bytes,
+// and returns the number of replacements made by each replacer, by name
+func CredentialsCleanerBytesWithCounts(file []byte) ([]byte, map[string]int, error) {
+	counts := make(map[string]int)
+	cleaned, err := credentialsCleaner(bytes.NewReader(file), counts)
+	return cleaned, counts, err
+}
+
+func credentialsCleaner(file io.Reader, counts map[string]int) ([]byte, error) {
 	var cleanedFile []byte
 
 	scanner := bufio.NewScanner(file)
@@ -139,11 +182,7 @@ func credentialsCleaner(file io.Reader) ([]byte, error) {
 					}
 				}
 				if len(repl.Hints) == 0 || containsHint {
-					if repl.ReplFunc != nil {
-						b = repl.Regex.ReplaceAllFunc(b, repl.ReplFunc)
-					} else {
-						b = repl.Regex.ReplaceAll(b, repl.Repl)
-					}
+					b = repl.Replace(b, counts)
 				}
 			}
 			if !first {
@@ -169,11 +208,7 @@ func credentialsCleaner(file io.Reader) ([]byte, error) {
 			}
 		}
 		if len(repl.Hints) == 0 || containsHint {
-			if repl.ReplFunc != nil {
-				cleanedFile = repl.Regex.ReplaceAllFunc(cleanedFile, repl.ReplFunc)
-			} else {
-				cleanedFile = repl.Regex.ReplaceAll(cleanedFile, repl.Repl)
-			}
+			cleanedFile = repl.Replace(cleanedFile, counts)
 		}
 	}
 